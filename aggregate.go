@@ -0,0 +1,210 @@
+package ezlog
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// defaultMaxErrorGroups is the default number of groups an ErrorAggregator
+// tracks between flushes.
+const defaultMaxErrorGroups = 100
+
+// defaultErrorFlushInterval is the flush interval of an ErrorAggregator
+// created with a non-positive one.
+const defaultErrorFlushInterval = time.Minute
+
+// ErrorAggregatorOption configures an ErrorAggregator.
+type ErrorAggregatorOption func(*ErrorAggregator)
+
+// WithErrorKey groups errors by key(err) instead of by root cause.
+func WithErrorKey(key func(err error) string) ErrorAggregatorOption {
+	return func(a *ErrorAggregator) {
+		a.key = key
+	}
+}
+
+// WithMaxErrorGroups sets how many groups are tracked between flushes, 100 by
+// default. Errors of new groups past the limit are only counted, and reported
+// in a single event on the next flush.
+func WithMaxErrorGroups(n int) ErrorAggregatorOption {
+	return func(a *ErrorAggregator) {
+		a.maxGroups = n
+	}
+}
+
+// ErrorAggregator collects transient errors and logs one summary per group
+// of errors at each flush, such as "dial tcp: i/o timeout ×342, first
+// 10:02:11 last 10:03:40", instead of one line per error.
+// It should be created using NewErrorAggregator.
+type ErrorAggregator struct {
+	mu        sync.Mutex
+	l         *zerolog.Logger
+	key       func(error) string
+	maxGroups int
+	groups    map[string]*errorGroup
+	order     []string
+	overflow  int
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// errorGroup is the state of a group of errors between flushes.
+type errorGroup struct {
+	cause  string
+	count  int
+	first  time.Time
+	last   time.Time
+	sample error
+	fields []any
+}
+
+// NewErrorAggregator creates an ErrorAggregator logging its summaries to l
+// every flushInterval, or every minute if flushInterval is not positive. By
+// default errors are grouped by root cause: the innermost error of their
+// Unwrap chain, compared by type and message, so fmt.Errorf wrappers with
+// different context end up in the same group.
+func NewErrorAggregator(l *zerolog.Logger, flushInterval time.Duration, opts ...ErrorAggregatorOption) *ErrorAggregator {
+	a := &ErrorAggregator{
+		l:         l,
+		key:       rootCauseKey,
+		maxGroups: defaultMaxErrorGroups,
+		groups:    make(map[string]*errorGroup),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultErrorFlushInterval
+	}
+	go a.run(flushInterval)
+	return a
+}
+
+// Record adds err to its group. fields are key/value pairs; those of the
+// first error of a group are added to its summary. A nil err is ignored.
+func (a *ErrorAggregator) Record(err error, fields ...any) {
+	if err == nil {
+		return
+	}
+	key := a.key(err)
+	now := time.Now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	g, ok := a.groups[key]
+	if !ok {
+		if a.maxGroups > 0 && len(a.groups) >= a.maxGroups {
+			a.overflow++
+			return
+		}
+		g = &errorGroup{cause: rootCause(err).Error(), first: now, fields: fields}
+		a.groups[key] = g
+		a.order = append(a.order, key)
+	}
+	g.count++
+	g.last = now
+	g.sample = err
+}
+
+// Flush logs the summaries of the current groups and starts new ones.
+func (a *ErrorAggregator) Flush() {
+	a.mu.Lock()
+	groups, order, overflow := a.groups, a.order, a.overflow
+	a.groups = make(map[string]*errorGroup)
+	a.order = nil
+	a.overflow = 0
+	a.mu.Unlock()
+
+	for _, key := range order {
+		g := groups[key]
+		a.l.Error().
+			Err(g.sample).
+			Fields(g.fields).
+			Int("count", g.count).
+			Time("first", g.first).
+			Time("last", g.last).
+			Msg(fmt.Sprintf("%s ×%d, first %s last %s",
+				g.cause, g.count, g.first.Format(time.TimeOnly), g.last.Format(time.TimeOnly)))
+	}
+	if overflow > 0 {
+		a.l.Error().Int("count", overflow).
+			Msgf("%d errors not grouped, more than %d groups", overflow, a.maxGroups)
+	}
+}
+
+// Close stops the periodic flush and flushes the remaining groups.
+func (a *ErrorAggregator) Close() error {
+	a.closeOnce.Do(func() {
+		close(a.stop)
+		<-a.done
+		a.Flush()
+	})
+	return nil
+}
+
+// run flushes every interval until the aggregator is closed.
+func (a *ErrorAggregator) run(interval time.Duration) {
+	defer close(a.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C:
+			a.Flush()
+		}
+	}
+}
+
+// rootCause returns the innermost error of err's Unwrap chain, following the
+// first error of joined errors. The depth is bounded like ErrorChain.
+func rootCause(err error) error {
+	for range maxErrorChainDepth {
+		var next error
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			next = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			if errs := u.Unwrap(); len(errs) > 0 {
+				next = errs[0]
+			}
+		}
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+	return err
+}
+
+// rootCauseKey groups errors by the type and message of their root cause.
+func rootCauseKey(err error) string {
+	cause := rootCause(err)
+	return reflect.TypeOf(cause).String() + ": " + cause.Error()
+}
+
+// SentinelErrorKey returns a key function for WithErrorKey that groups the
+// errors matching one of sentinels, as reported by errors.Is, under that
+// sentinel, and the others by root cause.
+func SentinelErrorKey(sentinels ...error) func(error) string {
+	return func(err error) string {
+		for i, sentinel := range sentinels {
+			if errors.Is(err, sentinel) {
+				return fmt.Sprintf("sentinel %d: %s", i, sentinel)
+			}
+		}
+		return rootCauseKey(err)
+	}
+}
@@ -0,0 +1,80 @@
+package ezlog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errTimeout = errors.New("i/o timeout")
+
+func TestErrorAggregatorGroupsByRootCause(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+	a := NewErrorAggregator(l, time.Hour)
+
+	a.Record(fmt.Errorf("dial db: %w", errTimeout), "host", "db1")
+	a.Record(fmt.Errorf("dial cache: %w", errTimeout), "host", "cache1")
+	a.Record(errors.New("refused"))
+	a.Record(nil)
+	a.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d events, want 2:\n%s", len(lines), buf.String())
+	}
+	for _, want := range []string{`"host":"db1"`, `"count":2`, `i/o timeout ×2`, `"error":"dial cache: i/o timeout"`} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("first summary = %s, want %s", lines[0], want)
+		}
+	}
+	if !strings.Contains(lines[1], `refused ×1`) {
+		t.Errorf("second summary = %s", lines[1])
+	}
+}
+
+func TestErrorAggregatorMaxGroups(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+	a := NewErrorAggregator(l, time.Hour, WithMaxErrorGroups(1))
+	defer a.Close()
+
+	a.Record(errors.New("a"))
+	a.Record(errors.New("b"))
+	a.Record(errors.New("c"))
+	a.Flush()
+
+	if got := buf.String(); !strings.Contains(got, "2 errors not grouped, more than 1 groups") {
+		t.Errorf("output = %s", got)
+	}
+}
+
+func TestErrorAggregatorSentinelKey(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+	a := NewErrorAggregator(l, time.Hour, WithErrorKey(SentinelErrorKey(errTimeout)))
+
+	a.Record(fmt.Errorf("read: %w", errTimeout))
+	a.Record(errors.Join(errors.New("other"), errTimeout))
+	a.Close()
+
+	if got := strings.Count(buf.String(), "\n"); got != 1 {
+		t.Errorf("got %d summaries, want 1:\n%s", got, buf.String())
+	}
+}
+
+func TestErrorAggregatorNonPositiveInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		var buf bytes.Buffer
+		l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+		a := NewErrorAggregator(l, interval)
+		a.Record(errTimeout)
+		a.Close()
+		if !strings.Contains(buf.String(), "i/o timeout ×1") {
+			t.Errorf("interval %v: output = %s", interval, buf.String())
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package ezlog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// AuditChainFieldName is the field holding the HMAC chain of audited events.
+const AuditChainFieldName = "chain"
+
+// auditChainMarker precedes the chain value, which is always the last field.
+var auditChainMarker = []byte(`"` + AuditChainFieldName + `":"`)
+
+// AuditOption configures an AuditWriter or VerifyAuditChain.
+type AuditOption func(*auditConfig)
+
+// auditConfig holds the settings shared by writing and verifying a chain.
+type auditConfig struct {
+	initial string
+}
+
+// WithAuditInitialValue starts the chain from prev instead of the empty
+// string, to continue a chain across process restarts or rotated files.
+// LastAuditChain reads the value to resume from.
+func WithAuditInitialValue(prev string) AuditOption {
+	return func(c *auditConfig) {
+		c.initial = prev
+	}
+}
+
+// AuditWriter appends a tamper-evident chain field to every JSON event. The
+// chain is the hex HMAC-SHA256, keyed with a secret, of the previous chain
+// value followed by the event as written by zerolog, so modifying, removing or
+// reordering lines breaks every following link.
+// It should be created using NewAuditWriter.
+type AuditWriter struct {
+	mu   sync.Mutex
+	out  io.Writer
+	key  []byte
+	prev string
+}
+
+// NewAuditWriter creates an AuditWriter writing the chained events to out.
+func NewAuditWriter(out io.Writer, key []byte, opts ...AuditOption) *AuditWriter {
+	var cfg auditConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &AuditWriter{out: out, key: key, prev: cfg.initial}
+}
+
+// Write implements io.Writer.
+func (w *AuditWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *AuditWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	event := bytes.TrimSpace(p)
+	if len(event) < 2 || event[0] != '{' || event[len(event)-1] != '}' {
+		return 0, errors.New("ezlog: audit chain needs JSON events")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	chain := auditMAC(w.key, w.prev, event)
+	line := make([]byte, 0, len(event)+len(chain)+16)
+	line = append(line, event[:len(event)-1]...)
+	if len(event) > 2 {
+		line = append(line, ',')
+	}
+	line = append(line, auditChainMarker...)
+	line = append(line, chain...)
+	line = append(line, "\"}\n"...)
+
+	if _, err := writeLevel(w.out, level, line); err != nil {
+		return 0, err
+	}
+	w.prev = chain
+	return len(p), nil
+}
+
+// Close closes the underlying writer if it implements io.Closer.
+func (w *AuditWriter) Close() error {
+	if c, ok := w.out.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// AuditChainError reports the first line of an audit log whose chain does
+// not match.
+type AuditChainError struct {
+	Line   int
+	Reason string
+}
+
+// Error implements error.
+func (e *AuditChainError) Error() string {
+	return fmt.Sprintf("ezlog: audit chain broken at line %d: %s", e.Line, e.Reason)
+}
+
+// VerifyAuditChain replays an audit log written with WithAuditChain and
+// returns an *AuditChainError for the first broken link, or nil when the
+// whole chain is intact. Blank lines are skipped.
+func VerifyAuditChain(r io.Reader, key []byte, opts ...AuditOption) error {
+	var cfg auditConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	prev := cfg.initial
+	err := scanAuditChain(r, func(n int, event []byte, chain string) error {
+		if chain == "" {
+			return &AuditChainError{Line: n, Reason: "missing chain field"}
+		}
+		if !hmac.Equal([]byte(chain), []byte(auditMAC(key, prev, event))) {
+			return &AuditChainError{Line: n, Reason: "chain mismatch"}
+		}
+		prev = chain
+		return nil
+	})
+	return err
+}
+
+// LastAuditChain returns the chain value of the last event of an audit log,
+// to resume the chain with WithAuditInitialValue after a restart.
+func LastAuditChain(r io.Reader) (string, error) {
+	var last string
+	err := scanAuditChain(r, func(n int, event []byte, chain string) error {
+		last = chain
+		return nil
+	})
+	return last, err
+}
+
+// scanAuditChain calls fn with the line number, the event without its chain
+// field and the chain value of each line of r.
+func scanAuditChain(r io.Reader, fn func(n int, event []byte, chain string) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		event, chain := splitAuditChain(line)
+		if err := fn(n, event, chain); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// splitAuditChain separates the trailing chain field from an audited event.
+// It returns the line unchanged and an empty chain when there is none.
+func splitAuditChain(line []byte) ([]byte, string) {
+	i := bytes.LastIndex(line, auditChainMarker)
+	if i < 1 || !bytes.HasSuffix(line, []byte(`"}`)) {
+		return line, ""
+	}
+	chain := string(line[i+len(auditChainMarker) : len(line)-2])
+
+	event := make([]byte, 0, i+1)
+	switch line[i-1] {
+	case ',':
+		event = append(event, line[:i-1]...)
+	case '{':
+		event = append(event, line[:i]...)
+	default:
+		return line, ""
+	}
+	return append(event, '}'), chain
+}
+
+// auditMAC returns the hex HMAC-SHA256 of prev followed by event.
+func auditMAC(key []byte, prev string, event []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(prev))
+	mac.Write(event)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WithAuditChain adds a tamper-evident chain field to every event, keyed with
+// key; see AuditWriter. The chain is computed on the JSON event as written,
+// after redaction, message transforms and field renaming, so only logs
+// written with WithJSONOutput can be checked with VerifyAuditChain.
+func (b *LogBuilder) WithAuditChain(key []byte, opts ...AuditOption) *LogBuilder {
+	b.auditKey = key
+	b.audit = opts
+	return b
+}
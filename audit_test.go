@@ -0,0 +1,115 @@
+package ezlog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAuditChainVerifies(t *testing.T) {
+	var buf bytes.Buffer
+	key := []byte("secret")
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithAuditChain(key).Build()
+	for _, msg := range []string{"one", "two", "three"} {
+		l.Info().Msg(msg)
+	}
+
+	if err := VerifyAuditChain(bytes.NewReader(buf.Bytes()), key); err != nil {
+		t.Fatalf("VerifyAuditChain: %v", err)
+	}
+	tampered := bytes.Replace(buf.Bytes(), []byte(`"two"`), []byte(`"2"`), 1)
+	var chainErr *AuditChainError
+	if err := VerifyAuditChain(bytes.NewReader(tampered), key); !errors.As(err, &chainErr) {
+		t.Errorf("VerifyAuditChain of a tampered log = %v, want an AuditChainError", err)
+	}
+}
+
+func TestAuditChainWithRedactedFields(t *testing.T) {
+	var buf bytes.Buffer
+	key := []byte("secret")
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithAuditChain(key).
+		WithRedactedFields("password").WithErrorChain().Build()
+	l.Info().Str("user", "ann").Str("password", "hunter2").Msg("login")
+	l.Error().Err(errors.New("denied")).Msg("login failed")
+
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Fatalf("password not redacted: %s", buf.String())
+	}
+	if err := VerifyAuditChain(&buf, key); err != nil {
+		t.Errorf("VerifyAuditChain: %v", err)
+	}
+}
+
+func TestAuditChainWithFieldNames(t *testing.T) {
+	var buf bytes.Buffer
+	key := []byte("secret")
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithAuditChain(key).
+		WithFieldNames(FieldNames{Message: "msg"}).Build()
+	l.Info().Msg("renamed")
+
+	if !strings.Contains(buf.String(), `"msg":"renamed"`) {
+		t.Fatalf("output = %s", buf.String())
+	}
+	if err := VerifyAuditChain(&buf, key); err != nil {
+		t.Errorf("VerifyAuditChain: %v", err)
+	}
+}
+
+func TestAuditChainPinpointsTamperedLine(t *testing.T) {
+	var buf bytes.Buffer
+	key := []byte("secret")
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithAuditChain(key).Build()
+	for i := range 5 {
+		l.Info().Int("n", i).Msg("event")
+	}
+	lines := strings.SplitAfter(buf.String(), "\n")
+
+	tests := []struct {
+		name   string
+		log    string
+		key    string
+		line   int
+		reason string
+	}{
+		{"modified", strings.Replace(buf.String(), `"n":2`, `"n":9`, 1), "secret", 3, "chain mismatch"},
+		{"removed", strings.Join(append(lines[:1:1], lines[2:]...), ""), "secret", 2, "chain mismatch"},
+		{"reordered", lines[0] + lines[2] + lines[1] + strings.Join(lines[3:], ""), "secret", 2, "chain mismatch"},
+		{"unchained", lines[0] + `{"level":"info","message":"forged"}` + "\n" + strings.Join(lines[1:], ""), "secret", 2, "missing chain field"},
+		{"wrong key", buf.String(), "other", 1, "chain mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var chainErr *AuditChainError
+			if err := VerifyAuditChain(strings.NewReader(tt.log), []byte(tt.key)); !errors.As(err, &chainErr) {
+				t.Fatalf("VerifyAuditChain = %v, want an AuditChainError", err)
+			}
+			if chainErr.Line != tt.line || chainErr.Reason != tt.reason {
+				t.Errorf("error = %v, want line %d: %s", chainErr, tt.line, tt.reason)
+			}
+		})
+	}
+}
+
+func TestAuditChainResumesAfterRestart(t *testing.T) {
+	var first, second bytes.Buffer
+	key := []byte("secret")
+	New().AsLocal().WithWriter(&first).WithJSONOutput().WithAuditChain(key).Build().Info().Msg("before")
+
+	last, err := LastAuditChain(bytes.NewReader(first.Bytes()))
+	if err != nil || last == "" {
+		t.Fatalf("LastAuditChain = %q, %v", last, err)
+	}
+	New().AsLocal().WithWriter(&second).WithJSONOutput().WithAuditChain(key, WithAuditInitialValue(last)).Build().Info().Msg("after")
+
+	if err := VerifyAuditChain(io.MultiReader(&first, bytes.NewReader(second.Bytes())), key); err != nil {
+		t.Errorf("VerifyAuditChain of both runs: %v", err)
+	}
+	if err := VerifyAuditChain(bytes.NewReader(second.Bytes()), key, WithAuditInitialValue(last)); err != nil {
+		t.Errorf("VerifyAuditChain of the second run: %v", err)
+	}
+	if err := VerifyAuditChain(&second, key); err == nil {
+		t.Error("the second run verifies without the initial value")
+	}
+}
@@ -0,0 +1,111 @@
+package ezlog
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errBatcherClosed is returned when writing to a closed batcher.
+var errBatcherClosed = errors.New("ezlog: writer is closed")
+
+// batchItem is a queued event with the time it was written.
+type batchItem struct {
+	at time.Time
+	p  []byte
+}
+
+// batcher queues events and hands them to a flush function in batches, when
+// a batch is full or when the flush interval elapses. Events written while
+// the queue is full are dropped and counted.
+type batcher struct {
+	queue    chan batchItem
+	flush    func(batch []batchItem)
+	size     int
+	interval time.Duration
+
+	dropped   atomic.Uint64
+	closeOnce sync.Once
+	closed    chan struct{}
+	done      chan struct{}
+}
+
+// newBatcher starts a batcher. flush is always called from a single goroutine.
+func newBatcher(queueSize, batchSize int, interval time.Duration, flush func(batch []batchItem)) *batcher {
+	b := &batcher{
+		queue:    make(chan batchItem, queueSize),
+		flush:    flush,
+		size:     batchSize,
+		interval: interval,
+		closed:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// add queues a copy of p without blocking.
+func (b *batcher) add(p []byte) error {
+	select {
+	case <-b.closed:
+		return errBatcherClosed
+	default:
+	}
+
+	select {
+	case b.queue <- batchItem{at: time.Now(), p: append([]byte(nil), p...)}:
+	default:
+		b.dropped.Add(1)
+	}
+	return nil
+}
+
+// close flushes the queued events and stops the batcher.
+func (b *batcher) close() {
+	b.closeOnce.Do(func() {
+		close(b.closed)
+	})
+	<-b.done
+}
+
+// run collects events into batches until the batcher is closed.
+func (b *batcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	batch := make([]batchItem, 0, b.size)
+	send := func() {
+		if len(batch) > 0 {
+			b.flush(batch)
+			batch = make([]batchItem, 0, b.size)
+		}
+	}
+
+	for {
+		select {
+		case item := <-b.queue:
+			batch = append(batch, item)
+			if len(batch) >= b.size {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case <-b.closed:
+			for {
+				select {
+				case item := <-b.queue:
+					batch = append(batch, item)
+					if len(batch) >= b.size {
+						send()
+					}
+				default:
+					send()
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package ezlog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// BatchedWriter accumulates events in memory and writes them to the
+// underlying writer in one call when size events are buffered, when the
+// flush interval elapses, or as soon as an event at or above the flush level
+// is written. Levels are read from JSON events and from the level prefix of
+// console lines, in their default or short names; events without a
+// recognizable level never trigger a flush on their own.
+// It should be created using NewBatchedWriter.
+type BatchedWriter struct {
+	mu         sync.Mutex
+	out        io.Writer
+	buf        bytes.Buffer
+	count      int
+	size       int
+	flushLevel zerolog.Level
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+	closed    bool
+}
+
+// NewBatchedWriter creates a BatchedWriter writing to underlying. A size <= 1
+// disables batching by count and an interval <= 0 disables the timed flush.
+func NewBatchedWriter(underlying io.Writer, size int, interval time.Duration, flushLevel zerolog.Level) *BatchedWriter {
+	w := &BatchedWriter{
+		out:        underlying,
+		size:       size,
+		flushLevel: flushLevel,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	if interval > 0 {
+		go w.run(interval)
+	} else {
+		close(w.done)
+	}
+	return w
+}
+
+// Write implements io.Writer.
+func (w *BatchedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, errBatcherClosed
+	}
+	w.buf.Write(p)
+	w.count++
+
+	if w.count >= w.size || isLevelAtLeast(p, w.flushLevel) {
+		if err := w.flushLocked(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes the buffered events to the underlying writer.
+func (w *BatchedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flushLocked()
+}
+
+// Close flushes the buffered events, stops the flush timer and closes the
+// underlying writer if it implements io.Closer.
+func (w *BatchedWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if err := w.flushLocked(); err != nil {
+		return err
+	}
+	if c, ok := w.out.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// run flushes the buffer every interval until the writer is closed.
+func (w *BatchedWriter) run(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.Flush(); err != nil {
+				diag(zerolog.ErrorLevel, "flush", "batched flush failed: %v", err)
+			}
+		}
+	}
+}
+
+// flushLocked writes the buffer out. It must be called with w.mu held.
+func (w *BatchedWriter) flushLocked() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.out.Write(w.buf.Bytes())
+	w.buf.Reset()
+	w.count = 0
+	return err
+}
+
+// isLevelAtLeast reports whether p is an event at or above min. Levels are
+// read from JSON events and from the level prefix of console lines, in their
+// default or short names.
+func isLevelAtLeast(p []byte, min zerolog.Level) bool {
+	var name string
+	if trimmed := bytes.TrimSpace(p); len(trimmed) > 0 && trimmed[0] == '{' {
+		name = eventLevel(trimmed)
+	} else {
+		name, _ = consolePrefix(p)
+	}
+	level, err := ParseLevel(name)
+	if err != nil {
+		for l, short := range shortLevelNames {
+			if short == name {
+				return l >= min
+			}
+		}
+		return false
+	}
+	return level >= min
+}
+
+// WithBatchedWriter writes through bw, flushing it on Shutdown.
+func (b *LogBuilder) WithBatchedWriter(bw *BatchedWriter) *LogBuilder {
+	return b.WithWriter(bw)
+}
@@ -0,0 +1,124 @@
+package ezlog
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// writeRecorder records every write call it receives.
+type writeRecorder struct {
+	mu     sync.Mutex
+	writes []string
+	closed bool
+}
+
+func (r *writeRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writes = append(r.writes, string(p))
+	return len(p), nil
+}
+
+func (r *writeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (r *writeRecorder) Writes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.writes...)
+}
+
+func TestBatchedWriterFlushesBySize(t *testing.T) {
+	rec := &writeRecorder{}
+	w := NewBatchedWriter(rec, 3, 0, zerolog.Disabled)
+	for range 7 {
+		w.Write([]byte(`{"level":"info"}` + "\n"))
+	}
+
+	writes := rec.Writes()
+	if len(writes) != 2 || strings.Count(writes[0], "\n") != 3 {
+		t.Fatalf("writes = %q, want 2 batches of 3 events", writes)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if writes := rec.Writes(); len(writes) != 3 || strings.Count(writes[2], "\n") != 1 || !rec.closed {
+		t.Errorf("writes after Close = %q, closed = %v", writes, rec.closed)
+	}
+	if _, err := w.Write([]byte("late\n")); !errors.Is(err, errBatcherClosed) {
+		t.Errorf("Write after Close = %v", err)
+	}
+}
+
+func TestBatchedWriterFlushesByLevel(t *testing.T) {
+	tests := []struct {
+		event string
+		flush bool
+	}{
+		{`{"level":"warn","message":"w"}`, false},
+		{`{"level":"error","message":"e"}`, true},
+		{`{"level":"fatal","message":"f"}`, true},
+		{"09:26:53.000 [WARN] [api] w", false},
+		{"09:26:53.000 [ERROR] [api] e", true},
+		{"\x1b[90m09:26:53.000\x1b[0m \x1b[31m[ERR]\x1b[0m e", true},
+		{"no level at all", false},
+	}
+	for _, tt := range tests {
+		rec := &writeRecorder{}
+		w := NewBatchedWriter(rec, 100, 0, zerolog.ErrorLevel)
+		w.Write([]byte(tt.event + "\n"))
+		if flushed := len(rec.Writes()) == 1; flushed != tt.flush {
+			t.Errorf("%q: flushed = %v, want %v", tt.event, flushed, tt.flush)
+		}
+	}
+}
+
+func TestBatchedWriterFlushesByInterval(t *testing.T) {
+	rec := &writeRecorder{}
+	w := NewBatchedWriter(rec, 100, 10*time.Millisecond, zerolog.Disabled)
+	defer w.Close()
+	w.Write([]byte(`{"level":"info"}` + "\n"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rec.Writes()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the interval never flushed the batch")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBatchedWriterConcurrent(t *testing.T) {
+	rec := &writeRecorder{}
+	bw := NewBatchedWriter(rec, 16, time.Millisecond, zerolog.ErrorLevel)
+	defer bw.Close()
+	l := New().AsLocal().WithJSONOutput().WithBatchedWriter(bw).Build()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				l.Info().Msg("event")
+			}
+		}()
+	}
+	wg.Wait()
+	l.Error().Msg("flush")
+
+	events := 0
+	for _, batch := range rec.Writes() {
+		events += strings.Count(batch, "\n")
+	}
+	if events != 801 {
+		t.Errorf("got %d events, want 801", events)
+	}
+}
@@ -0,0 +1,137 @@
+package ezlog
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/rs/zerolog"
+)
+
+// WithCaller adds the file and line of the logging call to every event.
+func (b *LogBuilder) WithCaller() *LogBuilder {
+	b.caller = true
+	return b
+}
+
+// WithCallerPrefix strips prefix from caller paths in console output, e.g.
+// a workspace directory. It may be called several times; the first matching
+// prefix is stripped.
+func (b *LogBuilder) WithCallerPrefix(prefix string) *LogBuilder {
+	b.callerPrefixes = append(b.callerPrefixes, prefix)
+	return b
+}
+
+// WithModuleRelativeCaller shortens caller paths to the path inside the main
+// module, e.g. "pkg/server/handler.go:42". The module path is read from the
+// build info, so this works best with binaries built with -trimpath; other
+// paths fall back to being relative to the working directory.
+func (b *LogBuilder) WithModuleRelativeCaller() *LogBuilder {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Path != "" {
+		b.callerModule = info.Main.Path + "/"
+	}
+	return b
+}
+
+// WithHyperlinkCaller enables the caller and turns it into an OSC 8
+// hyperlink to the source file, so it can be clicked in terminals that
+// support them, such as iTerm2, VS Code, Ghostty, WezTerm and kitty. Links
+// are only emitted when the writer is such a terminal and colors are on, and
+// only for absolute caller paths, which binaries built with -trimpath lack.
+func (b *LogBuilder) WithHyperlinkCaller() *LogBuilder {
+	b.caller = true
+	b.hyperlinkCaller = true
+	return b
+}
+
+// WithHyperlinkedCaller is like WithHyperlinkCaller but builds the link
+// from template, which receives the absolute file path and the line number,
+// e.g. "vscode://file/%s:%d" to open the file in VS Code at the line. An
+// empty template links to the file:// URL. As the terminal is chosen
+// explicitly, links are emitted on any terminal with colors on.
+func (b *LogBuilder) WithHyperlinkedCaller(template string) *LogBuilder {
+	b.caller = true
+	b.hyperlinkCaller = true
+	b.hyperlinkTemplate = template
+	b.hyperlinkForced = true
+	return b
+}
+
+// formatCaller returns the console formatter for the caller part.
+func (b *LogBuilder) formatCaller() zerolog.Formatter {
+	links := b.hyperlinkCaller && !color.NoColor && !b.tviewCompat &&
+		b.colorLevel.resolve() != ColorLevelNone && isTerminal(b.writer) &&
+		(b.hyperlinkForced || supportsHyperlinks())
+
+	return func(i any) string {
+		c, _ := i.(string)
+		if c == "" {
+			return ""
+		}
+		text := boldColor.Sprint(b.shortCaller(c))
+		if links {
+			text = hyperlinkCaller(c, text, b.hyperlinkTemplate)
+		}
+		return text + cyanColor.Sprint(" >")
+	}
+}
+
+// hyperlinkCaller wraps text in an OSC 8 hyperlink to the file of the caller
+// c, in "path:line" form, built from template when it is not empty. text is
+// returned unchanged when the path is not absolute.
+func hyperlinkCaller(c, text, template string) string {
+	path, line := c, 0
+	if i := strings.LastIndexByte(c, ':'); i > 0 {
+		path = c[:i]
+		line, _ = strconv.Atoi(c[i+1:])
+	}
+	if !filepath.IsAbs(path) {
+		return text
+	}
+	var link string
+	if template != "" {
+		link = fmt.Sprintf(template, filepath.ToSlash(path), line)
+	} else {
+		link = (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+	}
+	return "\x1b]8;;" + link + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// supportsHyperlinks reports whether the terminal is known to render OSC 8
+// hyperlinks.
+func supportsHyperlinks() bool {
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "vscode", "ghostty", "WezTerm", "Hyper":
+		return true
+	}
+	switch os.Getenv("TERM") {
+	case "xterm-kitty", "xterm-ghostty", "wezterm":
+		return true
+	}
+	return os.Getenv("WT_SESSION") != "" || os.Getenv("VTE_VERSION") != ""
+}
+
+// shortCaller strips the configured prefixes from the caller path c, then
+// the working directory read at build time. Paths outside of it are returned
+// unchanged.
+func (b *LogBuilder) shortCaller(c string) string {
+	for _, prefix := range b.callerPrefixes {
+		if strings.HasPrefix(c, prefix) {
+			return strings.TrimPrefix(strings.TrimPrefix(c, prefix), "/")
+		}
+	}
+	if b.callerModule != "" {
+		if idx := strings.Index(c, b.callerModule); idx >= 0 {
+			return c[idx+len(b.callerModule):]
+		}
+	}
+	if b.callerRoot != "" && strings.HasPrefix(c, b.callerRoot) {
+		return c[len(b.callerRoot):]
+	}
+	return c
+}
@@ -0,0 +1,73 @@
+package ezlog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHyperlinkCallerOnTerminal(t *testing.T) {
+	enableColor(t)
+	tests := []struct {
+		name        string
+		termProgram string
+		link        bool
+	}{
+		{"supported", "vscode", true},
+		{"unsupported", "Apple_Terminal", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TERM_PROGRAM", tt.termProgram)
+			t.Setenv("TERM", "xterm-256color")
+			t.Setenv("WT_SESSION", "")
+			t.Setenv("VTE_VERSION", "")
+			term := openTerminal(t)
+			New().AsLocal().WithWriter(term.File).WithHyperlinkCaller().Build().Info().Msg("hello")
+
+			got := term.Output()
+			if link := strings.Contains(got, "\x1b]8;;file:///") && strings.Contains(got, "caller_linux_test.go\x1b\\"); link != tt.link {
+				t.Errorf("hyperlink = %v, want %v: %q", link, tt.link, got)
+			}
+		})
+	}
+}
+
+func TestHyperlinkedCallerTemplateOnTerminal(t *testing.T) {
+	enableColor(t)
+	t.Setenv("TERM_PROGRAM", "Apple_Terminal")
+	tests := []struct {
+		name  string
+		setup func(*LogBuilder) *LogBuilder
+		link  bool
+	}{
+		{"template", func(b *LogBuilder) *LogBuilder { return b }, true},
+		{"tview", func(b *LogBuilder) *LogBuilder { return b.WithTviewCompat() }, false},
+		{"no color", func(b *LogBuilder) *LogBuilder { return b.WithColorSupport(ColorLevelNone) }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term := openTerminal(t)
+			b := New().AsLocal().WithWriter(term.File).WithHyperlinkedCaller("vscode://file/%s:%d")
+			tt.setup(b).Build().Info().Msg("hello")
+
+			got := term.Output()
+			i := strings.Index(got, "\x1b]8;;vscode://file/")
+			if link := i >= 0; link != tt.link {
+				t.Fatalf("hyperlink = %v, want %v: %q", link, tt.link, got)
+			}
+			if !tt.link {
+				if strings.Contains(got, "\x1b]8;") {
+					t.Errorf("output %q has an OSC 8 sequence", got)
+				}
+				return
+			}
+			// The link ends with the line number, then the text is framed by
+			// the opening and closing sequences.
+			rest := got[i:]
+			end := strings.Index(rest, "\x1b\\")
+			if end < 0 || !strings.Contains(rest[:end], "/caller_linux_test.go:") || !strings.Contains(rest[end:], "\x1b]8;;\x1b\\") {
+				t.Errorf("malformed hyperlink %q", rest)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestShortCaller(t *testing.T) {
+	b := &LogBuilder{
+		callerPrefixes: []string{"/workspace/app"},
+		callerModule:   "github.com/acme/app/",
+		callerRoot:     "/home/ann/src/",
+	}
+	tests := []struct {
+		in, want string
+	}{
+		{"/workspace/app/pkg/server/handler.go:42", "pkg/server/handler.go:42"},
+		{"github.com/acme/app/pkg/server/handler.go:42", "pkg/server/handler.go:42"},
+		{"/home/ann/src/tool/main.go:7", "tool/main.go:7"},
+		{"/usr/local/go/src/net/http/server.go:3210", "/usr/local/go/src/net/http/server.go:3210"},
+	}
+	for _, tt := range tests {
+		if got := b.shortCaller(tt.in); got != tt.want {
+			t.Errorf("shortCaller(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWithCallerPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithCaller().WithCallerPrefix("/nowhere").Build()
+	l.Info().Msg("hello")
+
+	if got := buf.String(); !strings.Contains(got, "caller_test.go:") || strings.Contains(got, "/caller_test.go") {
+		t.Errorf("output = %q, want a caller relative to the working directory", got)
+	}
+}
+
+func TestHyperlinkCaller(t *testing.T) {
+	tests := []struct {
+		caller, template, want string
+	}{
+		{"/src/app/main.go:42", "", "\x1b]8;;file:///src/app/main.go\x1b\\main.go:42\x1b]8;;\x1b\\"},
+		{"/src/my app/main.go:42", "", "\x1b]8;;file:///src/my%20app/main.go\x1b\\main.go:42\x1b]8;;\x1b\\"},
+		{"/src/app/main.go:42", "vscode://file/%s:%d", "\x1b]8;;vscode://file//src/app/main.go:42\x1b\\main.go:42\x1b]8;;\x1b\\"},
+		{"app/main.go:42", "", "main.go:42"},
+	}
+	for _, tt := range tests {
+		if got := hyperlinkCaller(tt.caller, "main.go:42", tt.template); got != tt.want {
+			t.Errorf("hyperlinkCaller(%q, %q) = %q, want %q", tt.caller, tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestSupportsHyperlinks(t *testing.T) {
+	tests := []struct {
+		termProgram, term, wtSession string
+		want                         bool
+	}{
+		{"iTerm.app", "xterm-256color", "", true},
+		{"vscode", "", "", true},
+		{"", "xterm-kitty", "", true},
+		{"", "xterm-256color", "1", true},
+		{"Apple_Terminal", "xterm-256color", "", false},
+	}
+	for _, tt := range tests {
+		t.Setenv("TERM_PROGRAM", tt.termProgram)
+		t.Setenv("TERM", tt.term)
+		t.Setenv("WT_SESSION", tt.wtSession)
+		t.Setenv("VTE_VERSION", "")
+		if got := supportsHyperlinks(); got != tt.want {
+			t.Errorf("supportsHyperlinks() with %q %q %q = %v, want %v", tt.termProgram, tt.term, tt.wtSession, got, tt.want)
+		}
+	}
+}
+
+func TestHyperlinkCallerNotOnPipes(t *testing.T) {
+	t.Setenv("TERM_PROGRAM", "vscode")
+	var buf bytes.Buffer
+	newTestBuilder(&buf).WithHyperlinkedCaller("").Build().Info().Msg("hello")
+
+	if got := buf.String(); strings.Contains(got, "\x1b]8;;") || !strings.Contains(got, "caller_test.go:") {
+		t.Errorf("output = %q, want a plain caller", got)
+	}
+}
@@ -0,0 +1,278 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ChatOption configures a ChatNotifier.
+type ChatOption func(*ChatNotifier)
+
+// WithChatDiscord sends Discord webhook payloads instead of Slack ones.
+func WithChatDiscord() ChatOption {
+	return func(n *ChatNotifier) {
+		n.discord = true
+	}
+}
+
+// WithChatFields selects the event fields included in notifications.
+// By default all fields are included.
+func WithChatFields(keys ...string) ChatOption {
+	return func(n *ChatNotifier) {
+		n.fields = keys
+	}
+}
+
+// WithChatRateLimit sets the maximum number of notifications per minute,
+// 10 by default. Events over the limit are counted and summarized.
+func WithChatRateLimit(perMinute int) ChatOption {
+	return func(n *ChatNotifier) {
+		n.perMinute = perMinute
+	}
+}
+
+// WithChatQueueSize sets how many events may wait to be sent before new ones
+// are dropped, 32 by default.
+func WithChatQueueSize(size int) ChatOption {
+	return func(n *ChatNotifier) {
+		n.queueSize = size
+	}
+}
+
+// WithChatHTTPClient sets the HTTP client used to call the webhook.
+func WithChatHTTPClient(c *http.Client) ChatOption {
+	return func(n *ChatNotifier) {
+		n.client = c
+	}
+}
+
+// ChatNotifier posts events at or above a minimum level to a Slack or Discord
+// incoming webhook. Sending happens in the background and writing never
+// blocks. It should be created using NewChatNotifier.
+type ChatNotifier struct {
+	url       string
+	minLevel  zerolog.Level
+	discord   bool
+	fields    []string
+	perMinute int
+	queueSize int
+	client    *http.Client
+
+	queue     chan map[string]any
+	closeOnce sync.Once
+	closed    chan struct{}
+	done      chan struct{}
+	dropped   atomic.Uint64
+
+	windowStart time.Time
+	sent        int
+	suppressed  int
+}
+
+// NewChatNotifier creates a ChatNotifier posting events at or above minLevel
+// to webhookURL.
+func NewChatNotifier(webhookURL string, minLevel zerolog.Level, opts ...ChatOption) *ChatNotifier {
+	n := &ChatNotifier{
+		url:       webhookURL,
+		minLevel:  minLevel,
+		perMinute: 10,
+		queueSize: 32,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		closed:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	n.queue = make(chan map[string]any, n.queueSize)
+
+	go n.run()
+	return n
+}
+
+// Write implements io.Writer. Events below the minimum level are ignored and
+// events are dropped when the queue is full.
+func (n *ChatNotifier) Write(p []byte) (int, error) {
+	level, err := ParseLevel(eventLevel(p))
+	if err != nil || level < n.minLevel {
+		return len(p), nil
+	}
+
+	var evt map[string]any
+	if err := json.Unmarshal(p, &evt); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %w", err)
+	}
+
+	select {
+	case <-n.closed:
+		return 0, errBatcherClosed
+	default:
+	}
+	select {
+	case n.queue <- evt:
+	default:
+		n.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of events dropped because the queue was full.
+func (n *ChatNotifier) Dropped() uint64 {
+	return n.dropped.Load()
+}
+
+// Close sends the queued events and stops the notifier.
+func (n *ChatNotifier) Close() error {
+	n.closeOnce.Do(func() {
+		close(n.closed)
+	})
+	<-n.done
+	return nil
+}
+
+// run sends queued events, applying the rate limit.
+func (n *ChatNotifier) run() {
+	defer close(n.done)
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case evt := <-n.queue:
+			n.handle(evt)
+		case <-ticker.C:
+			n.rollWindow(time.Now())
+		case <-n.closed:
+			for {
+				select {
+				case evt := <-n.queue:
+					n.handle(evt)
+				default:
+					n.flushSuppressed()
+					return
+				}
+			}
+		}
+	}
+}
+
+// handle sends evt unless the rate limit is reached.
+func (n *ChatNotifier) handle(evt map[string]any) {
+	now := time.Now()
+	if now.Sub(n.windowStart) >= time.Minute {
+		n.rollWindow(now)
+	}
+	if n.sent >= n.perMinute {
+		n.suppressed++
+		return
+	}
+	n.sent++
+	n.post(n.payload(evt))
+}
+
+// rollWindow starts a new rate limit window, first reporting the events
+// suppressed in the previous one.
+func (n *ChatNotifier) rollWindow(now time.Time) {
+	n.flushSuppressed()
+	n.windowStart = now
+	n.sent = 0
+}
+
+// flushSuppressed sends a summary of the suppressed events, if any.
+func (n *ChatNotifier) flushSuppressed() {
+	if n.suppressed == 0 {
+		return
+	}
+	text := fmt.Sprintf("suppressed %d similar notifications", n.suppressed)
+	n.suppressed = 0
+	if n.discord {
+		n.post(map[string]any{"content": text})
+	} else {
+		n.post(map[string]any{"text": text})
+	}
+}
+
+// payload builds the webhook payload for evt.
+func (n *ChatNotifier) payload(evt map[string]any) map[string]any {
+	levelStr, _ := evt[zerolog.LevelFieldName].(string)
+	msg, _ := evt[zerolog.MessageFieldName].(string)
+	level, _ := ParseLevel(levelStr)
+	title := fmt.Sprintf("[%s] %s", strings.ToUpper(LevelString(level)), msg)
+
+	keys := n.fields
+	if len(keys) == 0 {
+		for key := range evt {
+			switch key {
+			case zerolog.LevelFieldName, zerolog.MessageFieldName, zerolog.TimestampFieldName:
+				continue
+			}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+
+	var fields []map[string]any
+	for _, key := range keys {
+		v, ok := evt[key]
+		if !ok {
+			continue
+		}
+		value := fmt.Sprint(v)
+		if s, ok := v.(string); ok {
+			value = s
+		}
+		if n.discord {
+			fields = append(fields, map[string]any{"name": key, "value": value, "inline": true})
+		} else {
+			fields = append(fields, map[string]any{"title": key, "value": value, "short": true})
+		}
+	}
+
+	color := chatLevelColor(level)
+	if n.discord {
+		return map[string]any{
+			"embeds": []map[string]any{{"title": title, "color": color, "fields": fields}},
+		}
+	}
+	return map[string]any{
+		"text":        title,
+		"attachments": []map[string]any{{"color": fmt.Sprintf("#%06x", color), "fields": fields}},
+	}
+}
+
+// post sends payload to the webhook, giving up on failure.
+func (n *ChatNotifier) post(payload map[string]any) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+// chatLevelColor returns the RGB color of the notification bar for level.
+func chatLevelColor(level zerolog.Level) int {
+	switch level {
+	case zerolog.WarnLevel:
+		return 0xecb22e
+	case zerolog.ErrorLevel:
+		return 0xe01e5a
+	case zerolog.FatalLevel, zerolog.PanicLevel:
+		return 0x8b0000
+	default:
+		return 0x2eb67d
+	}
+}
@@ -0,0 +1,123 @@
+package ezlog
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// chatServer records the payloads posted to it.
+type chatServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []map[string]any
+}
+
+func newChatServer(t *testing.T) *chatServer {
+	s := &chatServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]any
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("invalid payload %q: %v", body, err)
+		}
+		s.mu.Lock()
+		s.payloads = append(s.payloads, payload)
+		s.mu.Unlock()
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestChatNotifierSlackPayload(t *testing.T) {
+	s := newChatServer(t)
+	n := NewChatNotifier(s.URL, zerolog.ErrorLevel, WithChatFields("user", "missing"))
+	n.Write([]byte(`{"level":"warn","message":"ignored"}`))
+	n.Write([]byte(`{"level":"error","message":"payment failed","user":"ada","amount":42}`))
+	n.Close()
+
+	if len(s.payloads) != 1 {
+		t.Fatalf("got %d payloads, want 1", len(s.payloads))
+	}
+	p := s.payloads[0]
+	if p["text"] != "[ERROR] payment failed" {
+		t.Errorf("text = %v", p["text"])
+	}
+	attachments, _ := p["attachments"].([]any)
+	if len(attachments) != 1 {
+		t.Fatalf("attachments = %v", p["attachments"])
+	}
+	a := attachments[0].(map[string]any)
+	fields, _ := a["fields"].([]any)
+	if a["color"] != "#e01e5a" || len(fields) != 1 {
+		t.Fatalf("attachment = %v", a)
+	}
+	if f := fields[0].(map[string]any); f["title"] != "user" || f["value"] != "ada" || f["short"] != true {
+		t.Errorf("field = %v", f)
+	}
+}
+
+func TestChatNotifierDiscordPayload(t *testing.T) {
+	s := newChatServer(t)
+	n := NewChatNotifier(s.URL, zerolog.WarnLevel, WithChatDiscord())
+	n.Write([]byte(`{"level":"fatal","message":"out of memory","time":"2025-03-14T09:26:53Z","pid":7}`))
+	n.Close()
+
+	if len(s.payloads) != 1 {
+		t.Fatalf("got %d payloads, want 1", len(s.payloads))
+	}
+	embeds, _ := s.payloads[0]["embeds"].([]any)
+	if len(embeds) != 1 {
+		t.Fatalf("embeds = %v", s.payloads[0])
+	}
+	e := embeds[0].(map[string]any)
+	fields, _ := e["fields"].([]any)
+	if e["title"] != "[FATAL] out of memory" || e["color"] != float64(0x8b0000) || len(fields) != 1 {
+		t.Fatalf("embed = %v", e)
+	}
+	if f := fields[0].(map[string]any); f["name"] != "pid" || f["value"] != "7" || f["inline"] != true {
+		t.Errorf("field = %v", f)
+	}
+}
+
+func TestChatNotifierRateLimit(t *testing.T) {
+	s := newChatServer(t)
+	n := NewChatNotifier(s.URL, zerolog.ErrorLevel, WithChatRateLimit(2))
+	for range 5 {
+		n.Write([]byte(`{"level":"error","message":"db down"}`))
+	}
+	n.Close()
+
+	if len(s.payloads) != 3 {
+		t.Fatalf("got %d payloads, want 2 and a summary", len(s.payloads))
+	}
+	if got := s.payloads[2]["text"]; got != "suppressed 3 similar notifications" {
+		t.Errorf("summary = %v", got)
+	}
+}
+
+func TestChatNotifierQueueOverflow(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+
+	n := NewChatNotifier(srv.URL, zerolog.ErrorLevel, WithChatQueueSize(1))
+	for range 10 {
+		if _, err := n.Write([]byte(`{"level":"error","message":"db down"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(release)
+	n.Close()
+
+	if got := n.Dropped(); got < 8 {
+		t.Errorf("Dropped() = %d, want at least 8 of 10 with room for 2", got)
+	}
+}
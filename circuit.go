@@ -0,0 +1,140 @@
+package ezlog
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// CircuitState is the state of a CircuitBreakerWriter.
+type CircuitState int32
+
+// States of a CircuitBreakerWriter.
+const (
+	// CircuitClosed lets writes through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen drops writes.
+	CircuitOpen
+	// CircuitHalfOpen is the state while a write tests whether the writer
+	// works again.
+	CircuitHalfOpen
+)
+
+// String implements fmt.Stringer.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int32(s))
+	}
+}
+
+// CircuitBreakerWriter stops writing to a failing writer, such as a network
+// destination that is down, so that events do not each wait for it to fail.
+// After threshold consecutive write errors the circuit opens and events are
+// dropped; every test interval, one event is written to test the writer,
+// closing the circuit when it succeeds. The number of dropped events is
+// then reported in an event of its own.
+// It should be created using NewCircuitBreakerWriter.
+type CircuitBreakerWriter struct {
+	mu           sync.Mutex
+	out          io.Writer
+	threshold    int
+	testInterval time.Duration
+	now          func() time.Time
+
+	state       atomic.Int32
+	consecutive int
+	openedAt    time.Time
+	dropped     atomic.Uint64
+	droppedOpen uint64
+}
+
+// NewCircuitBreakerWriter creates a CircuitBreakerWriter over w, opening
+// after threshold consecutive errors and testing w every testInterval while
+// open.
+func NewCircuitBreakerWriter(w io.Writer, threshold int, testInterval time.Duration) *CircuitBreakerWriter {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreakerWriter{
+		out:          w,
+		threshold:    threshold,
+		testInterval: testInterval,
+		now:          time.Now,
+	}
+}
+
+// State returns the current state of the circuit.
+func (w *CircuitBreakerWriter) State() CircuitState {
+	return CircuitState(w.state.Load())
+}
+
+// Dropped returns the number of events dropped while the circuit was open.
+func (w *CircuitBreakerWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Write implements io.Writer. Dropped events are not reported as errors.
+func (w *CircuitBreakerWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.State() == CircuitOpen {
+		if w.now().Sub(w.openedAt) < w.testInterval {
+			w.dropped.Add(1)
+			w.droppedOpen++
+			return len(p), nil
+		}
+		w.state.Store(int32(CircuitHalfOpen))
+		if _, err := w.out.Write(p); err != nil {
+			w.open()
+			return 0, err
+		}
+		w.state.Store(int32(CircuitClosed))
+		w.consecutive = 0
+		writeMetaEvent(w.out, p, zerolog.WarnLevel,
+			fmt.Sprintf("ezlog: writer recovered, %d events dropped", w.droppedOpen), nil)
+		w.droppedOpen = 0
+		return len(p), nil
+	}
+
+	n, err := w.out.Write(p)
+	if err != nil {
+		w.consecutive++
+		if w.consecutive >= w.threshold {
+			w.open()
+		}
+		return n, err
+	}
+	w.consecutive = 0
+	return n, nil
+}
+
+// open opens the circuit.
+func (w *CircuitBreakerWriter) open() {
+	w.state.Store(int32(CircuitOpen))
+	w.openedAt = w.now()
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (w *CircuitBreakerWriter) Close() error {
+	if c, ok := w.out.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// WithCircuitBreaker writes through cbw, closing it on Shutdown.
+func (b *LogBuilder) WithCircuitBreaker(cbw *CircuitBreakerWriter) *LogBuilder {
+	return b.WithWriter(cbw)
+}
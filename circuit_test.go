@@ -0,0 +1,96 @@
+package ezlog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerWriter(t *testing.T) {
+	out := &flakyWriter{}
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	w := NewCircuitBreakerWriter(out, 2, time.Minute)
+	w.now = func() time.Time { return now }
+	event := func(msg string) []byte { return []byte(`{"level":"info","message":"` + msg + `"}` + "\n") }
+
+	out.fail = true
+	for i := range 2 {
+		if _, err := w.Write(event("failed")); err == nil {
+			t.Fatalf("write %d: no error", i)
+		}
+	}
+	if w.State() != CircuitOpen {
+		t.Fatalf("after 2 errors: state = %v, want open", w.State())
+	}
+
+	out.fail = false
+	for range 3 {
+		if n, err := w.Write(event("dropped")); n == 0 || err != nil {
+			t.Errorf("dropped write = %d, %v, want success", n, err)
+		}
+	}
+	if w.Dropped() != 3 || out.Len() != 0 {
+		t.Errorf("dropped %d events, wrote %q", w.Dropped(), out.String())
+	}
+
+	// A failed test keeps the circuit open for another interval.
+	out.fail = true
+	now = now.Add(time.Minute)
+	if _, err := w.Write(event("test")); err == nil || w.State() != CircuitOpen {
+		t.Errorf("failed test: err = %v, state = %v", err, w.State())
+	}
+	w.Write(event("dropped"))
+
+	out.fail = false
+	now = now.Add(time.Minute)
+	if _, err := w.Write(event("recovered")); err != nil || w.State() != CircuitClosed {
+		t.Errorf("passed test: err = %v, state = %v", err, w.State())
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "recovered") || !strings.Contains(lines[1], "writer recovered, 4 events dropped") {
+		t.Errorf("output = %q, want the event and the drop report", lines)
+	}
+}
+
+func TestCircuitBreakerResetsOnSuccess(t *testing.T) {
+	out := &flakyWriter{}
+	w := NewCircuitBreakerWriter(out, 2, time.Minute)
+	for range 3 {
+		out.fail = true
+		w.Write([]byte("x\n"))
+		out.fail = false
+		w.Write([]byte("x\n"))
+	}
+	if w.State() != CircuitClosed {
+		t.Errorf("state = %v, want closed as errors were not consecutive", w.State())
+	}
+}
+
+func TestCircuitState(t *testing.T) {
+	for s, want := range map[CircuitState]string{
+		CircuitClosed:   "closed",
+		CircuitOpen:     "open",
+		CircuitHalfOpen: "half-open",
+		CircuitState(7): "CircuitState(7)",
+	} {
+		if got := s.String(); got != want {
+			t.Errorf("CircuitState(%d).String() = %q, want %q", int32(s), got, want)
+		}
+	}
+	w := NewCircuitBreakerWriter(&flakyWriter{fail: true}, 0, time.Minute)
+	w.Write([]byte("x"))
+	if w.State() != CircuitOpen {
+		t.Error("a threshold below 1 does not open on the first error")
+	}
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	out := &flakyWriter{fail: true}
+	cbw := NewCircuitBreakerWriter(out, 1, time.Hour)
+	l := New().AsLocal().WithJSONOutput().WithCircuitBreaker(cbw).Build()
+	l.Info().Msg("fails")
+	l.Info().Msg("dropped")
+	if cbw.State() != CircuitOpen || cbw.Dropped() != 1 {
+		t.Errorf("state = %v, dropped = %d", cbw.State(), cbw.Dropped())
+	}
+}
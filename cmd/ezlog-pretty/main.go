@@ -0,0 +1,54 @@
+// Command ezlog-pretty renders zerolog JSON logs read from files or stdin as
+// colored console lines:
+//
+//	tail -f app.log | ezlog-pretty
+//	ezlog-pretty -level warn app.log
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ezydark/ezlog"
+)
+
+func main() {
+	level := flag.String("level", "trace", "skip events below `level`")
+	utc := flag.Bool("utc", false, "render timestamps in UTC")
+	flag.Parse()
+
+	minLevel, err := ezlog.ParseLevel(*level)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ezlog-pretty:", err)
+		os.Exit(2)
+	}
+	b := ezlog.New().AsLocal()
+	if *utc {
+		b = b.WithUTC()
+	}
+	opts := []ezlog.PrettyOption{ezlog.WithPrettyBuilder(b), ezlog.WithPrettyMinLevel(minLevel)}
+
+	if flag.NArg() == 0 {
+		run(os.Stdin, opts)
+		return
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "ezlog-pretty:", err)
+			os.Exit(1)
+		}
+		run(f, opts)
+		f.Close()
+	}
+}
+
+// run renders r to stdout, exiting on error.
+func run(r io.Reader, opts []ezlog.PrettyOption) {
+	if err := ezlog.Pretty(r, os.Stdout, opts...); err != nil {
+		fmt.Fprintln(os.Stderr, "ezlog-pretty:", err)
+		os.Exit(1)
+	}
+}
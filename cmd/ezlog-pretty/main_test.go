@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv("EZLOG_PRETTY_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runPretty runs the command with args and stdin, returning its stdout.
+func runPretty(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "EZLOG_PRETTY_MAIN=1", "NO_COLOR=1")
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+const prettyInput = `{"level":"info","tag":"api","time":"2025-03-14T09:26:53Z","message":"started"}
+{"level":"warn","time":"2025-03-14T09:26:54Z","message":"slow"}
+garbage
+`
+
+func TestPrettyStdin(t *testing.T) {
+	out, err := runPretty(t, prettyInput, "-utc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "09:26:53.000 [INFO] [api] started\n09:26:54.000 [WARN] slow\ngarbage\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestPrettyFilesAndLevel(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(name, []byte(prettyInput), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := runPretty(t, "", "-utc", "-level", "warn", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "09:26:54.000 [WARN] slow\ngarbage\n"; out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestPrettyErrors(t *testing.T) {
+	if _, err := runPretty(t, "", "-level", "loud"); err == nil {
+		t.Error("no error for an invalid level")
+	}
+	if _, err := runPretty(t, "", filepath.Join(t.TempDir(), "missing.log")); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
@@ -0,0 +1,158 @@
+package ezlog
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/rs/zerolog"
+)
+
+// ColorLevel is the color capability of the output terminal.
+type ColorLevel int
+
+const (
+	// ColorLevelNone disables colors.
+	ColorLevelNone ColorLevel = iota
+	// ColorLevelBasic uses the 16 standard ANSI colors. This is the default.
+	ColorLevelBasic
+	// ColorLevel256 uses the 256 color palette.
+	ColorLevel256
+	// ColorLevelTrueColor uses 24-bit RGB colors.
+	ColorLevelTrueColor
+	// ColorLevelAuto detects the capability from the COLORTERM and TERM
+	// environment variables.
+	ColorLevelAuto
+)
+
+// WithColorSupport sets the color capability used for console output.
+// Richer levels render levels with more nuanced colors.
+func (b *LogBuilder) WithColorSupport(level ColorLevel) *LogBuilder {
+	b.colorLevel = level
+	return b
+}
+
+// WithColorSupport sets the color capability used to highlight queries.
+func (b *GormLoggerBuilder) WithColorSupport(level ColorLevel) *GormLoggerBuilder {
+	b.logger.colorLevel = level
+	return b
+}
+
+// DetectColorLevel returns the color capability of the terminal described by
+// the NO_COLOR, COLORTERM and TERM environment variables.
+func DetectColorLevel() ColorLevel {
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorLevelNone
+	}
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorLevelTrueColor
+	}
+	term := strings.ToLower(os.Getenv("TERM"))
+	switch {
+	case term == "dumb":
+		return ColorLevelNone
+	case strings.Contains(term, "truecolor") || strings.Contains(term, "direct"):
+		return ColorLevelTrueColor
+	case strings.Contains(term, "256color"):
+		return ColorLevel256
+	default:
+		return ColorLevelBasic
+	}
+}
+
+// Colors of the console output, created once rather than on every event.
+var (
+	cyanColor    = color.New(color.FgCyan)
+	greenColor   = color.New(color.FgGreen)
+	magentaColor = color.New(color.FgMagenta)
+	yellowColor  = color.New(color.FgYellow)
+	redColor     = color.New(color.FgRed)
+	boldRedColor = color.New(color.FgRed, color.Bold)
+	grayColor    = color.New(color.FgHiBlack)
+	boldColor    = color.New(color.Bold)
+	whiteColor   = color.New(color.FgWhite)
+)
+
+// resolve returns l, detecting the capability when l is ColorLevelAuto.
+func (l ColorLevel) resolve() ColorLevel {
+	if l == ColorLevelAuto {
+		return DetectColorLevel()
+	}
+	return l
+}
+
+// rgb returns a color for the given RGB value, downgraded to the 256 color
+// palette when needed, or basic when neither is supported. The color is
+// disabled at ColorLevelNone.
+func (l ColorLevel) rgb(basic color.Attribute, r, g, b int, attrs ...color.Attribute) *color.Color {
+	var c *color.Color
+	switch l {
+	case ColorLevelTrueColor:
+		c = color.New(38, 2, color.Attribute(r), color.Attribute(g), color.Attribute(b))
+	case ColorLevel256:
+		c = color.New(38, 5, color.Attribute(ansi256(r, g, b)))
+	case ColorLevelNone:
+		c = color.New(basic)
+		c.DisableColor()
+	default:
+		c = color.New(basic)
+	}
+	return c.Add(attrs...)
+}
+
+// ansi256 returns the closest color of the 6x6x6 cube of the 256 color palette.
+func ansi256(r, g, b int) int {
+	q := func(v int) int { return (v*5 + 127) / 255 }
+	return 16 + 36*q(r) + 6*q(g) + q(b)
+}
+
+// levelColor returns the color used to render level at color capability l.
+func (l ColorLevel) levelColor(level zerolog.Level) *color.Color {
+	switch level {
+	case zerolog.TraceLevel:
+		return l.rgb(color.FgWhite, 140, 140, 140)
+	case zerolog.DebugLevel:
+		return l.rgb(color.FgBlue, 80, 160, 255)
+	case zerolog.InfoLevel:
+		return l.rgb(color.FgGreen, 80, 200, 120)
+	case zerolog.WarnLevel:
+		return l.rgb(color.FgYellow, 255, 200, 60)
+	case zerolog.ErrorLevel:
+		return l.rgb(color.FgRed, 255, 100, 60)
+	case zerolog.FatalLevel:
+		return l.rgb(color.FgRed, 255, 40, 40, color.Bold)
+	case zerolog.PanicLevel:
+		return l.rgb(color.FgWhite, 220, 0, 100, color.Bold)
+	default:
+		return l.rgb(color.FgWhite, 255, 255, 255)
+	}
+}
+
+// levelColors returns the colors of the known levels at color capability l.
+func (l ColorLevel) levelColors() map[zerolog.Level]*color.Color {
+	colors := make(map[zerolog.Level]*color.Color, len(knownLevels))
+	for _, level := range knownLevels {
+		colors[level] = l.levelColor(level)
+	}
+	return colors
+}
+
+// stripANSIWriter removes ANSI escapes from everything written to out.
+type stripANSIWriter struct {
+	out io.Writer
+}
+
+// Write implements io.Writer.
+func (w stripANSIWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w stripANSIWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if _, err := writeLevel(w.out, level, ansiEscape.ReplaceAll(p, nil)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
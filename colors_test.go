@@ -0,0 +1,115 @@
+package ezlog
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm/logger"
+)
+
+func TestDetectColorLevel(t *testing.T) {
+	tests := []struct {
+		noColor, colorTerm, term string
+		want                     ColorLevel
+	}{
+		{"1", "truecolor", "xterm-256color", ColorLevelNone},
+		{"", "truecolor", "xterm", ColorLevelTrueColor},
+		{"", "24bit", "", ColorLevelTrueColor},
+		{"", "", "xterm-direct", ColorLevelTrueColor},
+		{"", "", "xterm-256color", ColorLevel256},
+		{"", "", "xterm", ColorLevelBasic},
+		{"", "", "dumb", ColorLevelNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.colorTerm+"/"+tt.term, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("COLORTERM", tt.colorTerm)
+			t.Setenv("TERM", tt.term)
+			if got := DetectColorLevel(); got != tt.want {
+				t.Errorf("DetectColorLevel() = %d, want %d", got, tt.want)
+			}
+			if got := ColorLevelAuto.resolve(); got != tt.want {
+				t.Errorf("ColorLevelAuto.resolve() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelColorEscapes(t *testing.T) {
+	tests := []struct {
+		level ColorLevel
+		want  string
+	}{
+		{ColorLevelBasic, "\x1b[31merror\x1b[0"},
+		{ColorLevel256, "\x1b[38;5;209merror\x1b[0"},
+		{ColorLevelTrueColor, "\x1b[38;2;255;100;60merror\x1b[0"},
+	}
+	for _, tt := range tests {
+		c := tt.level.levelColor(zerolog.ErrorLevel)
+		c.EnableColor()
+		// The reset sequence varies with the parameters, so only its start is
+		// compared.
+		if got := c.Sprint("error"); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("level %d: got %q, want %q", tt.level, got, tt.want)
+		}
+	}
+
+	if got := ColorLevelNone.levelColor(zerolog.ErrorLevel).Sprint("error"); got != "error" {
+		t.Errorf("ColorLevelNone: got %q, want plain text", got)
+	}
+}
+
+func TestANSI256(t *testing.T) {
+	tests := []struct{ r, g, b, want int }{
+		{0, 0, 0, 16},
+		{255, 255, 255, 231},
+		{255, 0, 0, 196},
+		{255, 100, 60, 209},
+	}
+	for _, tt := range tests {
+		if got := ansi256(tt.r, tt.g, tt.b); got != tt.want {
+			t.Errorf("ansi256(%d, %d, %d) = %d, want %d", tt.r, tt.g, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestGormColorsFollowColorLevel(t *testing.T) {
+	c := newGormColors(ColorLevelTrueColor).sql
+	c.EnableColor()
+	if got := c.Sprint("SELECT 1"); !strings.HasPrefix(got, "\x1b[38;2;130;210;110mSELECT 1") {
+		t.Errorf("true color query = %q", got)
+	}
+	if got := newGormColors(ColorLevelNone).sql.Sprint("SELECT 1"); got != "SELECT 1" {
+		t.Errorf("uncolored query = %q", got)
+	}
+}
+
+// BenchmarkColoredConsole measures a colored info line with three fields and
+// a colored GORM query, whose colors are created once per logger.
+func BenchmarkColoredConsole(b *testing.B) {
+	noColor := color.NoColor
+	color.NoColor = false
+	b.Cleanup(func() { color.NoColor = noColor })
+
+	b.Run("info", func(b *testing.B) {
+		l := New().AsLocal().WithWriter(io.Discard).WithTag("api").Build()
+		b.ReportAllocs()
+		for b.Loop() {
+			l.Info().Str("user", "ann").Int("status", 200).Dur("elapsed", time.Millisecond).Msg("request served")
+		}
+	})
+	b.Run("gorm", func(b *testing.B) {
+		l := NewGormLogger().WithWriter(io.Discard).WithSlowThreshold(time.Hour).Build().LogMode(logger.Info)
+		ctx := context.Background()
+		sql := func() (string, int64) { return "SELECT * FROM users WHERE id = 1", 1 }
+		b.ReportAllocs()
+		for b.Loop() {
+			l.Trace(ctx, time.Now(), sql, nil)
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package ezlog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// CompressionAlgo is a compression algorithm for log output.
+type CompressionAlgo int
+
+const (
+	// CompressionNone writes uncompressed output.
+	CompressionNone CompressionAlgo = iota
+	// CompressionGzip writes a gzip stream.
+	CompressionGzip
+	// CompressionZstd writes a zstd stream. It requires importing the
+	// ezlog/zstd package, which keeps the zstd library out of the programs
+	// that do not:
+	//
+	//	import _ "github.com/ezydark/ezlog/zstd"
+	CompressionZstd
+)
+
+// String implements fmt.Stringer.
+func (a CompressionAlgo) String() string {
+	switch a {
+	case CompressionNone:
+		return "none"
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZstd:
+		return "zstd"
+	}
+	return fmt.Sprintf("CompressionAlgo(%d)", int(a))
+}
+
+// compression is a compression algorithm added with RegisterCompression.
+type compression struct {
+	ext       string
+	newWriter func(io.Writer) io.WriteCloser
+}
+
+// compressions holds the registered compression algorithms.
+var compressions struct {
+	sync.RWMutex
+	algos map[CompressionAlgo]compression
+}
+
+// RegisterCompression makes algo write its streams with newWriter, to files
+// ending with ext, dot included. It is meant to be called by the packages
+// providing an algorithm, such as ezlog/zstd for CompressionZstd, so the
+// programs not using one do not depend on its library. Other algorithms use
+// values above CompressionZstd.
+func RegisterCompression(algo CompressionAlgo, ext string, newWriter func(io.Writer) io.WriteCloser) {
+	compressions.Lock()
+	defer compressions.Unlock()
+	if compressions.algos == nil {
+		compressions.algos = make(map[CompressionAlgo]compression)
+	}
+	compressions.algos[algo] = compression{ext: ext, newWriter: newWriter}
+}
+
+// registered returns the registered algorithm a, if any.
+func (a CompressionAlgo) registered() (compression, bool) {
+	compressions.RLock()
+	defer compressions.RUnlock()
+	c, ok := compressions.algos[a]
+	return c, ok
+}
+
+// check returns an error if a is neither built in nor registered.
+func (a CompressionAlgo) check() error {
+	if a == CompressionNone || a == CompressionGzip {
+		return nil
+	}
+	if _, ok := a.registered(); ok {
+		return nil
+	}
+	if a == CompressionZstd {
+		return fmt.Errorf("ezlog: compression zstd is not registered, import github.com/ezydark/ezlog/zstd")
+	}
+	return fmt.Errorf("ezlog: compression %s is not registered", a)
+}
+
+// ext returns the file extension of the algorithm, with its dot.
+func (a CompressionAlgo) ext() string {
+	if a == CompressionGzip {
+		return ".gz"
+	}
+	c, _ := a.registered()
+	return c.ext
+}
+
+// newCompressor returns a writer compressing to w with algo. An algorithm
+// that is not registered is reported to the diagnostics, and w is written
+// uncompressed.
+func newCompressor(w io.Writer, algo CompressionAlgo) io.WriteCloser {
+	if algo == CompressionGzip {
+		return gzip.NewWriter(w)
+	}
+	if c, ok := algo.registered(); ok {
+		return c.newWriter(w)
+	}
+	if err := algo.check(); err != nil {
+		diag(zerolog.ErrorLevel, "compression", "%v, writing uncompressed output", err)
+	}
+	return nopWriteCloser{w}
+}
+
+// nopWriteCloser is an io.Writer with a no-op Close.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close implements io.Closer.
+func (nopWriteCloser) Close() error { return nil }
+
+// CompressedWriter compresses everything written to it. The stream is only
+// complete once Close has been called, which also closes the underlying
+// writer if it implements io.Closer.
+// It should be created using NewCompressedWriter.
+type CompressedWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+	zw  io.WriteCloser
+}
+
+// NewCompressedWriter creates a CompressedWriter compressing to w with algo.
+func NewCompressedWriter(w io.Writer, algo CompressionAlgo) *CompressedWriter {
+	return &CompressedWriter{out: w, zw: newCompressor(w, algo)}
+}
+
+// Write implements io.Writer.
+func (w *CompressedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.zw.Write(p)
+}
+
+// Flush writes the pending compressed data to the underlying writer, without
+// ending the stream.
+func (w *CompressedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if f, ok := w.zw.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close ends the compressed stream and closes the underlying writer.
+func (w *CompressedWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.zw.Close(); err != nil {
+		return err
+	}
+	if c, ok := w.out.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// WithCompression compresses the output with algo. A RotatingFileWriter
+// compresses each of its files; any other writer is wrapped in a
+// CompressedWriter, so the output is only complete after Shutdown. BuildE
+// fails if algo is not registered, e.g. CompressionZstd without the
+// ezlog/zstd package, while Build reports it to the diagnostics and writes
+// uncompressed output.
+func (b *LogBuilder) WithCompression(algo CompressionAlgo) *LogBuilder {
+	b.compression = algo
+	return b
+}
+
+// compressedWriter returns the builder's writer, compressed when requested.
+func (b *LogBuilder) compressedWriter() io.Writer {
+	if b.compression == CompressionNone || b.compression.check() != nil {
+		return b.writer
+	}
+	if rw, ok := b.writer.(*RotatingFileWriter); ok {
+		rw.mu.Lock()
+		if rw.file == nil {
+			rw.compression = b.compression
+		}
+		rw.mu.Unlock()
+		return rw
+	}
+	if b.compressed == nil || b.compressed.out != b.writer {
+		b.compressed = NewCompressedWriter(b.writer, b.compression)
+	}
+	return b.compressed
+}
@@ -0,0 +1,162 @@
+package ezlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// closeBuffer is a bytes.Buffer recording whether it was closed.
+type closeBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closeBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+// gunzip returns the decompressed content of a gzip stream.
+func gunzip(t *testing.T, r io.Reader) string {
+	t.Helper()
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestWithCompressionGzip(t *testing.T) {
+	var out closeBuffer
+	b := New().AsLocal().WithWriter(&out).WithJSONOutput().WithCompression(CompressionGzip)
+	l := b.Build()
+	l.Info().Msg("first")
+	l.Info().Msg("second")
+
+	if err := b.compressed.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !out.closed {
+		t.Error("the underlying writer was not closed")
+	}
+	got := gunzip(t, &out)
+	if strings.Count(got, "\n") != 2 || !strings.Contains(got, `"message":"second"`) {
+		t.Errorf("decompressed output = %q", got)
+	}
+}
+
+func TestCompressedWriterFlush(t *testing.T) {
+	var out bytes.Buffer
+	w := NewCompressedWriter(&out, CompressionGzip)
+	w.Write([]byte("event\n"))
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A flushed but unfinished stream can be read up to the flush point.
+	zr, err := gzip.NewReader(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, 6)
+	if _, err := io.ReadFull(zr, got); err != nil || string(got) != "event\n" {
+		t.Errorf("read %q, %v after Flush", got, err)
+	}
+}
+
+func TestRotatingFileCompression(t *testing.T) {
+	dir := t.TempDir()
+	clock := newRotationClock(time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC))
+	w := NewRotatingFileWriter(dir, "app", "log", HourlyRotation(time.UTC), 0,
+		WithRotationClock(clock.now), WithRotationCompression(CompressionGzip))
+	w.Write([]byte("nine\n"))
+	clock.set(time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC))
+	w.Write([]byte("ten\n"))
+
+	// The previous file is finalized on rollover, before Close.
+	f, err := os.Open(filepath.Join(dir, "app-2025-03-14-09.log.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if got := gunzip(t, f); got != "nine\n" {
+		t.Errorf("first file = %q", got)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f2, err := os.Open(filepath.Join(dir, "app-2025-03-14-10.log.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f2.Close()
+	if got := gunzip(t, f2); got != "ten\n" {
+		t.Errorf("second file = %q", got)
+	}
+}
+
+// upperWriter is a test compression algorithm upper-casing its input.
+type upperWriter struct {
+	w io.Writer
+}
+
+func (u upperWriter) Write(p []byte) (int, error) { return u.w.Write(bytes.ToUpper(p)) }
+func (upperWriter) Close() error                  { return nil }
+
+func TestRegisterCompression(t *testing.T) {
+	const algo = CompressionZstd + 1
+	RegisterCompression(algo, ".up", func(w io.Writer) io.WriteCloser { return upperWriter{w} })
+	if algo.ext() != ".up" || algo.check() != nil {
+		t.Fatalf("registered algo %d with extension %q", algo, algo.ext())
+	}
+
+	var out bytes.Buffer
+	w := NewCompressedWriter(&out, algo)
+	w.Write([]byte("quiet\n"))
+	w.Close()
+	if out.String() != "QUIET\n" {
+		t.Errorf("output = %q", out.String())
+	}
+
+	rw := NewRotatingFileWriter("logs", "app", "log", DailyRotation(time.UTC), 0, WithRotationCompression(algo))
+	if got := rw.Filename(time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)); got != filepath.Join("logs", "app-2025-03-14.log.up") {
+		t.Errorf("Filename = %q", got)
+	}
+	if unknown := algo + 100; unknown.ext() != "" {
+		t.Errorf("unregistered algo has extension %q", unknown.ext())
+	}
+}
+
+func TestUnregisteredCompression(t *testing.T) {
+	// The root package tests do not import ezlog/zstd.
+	_, err := New().AsLocal().WithWriter(&bytes.Buffer{}).WithCompression(CompressionZstd).BuildE()
+	if err == nil || !strings.Contains(err.Error(), "import github.com/ezydark/ezlog/zstd") {
+		t.Errorf("BuildE() = %v, want the missing zstd package", err)
+	}
+
+	var out, diags bytes.Buffer
+	l := New().AsLocal().WithWriter(&out).WithJSONOutput().WithDiagnostics(&diags).
+		WithCompression(CompressionZstd).Build()
+	l.Info().Msg("plain")
+	if !strings.Contains(out.String(), `"message":"plain"`) {
+		t.Errorf("output = %q, want the uncompressed event", out.String())
+	}
+	if want := "[ezlog] error: ezlog: compression zstd is not registered, import github.com/ezydark/ezlog/zstd, writing uncompressed output\n"; diags.String() != want {
+		t.Errorf("diagnostics = %q, want %q", diags.String(), want)
+	}
+
+	if got := (CompressionZstd + 50).check(); got == nil || got.Error() != "ezlog: compression CompressionAlgo(52) is not registered" {
+		t.Errorf("check() = %v", got)
+	}
+}
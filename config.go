@@ -0,0 +1,272 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// Output formats of Config.Format.
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+	FormatLogfmt  = "logfmt"
+)
+
+// Config is the logger setup of a configuration file, for services keeping
+// all their settings in one place:
+//
+//	logging:
+//	  level: info
+//	  format: json
+//	  tag: api
+//	  file:
+//	    path: /var/log/api/api.log
+//	    max_size_mb: 100
+//	    max_backups: 5
+//	  tag_levels:
+//	    db: warn
+//	  redact: [password, token]
+//	  error_sampling: 0.1
+//
+// The zero value logs every level to the console.
+type Config struct {
+	// Level is the minimum level, such as "info" or "warn". It defaults to
+	// trace.
+	Level string `json:"level" yaml:"level"`
+	// Format is "console", the default, "json" or "logfmt".
+	Format string `json:"format" yaml:"format"`
+	// NoColor disables colors.
+	NoColor bool `json:"no_color" yaml:"no_color"`
+	// Tag is the tag of the logger.
+	Tag string `json:"tag" yaml:"tag"`
+	// TimeFormat is the layout of console timestamps.
+	TimeFormat string `json:"time_format" yaml:"time_format"`
+	// UTC renders timestamps in UTC.
+	UTC bool `json:"utc" yaml:"utc"`
+	// Caller adds the file and line of the logging call.
+	Caller bool `json:"caller" yaml:"caller"`
+	// File writes to a file instead of the standard output.
+	File FileConfig `json:"file" yaml:"file"`
+	// TagLevels overrides Level for the loggers of the given tags.
+	TagLevels map[string]string `json:"tag_levels" yaml:"tag_levels"`
+	// Redact lists the fields whose values are replaced with "[REDACTED]".
+	Redact []string `json:"redact" yaml:"redact"`
+	// ErrorSampling keeps only this fraction of error events, all of them
+	// when 0.
+	ErrorSampling float64 `json:"error_sampling" yaml:"error_sampling"`
+}
+
+// FileConfig is the log file setup of a Config.
+type FileConfig struct {
+	// Path is the path of the file. The file is not used when it is empty.
+	Path string `json:"path" yaml:"path"`
+	// MaxSizeMB rotates the file once it reaches this many megabytes. The
+	// file is never rotated when it is 0.
+	MaxSizeMB int `json:"max_size_mb" yaml:"max_size_mb"`
+	// MaxBackups is the number of rotated files kept, all of them when 0.
+	MaxBackups int `json:"max_backups" yaml:"max_backups"`
+	// Compress gzip-compresses the rotated files.
+	Compress bool `json:"compress" yaml:"compress"`
+}
+
+// configFormats holds the decoders of the config file extensions added with
+// RegisterConfigFormat.
+var configFormats struct {
+	sync.RWMutex
+	m map[string]func(data []byte, cfg *Config) error
+}
+
+// RegisterConfigFormat makes LoadConfig decode the files ending with ext, dot
+// included, with decode, which should reject unknown keys. It is meant to be
+// called by the packages providing a format, such as ezlog/yaml, so the
+// programs not using one do not depend on its library.
+func RegisterConfigFormat(ext string, decode func(data []byte, cfg *Config) error) {
+	configFormats.Lock()
+	defer configFormats.Unlock()
+	if configFormats.m == nil {
+		configFormats.m = make(map[string]func([]byte, *Config) error)
+	}
+	configFormats.m[strings.ToLower(ext)] = decode
+}
+
+// configFormat returns the decoder registered for ext, if any, and the
+// supported extensions.
+func configFormat(ext string) (func([]byte, *Config) error, []string) {
+	configFormats.RLock()
+	defer configFormats.RUnlock()
+	exts := []string{".json"}
+	for e := range configFormats.m {
+		exts = append(exts, e)
+	}
+	sort.Strings(exts)
+	return configFormats.m[ext], exts
+}
+
+// LoadConfig reads a Config from the file at path, decoded depending on its
+// extension: JSON for ".json", or a format added with RegisterConfigFormat.
+// YAML files need the ezlog/yaml package:
+//
+//	import _ "github.com/ezydark/ezlog/yaml"
+//
+// Unknown keys are rejected so typos do not go unnoticed. The Config is
+// validated.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("ezlog: read config: %w", err)
+	}
+	return parseConfig(path, data)
+}
+
+// parseConfig decodes and validates the Config data read from path.
+func parseConfig(path string, data []byte) (Config, error) {
+	var cfg Config
+	var err error
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".json" {
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.DisallowUnknownFields()
+		err = d.Decode(&cfg)
+	} else if decode, exts := configFormat(ext); decode != nil {
+		err = decode(data, &cfg)
+	} else if ext == ".yaml" || ext == ".yml" {
+		return Config{}, fmt.Errorf("ezlog: config %s: YAML is not registered, import github.com/ezydark/ezlog/yaml", path)
+	} else {
+		return Config{}, fmt.Errorf("ezlog: config %s: unknown extension %q, want %s", path, ext, strings.Join(exts, ", "))
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("ezlog: parse config %s: %w", path, err)
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate reports every invalid setting of c.
+func (c Config) Validate() error {
+	var errs []error
+	if c.Level != "" {
+		if _, err := ParseLevel(c.Level); err != nil {
+			errs = append(errs, fmt.Errorf("level: unknown level %q, want trace, debug, info, warn, error, fatal or panic", c.Level))
+		}
+	}
+	switch strings.ToLower(c.Format) {
+	case "", FormatConsole, FormatJSON, FormatLogfmt:
+	default:
+		errs = append(errs, fmt.Errorf("format: unknown format %q, want console, json or logfmt", c.Format))
+	}
+
+	tags := make([]string, 0, len(c.TagLevels))
+	for tag := range c.TagLevels {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		if _, err := ParseLevel(c.TagLevels[tag]); err != nil {
+			errs = append(errs, fmt.Errorf("tag_levels.%s: unknown level %q, want trace, debug, info, warn, error, fatal or panic", tag, c.TagLevels[tag]))
+		}
+	}
+
+	if c.ErrorSampling < 0 || c.ErrorSampling > 1 {
+		errs = append(errs, fmt.Errorf("error_sampling: %g is not in [0,1], use 0 to keep all error events", c.ErrorSampling))
+	}
+
+	f := c.File
+	if f.MaxSizeMB < 0 {
+		errs = append(errs, fmt.Errorf("file.max_size_mb: %d is negative, use 0 to never rotate", f.MaxSizeMB))
+	}
+	if f.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("file.max_backups: %d is negative, use 0 to keep all backups", f.MaxBackups))
+	}
+	if f.Path == "" && (f.MaxSizeMB != 0 || f.MaxBackups != 0 || f.Compress) {
+		errs = append(errs, errors.New("file.path: required when other file settings are set"))
+	}
+	if f.Compress && f.MaxSizeMB == 0 {
+		errs = append(errs, errors.New("file.compress: only rotated files are compressed, set file.max_size_mb"))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("ezlog: invalid config: %w", errors.Join(errs...))
+}
+
+// ApplyTo configures b with c. The level is the one of the builder's tag in
+// TagLevels, or Level; the tag is set when c.Tag is not empty, so a Config can
+// configure the loggers of several tags:
+//
+//	cfg.ApplyTo(ezlog.New().AsLocal().WithTag("db"))
+//
+// The file, if any, is opened when the logger is built, where BuildE reports
+// the errors.
+func (c Config) ApplyTo(b *LogBuilder) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if c.Tag != "" {
+		b.WithTag(c.Tag)
+	}
+	level := c.Level
+	if l, ok := c.TagLevels[b.tag]; ok {
+		level = l
+	}
+	if level != "" {
+		l, _ := ParseLevel(level)
+		b.WithLevel(l)
+	}
+
+	switch strings.ToLower(c.Format) {
+	case FormatJSON:
+		b.WithJSONOutput()
+	case FormatLogfmt:
+		b.WithLogfmtOutput()
+	}
+	if c.NoColor {
+		b.WithColorSupport(ColorLevelNone)
+	}
+	if c.TimeFormat != "" {
+		b.WithTimeFormat(c.TimeFormat)
+	}
+	if c.UTC {
+		b.WithUTC()
+	}
+	if c.Caller {
+		b.WithCaller()
+	}
+	if len(c.Redact) > 0 {
+		b.WithRedactedFields(c.Redact...)
+	}
+	if c.ErrorSampling != 0 {
+		b.WithErrorSampling(c.ErrorSampling)
+	}
+
+	if f := c.File; f.Path != "" {
+		b.WithWriterF(func() (io.Writer, error) {
+			if f.MaxSizeMB > 0 {
+				return NewSizeRotatingFileWriter(f.Path, f.MaxSizeMB, f.MaxBackups, f.Compress), nil
+			}
+			if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+				return nil, err
+			}
+			return os.OpenFile(f.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		})
+	}
+	return nil
+}
+
+// NewFromConfig builds the global logger configured by c.
+func NewFromConfig(c Config) (*zerolog.Logger, error) {
+	b := New()
+	if err := c.ApplyTo(b); err != nil {
+		return nil, err
+	}
+	return b.BuildE()
+}
@@ -0,0 +1,194 @@
+package ezlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes data to a config file named name in a temporary
+// directory and returns its path.
+func writeConfig(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigJSON(t *testing.T) {
+	path := writeConfig(t, "log.json", `{
+		"level": "info",
+		"format": "json",
+		"tag": "api",
+		"file": {"path": "/var/log/api.log", "max_size_mb": 10, "max_backups": 3, "compress": true},
+		"tag_levels": {"db": "warn"},
+		"redact": ["password"]
+	}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "info" || cfg.Format != FormatJSON || cfg.Tag != "api" || cfg.TagLevels["db"] != "warn" ||
+		cfg.File != (FileConfig{Path: "/var/log/api.log", MaxSizeMB: 10, MaxBackups: 3, Compress: true}) ||
+		len(cfg.Redact) != 1 || cfg.Redact[0] != "password" {
+		t.Errorf("config = %+v", cfg)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name, file, data, want string
+	}{
+		{"unknown key", "log.json", `{"levle": "info"}`, `unknown field "levle"`},
+		{"unknown extension", "log.toml", `level = "info"`, `unknown extension ".toml", want .json`},
+		{"yaml without its package", "log.yaml", `level: info`, `YAML is not registered, import github.com/ezydark/ezlog/yaml`},
+		{"invalid level", "log.json", `{"level": "verbose"}`, `level: unknown level "verbose"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, tt.file, tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadConfig() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil || !strings.Contains(err.Error(), "read config") {
+		t.Errorf("LoadConfig(missing) error = %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"zero value", Config{}, ""},
+		{"level case", Config{Level: "WARN", Format: "JSON"}, ""},
+		{"format", Config{Format: "xml"}, `format: unknown format "xml", want console, json or logfmt`},
+		{"tag level", Config{TagLevels: map[string]string{"db": "loud"}}, `tag_levels.db: unknown level "loud"`},
+		{"error sampling", Config{ErrorSampling: 2}, "error_sampling: 2 is not in [0,1]"},
+		{"max size", Config{File: FileConfig{Path: "a.log", MaxSizeMB: -1}}, "file.max_size_mb: -1 is negative"},
+		{"max backups", Config{File: FileConfig{Path: "a.log", MaxBackups: -1}}, "file.max_backups: -1 is negative"},
+		{"no path", Config{File: FileConfig{MaxSizeMB: 1}}, "file.path: required"},
+		{"compress unrotated", Config{File: FileConfig{Path: "a.log", Compress: true}}, "file.compress: only rotated files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("Validate() = %v, want nil", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("Validate() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	err := Config{Level: "x", Format: "y"}.Validate()
+	if err == nil || !strings.Contains(err.Error(), "level:") || !strings.Contains(err.Error(), "format:") {
+		t.Errorf("Validate() = %v, want both errors", err)
+	}
+}
+
+func TestConfigApplyTo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	cfg := Config{
+		Level:     "info",
+		Format:    FormatJSON,
+		File:      FileConfig{Path: path},
+		TagLevels: map[string]string{"db": "error"},
+		Redact:    []string{"Password"},
+	}
+	for _, tag := range []string{"api", "db"} {
+		b := New().AsLocal().WithTag(tag)
+		if err := cfg.ApplyTo(b); err != nil {
+			t.Fatal(err)
+		}
+		l, err := b.BuildE()
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.Debug().Msg(tag + " debug")
+		l.Info().Str("password", "hunter2").Msg(tag + " info")
+		l.Error().Msg(tag + " error")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{`"api info"`, `"api error"`, `"db error"`, `"password":"[REDACTED]"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log file lacks %s:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"debug", `"db info"`, "hunter2"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("log file has %s:\n%s", unwanted, got)
+		}
+	}
+
+	if err := (Config{Level: "nope"}).ApplyTo(New().AsLocal()); err == nil {
+		t.Error("ApplyTo accepted an invalid config")
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	restoreGlobalLogger(t)
+	if _, err := NewFromConfig(Config{Format: "yaml"}); err == nil {
+		t.Error("NewFromConfig accepted an invalid format")
+	}
+	blocked := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocked, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFromConfig(Config{File: FileConfig{Path: filepath.Join(blocked, "app.log")}}); err == nil {
+		t.Error("NewFromConfig did not report the file error")
+	}
+}
+
+func TestSizeRotatingFileWriter(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "app.log")
+		w := NewSizeRotatingFileWriter(path, 1, 2, compress)
+		w.maxSize = 10
+		for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n", "six\n"} {
+			if _, err := w.Write([]byte(line)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]string{path: "six\n", path + ".1": "four\nfive\n", path + ".2": "three\n"}
+		for name, content := range want {
+			if compress && name != path {
+				f, err := os.Open(name + ".gz")
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := gunzip(t, f)
+				f.Close()
+				if got != content {
+					t.Errorf("compressed %s = %q, want %q", name, got, content)
+				}
+				continue
+			}
+			if got, err := os.ReadFile(name); err != nil || string(got) != content {
+				t.Errorf("%s = %q, %v, want %q", name, got, err, content)
+			}
+		}
+		if files := dirFiles(t, filepath.Dir(path)); len(files) != 3 {
+			t.Errorf("compress=%v: files = %v, want 2 backups", compress, files)
+		}
+		if _, err := w.Write([]byte("late\n")); err != os.ErrClosed {
+			t.Errorf("Write after Close: %v", err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ezlog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// contextExtractor maps a context key to the field it is logged as.
+type contextExtractor struct {
+	key       any
+	fieldName string
+}
+
+// contextExtractors holds the extractors registered with RegisterContextExtractor.
+var contextExtractors = struct {
+	sync.RWMutex
+	list []contextExtractor
+}{}
+
+// RegisterContextExtractor makes loggers built with WithContextExtraction add
+// the value stored in the event context under key as the field fieldName.
+// Registering the same key again replaces its field name.
+func RegisterContextExtractor(key any, fieldName string) {
+	contextExtractors.Lock()
+	defer contextExtractors.Unlock()
+
+	for i, ex := range contextExtractors.list {
+		if ex.key == key {
+			contextExtractors.list[i].fieldName = fieldName
+			return
+		}
+	}
+	contextExtractors.list = append(contextExtractors.list, contextExtractor{key: key, fieldName: fieldName})
+}
+
+// WithContextExtraction installs a hook adding the registered context values
+// to every event carrying a context, e.g. one logged through CtxInfo or with
+// zerolog's Event.Ctx.
+func (b *LogBuilder) WithContextExtraction() *LogBuilder {
+	b.contextExtraction = true
+	return b
+}
+
+// contextHook adds the registered context values to events.
+type contextHook struct{}
+
+// Run implements zerolog.Hook.
+func (contextHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	ctx := e.GetCtx()
+	if ctx == nil || ctx == context.Background() {
+		return
+	}
+
+	contextExtractors.RLock()
+	defer contextExtractors.RUnlock()
+
+	for _, ex := range contextExtractors.list {
+		v := ctx.Value(ex.key)
+		if v == nil {
+			continue
+		}
+		switch vv := v.(type) {
+		case string:
+			e.Str(ex.fieldName, vv)
+		case error:
+			e.Str(ex.fieldName, vv.Error())
+		case fmt.Stringer:
+			e.Str(ex.fieldName, SafeString(vv))
+		case int:
+			e.Int(ex.fieldName, vv)
+		case int64:
+			e.Int64(ex.fieldName, vv)
+		case uint64:
+			e.Uint64(ex.fieldName, vv)
+		case bool:
+			e.Bool(ex.fieldName, vv)
+		default:
+			e.Interface(ex.fieldName, vv)
+		}
+	}
+}
+
+// CtxDebug logs msg at debug level on the global logger with ctx attached.
+func CtxDebug(ctx context.Context, msg string) {
+	log.Debug().Ctx(ctx).Msg(msg)
+}
+
+// CtxInfo logs msg at info level on the global logger with ctx attached.
+func CtxInfo(ctx context.Context, msg string) {
+	log.Info().Ctx(ctx).Msg(msg)
+}
+
+// CtxWarn logs msg at warn level on the global logger with ctx attached.
+func CtxWarn(ctx context.Context, msg string) {
+	log.Warn().Ctx(ctx).Msg(msg)
+}
+
+// CtxError logs msg and err at error level on the global logger with ctx attached.
+func CtxError(ctx context.Context, err error, msg string) {
+	log.Error().Ctx(ctx).Err(err).Msg(msg)
+}
@@ -0,0 +1,67 @@
+package ezlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testCtxKey is a context key of the tests.
+type testCtxKey string
+
+func TestWithContextExtraction(t *testing.T) {
+	RegisterContextExtractor(testCtxKey("user"), "user_id")
+	RegisterContextExtractor(testCtxKey("tenant"), "tenant")
+	RegisterContextExtractor(testCtxKey("tenant"), "tenant_id")
+	RegisterContextExtractor(testCtxKey("attempt"), "attempt")
+	RegisterContextExtractor(testCtxKey("cause"), "cause")
+
+	ctx := context.WithValue(context.Background(), testCtxKey("user"), "ada")
+	ctx = context.WithValue(ctx, testCtxKey("tenant"), "acme")
+	ctx = context.WithValue(ctx, testCtxKey("attempt"), 3)
+	ctx = context.WithValue(ctx, testCtxKey("cause"), errors.New("timeout"))
+
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithContextExtraction().Build()
+	l.Info().Ctx(ctx).Msg("retrying")
+
+	got := buf.String()
+	for _, want := range []string{`"user_id":"ada"`, `"tenant_id":"acme"`, `"attempt":3`, `"cause":"timeout"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output %s lacks %s", got, want)
+		}
+	}
+	if strings.Contains(got, `"tenant":`) {
+		t.Errorf("output %s uses the replaced field name", got)
+	}
+}
+
+func TestWithoutContextExtraction(t *testing.T) {
+	RegisterContextExtractor(testCtxKey("user"), "user_id")
+	ctx := context.WithValue(context.Background(), testCtxKey("user"), "ada")
+
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+	l.Info().Ctx(ctx).Msg("hello")
+	// Events without a context are left alone too.
+	New().AsLocal().WithWriter(&buf).WithJSONOutput().WithContextExtraction().Build().Info().Msg("hello")
+
+	if strings.Contains(buf.String(), "user_id") {
+		t.Errorf("output %s has context fields", buf.String())
+	}
+}
+
+func TestCtxInfo(t *testing.T) {
+	restoreGlobalLogger(t)
+	RegisterContextExtractor(testCtxKey("user"), "user_id")
+
+	var buf bytes.Buffer
+	New().WithWriter(&buf).WithJSONOutput().WithContextExtraction().Build()
+	CtxInfo(context.WithValue(context.Background(), testCtxKey("user"), "ada"), "hello")
+
+	if got := buf.String(); !strings.Contains(got, `"level":"info"`) || !strings.Contains(got, `"user_id":"ada"`) {
+		t.Errorf("output = %s", got)
+	}
+}
@@ -0,0 +1,149 @@
+package ezlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// csvFlushSize is how many bytes a CSVWriter buffers before flushing.
+const csvFlushSize = 64 << 10
+
+// csvColumnAliases maps the short column names accepted by NewCSVWriter to
+// zerolog's field names.
+var csvColumnAliases = map[string]string{
+	"ts":      zerolog.TimestampFieldName,
+	"time":    zerolog.TimestampFieldName,
+	"level":   zerolog.LevelFieldName,
+	"message": zerolog.MessageFieldName,
+	"msg":     zerolog.MessageFieldName,
+}
+
+// CSVWriter re-encodes JSON events as CSV rows, one per event, for
+// spreadsheet-friendly exports. Rows are buffered and flushed every 64 KiB
+// and on Close.
+// It should be created using NewCSVWriter.
+type CSVWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	buf     *bufio.Writer
+	csv     *csv.Writer
+	columns []string
+	header  bool
+}
+
+// NewCSVWriter creates a CSVWriter writing the given columns to w. Columns
+// are field names, with "ts", "level", "tag" and "message" for the common
+// ones, and dotted paths such as "http.status" for nested fields. The header
+// row is written before the first event; missing fields are left empty.
+func NewCSVWriter(w io.Writer, columns []string) *CSVWriter {
+	buf := bufio.NewWriterSize(w, csvFlushSize)
+	return &CSVWriter{
+		out:     w,
+		buf:     buf,
+		csv:     csv.NewWriter(buf),
+		columns: columns,
+	}
+}
+
+// Write implements io.Writer.
+func (w *CSVWriter) Write(p []byte) (int, error) {
+	var evt map[string]any
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %w", err)
+	}
+
+	row := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		if v, ok := lookupPath(evt, column); ok {
+			row[i] = csvValue(v)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.header {
+		if err := w.csv.Write(w.columns); err != nil {
+			return 0, err
+		}
+		w.header = true
+	}
+	if err := w.csv.Write(row); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes the buffered rows to the underlying writer.
+func (w *CSVWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	return w.buf.Flush()
+}
+
+// Close flushes the buffered rows and closes the underlying writer if it
+// implements io.Closer.
+func (w *CSVWriter) Close() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if c, ok := w.out.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// lookupPath returns the value of column in evt, following dotted paths into
+// nested objects.
+func lookupPath(evt map[string]any, column string) (any, bool) {
+	if alias, ok := csvColumnAliases[column]; ok {
+		column = alias
+	}
+	if v, ok := evt[column]; ok {
+		return v, true
+	}
+
+	head, rest, found := strings.Cut(column, ".")
+	if !found {
+		return nil, false
+	}
+	nested, ok := evt[head].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return lookupPath(nested, rest)
+}
+
+// csvValue renders a decoded JSON value as a CSV cell.
+func csvValue(v any) string {
+	switch vv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return vv
+	case json.Number:
+		return vv.String()
+	case bool:
+		return fmt.Sprint(vv)
+	default:
+		b, err := json.Marshal(vv)
+		if err != nil {
+			return fmt.Sprint(vv)
+		}
+		return string(b)
+	}
+}
@@ -0,0 +1,68 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, []string{"ts", "level", "tag", "message", "http.status", "user"})
+	events := []string{
+		`{"time":"2025-03-14T09:26:53Z","level":"info","tag":"api","message":"ok","http":{"status":200,"path":"/"}}`,
+		`{"level":"error","message":"a, b\nand \"c\"","user":{"id":7},"http":{"status":"none"}}`,
+		`{"level":"debug"}`,
+	}
+	for _, evt := range events {
+		if _, err := w.Write([]byte(evt + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("rows written before Flush: %q", buf.String())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV %q: %v", buf.String(), err)
+	}
+	want := [][]string{
+		{"ts", "level", "tag", "message", "http.status", "user"},
+		{"2025-03-14T09:26:53Z", "info", "api", "ok", "200", ""},
+		{"", "error", "", "a, b\nand \"c\"", "none", `{"id":7}`},
+		{"", "debug", "", "", "", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
+
+func TestCSVWriterFlushesAtThreshold(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, []string{"message"})
+	evt := []byte(`{"message":"` + strings.Repeat("x", 1000) + `"}`)
+	for range csvFlushSize/1000 + 10 {
+		w.Write(evt)
+	}
+	if buf.Len() == 0 {
+		t.Error("nothing flushed after more than 64 KiB of rows")
+	}
+}
+
+func TestCSVWriterInvalidEvent(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, []string{"message"})
+	if _, err := w.Write([]byte("not json")); err == nil {
+		t.Error("no error for an invalid event")
+	}
+	w.Close()
+	if buf.Len() != 0 {
+		t.Errorf("output = %q, want nothing, not even the header", buf.String())
+	}
+}
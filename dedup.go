@@ -0,0 +1,157 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// DedupOption configures WithDedup.
+type DedupOption func(*dedupWriter)
+
+// WithDedupExemptLevel never suppresses events at or above level, e.g.
+// zerolog.ErrorLevel to always see errors and fatal events.
+func WithDedupExemptLevel(level zerolog.Level) DedupOption {
+	return func(w *dedupWriter) {
+		w.exempt = level
+	}
+}
+
+// WithDedup suppresses consecutive events with the same level, message and
+// fields, the timestamp aside, within window of the first one. When a
+// different event arrives or the window expires, a single "last message
+// repeated N times" event is written with the original level and tag. Only
+// the last event is tracked.
+func (b *LogBuilder) WithDedup(window time.Duration, opts ...DedupOption) *LogBuilder {
+	b.dedupWindow = window
+	b.dedup = opts
+	return b
+}
+
+// dedupWriter drops repeated JSON events before they reach out.
+type dedupWriter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	window time.Duration
+	exempt zerolog.Level
+
+	last *dedupState
+}
+
+// dedupState is the last event written and how often it was repeated since.
+type dedupState struct {
+	key      string
+	start    time.Time
+	level    any
+	tag      any
+	lastTime any
+	repeats  int
+	timer    *time.Timer
+}
+
+// newDedupWriter creates a dedupWriter writing to out.
+func newDedupWriter(out io.Writer, window time.Duration, opts ...DedupOption) *dedupWriter {
+	w := &dedupWriter{out: out, window: window, exempt: zerolog.Disabled}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Write implements io.Writer.
+func (w *dedupWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *dedupWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	var evt map[string]any
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return writeLevel(w.out, level, p)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if name, ok := evt[zerolog.LevelFieldName].(string); ok {
+		if l, err := ParseLevel(name); err == nil && l >= w.exempt {
+			if err := w.flushLocked(); err != nil {
+				return 0, err
+			}
+			return writeLevel(w.out, level, p)
+		}
+	}
+
+	ts := evt[zerolog.TimestampFieldName]
+	delete(evt, zerolog.TimestampFieldName)
+	key, err := json.Marshal(evt)
+	if err != nil {
+		return writeLevel(w.out, level, p)
+	}
+
+	now := time.Now()
+	if last := w.last; last != nil && last.key == string(key) && now.Sub(last.start) < w.window {
+		last.repeats++
+		last.lastTime = ts
+		return len(p), nil
+	}
+
+	if err := w.flushLocked(); err != nil {
+		return 0, err
+	}
+	state := &dedupState{
+		key:   string(key),
+		start: now,
+		level: evt[zerolog.LevelFieldName],
+		tag:   evt["tag"],
+	}
+	state.timer = time.AfterFunc(w.window, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.last == state {
+			_ = w.flushLocked()
+		}
+	})
+	w.last = state
+	return writeLevel(w.out, level, p)
+}
+
+// flushLocked writes the summary of the tracked event, if it was repeated,
+// and stops tracking it. It must be called with w.mu held.
+func (w *dedupWriter) flushLocked() error {
+	last := w.last
+	if last == nil {
+		return nil
+	}
+	w.last = nil
+	last.timer.Stop()
+	if last.repeats == 0 {
+		return nil
+	}
+
+	summary := map[string]any{
+		zerolog.MessageFieldName: fmt.Sprintf("last message repeated %d times", last.repeats),
+	}
+	if last.level != nil {
+		summary[zerolog.LevelFieldName] = last.level
+	}
+	if last.tag != nil {
+		summary["tag"] = last.tag
+	}
+	if last.lastTime != nil {
+		summary[zerolog.TimestampFieldName] = last.lastTime
+	}
+	p, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	_, err = w.out.Write(append(p, '\n'))
+	return err
+}
@@ -0,0 +1,82 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestDedupBurst(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithTag("net").WithJSONOutput().WithDedup(time.Minute).Build()
+	for range 5 {
+		l.Warn().Str("addr", "db:5432").Msg("reconnecting")
+	}
+	l.Warn().Str("addr", "cache:6379").Msg("reconnecting")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("output = %q, want the event, a summary and the new event", buf.String())
+	}
+	if !strings.Contains(lines[0], `"addr":"db:5432"`) || !strings.Contains(lines[2], `"addr":"cache:6379"`) {
+		t.Errorf("events = %q", lines)
+	}
+	for _, want := range []string{`"message":"last message repeated 4 times"`, `"level":"warn"`, `"tag":"net"`, `"time":`} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("summary %q lacks %s", lines[1], want)
+		}
+	}
+}
+
+func TestDedupWindowExpiry(t *testing.T) {
+	var buf lockedBuffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithDedup(20 * time.Millisecond).Build()
+	l.Info().Msg("tick")
+	l.Info().Msg("tick")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(buf.String(), "last message repeated 1 times") {
+		if time.Now().After(deadline) {
+			t.Fatalf("no summary after the window: %q", buf.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	l.Info().Msg("tick")
+	if n := strings.Count(buf.String(), `"message":"tick"`); n != 2 {
+		t.Errorf("got %d tick events, want 2 as the window expired", n)
+	}
+}
+
+func TestDedupExemptLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithJSONOutput().WithDedup(time.Minute, WithDedupExemptLevel(zerolog.ErrorLevel)).Build()
+	l.Info().Msg("same")
+	l.Info().Msg("same")
+	l.Error().Msg("boom")
+	l.Error().Msg("boom")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[1], "repeated 1 times") {
+		t.Fatalf("output = %q, want the info event, its summary and both errors", lines)
+	}
+	if strings.Count(buf.String(), `"message":"boom"`) != 2 {
+		t.Errorf("errors were deduplicated: %q", lines)
+	}
+}
+
+func TestDedupConsoleSummary(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithTag("net").WithDedup(time.Minute).Build()
+	l.Warn().Msg("again")
+	l.Warn().Msg("again")
+	l.Info().Msg("other")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "[WARN] [net] last message repeated 1 times") {
+		t.Errorf("output = %q", lines)
+	}
+}
@@ -0,0 +1,105 @@
+package ezlog
+
+import (
+	"os"
+	"sort"
+
+	"github.com/rs/zerolog"
+)
+
+// contextField is a field added to every event of the built logger.
+type contextField struct {
+	key   string
+	value any
+}
+
+// buildValue is a field value computed once, when the logger is built.
+type buildValue func() any
+
+// WithField adds a field to every event of the logger.
+func (b *LogBuilder) WithField(key string, value any) *LogBuilder {
+	b.fields = append(b.fields, contextField{key: key, value: value})
+	return b
+}
+
+// WithDefaultFields adds the entries of fields to every event of the logger,
+// in key order. Nested map[string]any values are logged as objects.
+// It can be combined with WithField.
+func (b *LogBuilder) WithDefaultFields(fields map[string]any) *LogBuilder {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		b.WithField(key, fields[key])
+	}
+	return b
+}
+
+// WithHostnameField adds the machine's hostname under fieldName to every
+// event. The hostname is read once by Build, and is "unknown" when it cannot
+// be read.
+func (b *LogBuilder) WithHostnameField(fieldName string) *LogBuilder {
+	b.fields = append(b.fields, contextField{key: fieldName, value: buildValue(func() any {
+		host, err := os.Hostname()
+		if err != nil {
+			return "unknown"
+		}
+		return host
+	})})
+	return b
+}
+
+// WithPIDField adds the process ID under fieldName to every event.
+func (b *LogBuilder) WithPIDField(fieldName string) *LogBuilder {
+	b.fields = append(b.fields, contextField{key: fieldName, value: buildValue(func() any {
+		return os.Getpid()
+	})})
+	return b
+}
+
+// WithAppVersion adds version under the "app_version" field to every event.
+func (b *LogBuilder) WithAppVersion(version string) *LogBuilder {
+	return b.WithField("app_version", version)
+}
+
+// applyFields adds the builder's fields to the logger context.
+func (b *LogBuilder) applyFields(l zerolog.Logger) zerolog.Logger {
+	if len(b.fields) == 0 {
+		return l
+	}
+	ctx := l.With()
+	for _, f := range b.fields {
+		if fn, ok := f.value.(buildValue); ok {
+			ctx = ctx.Fields([]any{f.key, fn()})
+			continue
+		}
+		if m, ok := f.value.(map[string]any); ok {
+			ctx = ctx.Dict(f.key, dictFromMap(m))
+			continue
+		}
+		ctx = ctx.Fields([]any{f.key, f.value})
+	}
+	return ctx.Logger()
+}
+
+// dictFromMap converts m to a zerolog dictionary, in key order.
+func dictFromMap(m map[string]any) *zerolog.Event {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	dict := zerolog.Dict()
+	for _, key := range keys {
+		if nested, ok := m[key].(map[string]any); ok {
+			dict = dict.Dict(key, dictFromMap(nested))
+			continue
+		}
+		dict = dict.Fields([]any{key, m[key]})
+	}
+	return dict
+}
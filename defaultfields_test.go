@@ -0,0 +1,46 @@
+package ezlog
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWithDefaultFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().
+		WithField("service", "api").
+		WithDefaultFields(map[string]any{
+			"zone":    "eu-1",
+			"version": 3,
+			"build":   map[string]any{"commit": "abc123", "dirty": false},
+		}).
+		Build()
+	l.Info().Msg("started")
+
+	got := buf.String()
+	want := `"service":"api","build":{"commit":"abc123","dirty":false},"version":3,"zone":"eu-1"`
+	if !strings.Contains(got, want) {
+		t.Errorf("output = %s, want fields %s", got, want)
+	}
+}
+
+func TestDeploymentFields(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithJSONOutput().
+		WithHostnameField("host").WithPIDField("pid").WithAppVersion("1.4.2").WithField("region", "eu").Build()
+	l.Info().Msg("started")
+
+	evt := decodeLines(t, &buf)[0]
+	want := map[string]any{"host": host, "pid": float64(os.Getpid()), "app_version": "1.4.2", "region": "eu"}
+	for k, v := range want {
+		if evt[k] != v {
+			t.Errorf("%s = %v, want %v", k, evt[k], v)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package ezlog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// diagnosticsInterval is the minimum delay between two diagnostics of the
+// same kind; the ones in between are counted and reported with the next.
+const diagnosticsInterval = 10 * time.Second
+
+// diagnosticsDropThreshold is the number of events an asynchronous writer
+// must drop before it is reported.
+const diagnosticsDropThreshold = 100
+
+// diagnostics writes ezlog's own operational messages, such as write
+// failures, as plain "[ezlog] level: message" lines. It never goes through
+// a logger, so a failing logger cannot recurse into itself.
+type diagnostics struct {
+	out   io.Writer
+	level zerolog.Level
+	now   func() time.Time
+
+	mu         sync.Mutex
+	last       map[string]time.Time
+	suppressed map[string]int
+}
+
+// newDiagnostics creates a diagnostics writing the messages at or above
+// level to out.
+func newDiagnostics(out io.Writer, level zerolog.Level) *diagnostics {
+	return &diagnostics{
+		out:        out,
+		level:      level,
+		now:        time.Now,
+		last:       make(map[string]time.Time),
+		suppressed: make(map[string]int),
+	}
+}
+
+// defaultDiagnostics receives the diagnostics of the writers not built by a
+// LogBuilder with WithDiagnostics: the standard error at the warn level,
+// unless the global logger was built with WithDiagnostics.
+var defaultDiagnostics atomic.Pointer[diagnostics]
+
+func init() {
+	defaultDiagnostics.Store(newDiagnostics(os.Stderr, zerolog.WarnLevel))
+}
+
+// diag reports a diagnostic to the default diagnostics.
+func diag(level zerolog.Level, kind, format string, args ...any) {
+	defaultDiagnostics.Load().report(level, kind, format, args...)
+}
+
+// report writes a diagnostic of the given kind, unless one of the same kind
+// was written less than diagnosticsInterval ago. A nil d reports to the
+// default diagnostics.
+func (d *diagnostics) report(level zerolog.Level, kind, format string, args ...any) {
+	if d == nil {
+		d = defaultDiagnostics.Load()
+	}
+	if level < d.level {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	if last, ok := d.last[kind]; ok && now.Sub(last) < diagnosticsInterval {
+		d.suppressed[kind]++
+		return
+	}
+	d.last[kind] = now
+
+	msg := fmt.Sprintf(format, args...)
+	if n := d.suppressed[kind]; n > 0 {
+		msg += fmt.Sprintf(" (%d similar messages suppressed)", n)
+		d.suppressed[kind] = 0
+	}
+	fmt.Fprintf(d.out, "[ezlog] %s: %s\n", LevelString(level), msg)
+}
+
+// WithDiagnostics writes ezlog's own operational messages about the logger,
+// such as write failures or events dropped by its asynchronous writer, to w
+// instead of the standard error. Messages are plain lines prefixed with
+// "[ezlog]", each kind written at most every 10 seconds; w must not be
+// written to by the logger itself. When the logger is global, the messages
+// of writers created outside of a builder, such as rotating writers, are
+// written to w too.
+func (b *LogBuilder) WithDiagnostics(w io.Writer) *LogBuilder {
+	b.diagnosticsOut = w
+	return b
+}
+
+// WithDiagnosticsLevel sets the minimum level of the diagnostics, warn by
+// default. Use zerolog.Disabled to silence them.
+func (b *LogBuilder) WithDiagnosticsLevel(level zerolog.Level) *LogBuilder {
+	b.diagnosticsLevel = &level
+	return b
+}
+
+// buildDiagnostics returns the diagnostics of the logger, nil for the
+// default ones, installing them as the default for a global logger.
+func (b *LogBuilder) buildDiagnostics() *diagnostics {
+	if b.diagnosticsOut == nil && b.diagnosticsLevel == nil {
+		return nil
+	}
+	out, level := b.diagnosticsOut, zerolog.WarnLevel
+	if out == nil {
+		out = os.Stderr
+	}
+	if b.diagnosticsLevel != nil {
+		level = *b.diagnosticsLevel
+	}
+	d := newDiagnostics(out, level)
+	if b.isGlobal {
+		defaultDiagnostics.Store(d)
+	}
+	return d
+}
+
+// diagnosticsWriter reports the write failures of out, and the events
+// dropped by the asynchronous writer of the logger, if any.
+type diagnosticsWriter struct {
+	out      io.Writer
+	d        *diagnostics
+	async    asyncWriter
+	reported atomic.Uint64
+}
+
+// Write implements io.Writer.
+func (w *diagnosticsWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *diagnosticsWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	n, err := writeLevel(w.out, level, p)
+	if err != nil {
+		w.d.report(zerolog.ErrorLevel, "write", "write failed: %v", err)
+	}
+	if w.async != nil {
+		dropped, reported := w.async.Dropped(), w.reported.Load()
+		if dropped-reported >= diagnosticsDropThreshold && w.reported.CompareAndSwap(reported, dropped) {
+			w.d.report(zerolog.WarnLevel, "drop", "%d events dropped by %T", dropped-reported, w.async)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,61 @@
+package ezlog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestDiagnosticsReportWriteFailureOnce(t *testing.T) {
+	var diags bytes.Buffer
+	l := New().AsLocal().WithWriter(failingWriter{}).WithJSONOutput().WithDiagnostics(&diags).Build()
+	for range 5 {
+		l.Info().Msg("lost")
+	}
+
+	lines := strings.Split(strings.TrimSpace(diags.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d diagnostics, want 1:\n%s", len(lines), diags.String())
+	}
+	if want := "[ezlog] error: write failed: disk full"; lines[0] != want {
+		t.Errorf("diagnostic = %q, want %q", lines[0], want)
+	}
+}
+
+func TestDiagnosticsLevel(t *testing.T) {
+	var diags bytes.Buffer
+	l := New().AsLocal().WithWriter(failingWriter{}).WithJSONOutput().
+		WithDiagnostics(&diags).WithDiagnosticsLevel(zerolog.Disabled).Build()
+	l.Info().Msg("lost")
+
+	if diags.Len() != 0 {
+		t.Errorf("diagnostics = %q, want none", diags.String())
+	}
+}
+
+func TestDiagnosticsKeepPerLevelSampling(t *testing.T) {
+	var buf, diags bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithDiagnostics(&diags).
+		WithSamplerPerLevel(map[zerolog.Level]uint32{zerolog.InfoLevel: 10}).Build()
+	for range 100 {
+		l.Info().Msg("sampled")
+		l.Warn().Msg("kept")
+	}
+
+	if got := strings.Count(buf.String(), "sampled"); got != 10 {
+		t.Errorf("got %d info events, want 10", got)
+	}
+	if got := strings.Count(buf.String(), "kept"); got != 100 {
+		t.Errorf("got %d warn events, want 100", got)
+	}
+}
@@ -0,0 +1,20 @@
+// Package ezlog builds zerolog loggers with colored console output, file and
+// network writers, and GORM logging.
+//
+// The integrations with other libraries live in subpackages, so that their
+// dependencies are only compiled into the programs importing them:
+//
+//   - logrus forwards logrus entries to ezlog, with LogrusHook.
+//   - otel exports events and GORM queries to OpenTelemetry.
+//   - prometheus exports the metrics of WithMetrics and WithEventMetrics.
+//   - sentry reports error events to Sentry.
+//   - stream serves log output to browsers over WebSocket, with
+//     WithStreamServer.
+//   - testlog captures log output in tests.
+//   - validator logs go-playground/validator errors and validates the models
+//     written through a GormLogger, with WithModelValidation.
+//   - yaml makes LoadConfig and WatchConfig read YAML files.
+//   - zap bridges ezlog and zap in both directions, with ZapAdapter and
+//     ZapCore.
+//   - zstd implements CompressionZstd.
+package ezlog
@@ -0,0 +1,186 @@
+package ezlog
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// DynamicLevel is a minimum level that can be changed while the program runs,
+// e.g. through LevelHandler. Loggers built with WithDynamicLevel drop the
+// events below it.
+// It should be created using NewDynamicLevel.
+type DynamicLevel struct {
+	level         atomic.Int32
+	errorSampling atomic.Uint64
+}
+
+// NewDynamicLevel creates a DynamicLevel set to level.
+func NewDynamicLevel(level zerolog.Level) *DynamicLevel {
+	d := &DynamicLevel{}
+	d.SetLevel(level)
+	return d
+}
+
+// Level returns the current level.
+func (d *DynamicLevel) Level() zerolog.Level {
+	return zerolog.Level(d.level.Load())
+}
+
+// SetLevel changes the level. Setting it to trace has the effect of debug:
+// Build sets zerolog's global level to debug, which drops trace events before
+// any logger sees them. Applications wanting trace events lower it with
+// zerolog.SetGlobalLevel(zerolog.TraceLevel) once their loggers are built.
+func (d *DynamicLevel) SetLevel(level zerolog.Level) {
+	d.level.Store(int32(level))
+}
+
+// String implements fmt.Stringer.
+func (d *DynamicLevel) String() string {
+	return LevelString(d.Level())
+}
+
+// Run implements zerolog.Hook.
+func (d *DynamicLevel) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level < d.Level() {
+		e.Discard()
+	}
+}
+
+// WithDynamicLevel drops the events below the current level of d.
+func (b *LogBuilder) WithDynamicLevel(d *DynamicLevel) *LogBuilder {
+	b.dynamicLevel = d
+	return b
+}
+
+// LevelHandlerOption configures LevelHandler.
+type LevelHandlerOption func(*levelHandler)
+
+// WithBasicAuth requires HTTP basic authentication with user and pass.
+func WithBasicAuth(user, pass string) LevelHandlerOption {
+	return func(h *levelHandler) {
+		h.user = user
+		h.pass = pass
+	}
+}
+
+// levelBody is the JSON body read and written by LevelHandler.
+type levelBody struct {
+	Level string `json:"level"`
+}
+
+// levelPage is the HTML form served to browsers by LevelHandler.
+var levelPage = template.Must(template.New("level").Parse(`<!DOCTYPE html>
+<html><head><title>Log level</title></head>
+<body>
+<form method="post">
+<label>Log level
+<select name="level">
+{{range .Levels}}<option value="{{.}}"{{if eq . $.Current}} selected{{end}}>{{.}}</option>
+{{end}}</select>
+</label>
+<button type="submit">Set</button>
+</form>
+</body></html>
+`))
+
+// levelHandler serves and changes a DynamicLevel.
+type levelHandler struct {
+	level *DynamicLevel
+	user  string
+	pass  string
+}
+
+// LevelHandler returns a handler exposing d over HTTP. GET returns the level
+// as {"level":"info"}, or an HTML form when the client accepts text/html.
+// PUT sets it from a body of the same form, and POST from the form's "level"
+// value, which is what browsers send.
+func LevelHandler(d *DynamicLevel, opts ...LevelHandlerOption) http.Handler {
+	h := &levelHandler{level: d}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="ezlog"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if strings.Contains(r.Header.Get("Accept"), "text/html") {
+			h.servePage(w)
+			return
+		}
+		h.serveJSON(w)
+	case http.MethodPut:
+		var body levelBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !h.set(w, body.Level) {
+			return
+		}
+		h.serveJSON(w)
+	case http.MethodPost:
+		if !h.set(w, r.FormValue("level")) {
+			return
+		}
+		http.Redirect(w, r, r.URL.String(), http.StatusSeeOther)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// authorized checks the basic authentication credentials, if required.
+func (h *levelHandler) authorized(r *http.Request) bool {
+	if h.user == "" && h.pass == "" {
+		return true
+	}
+	user, pass, ok := r.BasicAuth()
+	return ok &&
+		subtle.ConstantTimeCompare([]byte(user), []byte(h.user)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(pass), []byte(h.pass)) == 1
+}
+
+// set parses and applies name, replying with an error when it is invalid.
+func (h *levelHandler) set(w http.ResponseWriter, name string) bool {
+	level, err := ParseLevel(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	h.level.SetLevel(level)
+	return true
+}
+
+// serveJSON writes the current level as JSON.
+func (h *levelHandler) serveJSON(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(levelBody{Level: h.level.String()})
+}
+
+// servePage writes the HTML form.
+func (h *levelHandler) servePage(w http.ResponseWriter) {
+	levels := make([]string, len(knownLevels))
+	for i, level := range knownLevels {
+		levels[i] = LevelString(level)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = levelPage.Execute(w, struct {
+		Levels  []string
+		Current string
+	}{levels, h.level.String()})
+}
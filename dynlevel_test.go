@@ -0,0 +1,164 @@
+package ezlog
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// serveLevel sends a request to h and returns the response.
+func serveLevel(h http.Handler, method, body string, header map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/debug/level", strings.NewReader(body))
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestLevelHandlerGet(t *testing.T) {
+	h := LevelHandler(NewDynamicLevel(zerolog.InfoLevel))
+	w := serveLevel(h, http.MethodGet, "", nil)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("status %d, content type %q, want a JSON 200", w.Code, w.Header().Get("Content-Type"))
+	}
+	if got, want := w.Body.String(), "{\"level\":\"info\"}\n"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestLevelHandlerPage(t *testing.T) {
+	h := LevelHandler(NewDynamicLevel(zerolog.WarnLevel))
+	w := serveLevel(h, http.MethodGet, "", map[string]string{"Accept": "text/html,application/xhtml+xml"})
+
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("status %d, content type %q, want an HTML 200", w.Code, w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	for _, want := range []string{`<form method="post">`, `<option value="trace">`, `<option value="warn" selected>`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page lacks %s:\n%s", want, body)
+		}
+	}
+}
+
+func TestLevelHandlerPut(t *testing.T) {
+	dl := NewDynamicLevel(zerolog.InfoLevel)
+	w := serveLevel(LevelHandler(dl), http.MethodPut, `{"level":"debug"}`, nil)
+
+	if w.Code != http.StatusOK || w.Body.String() != "{\"level\":\"debug\"}\n" {
+		t.Errorf("status %d, body %q, want the new level", w.Code, w.Body.String())
+	}
+	if dl.Level() != zerolog.DebugLevel {
+		t.Errorf("level = %v, want debug", dl.Level())
+	}
+}
+
+func TestLevelHandlerPostForm(t *testing.T) {
+	dl := NewDynamicLevel(zerolog.InfoLevel)
+	form := url.Values{"level": {"error"}}.Encode()
+	w := serveLevel(LevelHandler(dl), http.MethodPost, form, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/debug/level" {
+		t.Errorf("status %d, location %q, want a redirect to the form", w.Code, w.Header().Get("Location"))
+	}
+	if dl.Level() != zerolog.ErrorLevel {
+		t.Errorf("level = %v, want error", dl.Level())
+	}
+}
+
+func TestLevelHandlerChangesLoggerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	dl := NewDynamicLevel(zerolog.WarnLevel)
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithDynamicLevel(dl).Build()
+	l.Info().Msg("dropped")
+	serveLevel(LevelHandler(dl), http.MethodPut, `{"level":"info"}`, nil)
+	l.Info().Msg("kept")
+
+	if got := buf.String(); strings.Contains(got, "dropped") || !strings.Contains(got, "kept") {
+		t.Errorf("output = %q, want only the event logged after the change", got)
+	}
+}
+
+func TestLevelHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		header map[string]string
+		want   int
+	}{
+		{"invalid level", http.MethodPut, `{"level":"loud"}`, nil, http.StatusBadRequest},
+		{"invalid body", http.MethodPut, `level=info`, nil, http.StatusBadRequest},
+		{"invalid form level", http.MethodPost, "level=loud",
+			map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, http.StatusBadRequest},
+		{"unsupported method", http.MethodDelete, "", nil, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dl := NewDynamicLevel(zerolog.InfoLevel)
+			w := serveLevel(LevelHandler(dl), tt.method, tt.body, tt.header)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if dl.Level() != zerolog.InfoLevel {
+				t.Errorf("level changed to %v", dl.Level())
+			}
+		})
+	}
+	w := serveLevel(LevelHandler(NewDynamicLevel(zerolog.InfoLevel)), http.MethodDelete, "", nil)
+	if got := w.Header().Get("Allow"); got != "GET, HEAD, PUT, POST" {
+		t.Errorf("Allow = %q", got)
+	}
+}
+
+func TestLevelHandlerBasicAuth(t *testing.T) {
+	dl := NewDynamicLevel(zerolog.InfoLevel)
+	h := LevelHandler(dl, WithBasicAuth("admin", "secret"))
+
+	auth := func(user, pass string) map[string]string {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.SetBasicAuth(user, pass)
+		return map[string]string{"Authorization": r.Header.Get("Authorization")}
+	}
+	for name, header := range map[string]map[string]string{
+		"none":         nil,
+		"bad password": auth("admin", "wrong"),
+		"bad user":     auth("root", "secret"),
+	} {
+		w := serveLevel(h, http.MethodPut, `{"level":"debug"}`, header)
+		if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != `Basic realm="ezlog"` {
+			t.Errorf("%s: status %d, want 401 with a challenge", name, w.Code)
+		}
+	}
+	if dl.Level() != zerolog.InfoLevel {
+		t.Errorf("level changed to %v without credentials", dl.Level())
+	}
+
+	if w := serveLevel(h, http.MethodPut, `{"level":"debug"}`, auth("admin", "secret")); w.Code != http.StatusOK || dl.Level() != zerolog.DebugLevel {
+		t.Errorf("status %d, level %v, want the update accepted", w.Code, dl.Level())
+	}
+}
+
+func TestDynamicLevelTraceNeedsGlobalLevel(t *testing.T) {
+	var buf bytes.Buffer
+	dl := NewDynamicLevel(zerolog.TraceLevel)
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithDynamicLevel(dl).Build()
+	l.Trace().Msg("dropped")
+
+	global := zerolog.GlobalLevel()
+	t.Cleanup(func() { zerolog.SetGlobalLevel(global) })
+	zerolog.SetGlobalLevel(zerolog.TraceLevel)
+	l.Trace().Msg("kept")
+
+	if got := buf.String(); strings.Contains(got, "dropped") || !strings.Contains(got, "kept") {
+		t.Errorf("output = %q, want trace events once the global level allows them", got)
+	}
+}
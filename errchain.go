@@ -0,0 +1,100 @@
+package ezlog
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrorChainFieldName is the field holding the wrapped causes of an error.
+const ErrorChainFieldName = "error_chain"
+
+// maxErrorChainDepth caps how deep error chains are followed, which also
+// protects against errors that unwrap to themselves.
+const maxErrorChainDepth = 32
+
+// WithErrorChain renders the error_chain field added by ErrWithChain as one
+// red "caused by:" line per wrapped error beneath the log line, instead of a
+// single long field. Errors logged with a plain Err have no chain, as zerolog
+// does not hand their value to the writers.
+func (b *LogBuilder) WithErrorChain() *LogBuilder {
+	b.errorChain = true
+	return b
+}
+
+// ErrWithChain adds err to e as the error field, plus an error_chain array
+// listing the message of every error wrapped by err. Errors joined with
+// errors.Join are listed one after the other.
+//
+//	ezlog.ErrWithChain(log.Error(), err).Msg("startup failed")
+func ErrWithChain(e *zerolog.Event, err error) *zerolog.Event {
+	e = e.Err(err)
+	if err == nil {
+		return e
+	}
+	if chain := ErrorChain(err); len(chain) > 0 {
+		e = e.Strs(ErrorChainFieldName, chain)
+	}
+	return e
+}
+
+// ErrorChain returns the messages of the errors wrapped by err, in unwrap
+// order, excluding err itself. Joined errors are each followed in turn.
+func ErrorChain(err error) []string {
+	var chain []string
+	walkErrorChain(err, 0, &chain)
+	return chain
+}
+
+// walkErrorChain appends the causes of err to chain.
+func walkErrorChain(err error, depth int, chain *[]string) {
+	if depth >= maxErrorChainDepth {
+		return
+	}
+
+	var causes []error
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		causes = u.Unwrap()
+	case interface{ Unwrap() error }:
+		causes = []error{u.Unwrap()}
+	}
+
+	for _, cause := range causes {
+		if cause == nil || sameError(cause, err) || len(*chain) >= maxErrorChainDepth {
+			continue
+		}
+		*chain = append(*chain, cause.Error())
+		walkErrorChain(cause, depth+1, chain)
+	}
+}
+
+// sameError reports whether a and b are the same comparable error value.
+func sameError(a, b error) bool {
+	if !reflect.TypeOf(a).Comparable() || !reflect.TypeOf(b).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// renderErrorChain moves the error_chain field of evt to the continuation lines.
+func renderErrorChain(evt map[string]any) error {
+	chain, ok := evt[ErrorChainFieldName].([]any)
+	if !ok {
+		return nil
+	}
+
+	red := redColor
+	lines := make([]string, 0, len(chain))
+	for _, cause := range chain {
+		s, ok := cause.(string)
+		if !ok {
+			continue
+		}
+		s = strings.ReplaceAll(s, "\n", " ")
+		lines = append(lines, red.Sprintf("caused by: %s", s))
+	}
+	appendContinuation(evt, lines...)
+	return nil
+}
@@ -0,0 +1,114 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestErrorChain(t *testing.T) {
+	root := errors.New("permission denied")
+	mid := fmt.Errorf("read config: %w", root)
+	top := fmt.Errorf("startup: %w", mid)
+	joined := errors.Join(errors.New("a"), errors.New("b"))
+
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"unwrapped", root, nil},
+		{"wrapped", top, []string{mid.Error(), root.Error()}},
+		{"joined", joined, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorChain(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ErrorChain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrWithChainJSON(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithJSONOutput().WithWriter(&buf).WithErrorChain().Build()
+	err := fmt.Errorf("load: %w", errors.New("disk"))
+	ErrWithChain(l.Error(), err).Msg("failed")
+	// An unrelated error with the same message has no chain.
+	l.Error().Err(errors.New("load: disk")).Msg("failed")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("got %d events, want 2: %q", len(lines), buf.String())
+	}
+	var wrapped, plain map[string]any
+	if err := json.Unmarshal(lines[0], &wrapped); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(lines[1], &plain); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := wrapped[ErrorChainFieldName], []any{"disk"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %v, want %v", ErrorChainFieldName, got, want)
+	}
+	if got, ok := plain[ErrorChainFieldName]; ok {
+		t.Errorf("plain error has %s = %v, want none", ErrorChainFieldName, got)
+	}
+}
+
+func TestWithErrorChainConsole(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithErrorChain().Build()
+	ErrWithChain(l.Error(), fmt.Errorf("startup: %w", errors.New("disk full"))).Msg("failed")
+
+	out := buf.String()
+	if !strings.Contains(out, "caused by: disk full") {
+		t.Errorf("output %q has no caused by line", out)
+	}
+	if strings.Contains(out, ErrorChainFieldName+"=") {
+		t.Errorf("output %q renders the chain as a field", out)
+	}
+}
+
+// selfError unwraps to itself.
+type selfError struct{}
+
+func (selfError) Error() string   { return "self" }
+func (e selfError) Unwrap() error { return e }
+
+// loopError unwraps to next, which may lead back to it.
+type loopError struct {
+	msg  string
+	next *loopError
+}
+
+func (e *loopError) Error() string { return e.msg }
+func (e *loopError) Unwrap() error { return e.next }
+
+func TestErrorChainCycles(t *testing.T) {
+	if got := ErrorChain(selfError{}); len(got) != 0 {
+		t.Errorf("ErrorChain(self-unwrapping) = %q, want none", got)
+	}
+
+	a := &loopError{msg: "a"}
+	a.next = &loopError{msg: "b", next: a}
+	if got := ErrorChain(a); len(got) != maxErrorChainDepth {
+		t.Errorf("ErrorChain(cycle) has %d causes, want the %d cap", len(got), maxErrorChainDepth)
+	}
+}
+
+func TestWithErrorChainConsoleJoined(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithErrorChain().Build()
+	ErrWithChain(l.Error(), errors.Join(errors.New("disk full"), errors.New("quota exceeded"))).Msg("failed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[1], "caused by: disk full") || !strings.HasSuffix(lines[2], "caused by: quota exceeded") {
+		t.Errorf("output = %q, want a caused by line per joined error", buf.String())
+	}
+}
@@ -0,0 +1,54 @@
+package ezlog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/rs/zerolog"
+)
+
+// WithEventIDField adds a unique ID to every event under fieldName, to refer
+// to a single event across systems. Unlike a request or trace ID, it differs
+// for every event. IDs are random UUIDs unless WithEventIDGenerator is used.
+func (b *LogBuilder) WithEventIDField(fieldName string) *LogBuilder {
+	b.eventIDField = fieldName
+	return b
+}
+
+// WithEventIDGenerator generates event IDs with fn, e.g. for ULIDs or KSUIDs.
+// The field is "event_id" unless set with WithEventIDField.
+func (b *LogBuilder) WithEventIDGenerator(fn func() string) *LogBuilder {
+	b.eventIDGen = fn
+	return b
+}
+
+// eventIDHook adds a generated ID to every event.
+type eventIDHook struct {
+	field string
+	gen   func() string
+}
+
+// Run implements zerolog.Hook.
+func (h eventIDHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	e.Str(h.field, h.gen())
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var u [16]byte
+	_, _ = rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
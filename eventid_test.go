@@ -0,0 +1,54 @@
+package ezlog
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+var uuidV4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewUUID(t *testing.T) {
+	seen := make(map[string]bool)
+	for range 1000 {
+		id := newUUID()
+		if !uuidV4.MatchString(id) {
+			t.Fatalf("newUUID() = %q, not a version 4 UUID", id)
+		}
+		if seen[id] {
+			t.Fatalf("newUUID() repeated %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestWithEventIDField(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithJSONOutput().WithEventIDField("eid").Build()
+	l.Info().Msg("one")
+	l.Info().Msg("two")
+
+	events := decodeLines(t, &buf)
+	first, _ := events[0]["eid"].(string)
+	second, _ := events[1]["eid"].(string)
+	if !uuidV4.MatchString(first) || !uuidV4.MatchString(second) || first == second {
+		t.Errorf("event IDs = %q, %q, want distinct UUIDs", first, second)
+	}
+}
+
+func TestWithEventIDGenerator(t *testing.T) {
+	var buf bytes.Buffer
+	n := 0
+	l := newTestBuilder(&buf).WithJSONOutput().WithEventIDGenerator(func() string {
+		n++
+		return "id-" + strconv.Itoa(n)
+	}).Build()
+	l.Info().Msg("one")
+	l.Warn().Msg("two")
+
+	events := decodeLines(t, &buf)
+	if events[0]["event_id"] != "id-1" || events[1]["event_id"] != "id-2" {
+		t.Errorf("events = %v, want generated IDs under event_id", events)
+	}
+}
@@ -4,10 +4,10 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strings"
+	"path/filepath"
+	"time"
 
 	"github.com/fatih/color"
-	"github.com/rivo/tview"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -17,10 +17,87 @@ var globalLogger *zerolog.Logger
 
 // LogBuilder is a builder for zerolog loggers.
 type LogBuilder struct {
-	tviewCompat bool
-	writer      io.Writer
-	tag         string
-	isGlobal    bool
+	tviewCompat       bool
+	writer            io.Writer
+	tag               string
+	isGlobal          bool
+	timeFormat        string
+	location          *time.Location
+	levelNames        map[zerolog.Level]string
+	jsonLevels        bool
+	aligned           bool
+	tagWidth          int
+	timestampFn       func() time.Time
+	levelIcons        map[zerolog.Level]string
+	sortedFields      bool
+	fieldOrder        []string
+	shutdownSignals   []os.Signal
+	humanize          bool
+	multiline         bool
+	errorChain        bool
+	rawMessages       bool
+	contextExtraction bool
+	goroutineFields   bool
+	redactedFields    map[string]struct{}
+	liveConfig        *LiveConfig
+	packageLevels     map[string]zerolog.Level
+	metrics           MetricsExporter
+	stats             *LoggerStats
+	writerFn          func() (io.Writer, error)
+	fieldNames        *FieldNames
+	messageTransforms []func(zerolog.Level, string) string
+	outputBuffer      int
+	buffered          *bufferedOutput
+	consoleExclude    []string
+	consoleOnly       []string
+	consoleTweaks     []func(*zerolog.ConsoleWriter)
+	levelRates        map[zerolog.Level]uint32
+	sectionWidth      int
+	sectionColor      *color.Color
+	colorScheme       *ColorScheme
+	resolvedScheme    *ColorScheme
+	autoTheme         bool
+	themeQuery        time.Duration
+	errorSampling     float64
+	samplingFunc      func(zerolog.Level, string) bool
+	nestedDepth       *int
+	logfmt            bool
+	tableMinFields    int
+	fields            []contextField
+	teeWriters        []io.Writer
+	caller            bool
+	callerPrefixes    []string
+	callerModule      string
+	callerRoot        string
+	colorLevel        ColorLevel
+	instrument        bool
+	onWriteError      func(error)
+	instrumented      *InstrumentedWriter
+	level             zerolog.Level
+	jsonOutput        bool
+	audit             []AuditOption
+	auditKey          []byte
+	hyperlinkTemplate string
+	hyperlinkForced   bool
+	hyperlinkCaller   bool
+	dedupWindow       time.Duration
+	dedup             []DedupOption
+	rateLimit         *rateLimitConfig
+	compression       CompressionAlgo
+	compressed        *CompressedWriter
+	fallback          io.Writer
+	fallbackOpts      []FailoverOption
+	failover          *FailoverWriter
+	dynamicLevel      *DynamicLevel
+	eventIDField      string
+	eventIDGen        func() string
+	fastConsole       bool
+	fatalHooks        []func(map[string]any)
+	panicHooks        []func(map[string]any)
+	fatalHookTimeout  time.Duration
+	exitFunc          func(int)
+	diagnosticsOut    io.Writer
+	diagnosticsLevel  *zerolog.Level
 }
 
 // New creates a new LogBuilder, configured by default to create a local logger instance.
@@ -29,9 +106,29 @@ func New() *LogBuilder {
 		tviewCompat: false,
 		writer:      os.Stdout,
 		isGlobal:    true, // Default behavior is to create a global logger
+		timeFormat:  "15:04:05.000",
+		tagWidth:    defaultTagWidth,
+		colorLevel:  ColorLevelBasic,
+		level:       zerolog.TraceLevel,
 	}
 }
 
+// NewDiscard creates a LogBuilder for a local logger that writes to io.Discard
+// at the disabled level, for tests and stubs that need a logger but not its
+// output.
+func NewDiscard() *LogBuilder {
+	b := New().AsLocal().WithWriter(io.Discard)
+	b.level = zerolog.Disabled
+	return b
+}
+
+// Discard returns a logger that does nothing. It is zerolog.Nop, exposed so
+// callers don't need to import zerolog just for it.
+func Discard() *zerolog.Logger {
+	l := zerolog.Nop()
+	return &l
+}
+
 // WithTag adds a custom colored tag to the logger's output.
 func (b *LogBuilder) WithTag(tag string) *LogBuilder {
 	b.tag = tag
@@ -63,87 +160,412 @@ func (b *LogBuilder) SetWriter(writer io.Writer) *LogBuilder {
 	return b
 }
 
+// WithTeeWriter also writes the logger's formatted output to w, in the same
+// format as the builder's writer. A failed write to w fails the logger's
+// write.
+func (b *LogBuilder) WithTeeWriter(w io.Writer) *LogBuilder {
+	b.teeWriters = append(b.teeWriters, w)
+	return b
+}
+
 // WithWriter sets the writer field to the given writer.
 func (b *LogBuilder) WithWriter(writer io.Writer) *LogBuilder {
 	b.writer = writer
 	return b
 }
 
+// WithJSONOutput writes zerolog's JSON events, one per line, instead of
+// colored console output. The tag becomes a "tag" field and timestamps use
+// RFC 3339 with nanoseconds, so files can be re-rendered with Pretty.
+func (b *LogBuilder) WithJSONOutput() *LogBuilder {
+	b.jsonOutput = true
+	return b
+}
+
+// WithTimeFormat sets the layout used to render timestamps in the console output.
+func (b *LogBuilder) WithTimeFormat(format string) *LogBuilder {
+	b.timeFormat = format
+	return b
+}
+
+// WithTimeZone renders timestamps in the given location instead of local time.
+//
+// The location only affects how timestamps are displayed, with the layout set
+// by WithTimeFormat; events always carry their full RFC 3339 timestamp.
+func (b *LogBuilder) WithTimeZone(loc *time.Location) *LogBuilder {
+	b.location = loc
+	return b
+}
+
+// WithUTC renders timestamps in UTC.
+func (b *LogBuilder) WithUTC() *LogBuilder {
+	return b.WithTimeZone(time.UTC)
+}
+
+// WithWriterF creates the writer with fn when the logger is built, for
+// writers that may fail to initialize, such as network or cloud writers
+// needing credentials, and to not create writers a test never uses. Build
+// logs to the standard error when fn fails, reporting the error there; use
+// BuildE to handle it instead.
+func (b *LogBuilder) WithWriterF(fn func() (io.Writer, error)) *LogBuilder {
+	b.writerFn = fn
+	return b
+}
+
+// WithTimestampFunc sets the clock used for this logger's timestamps, without
+// touching the process-wide zerolog.TimestampFunc. This is mostly useful in
+// tests that need a fixed or stepping clock.
+func (b *LogBuilder) WithTimestampFunc(fn func() time.Time) *LogBuilder {
+	b.timestampFn = fn
+	return b
+}
+
+// WithClock is WithTimestampFunc, named after the GormLoggerBuilder option
+// sharing a clock with it, to freeze or step time in golden-file tests.
+func (b *LogBuilder) WithClock(now func() time.Time) *LogBuilder {
+	return b.WithTimestampFunc(now)
+}
+
+// closerName returns the name under which the builder's writer is registered
+// for Shutdown.
+func (b *LogBuilder) closerName() string {
+	switch {
+	case b.isGlobal:
+		return "global"
+	case b.tag != "":
+		return b.tag
+	default:
+		return fmt.Sprintf("local-%p", b.writer)
+	}
+}
+
+// BuildE is like Build but returns the error of the WithWriterF function
+// instead of falling back to the standard error, and fails when the
+// WithCompression algorithm is not registered.
+func (b *LogBuilder) BuildE() (*zerolog.Logger, error) {
+	if err := b.compression.check(); err != nil {
+		return nil, err
+	}
+	if b.writerFn != nil {
+		w, err := b.writerFn()
+		if err != nil {
+			return nil, fmt.Errorf("ezlog: create writer: %w", err)
+		}
+		b.writer = w
+		b.writerFn = nil
+	}
+	return b.Build(), nil
+}
+
 // Build creates a zerolog.Logger based on the builder's configuration.
 func (b *LogBuilder) Build() *zerolog.Logger {
+	if b.writerFn != nil {
+		w, err := b.writerFn()
+		b.writerFn = nil
+		if err != nil {
+			b.writer = os.Stderr
+			l := b.Build()
+			l.Error().Err(err).Msg("ezlog: writer initialization failed, logging to stderr")
+			return l
+		}
+		b.writer = w
+	}
+
 	zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	zerolog.TimeFieldFormat = "15:04:05.000"
-
-	consoleOutput := zerolog.ConsoleWriter{
-		Out:        b.writer,
-		TimeFormat: "15:04:05.000",
-		NoColor:    false,
-	}
-
-	consoleOutput.FormatLevel = func(i any) string {
-		levelStr := strings.ToUpper(fmt.Sprintf("%s", i))
-		var coloredLevel string
-
-		switch levelStr {
-		case "DEBUG":
-			coloredLevel = color.New(color.FgBlue).Sprintf("[%s]", levelStr)
-		case "INFO":
-			coloredLevel = color.New(color.FgGreen).Sprintf("[%s]", levelStr)
-		case "WARN":
-			coloredLevel = color.New(color.FgYellow).Sprintf("[%s]", levelStr)
-		case "ERROR":
-			coloredLevel = color.New(color.FgRed).Sprintf("[%s]", levelStr)
-		case "FATAL":
-			coloredLevel = color.New(color.FgRed, color.Bold).Sprintf("[%s]", levelStr)
-		default:
-			coloredLevel = color.New(color.FgWhite).Sprintf("[%s]", levelStr)
+
+	writer := b.outputWriter()
+	out := writer
+	if r, ok := out.(*TagRouter); ok {
+		out = r.route(b.tag)
+	}
+	var progress *progressWriter
+	if !b.tviewCompat && !b.jsonOutput && !b.logfmt && isTerminal(b.writer) {
+		progress = &progressWriter{out: out}
+		out = progress
+	}
+	if b.instrument {
+		b.instrumented = NewInstrumentedWriter(out).OnError(b.onWriteError)
+		out = b.instrumented
+	}
+	if len(b.teeWriters) > 0 {
+		out = io.MultiWriter(append([]io.Writer{out}, b.teeWriters...)...)
+	}
+	if b.colorLevel.resolve() == ColorLevelNone {
+		out = stripANSIWriter{out}
+	}
+
+	if cwd, err := os.Getwd(); err == nil {
+		b.callerRoot = cwd + string(filepath.Separator)
+	}
+	consoleOutput := b.consoleWriter(out)
+
+	if b.isGlobal && b.fieldNames != nil {
+		applyGlobalFieldNames(*b.fieldNames)
+	}
+	if b.isGlobal && b.jsonLevels && len(b.levelNames) > 0 {
+		names := b.levelNames
+		zerolog.LevelFieldMarshalFunc = func(l zerolog.Level) string {
+			if name, ok := names[l]; ok {
+				return name
+			}
+			return l.String()
+		}
+	}
+
+	var output io.Writer = consoleOutput
+	switch {
+	case b.fastConsole && b.fastConsoleEligible(consoleOutput) && !b.jsonOutput && !b.logfmt:
+		output = newFastConsoleWriter(consoleOutput, !b.rawMessages)
+	case b.jsonOutput:
+		output = out
+	case b.logfmt:
+		output = newLogfmtWriter(out, b.tag, b.location)
+	}
+	// The audit chain covers events as written, after any rewriting.
+	if b.auditKey != nil {
+		output = NewAuditWriter(output, b.auditKey, b.audit...)
+	}
+	if b.jsonOutput && b.fieldNames != nil && !b.isGlobal {
+		if renames := b.fieldNames.renames(); len(renames) > 0 {
+			output = &fieldRenameWriter{out: output, renames: renames}
+		}
+	}
+	if b.stats != nil {
+		output = &statsWriter{out: output, stats: b.stats, b: b}
+	}
+	if b.metrics != nil {
+		output = &metricsWriter{out: output, exporter: b.metrics, tag: b.tag, b: b}
+	}
+	if len(b.redactedFields) > 0 || b.liveConfig != nil {
+		output = &redactWriter{out: output, fields: b.redactedFields, live: b.liveConfig}
+	}
+	if len(b.messageTransforms) > 0 && (b.jsonOutput || b.logfmt) {
+		output = &messageTransformWriter{out: output, transforms: b.messageTransforms}
+	}
+	if b.dedupWindow > 0 {
+		output = newDedupWriter(output, b.dedupWindow, b.dedup...)
+	}
+	if b.rateLimit != nil {
+		output = newRateLimitWriter(output, b.rateLimit)
+	}
+	if len(b.levelRates) > 0 {
+		output = newLevelSamplingWriter(output, b.levelRates)
+	}
+	diagnostics := b.buildDiagnostics()
+	if err := b.compression.check(); err != nil {
+		diagnostics.report(zerolog.ErrorLevel, "compression", "%v, writing uncompressed output", err)
+	}
+	var fatalHooks *fatalHookWriter
+	if len(b.fatalHooks) > 0 || len(b.panicHooks) > 0 || b.exitFunc != nil {
+		timeout := b.fatalHookTimeout
+		if timeout <= 0 {
+			timeout = defaultFatalHookTimeout
+		}
+		fatalHooks = &fatalHookWriter{
+			out:     output,
+			flush:   writer,
+			fatal:   b.fatalHooks,
+			panic:   b.panicHooks,
+			timeout: timeout,
+			exit:    b.exitFunc,
+			diag:    diagnostics,
+			b:       b,
 		}
+		output = fatalHooks
+	}
+	dw := &diagnosticsWriter{out: output, d: diagnostics}
+	if aw, ok := b.writer.(asyncWriter); ok {
+		dw.async = aw
+	}
+	output = dw
 
-		if b.tviewCompat {
-			return tview.Escape(coloredLevel)
+	// Events carry their full timestamp, rendered with the WithTimeFormat
+	// layout by the console writer only.
+	now := b.timestampFn
+	if now == nil {
+		now = time.Now
+	}
+	newLogger := zerolog.New(output).Hook(timestampHook{now: now, layout: time.RFC3339Nano})
+	if b.jsonOutput && b.tag != "" {
+		newLogger = newLogger.With().Str("tag", b.tag).Logger()
+	}
+	newLogger = newLogger.Level(b.level)
+	if b.caller {
+		newLogger = newLogger.With().Caller().Logger()
+	}
+	newLogger = b.applyFields(newLogger)
+	if b.contextExtraction {
+		newLogger = newLogger.Hook(contextHook{})
+	}
+	if b.goroutineFields {
+		newLogger = newLogger.Hook(goroutineHook{})
+	}
+	if b.dynamicLevel != nil {
+		newLogger = newLogger.Hook(b.dynamicLevel)
+	}
+	if b.errorSampling != 0 {
+		newLogger = newLogger.Hook(errorSamplingHook{rate: b.errorSampling, dl: b.dynamicLevel})
+	}
+	if b.liveConfig != nil {
+		newLogger = newLogger.Hook(liveConfigHook{lc: b.liveConfig, tag: b.tag})
+	}
+	if len(b.packageLevels) > 0 {
+		newLogger = newLogger.Hook(newPackageLevelHook(b.packageLevels))
+	}
+	if b.samplingFunc != nil {
+		newLogger = newLogger.Hook(samplingFuncHook(b.samplingFunc))
+	}
+	if b.eventIDField != "" || b.eventIDGen != nil {
+		h := eventIDHook{field: b.eventIDField, gen: b.eventIDGen}
+		if h.field == "" {
+			h.field = "event_id"
 		}
-		return coloredLevel
+		if h.gen == nil {
+			h.gen = newUUID
+		}
+		newLogger = newLogger.Hook(h)
+	}
+	if fatalHooks != nil {
+		newLogger = newLogger.Hook(terminalEventHook{w: fatalHooks})
+	}
+	if b.metrics != nil {
+		b.registerLevelGauge()
+	}
+	if b.stats != nil {
+		b.attachStats()
 	}
 
+	if b.isGlobal {
+		log.Logger = newLogger
+		globalLogger = &newLogger
+	}
+	if progress != nil {
+		storeProgressWriter(&newLogger, progress)
+		if b.isGlobal {
+			storeProgressWriter(&log.Logger, progress)
+		}
+	}
+
+	registerWriter(b.closerName(), writer)
+	if len(b.shutdownSignals) > 0 {
+		installSignalHandler(b.shutdownSignals)
+	}
+
+	return &newLogger
+}
+
+// WithConsoleWriterTweak calls fn with the console writer once ezlog has set
+// its own formatters, to change any ConsoleWriter setting ezlog has no option
+// for, such as PartsOrder or FieldsExclude:
+//
+//	WithConsoleWriterTweak(func(w *zerolog.ConsoleWriter) {
+//		w.PartsOrder = []string{zerolog.LevelFieldName, zerolog.MessageFieldName}
+//	})
+//
+// Tweaks apply in the order they were added. The tag prefix is rendered by
+// FormatMessage, so a tweak replacing FormatMessage must render the tag
+// itself.
+func (b *LogBuilder) WithConsoleWriterTweak(fn func(*zerolog.ConsoleWriter)) *LogBuilder {
+	b.consoleTweaks = append(b.consoleTweaks, fn)
+	return b
+}
+
+// consoleWriter returns the console writer rendering events to out.
+func (b *LogBuilder) consoleWriter(out io.Writer) zerolog.ConsoleWriter {
+	w := zerolog.ConsoleWriter{
+		Out:             out,
+		TimeFormat:      b.timeFormat,
+		NoColor:         false,
+		FormatTimestamp: formatTimestampIn(b.timeFormat, b.location),
+	}
+
+	w.FormatLevel = b.formatLevel()
+	w.FormatCaller = b.formatCaller()
+
 	if b.tag != "" {
-		tagStr := color.New(color.FgMagenta).Sprintf("[%s]", b.tag)
-		consoleOutput.FormatMessage = func(i any) string {
+		tagStr := b.scheme().Tag.Sprintf("[%s]", b.tagLabel())
+		if b.aligned {
+			tagStr = padRight(tagStr, b.tagWidth+2)
+		}
+		w.FormatMessage = func(i any) string {
 			return fmt.Sprintf("%s %s", tagStr, i)
 		}
 	} else {
-		consoleOutput.FormatMessage = func(i any) string {
+		w.FormatMessage = func(i any) string {
 			return fmt.Sprintf("%s", i)
 		}
 	}
 
-	consoleOutput.FormatFieldName = func(i any) string {
-		return color.New(color.FgCyan).Sprintf("%s=", i)
+	fieldName := b.scheme().FieldName
+	w.FormatFieldName = func(i any) string {
+		return fieldName.Sprintf("%s=", i)
+	}
+
+	w.FormatFieldValue = b.formatFieldValue()
+
+	b.applyFieldOrder(&w)
+
+	if !b.rawMessages {
+		addFormatPrepare(&w, sanitizeMessage(b.multiline))
+	}
+	addFormatPrepare(&w, b.formatSections())
+	b.applyFieldFilter(&w)
+	if b.humanize {
+		addFormatPrepare(&w, humanizeFields)
+	}
+	if b.multiline || b.errorChain || b.tableMinFields > 0 {
+		b.applyContinuation(&w)
+	}
+	if len(b.messageTransforms) > 0 {
+		b.applyMessageTransforms(&w)
+	}
+	for _, tweak := range b.consoleTweaks {
+		tweak(&w)
+	}
+	w.FormatFieldValue = safeFormatter(w.FormatFieldValue)
+	w.FormatErrFieldValue = safeFormatter(w.FormatErrFieldValue)
+	return w
+}
+
+// formatTimestampIn returns a timestamp formatter rendering the RFC 3339
+// event time with format, in loc when set and in local time otherwise.
+func formatTimestampIn(format string, loc *time.Location) zerolog.Formatter {
+	if loc == nil {
+		loc = time.Local
 	}
+	return func(i any) string {
+		t, ok := parseEventTime(i)
+		if !ok {
+			return grayColor.Sprint(i)
+		}
+		return grayColor.Sprint(t.In(loc).Format(format))
+	}
+}
 
-	consoleOutput.FormatFieldValue = func(i any) string {
+// formatFieldValue returns the console formatter for field values.
+func (b *LogBuilder) formatFieldValue() zerolog.Formatter {
+	maxDepth := b.maxNestedDepth()
+	s := b.scheme()
+	return func(i any) string {
 		if i == nil {
-			return color.New(color.FgRed).Sprint("nil")
+			return s.Nil.Sprint("nil")
 		}
 		switch v := i.(type) {
 		case string:
-			return color.New(color.FgGreen).Sprintf("%q", v)
+			return s.String.Sprintf("%q", v)
 		case bool:
-			return color.New(color.FgMagenta).Sprint(v)
+			return s.Bool.Sprint(v)
 		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-			return color.New(color.FgYellow).Sprintf("%d", v)
+			return s.Number.Sprintf("%d", v)
 		case float32, float64:
-			return color.New(color.FgYellow).Sprintf("%f", v)
+			return s.Number.Sprintf("%f", v)
+		case []byte:
+			if nested, ok := formatNested(v, maxDepth, s); ok {
+				return nested
+			}
+			return string(v)
 		default:
 			return fmt.Sprintf("%s", i)
 		}
 	}
-
-	newLogger := zerolog.New(consoleOutput).With().Timestamp().Logger()
-
-	if b.isGlobal {
-		log.Logger = newLogger
-		globalLogger = &newLogger
-	}
-
-	return &newLogger
 }
@@ -0,0 +1,195 @@
+package ezlog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// fixedClock returns a clock always returning t.
+func fixedClock(t time.Time) func() time.Time {
+	return func() time.Time { return t }
+}
+
+// newTestBuilder returns a local, uncolored console builder writing to buf.
+func newTestBuilder(buf *bytes.Buffer) *LogBuilder {
+	return New().AsLocal().WithWriter(buf).WithColorSupport(ColorLevelNone)
+}
+
+func TestWithTimeFormatDated(t *testing.T) {
+	var buf bytes.Buffer
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	l := newTestBuilder(&buf).
+		WithTimeFormat("2006-01-02 15:04:05").
+		WithTimestampFunc(fixedClock(ts)).
+		Build()
+	l.Info().Msg("hello")
+
+	if got, want := buf.String(), "2025-03-14 09:26:53 "; !strings.HasPrefix(got, want) {
+		t.Errorf("output = %q, want prefix %q", got, want)
+	}
+}
+
+func TestWithUTC(t *testing.T) {
+	var buf bytes.Buffer
+	// Just before midnight in New York is the next day in UTC.
+	ts := time.Date(2025, 12, 31, 23, 30, 0, 0, time.FixedZone("EST", -5*3600))
+	l := newTestBuilder(&buf).
+		WithTimeFormat("2006-01-02 15:04").
+		WithUTC().
+		WithTimestampFunc(fixedClock(ts)).
+		Build()
+	l.Info().Msg("hello")
+
+	if got, want := buf.String(), "2026-01-01 04:30 "; !strings.HasPrefix(got, want) {
+		t.Errorf("output = %q, want prefix %q", got, want)
+	}
+}
+
+func TestWithTimeZone(t *testing.T) {
+	var buf bytes.Buffer
+	tokyo := time.FixedZone("JST", 9*3600)
+	ts := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	l := newTestBuilder(&buf).
+		WithTimeFormat(time.RFC3339).
+		WithTimeZone(tokyo).
+		WithTimestampFunc(fixedClock(ts)).
+		Build()
+	l.Info().Msg("hello")
+
+	if got, want := buf.String(), "2025-06-01T21:00:00+09:00 "; !strings.HasPrefix(got, want) {
+		t.Errorf("output = %q, want prefix %q", got, want)
+	}
+}
+
+func TestWithTimestampFuncPerLogger(t *testing.T) {
+	var a, b bytes.Buffer
+	la := newTestBuilder(&a).WithJSONOutput().WithTimestampFunc(fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))).Build()
+	lb := newTestBuilder(&b).WithJSONOutput().WithTimestampFunc(fixedClock(time.Date(2030, 6, 7, 8, 9, 10, 0, time.UTC))).Build()
+	la.Info().Msg("a")
+	lb.Info().Msg("b")
+
+	if !strings.Contains(a.String(), `"time":"2025-01-02T03:04:05Z"`) {
+		t.Errorf("first logger output = %q", a.String())
+	}
+	if !strings.Contains(b.String(), `"time":"2030-06-07T08:09:10Z"`) {
+		t.Errorf("second logger output = %q", b.String())
+	}
+	if got := zerolog.TimestampFunc(); got.Year() != time.Now().Year() {
+		t.Errorf("zerolog.TimestampFunc() = %v, want the unchanged process clock", got)
+	}
+}
+
+func TestWithTeeWriter(t *testing.T) {
+	var buf, tee bytes.Buffer
+	l := newTestBuilder(&buf).WithTeeWriter(&tee).Build()
+	l.Info().Msg("hello")
+
+	if buf.String() == "" || tee.String() != buf.String() {
+		t.Errorf("tee output = %q, want %q", tee.String(), buf.String())
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	restoreGlobalLogger(t)
+	var global bytes.Buffer
+	log.Logger = zerolog.New(&global)
+
+	if got := Discard().GetLevel(); got != zerolog.Disabled {
+		t.Errorf("Discard() level = %v, want disabled", got)
+	}
+
+	var buf bytes.Buffer
+	l := NewDiscard().WithWriter(&buf).Build()
+	l.Error().Msg("dropped")
+	if l.GetLevel() != zerolog.Disabled || buf.Len() != 0 {
+		t.Errorf("NewDiscard logger at level %v wrote %q", l.GetLevel(), buf.String())
+	}
+	log.Info().Msg("still global")
+	if global.Len() == 0 {
+		t.Error("NewDiscard replaced the global logger")
+	}
+}
+
+func TestWithConsoleWriterTweak(t *testing.T) {
+	var buf bytes.Buffer
+	var calls []string
+	l := newTestBuilder(&buf).
+		WithTimestampFunc(fixedClock(time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local))).
+		WithConsoleWriterTweak(func(w *zerolog.ConsoleWriter) {
+			calls = append(calls, "first")
+			w.PartsOrder = []string{zerolog.TimestampFieldName, zerolog.MessageFieldName, zerolog.LevelFieldName}
+		}).
+		WithConsoleWriterTweak(func(w *zerolog.ConsoleWriter) {
+			calls = append(calls, "second")
+			w.PartsExclude = []string{zerolog.TimestampFieldName}
+		}).
+		Build()
+	l.Info().Str("k", "v").Msg("hello")
+
+	if got, want := buf.String(), "hello [INFO] k=\"v\"\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if strings.Join(calls, ",") != "first,second" {
+		t.Errorf("tweaks called as %v, want first then second", calls)
+	}
+}
+
+func TestWithWriterF(t *testing.T) {
+	var buf bytes.Buffer
+	calls := 0
+	b := New().AsLocal().WithJSONOutput().WithWriterF(func() (io.Writer, error) {
+		calls++
+		return &buf, nil
+	})
+	if calls != 0 {
+		t.Fatal("WithWriterF created the writer before Build")
+	}
+	l, err := b.BuildE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info().Msg("lazy")
+	if calls != 1 || !strings.Contains(buf.String(), "lazy") {
+		t.Errorf("writer created %d times, output = %q", calls, buf.String())
+	}
+}
+
+func TestWithWriterFError(t *testing.T) {
+	errAuth := errors.New("no token")
+	l, err := New().AsLocal().WithWriterF(func() (io.Writer, error) { return nil, errAuth }).BuildE()
+	if l != nil || !errors.Is(err, errAuth) {
+		t.Errorf("BuildE() = %v, %v, want %v", l, err, errAuth)
+	}
+}
+
+func TestWithWriterFBuildFallsBackToStderr(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	stderr := os.Stderr
+	os.Stderr = f
+	defer func() { os.Stderr = stderr }()
+
+	l := New().AsLocal().WithJSONOutput().WithWriterF(func() (io.Writer, error) { return nil, errors.New("no token") }).Build()
+	l.Info().Msg("still logged")
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	if !strings.Contains(got, "writer initialization failed") || !strings.Contains(got, "no token") || !strings.Contains(got, "still logged") {
+		t.Errorf("stderr = %q, want the error and later events", got)
+	}
+}
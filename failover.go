@@ -0,0 +1,239 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// errWriteTimeout is returned when a write exceeds the failover timeout.
+var errWriteTimeout = errors.New("ezlog: write timed out")
+
+// errWritePending is returned while a timed out write has not returned yet.
+var errWritePending = errors.New("ezlog: previous write still pending")
+
+// FailoverOption configures a FailoverWriter.
+type FailoverOption func(*FailoverWriter)
+
+// WithFailoverThreshold sets the number of consecutive primary write errors
+// that trigger a failover, 3 by default.
+func WithFailoverThreshold(n int) FailoverOption {
+	return func(w *FailoverWriter) {
+		w.threshold = n
+	}
+}
+
+// WithFailoverTimeout counts primary writes taking longer than d as errors.
+// A timed out write keeps running in the background, and the primary counts
+// as failing until it returns, so writes to it never overlap. Disabled by
+// default.
+func WithFailoverTimeout(d time.Duration) FailoverOption {
+	return func(w *FailoverWriter) {
+		w.timeout = d
+	}
+}
+
+// WithFailoverProbeInterval sets how often the primary is retried after a
+// failover, 10s by default.
+func WithFailoverProbeInterval(d time.Duration) FailoverOption {
+	return func(w *FailoverWriter) {
+		w.probeInterval = d
+	}
+}
+
+// FailoverStats holds the counters of a FailoverWriter.
+type FailoverStats struct {
+	// Failovers is the number of switches to the secondary writer.
+	Failovers uint64
+	// Failbacks is the number of switches back to the primary writer.
+	Failbacks uint64
+	// Errors is the number of failed primary writes.
+	Errors uint64
+	// OnSecondary tells whether the secondary writer is currently in use.
+	OnSecondary bool
+}
+
+// FailoverWriter writes to a primary writer and switches to a secondary one
+// when the primary keeps failing, probing the primary periodically to switch
+// back. It should be created using NewFailoverWriter.
+type FailoverWriter struct {
+	mu            sync.Mutex
+	primary       io.Writer
+	secondary     io.Writer
+	threshold     int
+	timeout       time.Duration
+	probeInterval time.Duration
+	now           func() time.Time
+
+	onSecondary bool
+	consecutive int
+	lastProbe   time.Time
+	pending     chan error
+	stats       FailoverStats
+}
+
+// NewFailoverWriter creates a FailoverWriter over primary and secondary.
+func NewFailoverWriter(primary, secondary io.Writer, opts ...FailoverOption) *FailoverWriter {
+	w := &FailoverWriter{
+		primary:       primary,
+		secondary:     secondary,
+		threshold:     3,
+		probeInterval: 10 * time.Second,
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Write implements io.Writer.
+func (w *FailoverWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.onSecondary {
+		if w.now().Sub(w.lastProbe) < w.probeInterval {
+			return w.secondary.Write(p)
+		}
+		w.lastProbe = w.now()
+		if err := w.writePrimary(p); err != nil {
+			w.stats.Errors++
+			return w.secondary.Write(p)
+		}
+		w.onSecondary = false
+		w.consecutive = 0
+		w.stats.Failbacks++
+		w.stats.OnSecondary = false
+		writeMetaEvent(w.primary, p, zerolog.InfoLevel, "ezlog: primary writer recovered", nil)
+		return len(p), nil
+	}
+
+	err := w.writePrimary(p)
+	if err == nil {
+		w.consecutive = 0
+		return len(p), nil
+	}
+
+	w.stats.Errors++
+	w.consecutive++
+	if w.consecutive >= w.threshold {
+		w.onSecondary = true
+		w.lastProbe = w.now()
+		w.stats.Failovers++
+		w.stats.OnSecondary = true
+		writeMetaEvent(w.secondary, p, zerolog.WarnLevel, "ezlog: primary writer failed, switching to secondary", err)
+	}
+	return w.secondary.Write(p)
+}
+
+// Stats returns the writer's counters.
+func (w *FailoverWriter) Stats() FailoverStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stats
+}
+
+// Close closes both writers if they are io.Closers.
+func (w *FailoverWriter) Close() error {
+	var errs []error
+	for _, out := range []io.Writer{w.primary, w.secondary} {
+		if c, ok := out.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writePrimary writes p to the primary writer, enforcing the timeout. It
+// fails without writing while a timed out write is still running.
+func (w *FailoverWriter) writePrimary(p []byte) error {
+	if w.timeout <= 0 {
+		_, err := w.primary.Write(p)
+		return err
+	}
+	if w.pending != nil {
+		select {
+		case <-w.pending:
+			w.pending = nil
+		default:
+			return errWritePending
+		}
+	}
+
+	done := make(chan error, 1)
+	buf := append([]byte(nil), p...)
+	go func() {
+		_, err := w.primary.Write(buf)
+		done <- err
+	}()
+
+	timer := time.NewTimer(w.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		w.pending = done
+		return errWriteTimeout
+	}
+}
+
+// writeMetaEvent writes an event about the writer itself to out, as JSON when
+// the events going through are JSON and as a plain line otherwise.
+func writeMetaEvent(out io.Writer, sample []byte, level zerolog.Level, msg string, err error) {
+	if bytes.HasPrefix(bytes.TrimSpace(sample), []byte("{")) {
+		evt := map[string]any{
+			zerolog.LevelFieldName:   LevelString(level),
+			zerolog.MessageFieldName: msg,
+		}
+		if err != nil {
+			evt[zerolog.ErrorFieldName] = err.Error()
+		}
+		if b, jerr := json.Marshal(evt); jerr == nil {
+			_, _ = out.Write(append(b, '\n'))
+		}
+		return
+	}
+
+	if err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, err)
+	}
+	_, _ = fmt.Fprintf(out, "[%s] %s\n", strings.ToUpper(LevelString(level)), msg)
+}
+
+// WithFallbackWriter writes events to fallback, typically os.Stderr, when the
+// builder's writer fails. After consecutive failures, 3 by default, a single
+// event reports that the writer is down and events go to fallback until the
+// writer works again, which is reported too; see FailoverWriter.
+func (b *LogBuilder) WithFallbackWriter(fallback io.Writer, opts ...FailoverOption) *LogBuilder {
+	b.fallback = fallback
+	b.fallbackOpts = opts
+	return b
+}
+
+// outputWriter returns the builder's writer, compressed, with its fallback
+// and buffered when requested.
+func (b *LogBuilder) outputWriter() io.Writer {
+	w := b.compressedWriter()
+	if b.fallback != nil {
+		if b.failover == nil || b.failover.primary != w || b.failover.secondary != b.fallback {
+			b.failover = NewFailoverWriter(w, b.fallback, b.fallbackOpts...)
+		}
+		w = b.failover
+	}
+	if b.outputBuffer > 0 {
+		if b.buffered == nil || b.buffered.out != w {
+			b.buffered = newBufferedOutput(w, b.outputBuffer)
+		}
+		w = b.buffered
+	}
+	return w
+}
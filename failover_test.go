@@ -0,0 +1,140 @@
+package ezlog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyWriter is a writer failing while fail is set.
+type flakyWriter struct {
+	bytes.Buffer
+	fail bool
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	if w.fail {
+		return 0, errors.New("disk gone")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestFailoverWriter(t *testing.T) {
+	primary := &flakyWriter{}
+	var secondary bytes.Buffer
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	w := NewFailoverWriter(primary, &secondary, WithFailoverThreshold(2), WithFailoverProbeInterval(time.Minute))
+	w.now = func() time.Time { return now }
+
+	w.Write([]byte(`{"message":"one"}` + "\n"))
+	primary.fail = true
+	w.Write([]byte(`{"message":"two"}` + "\n"))
+	if st := w.Stats(); st.OnSecondary || st.Errors != 1 {
+		t.Fatalf("after one error: stats = %+v", st)
+	}
+	w.Write([]byte(`{"message":"three"}` + "\n"))
+	if st := w.Stats(); !st.OnSecondary || st.Failovers != 1 {
+		t.Fatalf("after two errors: stats = %+v, want a failover", st)
+	}
+	if got := secondary.String(); strings.Count(got, "switching to secondary") != 1 ||
+		!strings.Contains(got, `"two"`) || !strings.Contains(got, `"three"`) {
+		t.Errorf("secondary = %q", got)
+	}
+
+	// The primary recovers but is only probed after the interval.
+	primary.fail = false
+	w.Write([]byte(`{"message":"four"}` + "\n"))
+	if strings.Contains(primary.String(), "four") {
+		t.Error("primary written to before the probe interval")
+	}
+	now = now.Add(time.Minute)
+	w.Write([]byte(`{"message":"five"}` + "\n"))
+	st := w.Stats()
+	if st.OnSecondary || st.Failbacks != 1 {
+		t.Fatalf("after recovery: stats = %+v, want a failback", st)
+	}
+	if got := primary.String(); !strings.Contains(got, "five") || !strings.Contains(got, "primary writer recovered") {
+		t.Errorf("primary = %q", got)
+	}
+}
+
+// slowWriter blocks writes until release is closed, recording the number of
+// concurrent writes.
+type slowWriter struct {
+	release  chan struct{}
+	inflight atomic.Int32
+	overlap  atomic.Bool
+	mu       sync.Mutex
+	buf      bytes.Buffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	if w.inflight.Add(1) > 1 {
+		w.overlap.Store(true)
+	}
+	defer w.inflight.Add(-1)
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func TestFailoverWriterTimeoutDoesNotOverlap(t *testing.T) {
+	primary := &slowWriter{release: make(chan struct{})}
+	var secondary bytes.Buffer
+	w := NewFailoverWriter(primary, &secondary, WithFailoverThreshold(5),
+		WithFailoverTimeout(10*time.Millisecond))
+
+	for _, msg := range []string{"a", "b", "c"} {
+		w.Write([]byte(msg + "\n"))
+	}
+	if primary.overlap.Load() {
+		t.Error("a primary write started while a timed out one was running")
+	}
+	if got := secondary.String(); got != "a\nb\nc\n" {
+		t.Errorf("secondary = %q, want all three lines", got)
+	}
+	if st := w.Stats(); st.Errors != 3 {
+		t.Errorf("errors = %d, want 3", st.Errors)
+	}
+
+	close(primary.release)
+	time.Sleep(20 * time.Millisecond)
+	w.Write([]byte("d\n"))
+	primary.mu.Lock()
+	defer primary.mu.Unlock()
+	if got := primary.buf.String(); got != "a\nd\n" {
+		t.Errorf("primary = %q, want the first and the last line", got)
+	}
+}
+
+func TestWithFallbackWriter(t *testing.T) {
+	primary := &flakyWriter{fail: true}
+	var fallback bytes.Buffer
+	l := New().AsLocal().WithWriter(primary).WithJSONOutput().
+		WithFallbackWriter(&fallback, WithFailoverProbeInterval(0)).Build()
+
+	for range 5 {
+		l.Info().Msg("lost")
+	}
+	got := fallback.String()
+	if strings.Count(got, `"message":"lost"`) != 5 || strings.Count(got, "switching to secondary") != 1 {
+		t.Fatalf("fallback = %q, want every event and a single down event", got)
+	}
+	if !strings.Contains(got, `"level":"warn"`) || !strings.Contains(got, "disk gone") {
+		t.Errorf("fallback = %q, want a warning with the primary error", got)
+	}
+
+	primary.fail = false
+	l.Info().Msg("back")
+	if got := primary.String(); !strings.Contains(got, `"message":"back"`) || !strings.Contains(got, "primary writer recovered") {
+		t.Errorf("primary = %q, want the event and a recovery event", got)
+	}
+	if strings.Contains(fallback.String(), "back") {
+		t.Error("event written to the fallback after recovery")
+	}
+}
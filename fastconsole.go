@@ -0,0 +1,371 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattn/go-colorable"
+	"github.com/rs/zerolog"
+)
+
+// WithFastConsole renders console lines straight from zerolog's JSON bytes
+// instead of decoding every event into a map first, as ConsoleWriter does.
+// The output is the same. Events with nested objects or arrays go through
+// ConsoleWriter, and so does everything when an option needing the decoded
+// event is enabled, such as WithHumanizeFields, WithMultiline,
+// WithErrorChain, WithTableFormat, WithSortedFields, WithFieldOrder,
+// WithMessageTransform or the console field filters.
+func (b *LogBuilder) WithFastConsole() *LogBuilder {
+	b.fastConsole = true
+	return b
+}
+
+// fastConsoleEligible reports whether w can be rendered by a
+// fastConsoleWriter.
+func (b *LogBuilder) fastConsoleEligible(w zerolog.ConsoleWriter) bool {
+	return b.fastConsole && !b.humanize && len(b.consoleExclude) == 0 && len(b.consoleOnly) == 0 &&
+		len(b.messageTransforms) == 0 &&
+		w.FormatExtra == nil && w.PartsOrder == nil && w.PartsExclude == nil &&
+		w.FieldsOrder == nil && w.FieldsExclude == nil && w.FormatPartValueByName == nil
+}
+
+// fastField is a top-level field of an event. Values are strings, json.Number
+// or, for true, false and null, their JSON bytes, which are the types
+// ConsoleWriter hands to the formatters.
+type fastField struct {
+	key   string
+	value any
+}
+
+// fastConsoleBufPool holds the buffers of fastConsoleWriter.
+var fastConsoleBufPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// fastConsoleWriter renders flat events with the formatters of a
+// ConsoleWriter, falling back to it for the other ones.
+type fastConsoleWriter struct {
+	slow     zerolog.ConsoleWriter
+	out      io.Writer
+	sanitize bool
+
+	formatTimestamp zerolog.Formatter
+	formatErrName   zerolog.Formatter
+	formatErrValue  zerolog.Formatter
+}
+
+// newFastConsoleWriter creates a fastConsoleWriter over slow. sanitize tells
+// whether slow sanitizes messages.
+func newFastConsoleWriter(slow zerolog.ConsoleWriter, sanitize bool) *fastConsoleWriter {
+	noColor := slow.NoColor || os.Getenv("NO_COLOR") != ""
+	w := &fastConsoleWriter{
+		slow:            slow,
+		out:             slow.Out,
+		sanitize:        sanitize,
+		formatTimestamp: slow.FormatTimestamp,
+		formatErrName:   slow.FormatErrFieldName,
+		formatErrValue:  slow.FormatErrFieldValue,
+	}
+	if w.formatTimestamp == nil {
+		w.formatTimestamp = consoleDefaultTimestamp(slow.TimeFormat, slow.TimeLocation, noColor)
+	}
+	if w.formatErrName == nil {
+		w.formatErrName = func(i any) string {
+			return colorize(fmt.Sprintf("%s=", i), 36, noColor)
+		}
+	}
+	if w.formatErrValue == nil {
+		w.formatErrValue = func(i any) string {
+			return colorize(colorize(fmt.Sprintf("%s", i), 1, noColor), 31, noColor)
+		}
+	}
+	if f, ok := w.out.(*os.File); ok && (f == os.Stdout || f == os.Stderr) {
+		w.out = colorable.NewColorable(f)
+	}
+	return w
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *fastConsoleWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	return w.Write(p)
+}
+
+// Write implements io.Writer.
+func (w *fastConsoleWriter) Write(p []byte) (int, error) {
+	var stack [16]fastField
+	fields, ok := scanFlatJSON(p, stack[:0])
+	if !ok {
+		return w.slow.Write(p)
+	}
+
+	buf := fastConsoleBufPool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		fastConsoleBufPool.Put(buf)
+	}()
+
+	var ts, level, caller, msg any
+	rest := fields[:0]
+	for _, f := range fields {
+		switch f.key {
+		case zerolog.TimestampFieldName:
+			ts = f.value
+		case zerolog.LevelFieldName:
+			level = f.value
+		case zerolog.CallerFieldName:
+			caller = f.value
+		case zerolog.MessageFieldName:
+			msg = f.value
+		case SectionFieldName:
+			return w.slow.Write(p)
+		default:
+			rest = append(rest, f)
+		}
+	}
+	if s, ok := msg.(string); ok && w.sanitize {
+		msg = sanitize(s, false)
+	}
+
+	writePart := func(f zerolog.Formatter, v any) {
+		if s := f(v); len(s) > 0 {
+			if buf.Len() > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(s)
+		}
+	}
+	writePart(w.formatTimestamp, ts)
+	writePart(w.slow.FormatLevel, level)
+	writePart(w.slow.FormatCaller, caller)
+	writePart(w.slow.FormatMessage, msg)
+
+	w.writeFields(buf, rest)
+	buf.WriteByte('\n')
+
+	if _, err := buf.WriteTo(w.out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeFields renders fields the way ConsoleWriter does: sorted by key, with
+// the error field first.
+func (w *fastConsoleWriter) writeFields(buf *bytes.Buffer, fields []fastField) {
+	sort.Slice(fields, func(i, j int) bool { return fields[i].key < fields[j].key })
+	if i := sort.Search(len(fields), func(i int) bool {
+		return fields[i].key >= zerolog.ErrorFieldName
+	}); i < len(fields) && fields[i].key == zerolog.ErrorFieldName {
+		errField := fields[i]
+		copy(fields[1:i+1], fields[:i])
+		fields[0] = errField
+	}
+
+	if buf.Len() > 0 && len(fields) > 0 {
+		buf.WriteByte(' ')
+	}
+	for i, f := range fields {
+		fn, fv := w.slow.FormatFieldName, w.slow.FormatFieldValue
+		if f.key == zerolog.ErrorFieldName {
+			fn, fv = w.formatErrName, w.formatErrValue
+		}
+		buf.WriteString(fn(f.key))
+		if s, ok := f.value.(string); ok && needsQuote(s) {
+			buf.WriteString(fv(strconv.Quote(s)))
+		} else {
+			buf.WriteString(fv(f.value))
+		}
+		if i < len(fields)-1 {
+			buf.WriteByte(' ')
+		}
+	}
+}
+
+// scanFlatJSON appends the fields of the JSON object p to fields. It fails on
+// nested objects and arrays, and on invalid JSON. A repeated key keeps its
+// last value, like decoding into a map.
+func scanFlatJSON(p []byte, fields []fastField) ([]fastField, bool) {
+	i := skipSpace(p, 0)
+	if i >= len(p) || p[i] != '{' {
+		return nil, false
+	}
+	i = skipSpace(p, i+1)
+	if i < len(p) && p[i] == '}' {
+		return fields, skipSpace(p, i+1) == len(p)
+	}
+
+	for {
+		key, next, ok := scanJSONString(p, i)
+		if !ok {
+			return nil, false
+		}
+		i = skipSpace(p, next)
+		if i >= len(p) || p[i] != ':' {
+			return nil, false
+		}
+		i = skipSpace(p, i+1)
+		if i >= len(p) {
+			return nil, false
+		}
+
+		var value any
+		switch c := p[i]; {
+		case c == '"':
+			var s string
+			s, i, ok = scanJSONString(p, i)
+			if !ok {
+				return nil, false
+			}
+			value = s
+		case c == '-' || (c >= '0' && c <= '9'):
+			start := i
+			for i < len(p) && bytes.IndexByte([]byte("+-.eE0123456789"), p[i]) >= 0 {
+				i++
+			}
+			value = json.Number(p[start:i])
+		case c == 't' || c == 'f' || c == 'n':
+			var lit string
+			switch c {
+			case 't':
+				lit = "true"
+			case 'f':
+				lit = "false"
+			default:
+				lit = "null"
+			}
+			if !bytes.HasPrefix(p[i:], []byte(lit)) {
+				return nil, false
+			}
+			value = []byte(lit)
+			i += len(lit)
+		default:
+			return nil, false
+		}
+
+		fields = setFastField(fields, key, value)
+
+		i = skipSpace(p, i)
+		if i >= len(p) {
+			return nil, false
+		}
+		switch p[i] {
+		case ',':
+			i = skipSpace(p, i+1)
+		case '}':
+			return fields, skipSpace(p, i+1) == len(p)
+		default:
+			return nil, false
+		}
+	}
+}
+
+// setFastField sets key to value in fields.
+func setFastField(fields []fastField, key string, value any) []fastField {
+	for j := range fields {
+		if fields[j].key == key {
+			fields[j].value = value
+			return fields
+		}
+	}
+	return append(fields, fastField{key: key, value: value})
+}
+
+// scanJSONString decodes the JSON string starting at p[i] and returns it with
+// the index following it. Strings with escapes or non-UTF-8 bytes are decoded
+// by encoding/json, to get exactly the same result.
+func scanJSONString(p []byte, i int) (string, int, bool) {
+	if i >= len(p) || p[i] != '"' {
+		return "", i, false
+	}
+	simple := true
+	for j := i + 1; j < len(p); j++ {
+		switch c := p[j]; {
+		case c == '\\':
+			simple = false
+			j++
+		case c == '"':
+			if simple {
+				s := p[i+1 : j]
+				if utf8.Valid(s) {
+					return string(s), j + 1, true
+				}
+			}
+			var s string
+			if err := json.Unmarshal(p[i:j+1], &s); err != nil {
+				return "", j + 1, false
+			}
+			return s, j + 1, true
+		case c < 0x20:
+			return "", j, false
+		}
+	}
+	return "", len(p), false
+}
+
+// skipSpace returns the index of the first non-whitespace byte from i.
+func skipSpace(p []byte, i int) int {
+	for i < len(p) && (p[i] == ' ' || p[i] == '\t' || p[i] == '\n' || p[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// consoleDefaultTimestamp is ConsoleWriter's default timestamp formatter.
+func consoleDefaultTimestamp(timeFormat string, location *time.Location, noColor bool) zerolog.Formatter {
+	if timeFormat == "" {
+		timeFormat = time.Kitchen
+	}
+	if location == nil {
+		location = time.Local
+	}
+	return func(i any) string {
+		t := "<nil>"
+		switch tt := i.(type) {
+		case string:
+			ts, err := time.ParseInLocation(zerolog.TimeFieldFormat, tt, location)
+			if err != nil {
+				t = tt
+			} else {
+				t = ts.In(location).Format(timeFormat)
+			}
+		case json.Number:
+			n, err := tt.Int64()
+			if err != nil {
+				t = tt.String()
+			} else {
+				var ts time.Time
+				switch zerolog.TimeFieldFormat {
+				case zerolog.TimeFormatUnixNano:
+					ts = time.Unix(0, n)
+				case zerolog.TimeFormatUnixMicro:
+					ts = time.Unix(0, n*int64(time.Microsecond))
+				case zerolog.TimeFormatUnixMs:
+					ts = time.Unix(0, n*int64(time.Millisecond))
+				default:
+					ts = time.Unix(n, 0)
+				}
+				t = ts.In(location).Format(timeFormat)
+			}
+		}
+		return colorize(t, 90, noColor)
+	}
+}
+
+// colorize wraps s in the ANSI color c unless noColor is set, like
+// ConsoleWriter's default formatters.
+func colorize(s string, c int, noColor bool) string {
+	if noColor {
+		return s
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", c, s)
+}
+
+// fastConsoleWriter must satisfy zerolog.LevelWriter.
+var _ zerolog.LevelWriter = (*fastConsoleWriter)(nil)
@@ -0,0 +1,76 @@
+package ezlog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// consolePair returns a fast and a regular console logger writing to fast
+// and slow.
+func consolePair(fast, slow io.Writer) (*zerolog.Logger, *zerolog.Logger) {
+	now := fixedClock(time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC))
+	build := func(w io.Writer, fastConsole bool) *zerolog.Logger {
+		b := New().AsLocal().WithWriter(w).WithColorSupport(ColorLevelTrueColor).
+			WithTag("api").WithTimestampFunc(now).WithUTC()
+		if fastConsole {
+			b = b.WithFastConsole()
+		}
+		return b.Build()
+	}
+	return build(fast, true), build(slow, false)
+}
+
+func TestFastConsoleMatchesConsoleWriter(t *testing.T) {
+	events := map[string]func(l *zerolog.Logger){
+		"message": func(l *zerolog.Logger) { l.Info().Msg("hello") },
+		"fields": func(l *zerolog.Logger) {
+			l.Warn().Str("user", "ann lee").Int("n", 3).Bool("ok", true).Float64("f", 1.5).Msg("done")
+		},
+		"error":    func(l *zerolog.Logger) { l.Error().Err(errors.New("boom")).Str("b", "x").Msg("failed") },
+		"escapes":  func(l *zerolog.Logger) { l.Info().Str("q", "say \"hi\"\n").Msg("tab\there") },
+		"unicode":  func(l *zerolog.Logger) { l.Debug().Str("name", "Zoë ✓").Msg("déjà vu") },
+		"null":     func(l *zerolog.Logger) { l.Info().Interface("v", nil).Msg("") },
+		"nested":   func(l *zerolog.Logger) { l.Info().Dict("req", zerolog.Dict().Str("m", "GET")).Msg("nested") },
+		"array":    func(l *zerolog.Logger) { l.Info().Strs("tags", []string{"a", "b"}).Msg("array") },
+		"no level": func(l *zerolog.Logger) { l.Log().Msg("plain") },
+	}
+	for name, log := range events {
+		t.Run(name, func(t *testing.T) {
+			var fast, slow bytes.Buffer
+			fl, sl := consolePair(&fast, &slow)
+			log(fl)
+			log(sl)
+			if fast.String() != slow.String() {
+				t.Errorf("fast console = %q\nconsole      = %q", fast.String(), slow.String())
+			}
+		})
+	}
+}
+
+func TestFastConsoleNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	var fast, slow bytes.Buffer
+	fl, sl := consolePair(&fast, &slow)
+	fl.Error().Err(errors.New("boom")).Msg("failed")
+	sl.Error().Err(errors.New("boom")).Msg("failed")
+	if fast.String() != slow.String() {
+		t.Errorf("fast console = %q\nconsole      = %q", fast.String(), slow.String())
+	}
+}
+
+func BenchmarkConsole(b *testing.B) {
+	fl, sl := consolePair(io.Discard, io.Discard)
+	for name, l := range map[string]*zerolog.Logger{"fast": fl, "slow": sl} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				l.Info().Str("user", "ann").Int("status", 200).Dur("elapsed", time.Millisecond).Msg("request served")
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package ezlog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// defaultFatalHookTimeout bounds how long each fatal or panic hook may run.
+const defaultFatalHookTimeout = 5 * time.Second
+
+// WithFatalHook runs fn with the fields of every event logged with Fatal,
+// once it is written and the output flushed, before the process exits, e.g.
+// to flush traces or write a crash marker. Fatal events logged with
+// WithLevel, which zerolog does not exit on, do not run the hooks. Hooks run in the order they were added,
+// each one for at most the WithFatalHookTimeout duration; a panicking hook
+// is reported to the diagnostics and the next one runs.
+func (b *LogBuilder) WithFatalHook(fn func(event map[string]any)) *LogBuilder {
+	b.fatalHooks = append(b.fatalHooks, fn)
+	return b
+}
+
+// WithPanicHook runs fn with the fields of every event logged with Panic,
+// once it is written and the output flushed, before the logger panics. Hooks
+// run like the WithFatalHook ones.
+func (b *LogBuilder) WithPanicHook(fn func(event map[string]any)) *LogBuilder {
+	b.panicHooks = append(b.panicHooks, fn)
+	return b
+}
+
+// WithFatalHookTimeout sets how long each fatal or panic hook may run before
+// it is abandoned, 5s by default.
+func (b *LogBuilder) WithFatalHookTimeout(d time.Duration) *LogBuilder {
+	b.fatalHookTimeout = d
+	return b
+}
+
+// WithExitFunc makes the events logged with Fatal call fn with the exit code
+// 1 instead of os.Exit once the fatal hooks ran. zerolog still exits when the
+// logging goroutine returns or unwinds, so tests intercepting exit should
+// block it, e.g. with select {}.
+func (b *LogBuilder) WithExitFunc(fn func(code int)) *LogBuilder {
+	b.exitFunc = fn
+	return b
+}
+
+// fatalHookWriter runs the fatal and panic hooks once the events logged with
+// Fatal or Panic are written to out and flush is flushed. Those events are
+// told apart from the WithLevel ones by a terminalEventHook.
+type fatalHookWriter struct {
+	out     io.Writer
+	flush   io.Writer
+	fatal   []func(map[string]any)
+	panic   []func(map[string]any)
+	timeout time.Duration
+	exit    func(int)
+	diag    *diagnostics
+	b       *LogBuilder
+
+	// terminal holds the IDs of the goroutines writing a terminal event.
+	terminal sync.Map
+}
+
+// Write implements io.Writer.
+func (w *fatalHookWriter) Write(p []byte) (int, error) {
+	level, ok := w.b.parseLevelValue(eventLevel(p))
+	if !ok {
+		level = zerolog.NoLevel
+	}
+	return w.WriteLevel(level, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *fatalHookWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level != zerolog.FatalLevel && level != zerolog.PanicLevel {
+		return writeLevel(w.out, level, p)
+	}
+	if _, ok := w.terminal.LoadAndDelete(goroutineID()); !ok {
+		return writeLevel(w.out, level, p)
+	}
+
+	n, err := writeLevel(w.out, level, p)
+	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	_ = flushWithContext(ctx, w.flush)
+	cancel()
+
+	hooks := w.panic
+	if level == zerolog.FatalLevel {
+		hooks = w.fatal
+	}
+	var event map[string]any
+	if len(hooks) > 0 && json.Unmarshal(p, &event) != nil {
+		event = map[string]any{zerolog.LevelFieldName: LevelString(level)}
+	}
+	for _, hook := range hooks {
+		w.run(level, hook, event)
+	}
+
+	if level == zerolog.FatalLevel && w.exit != nil {
+		w.exit(1)
+	}
+	return n, err
+}
+
+// terminalEventHook marks the fatal and panic events that end with zerolog
+// exiting or panicking, as the ones of Logger.Fatal and Logger.Panic do, for
+// the fatalHookWriter they are written to next by the same goroutine.
+type terminalEventHook struct {
+	w *fatalHookWriter
+}
+
+// Run implements zerolog.Hook.
+func (h terminalEventHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if (level == zerolog.FatalLevel || level == zerolog.PanicLevel) && e.Enabled() && eventTerminates(e) {
+		h.w.terminal.Store(goroutineID(), struct{}{})
+	}
+}
+
+// eventTerminates reports whether e has the completion function zerolog
+// sets for Logger.Fatal and Logger.Panic, which WithLevel leaves unset.
+// Should zerolog's Event lose that field, every event is taken as terminal.
+func eventTerminates(e *zerolog.Event) bool {
+	done := reflect.ValueOf(e).Elem().FieldByName("done")
+	return !done.IsValid() || done.Kind() != reflect.Func || !done.IsNil()
+}
+
+// run calls hook with event, giving up after the hook timeout and reporting
+// a panic of the hook to the diagnostics.
+func (w *fatalHookWriter) run(level zerolog.Level, hook func(map[string]any), event map[string]any) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				w.diag.report(zerolog.ErrorLevel, "hook panic", "%s hook panicked: %v", LevelString(level), r)
+			}
+		}()
+		hook(event)
+	}()
+
+	timer := time.NewTimer(w.timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		w.diag.report(zerolog.ErrorLevel, "hook timeout", "%s hook timed out after %s", LevelString(level), w.timeout)
+	}
+}
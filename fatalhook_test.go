@@ -0,0 +1,132 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestFatalHookRunsBeforeExit(t *testing.T) {
+	var buf lockedBuffer
+	hooked := make(chan map[string]any, 1)
+	exited := make(chan int, 1)
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().
+		WithFatalHook(func(evt map[string]any) { hooked <- evt }).
+		WithExitFunc(func(code int) {
+			exited <- code
+			select {} // zerolog exits once the exit func returns.
+		}).Build()
+
+	go l.Fatal().Str("reason", "disk").Msg("giving up")
+
+	select {
+	case code := <-exited:
+		if code != 1 {
+			t.Errorf("exit code = %d, want 1", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("exit func not called")
+	}
+	evt := <-hooked
+	if evt["reason"] != "disk" || evt["message"] != "giving up" {
+		t.Errorf("hook event = %v", evt)
+	}
+	if !strings.Contains(buf.String(), "giving up") {
+		t.Errorf("event not written before the hooks: %q", buf.String())
+	}
+}
+
+func TestPanicHook(t *testing.T) {
+	var buf bytes.Buffer
+	var got map[string]any
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().
+		WithPanicHook(func(evt map[string]any) { got = evt }).Build()
+
+	func() {
+		defer func() { recover() }()
+		l.Panic().Msg("bad state")
+	}()
+	if got["message"] != "bad state" {
+		t.Errorf("hook event = %v", got)
+	}
+}
+
+func TestPanicHooksOrderTimeoutAndPanics(t *testing.T) {
+	var buf, diags lockedBuffer
+	var ran []string
+	release := make(chan struct{})
+	defer close(release)
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithDiagnostics(&diags).
+		WithFatalHookTimeout(20 * time.Millisecond).
+		WithPanicHook(func(map[string]any) { ran = append(ran, "first") }).
+		WithPanicHook(func(map[string]any) { panic("boom") }).
+		WithPanicHook(func(map[string]any) { <-release }).
+		WithPanicHook(func(map[string]any) { ran = append(ran, "last") }).Build()
+
+	start := time.Now()
+	func() {
+		defer func() {
+			if r := recover(); r != "bad state" {
+				t.Errorf("recovered %v, want the logger panic", r)
+			}
+		}()
+		l.Panic().Msg("bad state")
+	}()
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("hooks ran for %s, want the hung one abandoned after 20ms", elapsed)
+	}
+	if got := strings.Join(ran, ","); got != "first,last" {
+		t.Errorf("ran %s, want first,last", got)
+	}
+	got := diags.String()
+	for _, want := range []string{
+		"[ezlog] error: panic hook panicked: boom",
+		"[ezlog] error: panic hook timed out after 20ms",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("diagnostics = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestFatalHooksSkipWithLevel(t *testing.T) {
+	var buf bytes.Buffer
+	ran := false
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().
+		WithFatalHook(func(map[string]any) { ran = true }).
+		WithPanicHook(func(map[string]any) { ran = true }).
+		WithExitFunc(func(int) { ran = true }).Build()
+
+	l.WithLevel(zerolog.FatalLevel).Msg("reported fatal")
+	l.WithLevel(zerolog.PanicLevel).Msg("reported panic")
+	if ran {
+		t.Error("hooks or exit func ran for WithLevel events")
+	}
+	if got := bytes.Count(buf.Bytes(), []byte("\n")); got != 2 {
+		t.Errorf("got %d events, want 2", got)
+	}
+}
+
+func TestFatalHookWriterForwardsLevel(t *testing.T) {
+	inner := &levelRecorder{}
+	w := &fatalHookWriter{out: inner, b: New().AsLocal()}
+	w.WriteLevel(zerolog.WarnLevel, []byte(`{"message":"x"}`))
+	if inner.level != zerolog.WarnLevel {
+		t.Errorf("inner level = %v, want warn", inner.level)
+	}
+}
+
+// levelRecorder records the level of the last event written to it.
+type levelRecorder struct {
+	bytes.Buffer
+	level zerolog.Level
+}
+
+func (r *levelRecorder) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	r.level = level
+	return r.Write(p)
+}
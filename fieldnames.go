@@ -0,0 +1,138 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// FieldNames are the names of the standard fields of JSON output. Empty
+// names keep zerolog's default.
+type FieldNames struct {
+	Level   string
+	Time    string
+	Message string
+	Error   string
+	Caller  string
+}
+
+// ECSPreset returns the field names of the Elastic Common Schema.
+func ECSPreset() FieldNames {
+	return FieldNames{
+		Level:   "log.level",
+		Time:    "@timestamp",
+		Message: "message",
+		Error:   "error.message",
+		Caller:  "log.origin.file.name",
+	}
+}
+
+// DatadogPreset returns the field names of Datadog's reserved attributes.
+func DatadogPreset() FieldNames {
+	return FieldNames{
+		Level:   "status",
+		Time:    "timestamp",
+		Message: "message",
+		Error:   "error.message",
+		Caller:  "logger.name",
+	}
+}
+
+// WithFieldNames renames the standard fields of JSON output, e.g. to
+// DatadogPreset() or ECSPreset() for ingestion. For the global logger this
+// sets zerolog's process-wide field name variables, such as
+// zerolog.LevelFieldName; local loggers rename the fields of their JSON
+// output only, leaving the variables alone. Console output renders the
+// renamed fields as usual.
+func (b *LogBuilder) WithFieldNames(names FieldNames) *LogBuilder {
+	b.fieldNames = &names
+	return b
+}
+
+// applyGlobalFieldNames sets zerolog's field name variables to names.
+func applyGlobalFieldNames(names FieldNames) {
+	set := func(v *string, name string) {
+		if name != "" {
+			*v = name
+		}
+	}
+	set(&zerolog.LevelFieldName, names.Level)
+	set(&zerolog.TimestampFieldName, names.Time)
+	set(&zerolog.MessageFieldName, names.Message)
+	set(&zerolog.ErrorFieldName, names.Error)
+	set(&zerolog.CallerFieldName, names.Caller)
+}
+
+// renames returns the field renames of names.
+func (names FieldNames) renames() map[string]string {
+	r := make(map[string]string, 5)
+	add := func(from, to string) {
+		if to != "" && to != from {
+			r[from] = to
+		}
+	}
+	add(zerolog.LevelFieldName, names.Level)
+	add(zerolog.TimestampFieldName, names.Time)
+	add(zerolog.MessageFieldName, names.Message)
+	add(zerolog.ErrorFieldName, names.Error)
+	add(zerolog.CallerFieldName, names.Caller)
+	return r
+}
+
+// fieldRenameWriter renames the top-level fields of JSON events before they
+// reach out.
+type fieldRenameWriter struct {
+	out     io.Writer
+	renames map[string]string
+}
+
+// Write implements io.Writer. Events that are not JSON objects are written
+// unchanged.
+func (w *fieldRenameWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *fieldRenameWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	renamed, ok := w.rename(p)
+	if !ok {
+		return writeLevel(w.out, level, p)
+	}
+	if _, err := writeLevel(w.out, level, renamed); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// rename returns p with its top-level fields renamed.
+func (w *fieldRenameWriter) rename(p []byte) ([]byte, bool) {
+	d := json.NewDecoder(bytes.NewReader(p))
+	if t, err := d.Token(); err != nil || t != json.Delim('{') {
+		return nil, false
+	}
+
+	out := make([]byte, 0, len(p)+32)
+	last := 0
+	for d.More() {
+		before := int(d.InputOffset())
+		t, err := d.Token()
+		if err != nil {
+			return nil, false
+		}
+		after := int(d.InputOffset())
+		if to, ok := w.renames[t.(string)]; ok {
+			// Only separators precede the key since the previous value.
+			start := before + bytes.IndexByte(p[before:after], '"')
+			out = append(out, p[last:start]...)
+			out = append(out, marshalJSONString(to)...)
+			last = after
+		}
+		var v json.RawMessage
+		if err := d.Decode(&v); err != nil {
+			return nil, false
+		}
+	}
+	return append(out, p[last:]...), true
+}
@@ -0,0 +1,108 @@
+package ezlog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// restoreFieldNames restores zerolog's field name variables after the test.
+func restoreFieldNames(t *testing.T) {
+	t.Helper()
+	level, ts, msg, errName, caller := zerolog.LevelFieldName, zerolog.TimestampFieldName,
+		zerolog.MessageFieldName, zerolog.ErrorFieldName, zerolog.CallerFieldName
+	t.Cleanup(func() {
+		zerolog.LevelFieldName, zerolog.TimestampFieldName, zerolog.MessageFieldName,
+			zerolog.ErrorFieldName, zerolog.CallerFieldName = level, ts, msg, errName, caller
+	})
+}
+
+func TestFieldNamesPresets(t *testing.T) {
+	tests := []struct {
+		name  string
+		names FieldNames
+		want  []string
+	}{
+		{"ecs", ECSPreset(), []string{"log.level", "@timestamp", "message", "error.message", "log.origin.file.name"}},
+		{"datadog", DatadogPreset(), []string{"status", "timestamp", "message", "error.message", "logger.name"}},
+		{"partial", FieldNames{Level: "severity"}, []string{"severity", "time", "message", "error", "caller"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithCaller().WithFieldNames(tt.names).Build()
+			l.Error().Err(errors.New("boom")).Str("level_hint", "x").Msg("failed")
+
+			evt := decodeLines(t, &buf)[0]
+			if len(evt) != len(tt.want)+1 {
+				t.Errorf("event %v has %d fields, want %d", evt, len(evt), len(tt.want)+1)
+			}
+			for _, key := range tt.want {
+				if _, ok := evt[key]; !ok {
+					t.Errorf("event %v lacks %q", evt, key)
+				}
+			}
+			if evt[tt.want[0]] != "error" || evt["level_hint"] != "x" {
+				t.Errorf("event %v, want level error and level_hint untouched", evt)
+			}
+			if zerolog.LevelFieldName != "level" || zerolog.TimestampFieldName != "time" {
+				t.Error("a local logger changed zerolog's field name variables")
+			}
+		})
+	}
+}
+
+func TestFieldNamesConsoleUnchanged(t *testing.T) {
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	var plain, renamed bytes.Buffer
+	for buf, b := range map[*bytes.Buffer]*LogBuilder{
+		&plain:   newTestBuilder(&plain),
+		&renamed: newTestBuilder(&renamed).WithFieldNames(DatadogPreset()),
+	} {
+		l := b.WithTimestampFunc(fixedClock(ts)).Build()
+		l.Warn().Err(errors.New("boom")).Int("n", 1).Msg("slow")
+		if buf.Len() == 0 {
+			t.Fatal("no console output")
+		}
+	}
+	if plain.String() != renamed.String() {
+		t.Errorf("console output = %q, want %q", renamed.String(), plain.String())
+	}
+}
+
+func TestFieldNamesGlobal(t *testing.T) {
+	restoreGlobalLogger(t)
+	restoreFieldNames(t)
+	var buf bytes.Buffer
+	New().WithWriter(&buf).WithJSONOutput().WithFieldNames(ECSPreset()).Build()
+	log.Info().Msg("global")
+
+	if zerolog.LevelFieldName != "log.level" || zerolog.TimestampFieldName != "@timestamp" {
+		t.Errorf("field names = %q, %q, want the ECS names", zerolog.LevelFieldName, zerolog.TimestampFieldName)
+	}
+	evt := decodeLines(t, &buf)[0]
+	if evt["log.level"] != "info" || evt["message"] != "global" || evt["@timestamp"] == nil {
+		t.Errorf("event = %v", evt)
+	}
+}
+
+func TestFieldRenameWriterPassesNonJSON(t *testing.T) {
+	var buf bytes.Buffer
+	w := &fieldRenameWriter{out: &buf, renames: map[string]string{"level": "status"}}
+	for _, in := range []string{"plain text\n", `["level"]` + "\n", `{"level":` + "\n"} {
+		buf.Reset()
+		if n, err := w.Write([]byte(in)); n != len(in) || err != nil || buf.String() != in {
+			t.Errorf("Write(%q) = %d, %v, wrote %q", in, n, err, buf.String())
+		}
+	}
+	buf.Reset()
+	in := `{"msg":"{\"level\":1}", "level" : "info","nested":{"level":"x"}}` + "\n"
+	w.Write([]byte(in))
+	if got, want := buf.String(), `{"msg":"{\"level\":1}", "status" : "info","nested":{"level":"x"}}`+"\n"; got != want {
+		t.Errorf("renamed = %q, want %q", got, want)
+	}
+}
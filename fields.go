@@ -0,0 +1,137 @@
+package ezlog
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/rs/zerolog"
+)
+
+// WithSortedFields renders fields in alphabetical order with the error field
+// last, and the caller right after the level. zerolog's ConsoleWriter already
+// sorts fields but moves the error to the front, which makes lines with and
+// without errors hard to compare. JSON output is unaffected.
+func (b *LogBuilder) WithSortedFields() *LogBuilder {
+	b.sortedFields = true
+	return b
+}
+
+// WithFieldOrder pins the given field keys, in order, before the remaining
+// alphabetically sorted fields in console output, e.g. request_id and user_id
+// first. ConsoleWriter would otherwise move the error field in front of the
+// pinned keys, so it is rendered last instead. JSON output is unaffected.
+func (b *LogBuilder) WithFieldOrder(keys ...string) *LogBuilder {
+	b.fieldOrder = append(b.fieldOrder, keys...)
+	return b
+}
+
+// WithConsoleFieldsExclude hides the named fields from console output, e.g.
+// trace IDs and build info that clutter the console but are wanted in the
+// JSON logs of a tee'd writer, which still carries them. The error and the
+// caller are only hidden when named.
+func (b *LogBuilder) WithConsoleFieldsExclude(names ...string) *LogBuilder {
+	b.consoleExclude = append(b.consoleExclude, names...)
+	return b
+}
+
+// WithConsoleFieldsOnly shows only the named fields in console output,
+// besides the timestamp, level, message, caller and error. Other writers
+// still get every field.
+func (b *LogBuilder) WithConsoleFieldsOnly(names ...string) *LogBuilder {
+	b.consoleOnly = append(b.consoleOnly, names...)
+	return b
+}
+
+// applyFieldFilter configures the fields hidden from the console writer.
+func (b *LogBuilder) applyFieldFilter(w *zerolog.ConsoleWriter) {
+	if len(b.consoleExclude) == 0 && len(b.consoleOnly) == 0 {
+		return
+	}
+	exclude := make(map[string]bool, len(b.consoleExclude))
+	for _, name := range b.consoleExclude {
+		exclude[name] = true
+	}
+	var only map[string]bool
+	if len(b.consoleOnly) > 0 {
+		only = map[string]bool{
+			zerolog.TimestampFieldName: true,
+			zerolog.LevelFieldName:     true,
+			zerolog.MessageFieldName:   true,
+			zerolog.CallerFieldName:    true,
+			zerolog.ErrorFieldName:     true,
+		}
+		for _, name := range b.consoleOnly {
+			only[name] = true
+		}
+	}
+	addFormatPrepare(w, func(evt map[string]any) error {
+		for k := range evt {
+			if exclude[k] || (only != nil && !only[k]) {
+				delete(evt, k)
+			}
+		}
+		return nil
+	})
+}
+
+// applyFieldOrder configures the field ordering of the console writer.
+func (b *LogBuilder) applyFieldOrder(w *zerolog.ConsoleWriter) {
+	w.FieldsOrder = b.fieldOrder
+
+	if b.sortedFields {
+		w.PartsOrder = []string{
+			zerolog.TimestampFieldName,
+			zerolog.LevelFieldName,
+			zerolog.CallerFieldName,
+			zerolog.MessageFieldName,
+		}
+	}
+	if b.sortedFields || len(b.fieldOrder) > 0 {
+		errorLast(w)
+	}
+}
+
+// errorLast renders the error field after every other field.
+func errorLast(w *zerolog.ConsoleWriter) {
+	w.FieldsExclude = append(w.FieldsExclude, zerolog.ErrorFieldName)
+	addFormatExtra(w, func(evt map[string]any, buf *bytes.Buffer) error {
+		v, ok := evt[zerolog.ErrorFieldName]
+		if !ok {
+			return nil
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(formatErrField(v))
+		return nil
+	})
+}
+
+// formatErrField renders the error field the way ConsoleWriter does.
+func formatErrField(v any) string {
+	var s string
+	switch vv := v.(type) {
+	case string:
+		s = vv
+		if needsQuote(s) {
+			s = strconv.Quote(s)
+		}
+	default:
+		b, err := zerolog.InterfaceMarshalFunc(vv)
+		if err != nil {
+			s = "[error: " + err.Error() + "]"
+		} else {
+			s = string(b)
+		}
+	}
+	return cyanColor.Sprintf("%s=", zerolog.ErrorFieldName) +
+		boldRedColor.Sprint(s)
+}
+
+// needsQuote reports whether s must be quoted in console output.
+func needsQuote(s string) bool {
+	for i := range s {
+		if s[i] < 0x20 || s[i] > 0x7e || s[i] == ' ' || s[i] == '\\' || s[i] == '"' {
+			return true
+		}
+	}
+	return false
+}
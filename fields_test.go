@@ -0,0 +1,142 @@
+package ezlog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// logFieldsEvent logs the same event with fields in no particular order.
+func logFieldsEvent(l *zerolog.Logger) {
+	l.Info().Str("zone", "eu").Err(errors.New("boom")).Int("id", 7).Str("user", "ada").Str("action", "login").Msg("done")
+}
+
+func TestFieldOrdering(t *testing.T) {
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	tests := []struct {
+		name  string
+		setup func(*LogBuilder) *LogBuilder
+		want  string
+	}{
+		{
+			name:  "unsorted",
+			setup: func(b *LogBuilder) *LogBuilder { return b },
+			want:  `09:26:53.000 [INFO] done error=boom action="login" id=7 user="ada" zone="eu"` + "\n",
+		},
+		{
+			name:  "sorted",
+			setup: func(b *LogBuilder) *LogBuilder { return b.WithSortedFields() },
+			want:  `09:26:53.000 [INFO] done action="login" id=7 user="ada" zone="eu" error=boom` + "\n",
+		},
+		{
+			name:  "pinned",
+			setup: func(b *LogBuilder) *LogBuilder { return b.WithFieldOrder("user", "id") },
+			want:  `09:26:53.000 [INFO] done user="ada" id=7 action="login" zone="eu" error=boom` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logFieldsEvent(tt.setup(newTestBuilder(&buf).WithTimestampFunc(fixedClock(ts))).Build())
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortedFieldsCallerAfterLevel(t *testing.T) {
+	var buf bytes.Buffer
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	logFieldsEvent(newTestBuilder(&buf).WithSortedFields().WithCaller().WithTimestampFunc(fixedClock(ts)).Build())
+
+	got := buf.String()
+	if !strings.HasPrefix(got, "09:26:53.000 [INFO] fields_test.go:") || !strings.Contains(got, ` > done action="login"`) {
+		t.Errorf("output = %q, want the caller between the level and the message", got)
+	}
+}
+
+func TestSortedFieldsNotInJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logFieldsEvent(newTestBuilder(&buf).WithJSONOutput().WithSortedFields().WithFieldOrder("user").Build())
+
+	got := buf.String()
+	if strings.Index(got, `"zone"`) > strings.Index(got, `"action"`) {
+		t.Errorf("JSON output %s was reordered", got)
+	}
+}
+
+func TestFieldOrderIndependentOfInsertion(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithFieldOrder("request_id", "user_id", "absent").Build()
+	l.Info().Str("path", "/").Str("user_id", "u1").Str("request_id", "r1").Msg("a")
+	l.Info().Str("request_id", "r2").Str("path", "/").Str("user_id", "u2").Msg("b")
+
+	for i, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		rest := line[strings.Index(line, "[INFO]"):]
+		want := fmt.Sprintf(`request_id="r%d" user_id="u%d" path="/"`, i+1, i+1)
+		if !strings.HasSuffix(rest, want) || strings.Contains(rest, "absent") {
+			t.Errorf("line %q does not end with %s", line, want)
+		}
+	}
+}
+
+func TestConsoleFieldFilter(t *testing.T) {
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	tests := []struct {
+		name  string
+		setup func(*LogBuilder) *LogBuilder
+		want  string
+	}{
+		{
+			name:  "exclude",
+			setup: func(b *LogBuilder) *LogBuilder { return b.WithConsoleFieldsExclude("zone", "id") },
+			want:  `09:26:53.000 [INFO] done error=boom action="login" user="ada"` + "\n",
+		},
+		{
+			name:  "exclude error",
+			setup: func(b *LogBuilder) *LogBuilder { return b.WithConsoleFieldsExclude("error", "zone") },
+			want:  `09:26:53.000 [INFO] done action="login" id=7 user="ada"` + "\n",
+		},
+		{
+			name:  "only",
+			setup: func(b *LogBuilder) *LogBuilder { return b.WithConsoleFieldsOnly("user") },
+			want:  `09:26:53.000 [INFO] done error=boom user="ada"` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var console, jsonBuf bytes.Buffer
+			cw := tt.setup(newTestBuilder(nil)).consoleWriter(&console)
+			cw.NoColor = true
+			l := zerolog.New(zerolog.MultiLevelWriter(&jsonBuf, cw)).With().Timestamp().Logger()
+			zerolog.TimestampFunc = fixedClock(ts)
+			t.Cleanup(func() { zerolog.TimestampFunc = time.Now })
+			logFieldsEvent(&l)
+
+			if got := console.String(); got != tt.want {
+				t.Errorf("console output = %q, want %q", got, tt.want)
+			}
+			for _, key := range []string{"zone", "id", "user", "action", "error"} {
+				if !strings.Contains(jsonBuf.String(), `"`+key+`":`) {
+					t.Errorf("JSON output %s lacks %q", jsonBuf.String(), key)
+				}
+			}
+		})
+	}
+}
+
+func TestConsoleFieldsOnlyKeepsCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithCaller().WithConsoleFieldsOnly("user").Build()
+	logFieldsEvent(l)
+
+	if got := buf.String(); !strings.Contains(got, "fields_test.go:") || strings.Contains(got, "zone") {
+		t.Errorf("output = %q, want the caller and no zone field", got)
+	}
+}
@@ -0,0 +1,93 @@
+package ezlog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/mattn/go-runewidth"
+	"github.com/rs/zerolog"
+)
+
+// defaultTagWidth is the tag column width used by WithAlignedColumns.
+const defaultTagWidth = 10
+
+// ansiEscape matches ANSI CSI escape sequences such as color codes.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]")
+
+// WithAlignedColumns pads the level and tag so that timestamps, levels, tags
+// and messages form stable columns across lines and loggers.
+func (b *LogBuilder) WithAlignedColumns() *LogBuilder {
+	b.aligned = true
+	return b
+}
+
+// WithTagWidth sets the width of the tag column used by WithAlignedColumns.
+// Longer tags are truncated.
+func (b *LogBuilder) WithTagWidth(width int) *LogBuilder {
+	b.tagWidth = width
+	return b
+}
+
+// tagLabel returns the tag, truncated to the tag column width when aligned.
+func (b *LogBuilder) tagLabel() string {
+	if !b.aligned || b.tagWidth <= 0 || runewidth.StringWidth(b.tag) <= b.tagWidth {
+		return b.tag
+	}
+	return runewidth.Truncate(b.tag, b.tagWidth, "…")
+}
+
+// visibleWidth returns the number of terminal cells s occupies, ignoring ANSI escapes.
+func visibleWidth(s string) int {
+	return runewidth.StringWidth(ansiEscape.ReplaceAllString(s, ""))
+}
+
+// padRight pads s with spaces up to width visible cells.
+func padRight(s string, width int) string {
+	if pad := width - visibleWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// addFormatPrepare chains fn after the writer's current FormatPrepare.
+func addFormatPrepare(w *zerolog.ConsoleWriter, fn func(map[string]any) error) {
+	prev := w.FormatPrepare
+	if prev == nil {
+		w.FormatPrepare = fn
+		return
+	}
+	w.FormatPrepare = func(evt map[string]any) error {
+		if err := prev(evt); err != nil {
+			return err
+		}
+		return fn(evt)
+	}
+}
+
+// addFormatExtra chains fn after the writer's current FormatExtra.
+func addFormatExtra(w *zerolog.ConsoleWriter, fn func(map[string]any, *bytes.Buffer) error) {
+	prev := w.FormatExtra
+	if prev == nil {
+		w.FormatExtra = fn
+		return
+	}
+	w.FormatExtra = func(evt map[string]any, buf *bytes.Buffer) error {
+		if err := prev(evt, buf); err != nil {
+			return err
+		}
+		return fn(evt, buf)
+	}
+}
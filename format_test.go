@@ -0,0 +1,62 @@
+package ezlog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func TestWithAlignedColumns(t *testing.T) {
+	var buf bytes.Buffer
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	for _, tag := range []string{"db", "scheduler", "notifications"} {
+		l := newTestBuilder(&buf).WithTag(tag).WithAlignedColumns().WithTimestampFunc(fixedClock(ts)).Build()
+		l.Debug().Msg("a")
+		l.Info().Msg("b")
+		l.Warn().Msg("c")
+		l.Error().Msg("d")
+	}
+
+	want := "09:26:53.000 [DEBUG] [db]         a\n" +
+		"09:26:53.000 [INFO]  [db]         b\n" +
+		"09:26:53.000 [WARN]  [db]         c\n" +
+		"09:26:53.000 [ERROR] [db]         d\n" +
+		"09:26:53.000 [DEBUG] [scheduler]  a\n" +
+		"09:26:53.000 [INFO]  [scheduler]  b\n" +
+		"09:26:53.000 [WARN]  [scheduler]  c\n" +
+		"09:26:53.000 [ERROR] [scheduler]  d\n" +
+		"09:26:53.000 [DEBUG] [notificat…] a\n" +
+		"09:26:53.000 [INFO]  [notificat…] b\n" +
+		"09:26:53.000 [WARN]  [notificat…] c\n" +
+		"09:26:53.000 [ERROR] [notificat…] d\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWithTagWidth(t *testing.T) {
+	var buf bytes.Buffer
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	l := newTestBuilder(&buf).WithTag("scheduler").WithAlignedColumns().WithTagWidth(4).WithTimestampFunc(fixedClock(ts)).Build()
+	l.Info().Msg("tick")
+
+	if got, want := buf.String(), "09:26:53.000 [INFO]  [sch…] tick\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestPadRightIgnoresEscapes(t *testing.T) {
+	c := color.New(color.FgRed)
+	c.EnableColor()
+	s := c.Sprint("[WARN]")
+
+	got := padRight(s, 8)
+	if want := s + "  "; got != want {
+		t.Errorf("padRight(%q, 8) = %q, want %q", s, got, want)
+	}
+	if visibleWidth(got) != 8 {
+		t.Errorf("visibleWidth(%q) = %d, want 8", got, visibleWidth(got))
+	}
+}
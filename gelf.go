@@ -0,0 +1,241 @@
+package ezlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// gelfDefaultChunkSize is the default maximum UDP datagram size.
+	gelfDefaultChunkSize = 1420
+	// gelfChunkHeaderSize is the size of the GELF chunk header.
+	gelfChunkHeaderSize = 12
+	// gelfMaxChunks is the maximum number of chunks of a GELF message.
+	gelfMaxChunks = 128
+)
+
+// GELFOption configures a GELFWriter.
+type GELFOption func(*GELFWriter)
+
+// WithGELFTCP sends messages over TCP, separated by null bytes, instead of UDP.
+func WithGELFTCP() GELFOption {
+	return func(w *GELFWriter) {
+		w.network = "tcp"
+	}
+}
+
+// WithGELFCompression gzips UDP messages.
+func WithGELFCompression() GELFOption {
+	return func(w *GELFWriter) {
+		w.compress = true
+	}
+}
+
+// WithGELFHost overrides the host reported in messages, the hostname by default.
+func WithGELFHost(host string) GELFOption {
+	return func(w *GELFWriter) {
+		w.host = host
+	}
+}
+
+// WithGELFChunkSize sets the maximum UDP datagram size, chunk header included.
+func WithGELFChunkSize(size int) GELFOption {
+	return func(w *GELFWriter) {
+		w.chunkSize = size
+	}
+}
+
+// GELFWriter sends zerolog JSON events to Graylog in the GELF format.
+// It should be created using NewGELFWriter.
+type GELFWriter struct {
+	mu        sync.Mutex
+	conn      net.Conn
+	network   string
+	host      string
+	compress  bool
+	chunkSize int
+}
+
+// NewGELFWriter connects to the GELF input at addr, over UDP by default.
+func NewGELFWriter(addr string, opts ...GELFOption) (*GELFWriter, error) {
+	w := &GELFWriter{
+		network:   "udp",
+		chunkSize: gelfDefaultChunkSize,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.host == "" {
+		w.host, _ = os.Hostname()
+	}
+	if w.chunkSize <= gelfChunkHeaderSize {
+		return nil, fmt.Errorf("ezlog: gelf chunk size %d is too small", w.chunkSize)
+	}
+
+	conn, err := net.Dial(w.network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("ezlog: dial gelf %s: %w", addr, err)
+	}
+	w.conn = conn
+	return w, nil
+}
+
+// Write implements io.Writer by converting the JSON event p to a GELF message.
+func (w *GELFWriter) Write(p []byte) (int, error) {
+	msg, err := w.encode(p)
+	if err != nil {
+		return 0, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.network == "tcp" {
+		_, err = w.conn.Write(append(msg, 0))
+	} else {
+		err = w.writeUDP(msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection.
+func (w *GELFWriter) Close() error {
+	return w.conn.Close()
+}
+
+// encode converts the JSON event p into a GELF 1.1 message.
+func (w *GELFWriter) encode(p []byte) ([]byte, error) {
+	var evt map[string]any
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return nil, fmt.Errorf("cannot decode event: %w", err)
+	}
+
+	// The event time, or now for events without one.
+	ts, ok := parseEventTime(evt[zerolog.TimestampFieldName])
+	if !ok {
+		ts = time.Now()
+	}
+	msg := map[string]any{
+		"version":   "1.1",
+		"host":      w.host,
+		"timestamp": float64(ts.UnixMilli()) / 1000,
+		"level":     6,
+	}
+
+	if lvl, ok := evt[zerolog.LevelFieldName].(string); ok {
+		if level, err := ParseLevel(lvl); err == nil {
+			msg["level"] = gelfLevel(level)
+		}
+	}
+	shortMessage, _ := evt[zerolog.MessageFieldName].(string)
+	if shortMessage == "" {
+		shortMessage = "-"
+	}
+	msg["short_message"] = shortMessage
+
+	for key, value := range evt {
+		switch key {
+		case zerolog.LevelFieldName, zerolog.MessageFieldName, zerolog.TimestampFieldName:
+			continue
+		}
+		if key == "id" {
+			key = "id_"
+		}
+		switch value.(type) {
+		case string, json.Number:
+			msg["_"+key] = value
+		default:
+			b, err := json.Marshal(value)
+			if err != nil {
+				continue
+			}
+			msg["_"+key] = string(b)
+		}
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	if !w.compress || w.network == "tcp" {
+		return b, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeUDP sends msg in one datagram, or in chunks when it is too large.
+func (w *GELFWriter) writeUDP(msg []byte) error {
+	if len(msg) <= w.chunkSize {
+		_, err := w.conn.Write(msg)
+		return err
+	}
+
+	payload := w.chunkSize - gelfChunkHeaderSize
+	count := int(math.Ceil(float64(len(msg)) / float64(payload)))
+	if count > gelfMaxChunks {
+		return errors.New("ezlog: gelf message too large")
+	}
+
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, 0, w.chunkSize)
+	for i := 0; i < count; i++ {
+		end := min((i+1)*payload, len(msg))
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*payload:end]...)
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gelfLevel maps a zerolog level to a syslog severity.
+func gelfLevel(level zerolog.Level) int {
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return 7
+	case zerolog.InfoLevel:
+		return 6
+	case zerolog.WarnLevel:
+		return 4
+	case zerolog.ErrorLevel:
+		return 3
+	case zerolog.FatalLevel:
+		return 2
+	case zerolog.PanicLevel:
+		return 1
+	default:
+		return 6
+	}
+}
@@ -0,0 +1,200 @@
+package ezlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestGELFEncode(t *testing.T) {
+	w := &GELFWriter{host: "web-1"}
+	p := []byte(`{"level":"error","time":"2025-03-14T09:26:53.25Z","message":"boom","id":7,"user":{"name":"ann"}}`)
+	b, err := w.encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg map[string]any
+	if err := json.Unmarshal(b, &msg); err != nil {
+		t.Fatalf("invalid GELF message %q: %v", b, err)
+	}
+	want := map[string]any{
+		"version":       "1.1",
+		"host":          "web-1",
+		"timestamp":     float64(time.Date(2025, 3, 14, 9, 26, 53, 250_000_000, time.UTC).UnixMilli()) / 1000,
+		"level":         float64(3),
+		"short_message": "boom",
+		"_id_":          float64(7),
+		"_user":         `{"name":"ann"}`,
+	}
+	for key, value := range want {
+		if msg[key] != value {
+			t.Errorf("%s = %v, want %v", key, msg[key], value)
+		}
+	}
+}
+
+func TestGELFWriterUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on UDP: %v", err)
+	}
+	defer conn.Close()
+
+	w, err := NewGELFWriter(conn.LocalAddr().String(), WithGELFHost("web-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if _, err := w.Write([]byte(`{"level":"info","time":"2025-03-14T09:26:53Z","message":"hello"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 8192)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg map[string]any
+	if err := json.Unmarshal(buf[:n], &msg); err != nil {
+		t.Fatalf("invalid GELF message %q: %v", buf[:n], err)
+	}
+	if msg["short_message"] != "hello" || msg["timestamp"] != float64(1741944413) {
+		t.Errorf("message = %v", msg)
+	}
+}
+
+// readGELFChunks reads chunked datagrams from conn until the message is
+// complete and returns it reassembled.
+func readGELFChunks(t *testing.T, conn net.PacketConn) []byte {
+	t.Helper()
+	var id []byte
+	var parts [][]byte
+	buf := make([]byte, 65536)
+	for received := 0; parts == nil || received < len(parts); received++ {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunk := buf[:n]
+		if n < gelfChunkHeaderSize || chunk[0] != 0x1e || chunk[1] != 0x0f {
+			t.Fatalf("datagram %q is not a GELF chunk", chunk)
+		}
+		if id == nil {
+			id = append([]byte(nil), chunk[2:10]...)
+			parts = make([][]byte, chunk[11])
+		}
+		if !bytes.Equal(chunk[2:10], id) {
+			t.Fatalf("chunk id %x, want %x", chunk[2:10], id)
+		}
+		parts[chunk[10]] = append([]byte(nil), chunk[gelfChunkHeaderSize:]...)
+	}
+	return bytes.Join(parts, nil)
+}
+
+func TestGELFWriterUDPChunks(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on UDP: %v", err)
+	}
+	defer conn.Close()
+
+	w, err := NewGELFWriter(conn.LocalAddr().String(), WithGELFChunkSize(512), WithGELFCompression())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// Random data does not compress, so the message needs several chunks.
+	data := make([]byte, 3000)
+	rand.Read(data)
+	stack := hex.EncodeToString(data)
+	if _, err := w.Write([]byte(`{"level":"error","message":"crash","stack":"` + stack + `"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(readGELFChunks(t, conn)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg map[string]any
+	if err := json.NewDecoder(zr).Decode(&msg); err != nil {
+		t.Fatalf("invalid reassembled message: %v", err)
+	}
+	if msg["short_message"] != "crash" || msg["_stack"] != stack || msg["level"] != float64(3) {
+		t.Errorf("reassembled message = %.100v", msg)
+	}
+}
+
+func TestGELFWriterTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on TCP: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		b, _ := io.ReadAll(c)
+		received <- b
+	}()
+
+	w, err := NewGELFWriter(ln.Addr().String(), WithGELFTCP(), WithGELFCompression())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(`{"level":"warn","message":"first"}`))
+	w.Write([]byte(`{"level":"debug","message":"second"}`))
+	w.Close()
+
+	frames := bytes.Split(bytes.TrimSuffix(<-received, []byte{0}), []byte{0})
+	if len(frames) != 2 {
+		t.Fatalf("got %d null-terminated frames, want 2", len(frames))
+	}
+	for i, want := range []struct {
+		msg   string
+		level float64
+	}{{"first", 4}, {"second", 7}} {
+		// TCP messages are never compressed.
+		var msg map[string]any
+		if err := json.Unmarshal(frames[i], &msg); err != nil {
+			t.Fatalf("frame %q: %v", frames[i], err)
+		}
+		if msg["short_message"] != want.msg || msg["level"] != want.level {
+			t.Errorf("frame %d = %v", i, msg)
+		}
+	}
+}
+
+func TestGELFLevel(t *testing.T) {
+	want := map[zerolog.Level]int{
+		zerolog.TraceLevel: 7,
+		zerolog.DebugLevel: 7,
+		zerolog.InfoLevel:  6,
+		zerolog.WarnLevel:  4,
+		zerolog.ErrorLevel: 3,
+		zerolog.FatalLevel: 2,
+		zerolog.PanicLevel: 1,
+		zerolog.NoLevel:    6,
+	}
+	for level, severity := range want {
+		if got := gelfLevel(level); got != severity {
+			t.Errorf("gelfLevel(%v) = %d, want %d", level, got, severity)
+		}
+	}
+}
@@ -2,12 +2,19 @@ package ezlog
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/ezydark/ezlog/log"
 	"github.com/fatih/color"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -15,25 +22,72 @@ import (
 // GormLogger is a custom logger for Gorm that uses zerolog.
 // It should be created using the GormLoggerBuilder.
 type GormLogger struct {
-	logLevel              logger.LogLevel
-	slowThreshold         time.Duration
-	sourceField           string
-	skipErrRecordNotFound bool
-	tag                   string
+	logLevel        logger.LogLevel
+	slowThreshold   time.Duration
+	criticalSlow    time.Duration
+	sourceField     string
+	callerDepth     int
+	ignoredErrors   []error
+	tag             string
+	zl              *zerolog.Logger
+	modelCheck      func(ctx context.Context, model any) zerolog.LogArrayMarshaler
+	poolStats       *sql.DB
+	colorLevel      ColorLevel
+	explainDB       *gorm.DB
+	explainMaxBytes int
+	explainSem      chan struct{}
+	colors          *gormColors
+	connName        string
+	connField       string
+	connLogger      *atomic.Pointer[gormConnLogger]
+	redactedColumns map[string]struct{}
+	queryAtField    string
+	queryAtLoc      *time.Location
+	now             func() time.Time
+	recorder        QueryRecorder
+	metrics         MetricsExporter
+}
+
+// GormQuery is a query traced by a GormLogger, as given to a QueryRecorder.
+type GormQuery struct {
+	// SQL is the query, with the WithRedactedColumns values redacted.
+	SQL string
+	// Rows is the number of rows affected or returned, -1 if unknown.
+	Rows int64
+	// Elapsed is the duration of the query.
+	Elapsed time.Duration
+	// Err is the error of the query, nil if it succeeded or the error is
+	// ignored.
+	Err error
+	// Slow tells whether the query exceeded the slow threshold.
+	Slow bool
+	// CriticalSlow tells whether the query exceeded the critical slow
+	// threshold.
+	CriticalSlow bool
+}
+
+// QueryRecorder records the queries of a GormLogger besides its log lines,
+// e.g. as trace span events; see the otel subpackage.
+type QueryRecorder interface {
+	// RecordQuery records q, run with ctx.
+	RecordQuery(ctx context.Context, q GormQuery)
 }
 
 // GormLoggerBuilder is a builder for the GormLogger.
 type GormLoggerBuilder struct {
 	logger GormLogger
+	writer io.Writer
 }
 
 // NewGormLogger creates a new GormLoggerBuilder with default values.
 func NewGormLogger() *GormLoggerBuilder {
 	return &GormLoggerBuilder{
 		logger: GormLogger{
-			logLevel:              logger.Info, // Default log level
-			slowThreshold:         200 * time.Millisecond,
-			skipErrRecordNotFound: true,
+			logLevel:      logger.Info, // Default log level
+			slowThreshold: 200 * time.Millisecond,
+			ignoredErrors: []error{gorm.ErrRecordNotFound},
+			colorLevel:    ColorLevelBasic,
+			connField:     "db_connection",
 		},
 	}
 }
@@ -57,20 +111,149 @@ func (b *GormLoggerBuilder) WithSlowThreshold(threshold time.Duration) *GormLogg
 	return b
 }
 
-// WithSourceField sets the source field for logging.
+// WithCriticalSlowThreshold logs queries slower than threshold at error
+// level as critical slow queries, to tell them apart from merely slow ones
+// logged at warn level. threshold must not be below the slow threshold; use
+// BuildE to have this checked.
+func (b *GormLoggerBuilder) WithCriticalSlowThreshold(threshold time.Duration) *GormLoggerBuilder {
+	b.logger.criticalSlow = threshold
+	return b
+}
+
+// WithTimestampField adds the start time of queries to their events under
+// fieldName, "query_at" if empty, in RFC 3339 with microseconds, to
+// correlate them with the slow query logs of the database.
+func (b *GormLoggerBuilder) WithTimestampField(fieldName string) *GormLoggerBuilder {
+	if fieldName == "" {
+		fieldName = "query_at"
+	}
+	b.logger.queryAtField = fieldName
+	return b
+}
+
+// WithTimezone renders the start time of WithTimestampField in loc instead of
+// local time, e.g. the time zone of the database server. It enables the
+// field under its default name if needed.
+func (b *GormLoggerBuilder) WithTimezone(loc *time.Location) *GormLoggerBuilder {
+	b.logger.queryAtLoc = loc
+	if b.logger.queryAtField == "" {
+		b.logger.queryAtField = "query_at"
+	}
+	return b
+}
+
+// WithClock computes the elapsed time of queries with now instead of
+// time.Now, from the begin time given by gorm. With WithWriter, the event
+// timestamps use it too. Tests can share a fake clock with
+// LogBuilder.WithClock to get deterministic output.
+func (b *GormLoggerBuilder) WithClock(now func() time.Time) *GormLoggerBuilder {
+	b.logger.now = now
+	return b
+}
+
+// WithSourceField adds the function running the query, the first one
+// outside of gorm and ezlog, to the events as field, formatted as
+// "package.Function:line".
 func (b *GormLoggerBuilder) WithSourceField(field string) *GormLoggerBuilder {
 	b.logger.sourceField = field
 	return b
 }
 
+// WithCallerDepth skips n more frames when looking for the WithSourceField
+// function, for projects calling gorm through their own wrappers.
+func (b *GormLoggerBuilder) WithCallerDepth(n int) *GormLoggerBuilder {
+	b.logger.callerDepth = n
+	return b
+}
+
 // WithSkipErrRecordNotFound sets whether to skip gorm.ErrRecordNotFound errors.
 func (b *GormLoggerBuilder) WithSkipErrRecordNotFound(skip bool) *GormLoggerBuilder {
-	b.logger.skipErrRecordNotFound = skip
+	ignored := b.logger.ignoredErrors[:0:0]
+	for _, err := range b.logger.ignoredErrors {
+		if err != gorm.ErrRecordNotFound {
+			ignored = append(ignored, err)
+		}
+	}
+	if skip {
+		ignored = append(ignored, gorm.ErrRecordNotFound)
+	}
+	b.logger.ignoredErrors = ignored
+	return b
+}
+
+// WithIgnoredErrors skips errors matching any of errs, as reported by
+// errors.Is, such as gorm.ErrDuplicatedKey in upsert-heavy code.
+// gorm.ErrRecordNotFound is ignored by default.
+func (b *GormLoggerBuilder) WithIgnoredErrors(errs ...error) *GormLoggerBuilder {
+	b.logger.ignoredErrors = append(b.logger.ignoredErrors, errs...)
 	return b
 }
 
+// WithConnectionName adds name under the "db_connection" field to every event,
+// to tell apart the connections of applications using several databases,
+// such as a primary and a replica.
+func (b *GormLoggerBuilder) WithConnectionName(name string) *GormLoggerBuilder {
+	b.logger.connName = name
+	return b
+}
+
+// WithConnectionNameField sets the field used by WithConnectionName.
+func (b *GormLoggerBuilder) WithConnectionNameField(fieldName string) *GormLoggerBuilder {
+	b.logger.connField = fieldName
+	return b
+}
+
+// WithConnectionPoolStats adds the connection pool statistics of db to slow
+// query events, to tell database slowness from pool starvation.
+func (b *GormLoggerBuilder) WithConnectionPoolStats(db *sql.DB) *GormLoggerBuilder {
+	b.logger.poolStats = db
+	return b
+}
+
+// WithQueryRecorder passes every query to r as well, whatever the log level.
+func (b *GormLoggerBuilder) WithQueryRecorder(r QueryRecorder) *GormLoggerBuilder {
+	b.logger.recorder = r
+	return b
+}
+
+// WithMetrics counts the queries in e, as MetricGormQueries, and records
+// their duration, as MetricGormQueryDuration, whatever the log level.
+func (b *GormLoggerBuilder) WithMetrics(e MetricsExporter) *GormLoggerBuilder {
+	b.logger.metrics = e
+	return b
+}
+
+// WithWriter makes the logger write to w through its own local logger instead
+// of the global one. If w is an io.Closer it is registered with Shutdown.
+func (b *GormLoggerBuilder) WithWriter(w io.Writer) *GormLoggerBuilder {
+	b.writer = w
+	return b
+}
+
+// BuildE is like Build but first checks the configuration, returning an
+// error if the critical slow threshold is below the slow threshold.
+func (b *GormLoggerBuilder) BuildE() (*GormLogger, error) {
+	if b.logger.criticalSlow > 0 && b.logger.criticalSlow < b.logger.slowThreshold {
+		return nil, fmt.Errorf("ezlog: critical slow threshold %s is below slow threshold %s",
+			b.logger.criticalSlow, b.logger.slowThreshold)
+	}
+	return b.Build(), nil
+}
+
 // Build creates and returns a configured GormLogger.
 func (b *GormLoggerBuilder) Build() *GormLogger {
+	b.logger.colors = newGormColors(b.logger.colorLevel)
+	if b.writer != nil {
+		lb := New().AsLocal().WithWriter(b.writer)
+		if b.logger.now != nil {
+			lb = lb.WithClock(b.logger.now)
+		}
+		b.logger.zl = lb.Build()
+		registerWriter("gorm", b.writer)
+	}
+	if b.logger.connName != "" {
+		b.logger.connLogger = new(atomic.Pointer[gormConnLogger])
+	}
 	return &b.logger
 }
 
@@ -84,53 +267,221 @@ func (l *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
 // Info logs an info message.
 func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
 	if l.logLevel >= logger.Info {
-		log.Info().Msgf(l.formatMsg(msg), data...)
+		l.withSource(l.log().Info()).Msgf(l.formatMsg(msg), data...)
 	}
 }
 
 // Warn logs a warning message.
 func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
 	if l.logLevel >= logger.Warn {
-		log.Warn().Msgf(l.formatMsg(msg), data...)
+		l.withSource(l.log().Warn()).Msgf(l.formatMsg(msg), data...)
 	}
 }
 
 // Error logs an error message.
 func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
 	if l.logLevel >= logger.Error {
-		log.Error().Msgf(l.formatMsg(msg), data...)
+		l.withSource(l.log().Error()).Msgf(l.formatMsg(msg), data...)
 	}
 }
 
 // Trace logs a trace message (SQL query).
 func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
-	if l.logLevel <= logger.Silent {
+	if l.logLevel <= logger.Silent && l.recorder == nil && l.metrics == nil {
 		return
 	}
 
-	elapsed := time.Since(begin)
+	now := time.Now
+	if l.now != nil {
+		now = l.now
+	}
+	elapsed := now().Sub(begin)
 	sql, rows := fc()
+	redacted := l.redactColumns(sql)
+	explainable := redacted == sql
+	sql = redacted
+
+	if l.recorder != nil || l.metrics != nil {
+		q := GormQuery{
+			SQL:          sql,
+			Rows:         rows,
+			Elapsed:      elapsed,
+			Slow:         l.slowThreshold > 0 && elapsed > l.slowThreshold,
+			CriticalSlow: l.criticalSlow > 0 && elapsed > l.criticalSlow,
+		}
+		if err != nil && !l.isIgnored(err) {
+			q.Err = err
+		}
+		if l.recorder != nil {
+			l.recorder.RecordQuery(ctx, q)
+		}
+		if l.metrics != nil {
+			l.recordMetrics(q)
+		}
+		if l.logLevel <= logger.Silent {
+			return
+		}
+	}
 
+	colors := l.colors
+	if colors == nil {
+		colors = newGormColors(l.colorLevel)
+	}
 	sqlLog := fmt.Sprintf("elapsed=%s rows=%s sql=%s",
-		color.New(color.FgYellow).Sprint(elapsed),
-		color.New(color.FgCyan).Sprint(rows),
-		color.New(color.FgGreen).Sprintf("%q", sql),
+		colors.elapsed.Sprint(elapsed),
+		colors.rows.Sprint(rows),
+		colors.sql.Sprintf("%q", sql),
 	)
 
 	switch {
-	case err != nil && (!l.skipErrRecordNotFound || !errors.Is(err, gorm.ErrRecordNotFound)) && l.logLevel >= logger.Error:
-		log.Error().Err(err).Msg(l.formatMsg("gorm error " + sqlLog))
+	case err != nil && !l.isIgnored(err) && l.logLevel >= logger.Error:
+		l.withSource(l.withQueryAt(l.log().Error(), begin)).Err(err).Msg(l.formatMsg("gorm error " + sqlLog))
+	case l.criticalSlow > 0 && elapsed > l.criticalSlow && l.logLevel >= logger.Error:
+		e := l.withSource(l.withQueryAt(l.log().Error(), begin))
+		l.withPoolStats(e).Msg(l.formatMsg("gorm critical slow query " + sqlLog))
+		if explainable {
+			l.explainAsync(ctx, sql)
+		}
 	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.logLevel >= logger.Warn:
-		log.Warn().Msg(l.formatMsg("gorm slow query " + sqlLog))
+		e := l.withSource(l.withQueryAt(l.log().Warn(), begin))
+		l.withPoolStats(e).Msg(l.formatMsg("gorm slow query " + sqlLog))
+		if explainable {
+			l.explainAsync(ctx, sql)
+		}
 	case l.logLevel >= logger.Info:
-		log.Debug().Msg(l.formatMsg("gorm query " + sqlLog))
+		l.withSource(l.withQueryAt(l.log().Debug(), begin)).Msg(l.formatMsg("gorm query " + sqlLog))
+	}
+}
+
+// recordMetrics records q in the metrics exporter.
+func (l *GormLogger) recordMetrics(q GormQuery) {
+	status := "ok"
+	if q.Err != nil {
+		status = "error"
+	}
+	l.metrics.IncrCounter(MetricGormQueries, map[string]string{
+		"status": status,
+		"slow":   strconv.FormatBool(q.Slow),
+	})
+	l.metrics.RecordHistogram(MetricGormQueryDuration, q.Elapsed.Seconds(), map[string]string{
+		"status": status,
+	})
+}
+
+// queryAtLayout is the layout of the WithTimestampField field.
+const queryAtLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+// withQueryAt adds the query start time begin to e, if enabled.
+func (l *GormLogger) withQueryAt(e *zerolog.Event, begin time.Time) *zerolog.Event {
+	if l.queryAtField == "" {
+		return e
+	}
+	if l.queryAtLoc != nil {
+		begin = begin.In(l.queryAtLoc)
+	}
+	return e.Str(l.queryAtField, begin.Format(queryAtLayout))
+}
+
+// withSource adds the function running the query to e, if enabled.
+func (l *GormLogger) withSource(e *zerolog.Event) *zerolog.Event {
+	if l.sourceField == "" || e == nil {
+		return e
 	}
+	var pcs [64]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs[:])])
+	skip := l.callerDepth
+	for {
+		f, more := frames.Next()
+		if p := funcPackage(f.Function); p != ezlogPkgPath && !strings.HasPrefix(p, "gorm.io/") {
+			if skip <= 0 {
+				fn := f.Function[strings.LastIndexByte(f.Function, '/')+1:]
+				return e.Str(l.sourceField, fn+":"+strconv.Itoa(f.Line))
+			}
+			skip--
+		}
+		if !more {
+			return e
+		}
+	}
+}
+
+// withPoolStats adds the connection pool statistics to e, if enabled.
+func (l *GormLogger) withPoolStats(e *zerolog.Event) *zerolog.Event {
+	if l.poolStats == nil {
+		return e
+	}
+	stats := l.poolStats.Stats()
+	return e.Int("pool_open", stats.OpenConnections).
+		Int("pool_idle", stats.Idle).
+		Int("pool_in_use", stats.InUse).
+		Int64("pool_wait_count", stats.WaitCount)
+}
+
+// log returns the logger events are written to.
+func (l *GormLogger) log() *zerolog.Logger {
+	if l.connLogger == nil {
+		if l.zl == nil {
+			return &log.Logger
+		}
+		return l.zl
+	}
+
+	// The child logger is built once per base logger: the builder's one, or
+	// the global logger until it is replaced by a new global Build.
+	base := l.zl
+	if base == nil {
+		base = globalLogger
+	}
+	if c := l.connLogger.Load(); c != nil && c.base == base {
+		return c.logger
+	}
+	zl := l.zl
+	if zl == nil {
+		zl = &log.Logger
+	}
+	withConn := zl.With().Str(l.connField, l.connName).Logger()
+	l.connLogger.Store(&gormConnLogger{base: base, logger: &withConn})
+	return &withConn
+}
+
+// gormConnLogger is the child logger of a GormLogger adding the connection
+// name, and the logger it was built from.
+type gormConnLogger struct {
+	base   *zerolog.Logger
+	logger *zerolog.Logger
 }
 
 // formatMsg adds the tag to the message if it exists.
 func (l *GormLogger) formatMsg(msg string) string {
 	if l.tag != "" {
-		return fmt.Sprintf("%s %s", color.New(color.FgMagenta).Sprintf("[%s]", l.tag), msg)
+		return fmt.Sprintf("%s %s", magentaColor.Sprintf("[%s]", l.tag), msg)
 	}
 	return msg
 }
+
+// isIgnored reports whether err matches one of the ignored errors.
+func (l *GormLogger) isIgnored(err error) bool {
+	for _, ignored := range l.ignoredErrors {
+		if errors.Is(err, ignored) {
+			return true
+		}
+	}
+	return false
+}
+
+// gormColors are the colors of the query parts, created once per logger.
+type gormColors struct {
+	elapsed *color.Color
+	rows    *color.Color
+	sql     *color.Color
+}
+
+// newGormColors returns the query colors at color capability level.
+func newGormColors(level ColorLevel) *gormColors {
+	l := level.resolve()
+	return &gormColors{
+		elapsed: l.rgb(color.FgYellow, 255, 200, 60),
+		rows:    l.rgb(color.FgCyan, 90, 200, 220),
+		sql:     l.rgb(color.FgGreen, 130, 210, 110),
+	}
+}
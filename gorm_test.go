@@ -0,0 +1,234 @@
+package ezlog
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormConnectionName(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewGormLogger().WithWriter(&buf).WithConnectionName("replica").Build()
+	l.Info(context.Background(), "connected to %s", "db2")
+	l.LogMode(logger.Warn).Warn(context.Background(), "lagging")
+
+	for _, line := range strings.Split(strings.TrimSpace(ansiEscape.ReplaceAllString(buf.String(), "")), "\n") {
+		if !strings.Contains(line, `db_connection="replica"`) {
+			t.Errorf("line %q lacks the connection field", line)
+		}
+	}
+	if l.log() != l.log() {
+		t.Error("the connection logger is built for every event")
+	}
+}
+
+func TestGormConnectionNameField(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewGormLogger().WithWriter(&buf).WithConnectionName("tenant-7").WithConnectionNameField("db").Build()
+	l.Error(context.Background(), "failed")
+
+	if got := ansiEscape.ReplaceAllString(buf.String(), ""); !strings.Contains(got, `db="tenant-7"`) || strings.Contains(got, "db_connection") {
+		t.Errorf("output = %q", got)
+	}
+}
+
+// stubConnector opens connections that support nothing but Close.
+type stubConnector struct{}
+
+func (stubConnector) Connect(context.Context) (driver.Conn, error) { return stubConn{}, nil }
+func (stubConnector) Driver() driver.Driver                        { return nil }
+
+type stubConn struct{}
+
+func (stubConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (stubConn) Close() error                        { return nil }
+func (stubConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func TestGormConnectionPoolStats(t *testing.T) {
+	db := sql.OpenDB(stubConnector{})
+	defer db.Close()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var buf bytes.Buffer
+	l := NewGormLogger().WithWriter(&buf).WithConnectionPoolStats(db).Build()
+	sqlFn := func() (string, int64) { return "SELECT 1", 1 }
+	l.Trace(context.Background(), time.Now(), sqlFn, nil)
+	l.Trace(context.Background(), time.Now().Add(-time.Second), sqlFn, nil)
+
+	lines := strings.Split(strings.TrimSpace(ansiEscape.ReplaceAllString(buf.String(), "")), "\n")
+	slow := lines[len(lines)-1]
+	for _, want := range []string{"pool_open=1", "pool_idle=0", "pool_in_use=1", "pool_wait_count=0"} {
+		if !strings.Contains(slow, want) {
+			t.Errorf("slow query line %q lacks %s", slow, want)
+		}
+	}
+	for _, line := range lines[:len(lines)-1] {
+		if strings.Contains(line, "pool_") {
+			t.Errorf("fast query line %q has pool statistics", line)
+		}
+	}
+}
+
+func TestGormIgnoredErrors(t *testing.T) {
+	errConflict := errors.New("conflict")
+	tests := []struct {
+		name   string
+		setup  func(*GormLoggerBuilder) *GormLoggerBuilder
+		err    error
+		logged bool
+	}{
+		{"record not found by default", func(b *GormLoggerBuilder) *GormLoggerBuilder { return b }, gorm.ErrRecordNotFound, false},
+		{"record not found kept", func(b *GormLoggerBuilder) *GormLoggerBuilder { return b.WithSkipErrRecordNotFound(false) },
+			gorm.ErrRecordNotFound, true},
+		{"wrapped duplicated key", func(b *GormLoggerBuilder) *GormLoggerBuilder { return b.WithIgnoredErrors(gorm.ErrDuplicatedKey) },
+			fmt.Errorf("upsert: %w", gorm.ErrDuplicatedKey), false},
+		{"custom sentinel", func(b *GormLoggerBuilder) *GormLoggerBuilder { return b.WithIgnoredErrors(errConflict) }, errConflict, false},
+		{"record not found after other ignored errors", func(b *GormLoggerBuilder) *GormLoggerBuilder {
+			return b.WithIgnoredErrors(errConflict).WithSkipErrRecordNotFound(false).WithSkipErrRecordNotFound(true)
+		}, gorm.ErrRecordNotFound, false},
+		{"other error", func(b *GormLoggerBuilder) *GormLoggerBuilder { return b.WithIgnoredErrors(errConflict) },
+			gorm.ErrInvalidTransaction, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := tt.setup(NewGormLogger().WithWriter(&buf)).Build()
+			l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 0 }, tt.err)
+			if logged := strings.Contains(buf.String(), tt.err.Error()); logged != tt.logged {
+				t.Errorf("logged = %v, want %v: %q", logged, tt.logged, buf.String())
+			}
+		})
+	}
+}
+
+// queryRecorder keeps the queries passed to RecordQuery.
+type queryRecorder struct {
+	queries []GormQuery
+}
+
+func (r *queryRecorder) RecordQuery(_ context.Context, q GormQuery) {
+	r.queries = append(r.queries, q)
+}
+
+func TestGormCriticalSlowThreshold(t *testing.T) {
+	var buf bytes.Buffer
+	rec := &queryRecorder{}
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	l, err := NewGormLogger().WithWriter(&buf).WithClock(func() time.Time { return now }).
+		WithSlowThreshold(100 * time.Millisecond).WithCriticalSlowThreshold(time.Second).
+		WithQueryRecorder(rec).BuildE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlFn := func() (string, int64) { return "SELECT 1", 1 }
+	l.Trace(context.Background(), now.Add(-500*time.Millisecond), sqlFn, nil)
+	l.Trace(context.Background(), now.Add(-2*time.Second), sqlFn, nil)
+
+	lines := strings.Split(strings.TrimSpace(ansiEscape.ReplaceAllString(buf.String(), "")), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("output = %q, want 2 events", lines)
+	}
+	if !strings.Contains(lines[0], "[WARN]") || !strings.Contains(lines[0], "gorm slow query") {
+		t.Errorf("slow query = %q, want a warning", lines[0])
+	}
+	if !strings.Contains(lines[1], "[ERROR]") || !strings.Contains(lines[1], "gorm critical slow query") {
+		t.Errorf("critical slow query = %q, want an error", lines[1])
+	}
+	if q := rec.queries; len(q) != 2 || !q[0].Slow || q[0].CriticalSlow || !q[1].Slow || !q[1].CriticalSlow {
+		t.Errorf("recorded queries = %+v", q)
+	}
+}
+
+func TestGormBuildEValidatesThresholds(t *testing.T) {
+	_, err := NewGormLogger().WithSlowThreshold(time.Second).WithCriticalSlowThreshold(500 * time.Millisecond).BuildE()
+	if err == nil || !strings.Contains(err.Error(), "below slow threshold") {
+		t.Errorf("BuildE with critical < slow: err = %v", err)
+	}
+	for _, critical := range []time.Duration{0, time.Second, 5 * time.Second} {
+		if _, err := NewGormLogger().WithSlowThreshold(time.Second).WithCriticalSlowThreshold(critical).BuildE(); err != nil {
+			t.Errorf("BuildE with critical %s: %v", critical, err)
+		}
+	}
+}
+
+func TestGormQueryTimestampField(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	begin := time.Date(2025, 3, 14, 9, 26, 53, 123456000, time.UTC)
+	tests := []struct {
+		name  string
+		setup func(*GormLoggerBuilder) *GormLoggerBuilder
+		want  string
+	}{
+		{"disabled", func(b *GormLoggerBuilder) *GormLoggerBuilder { return b }, ""},
+		{"default name", func(b *GormLoggerBuilder) *GormLoggerBuilder { return b.WithTimestampField("").WithTimezone(time.UTC) },
+			`query_at="2025-03-14T09:26:53.123456Z"`},
+		{"custom name", func(b *GormLoggerBuilder) *GormLoggerBuilder {
+			return b.WithTimestampField("started").WithTimezone(time.UTC)
+		},
+			`started="2025-03-14T09:26:53.123456Z"`},
+		{"timezone only", func(b *GormLoggerBuilder) *GormLoggerBuilder { return b.WithTimezone(tokyo) },
+			`query_at="2025-03-14T18:26:53.123456+09:00"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := tt.setup(NewGormLogger().WithWriter(&buf)).Build()
+			l.Trace(context.Background(), begin, func() (string, int64) { return "SELECT 1", 0 }, gorm.ErrInvalidTransaction)
+
+			got := ansiEscape.ReplaceAllString(buf.String(), "")
+			if tt.want == "" {
+				if strings.Contains(got, "query_at") {
+					t.Errorf("output = %q, want no start time", got)
+				}
+			} else if !strings.Contains(got, tt.want) {
+				t.Errorf("output = %q, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// steppingClock returns a clock starting at start and advancing by step on
+// every call.
+func steppingClock(start time.Time, step time.Duration) func() time.Time {
+	next := start
+	return func() time.Time {
+		now := next
+		next = next.Add(step)
+		return now
+	}
+}
+
+func TestSharedClock(t *testing.T) {
+	t0 := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithClock(steppingClock(t0, 250*time.Millisecond)).Build()
+	l.Info().Msg("first")
+	l.Info().Msg("second")
+	if got, want := buf.String(), "09:26:53.000 [INFO] first\n09:26:53.250 [INFO] second\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	// The first reading gives the elapsed time, the second the timestamp.
+	buf.Reset()
+	gl := NewGormLogger().WithWriter(&buf).WithClock(steppingClock(t0.Add(250*time.Millisecond), 250*time.Millisecond)).
+		WithSlowThreshold(100 * time.Millisecond).Build()
+	gl.Trace(context.Background(), t0, func() (string, int64) { return "SELECT 1", 1 }, nil)
+	want := `09:26:53.500 [WARN] gorm slow query elapsed=250ms rows=1 sql="SELECT 1"` + "\n"
+	if got := ansiEscape.ReplaceAllString(buf.String(), ""); got != want {
+		t.Errorf("gorm output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package ezlog
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// WithModelCheck runs check on models before they are created or updated and
+// logs a warning with the problems it returns under a "validation" key. check
+// returns nil for a valid model, and the write itself is never aborted. The
+// GormLogger must be registered as a plugin with db.Use. The ezlog/validator
+// package checks models with a validator.Validate.
+func (b *GormLoggerBuilder) WithModelCheck(check func(ctx context.Context, model any) zerolog.LogArrayMarshaler) *GormLoggerBuilder {
+	b.logger.modelCheck = check
+	return b
+}
+
+// Name implements gorm.Plugin.
+func (l *GormLogger) Name() string {
+	return "ezlog"
+}
+
+// Initialize implements gorm.Plugin by registering the model check callbacks.
+func (l *GormLogger) Initialize(db *gorm.DB) error {
+	if l.modelCheck == nil {
+		return nil
+	}
+	if err := db.Callback().Create().Before("gorm:create").Register("ezlog:validate", l.checkModel); err != nil {
+		return err
+	}
+	return db.Callback().Update().Before("gorm:update").Register("ezlog:validate", l.checkModel)
+}
+
+// checkModel logs the problems found in the statement's model, if any.
+func (l *GormLogger) checkModel(db *gorm.DB) {
+	if db.Statement == nil || l.logLevel < logger.Warn {
+		return
+	}
+
+	dest := reflect.Indirect(reflect.ValueOf(db.Statement.Dest))
+	if dest.Kind() != reflect.Struct {
+		return
+	}
+
+	problems := l.modelCheck(db.Statement.Context, dest.Interface())
+	if problems == nil {
+		return
+	}
+
+	l.log().Warn().
+		Array("validation", problems).
+		Str("table", db.Statement.Table).
+		Msg(l.formatMsg("gorm model validation failed"))
+}
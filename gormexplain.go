@@ -0,0 +1,183 @@
+package ezlog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultExplainMaxBytes is the default size limit of logged query plans.
+	defaultExplainMaxBytes = 4096
+	// maxConcurrentExplains bounds the EXPLAIN queries running at once; slow
+	// queries past it are not explained.
+	maxConcurrentExplains = 4
+	// explainTimeout bounds the duration of an EXPLAIN query.
+	explainTimeout = 10 * time.Second
+)
+
+// WithExplain runs EXPLAIN on db for slow SELECT queries and logs the plan
+// in a follow-up "gorm slow query plan" event. The EXPLAIN runs in its own
+// goroutine so the original query path is never blocked. PostgreSQL, MySQL
+// and SQLite are supported; other dialects are left alone.
+//
+// The query is explained with its original placeholders and arguments, which
+// WithExplain registers callbacks on db to capture, so db must be the
+// database the logger logs queries of.
+func (b *GormLoggerBuilder) WithExplain(db *gorm.DB) *GormLoggerBuilder {
+	b.logger.explainDB = db
+	registerExplainCallbacks(db)
+	if b.logger.explainMaxBytes == 0 {
+		b.logger.explainMaxBytes = defaultExplainMaxBytes
+	}
+	b.logger.explainSem = make(chan struct{}, maxConcurrentExplains)
+	return b
+}
+
+// WithExplainMaxBytes truncates logged query plans to n bytes. The default
+// is 4096.
+func (b *GormLoggerBuilder) WithExplainMaxBytes(n int) *GormLoggerBuilder {
+	b.logger.explainMaxBytes = n
+	return b
+}
+
+// explainStatementKey is the context key of the statement being executed.
+type explainStatementKey struct{}
+
+// registerExplainCallbacks makes the statements of db reachable from their
+// context, so Trace can explain them.
+func registerExplainCallbacks(db *gorm.DB) {
+	store := func(tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		tx.Statement.Context = context.WithValue(ctx, explainStatementKey{}, tx.Statement)
+	}
+	cb := db.Callback()
+	_ = cb.Query().Before("*").Register("ezlog:explain", store)
+	_ = cb.Row().Before("*").Register("ezlog:explain", store)
+	_ = cb.Raw().Before("*").Register("ezlog:explain", store)
+}
+
+// explainStatement returns the SQL and the arguments of the statement in
+// ctx, copied so they outlive it.
+func explainStatement(ctx context.Context) (string, []any, bool) {
+	if ctx == nil {
+		return "", nil, false
+	}
+	stmt, ok := ctx.Value(explainStatementKey{}).(*gorm.Statement)
+	if !ok || stmt.SQL.Len() == 0 {
+		return "", nil, false
+	}
+	return stmt.SQL.String(), append([]any(nil), stmt.Vars...), true
+}
+
+// explainAsync logs the plan of the slow query of ctx, logged as display,
+// unless it is not a SELECT or too many EXPLAIN queries are already running.
+func (l *GormLogger) explainAsync(ctx context.Context, display string) {
+	if l.explainDB == nil {
+		return
+	}
+	query, vars, ok := explainStatement(ctx)
+	if !ok || !isSelect(query) {
+		return
+	}
+	prefix := explainPrefix(l.explainDB.Dialector.Name())
+	if prefix == "" {
+		return
+	}
+
+	select {
+	case l.explainSem <- struct{}{}:
+	default:
+		return
+	}
+	go func() {
+		defer func() { <-l.explainSem }()
+
+		plan, err := l.explain(prefix+query, vars)
+		if err != nil {
+			l.log().Warn().Err(err).Msg(l.formatMsg("gorm explain failed"))
+			return
+		}
+		l.log().Warn().Str("sql", display).Str("plan", plan).Msg(l.formatMsg("gorm slow query plan"))
+	}()
+}
+
+// explain runs the EXPLAIN query with its arguments and renders its rows as
+// tab separated lines. The connection pool is queried directly so the
+// dialect's placeholders are passed through untouched.
+func (l *GormLogger) explain(query string, vars []any) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+	defer cancel()
+
+	rows, err := l.explainDB.ConnPool.QueryContext(ctx, query, vars...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	values := make([]sql.NullString, len(cols))
+	dest := make([]any, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var sb strings.Builder
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		for i, v := range values {
+			if i > 0 {
+				sb.WriteByte('\t')
+			}
+			sb.WriteString(v.String)
+		}
+		sb.WriteByte('\n')
+		if sb.Len() > l.explainMaxBytes {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return truncateBytes(strings.TrimRight(sb.String(), "\n"), l.explainMaxBytes), nil
+}
+
+// explainPrefix returns the EXPLAIN statement prefix for a GORM dialect name.
+func explainPrefix(dialect string) string {
+	switch dialect {
+	case "postgres":
+		return "EXPLAIN "
+	case "mysql":
+		return "EXPLAIN FORMAT=JSON "
+	case "sqlite":
+		return "EXPLAIN QUERY PLAN "
+	default:
+		return ""
+	}
+}
+
+// isSelect reports whether query is a SELECT statement.
+func isSelect(query string) bool {
+	query = strings.TrimSpace(query)
+	return len(query) >= 6 && strings.EqualFold(query[:6], "select")
+}
+
+// truncateBytes cuts s to at most n bytes, marking the cut.
+func truncateBytes(s string, n int) string {
+	if n <= 0 || len(s) <= n {
+		return s
+	}
+	return fmt.Sprintf("%s…(%d bytes truncated)", strings.ToValidUTF8(s[:n], ""), len(s)-n)
+}
@@ -0,0 +1,81 @@
+package ezlog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils/tests"
+)
+
+// statementLogger records the statement Trace sees, as explainAsync would.
+type statementLogger struct {
+	logger.Interface
+	query string
+	vars  []any
+	ok    bool
+}
+
+func (l *statementLogger) Trace(ctx context.Context, _ time.Time, _ func() (string, int64), _ error) {
+	l.query, l.vars, l.ok = explainStatement(ctx)
+}
+
+func TestExplainUsesStatementPlaceholders(t *testing.T) {
+	rec := &statementLogger{Interface: logger.Discard}
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true, Logger: rec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerExplainCallbacks(db)
+
+	var users []struct{ Name string }
+	db.Table("users").Where("name = ? AND note = ?", "ann", "it's").Find(&users)
+
+	if !rec.ok {
+		t.Fatal("no statement in the Trace context")
+	}
+	if want := "SELECT * FROM `users` WHERE name = ? AND note = ?"; rec.query != want {
+		t.Errorf("query = %q, want %q", rec.query, want)
+	}
+	if want := []any{"ann", "it's"}; !reflect.DeepEqual(rec.vars, want) {
+		t.Errorf("vars = %v, want %v", rec.vars, want)
+	}
+}
+
+func TestExplainPrefix(t *testing.T) {
+	for dialect, want := range map[string]string{
+		"postgres":  "EXPLAIN ",
+		"mysql":     "EXPLAIN FORMAT=JSON ",
+		"sqlite":    "EXPLAIN QUERY PLAN ",
+		"sqlserver": "",
+	} {
+		if got := explainPrefix(dialect); got != want {
+			t.Errorf("explainPrefix(%q) = %q, want %q", dialect, got, want)
+		}
+	}
+}
+
+func TestIsSelect(t *testing.T) {
+	for query, want := range map[string]bool{
+		"SELECT 1":              true,
+		"  select * from users": true,
+		"UPDATE users SET a=1":  false,
+		"sel":                   false,
+	} {
+		if got := isSelect(query); got != want {
+			t.Errorf("isSelect(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	if got := truncateBytes("abcdef", 4); got != "abcd…(2 bytes truncated)" {
+		t.Errorf("truncateBytes = %q", got)
+	}
+	if got := truncateBytes("abc", 4); got != "abc" {
+		t.Errorf("truncateBytes = %q", got)
+	}
+}
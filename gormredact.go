@@ -0,0 +1,314 @@
+package ezlog
+
+import (
+	"sort"
+	"strings"
+)
+
+// redactedValue replaces the redacted values in logged SQL.
+const redactedValue = "?[REDACTED]"
+
+// WithRedactedColumns replaces the values of the given columns in logged SQL
+// with ?[REDACTED], e.g. for passwords or tokens. Values are recognized in
+// the column and VALUES lists of INSERT statements, in assignments such as
+// `password` = 'x' in UPDATE SET clauses and in comparisons with =, !=, <>,
+// <, <=, >, >=, LIKE, ILIKE, IN (...) and BETWEEN, negated or not.
+// Column names are case insensitive and may be qualified with the table.
+// Slow queries with redacted values are not explained, as query plans may
+// show them.
+func (b *GormLoggerBuilder) WithRedactedColumns(columns ...string) *GormLoggerBuilder {
+	if b.logger.redactedColumns == nil {
+		b.logger.redactedColumns = make(map[string]struct{}, len(columns))
+	}
+	for _, c := range columns {
+		b.logger.redactedColumns[strings.ToLower(c)] = struct{}{}
+	}
+	return b
+}
+
+// sqlTokenKind is the kind of a SQL token.
+type sqlTokenKind int
+
+const (
+	sqlWord sqlTokenKind = iota
+	sqlQuoted
+	sqlString
+	sqlNumber
+	sqlPunct
+)
+
+// sqlToken is a token of a SQL statement, spanning sql[start:end].
+type sqlToken struct {
+	kind       sqlTokenKind
+	start, end int
+}
+
+// sqlSpan is a range of a SQL statement.
+type sqlSpan struct {
+	start, end int
+}
+
+// redactColumns returns query with the values of the redacted columns
+// replaced.
+func (l *GormLogger) redactColumns(query string) string {
+	if len(l.redactedColumns) == 0 {
+		return query
+	}
+	toks := tokenizeSQL(query)
+	redacted := func(i int) bool {
+		_, ok := l.redactedColumns[sqlColumnName(query, toks[i])]
+		return ok
+	}
+
+	var spans []sqlSpan
+	spans = append(spans, redactInsertValues(query, toks, redacted)...)
+	for i := 0; i+2 < len(toks); i++ {
+		t := toks[i]
+		if (t.kind != sqlWord && t.kind != sqlQuoted) || !redacted(i) {
+			continue
+		}
+		spans = append(spans, redactComparison(query, toks, i+1)...)
+	}
+	if len(spans) == 0 {
+		return query
+	}
+	return replaceSpans(query, spans)
+}
+
+// redactComparison returns the spans of the literal values compared to the
+// column before toks[i] by the operator starting at toks[i]: =, !=, <>, <,
+// <=, >, >=, [NOT] LIKE, [NOT] ILIKE, [NOT] IN (...) or [NOT] BETWEEN ... AND
+// ....
+func redactComparison(query string, toks []sqlToken, i int) []sqlSpan {
+	literal := func(j int) bool {
+		return j < len(toks) && isSQLLiteral(query, toks[j]) &&
+			(j+1 >= len(toks) || !isSQLPunct(query, toks[j+1], "."))
+	}
+	if i < len(toks) && isSQLWord(query, toks[i], "NOT") {
+		i++
+	}
+	if i >= len(toks) {
+		return nil
+	}
+
+	switch {
+	case isSQLWord(query, toks[i], "IN"):
+		if i+1 >= len(toks) || !isSQLPunct(query, toks[i+1], "(") {
+			return nil
+		}
+		// Only lists of literals are redacted, not subqueries.
+		j := i + 2
+		for ; literal(j); j += 2 {
+			if j+1 < len(toks) && isSQLPunct(query, toks[j+1], ")") {
+				return []sqlSpan{{toks[i+2].start, toks[j].end}}
+			}
+			if j+1 >= len(toks) || !isSQLPunct(query, toks[j+1], ",") {
+				return nil
+			}
+		}
+		return nil
+	case isSQLWord(query, toks[i], "BETWEEN"):
+		var spans []sqlSpan
+		if literal(i + 1) {
+			spans = append(spans, sqlSpan{toks[i+1].start, toks[i+1].end})
+		}
+		if i+2 < len(toks) && isSQLWord(query, toks[i+2], "AND") && literal(i+3) {
+			spans = append(spans, sqlSpan{toks[i+3].start, toks[i+3].end})
+		}
+		return spans
+	case isSQLWord(query, toks[i], "LIKE") || isSQLWord(query, toks[i], "ILIKE"):
+		i++
+	case isSQLPunct(query, toks[i], "="):
+		i++
+	case isSQLPunct(query, toks[i], "!") || isSQLPunct(query, toks[i], "<") || isSQLPunct(query, toks[i], ">"):
+		i++
+		if i < len(toks) && toks[i].start == toks[i-1].end &&
+			(isSQLPunct(query, toks[i], "=") || isSQLPunct(query, toks[i], ">")) {
+			i++
+		} else if isSQLPunct(query, toks[i-1], "!") {
+			return nil
+		}
+	default:
+		return nil
+	}
+	if !literal(i) {
+		return nil
+	}
+	return []sqlSpan{{toks[i].start, toks[i].end}}
+}
+
+// redactInsertValues returns the spans of the redacted values of an INSERT
+// statement.
+func redactInsertValues(query string, toks []sqlToken, redacted func(int) bool) []sqlSpan {
+	i := 0
+	for i < len(toks) && !isSQLWord(query, toks[i], "INSERT") {
+		i++
+	}
+	for i < len(toks) && !isSQLPunct(query, toks[i], "(") {
+		i++
+	}
+	// The column list.
+	var cols []bool
+	found := false
+	for i++; i < len(toks) && !isSQLPunct(query, toks[i], ")"); i++ {
+		if toks[i].kind == sqlWord || toks[i].kind == sqlQuoted {
+			r := redacted(i)
+			cols = append(cols, r)
+			found = found || r
+		}
+	}
+	if !found || i+1 >= len(toks) || !isSQLWord(query, toks[i+1], "VALUES") {
+		return nil
+	}
+
+	// The tuples of values, whose items may be expressions.
+	var spans []sqlSpan
+	for i += 2; i < len(toks) && isSQLPunct(query, toks[i], "("); {
+		col, depth, first := 0, 0, -1
+		for i++; i < len(toks); i++ {
+			t := toks[i]
+			atEnd := depth == 0 && (isSQLPunct(query, t, ",") || isSQLPunct(query, t, ")"))
+			if atEnd {
+				if first >= 0 && col < len(cols) && cols[col] {
+					spans = append(spans, sqlSpan{toks[first].start, toks[i-1].end})
+				}
+				col, first = col+1, -1
+				if isSQLPunct(query, t, ")") {
+					break
+				}
+				continue
+			}
+			if first < 0 {
+				first = i
+			}
+			switch {
+			case isSQLPunct(query, t, "("):
+				depth++
+			case isSQLPunct(query, t, ")"):
+				depth--
+			}
+		}
+		i++
+		if i < len(toks) && isSQLPunct(query, toks[i], ",") {
+			i++
+		}
+	}
+	return spans
+}
+
+// tokenizeSQL splits query into tokens. Comments are not recognized.
+func tokenizeSQL(query string) []sqlToken {
+	var toks []sqlToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			i = skipSQLQuoted(query, i)
+			kind := sqlQuoted
+			if c == '\'' {
+				kind = sqlString
+			}
+			toks = append(toks, sqlToken{kind, start, i})
+		case isDigit(c) || (c == '-' && i+1 < len(query) && isDigit(query[i+1])):
+			for i++; i < len(query) && (isDigit(query[i]) || query[i] == '.' || query[i] == 'e' || query[i] == 'E'); i++ {
+			}
+			toks = append(toks, sqlToken{sqlNumber, start, i})
+		case isSQLWordByte(c):
+			for i++; i < len(query) && (isSQLWordByte(query[i]) || isDigit(query[i]) || query[i] == '.'); i++ {
+			}
+			toks = append(toks, sqlToken{sqlWord, start, i})
+		default:
+			i++
+			toks = append(toks, sqlToken{sqlPunct, start, i})
+		}
+	}
+	return toks
+}
+
+// skipSQLQuoted returns the index after the quoted token starting at i.
+// Doubled quotes escape the quote, as in the SQL GORM logs; backslashes are
+// not escapes.
+func skipSQLQuoted(query string, i int) int {
+	q := query[i]
+	for i++; i < len(query); i++ {
+		if query[i] != q {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == q {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(query)
+}
+
+// sqlColumnName returns the lowercased, unquoted column name of the
+// identifier t, without its table.
+func sqlColumnName(query string, t sqlToken) string {
+	name := query[t.start:t.end]
+	if t.kind == sqlWord {
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			name = name[i+1:]
+		}
+	} else if len(name) >= 2 {
+		name = name[1 : len(name)-1]
+	}
+	return strings.ToLower(name)
+}
+
+// isSQLLiteral reports whether t is a literal value.
+func isSQLLiteral(query string, t sqlToken) bool {
+	switch t.kind {
+	case sqlString, sqlNumber, sqlQuoted:
+		return true
+	case sqlWord:
+		switch strings.ToUpper(query[t.start:t.end]) {
+		case "NULL", "TRUE", "FALSE":
+			return true
+		}
+	}
+	return false
+}
+
+// isSQLPunct reports whether t is the punctuation p.
+func isSQLPunct(query string, t sqlToken, p string) bool {
+	return t.kind == sqlPunct && query[t.start:t.end] == p
+}
+
+// isSQLWord reports whether t is the keyword w, in any case.
+func isSQLWord(query string, t sqlToken, w string) bool {
+	return t.kind == sqlWord && strings.EqualFold(query[t.start:t.end], w)
+}
+
+// isSQLWordByte reports whether c may start a SQL word.
+func isSQLWordByte(c byte) bool {
+	return c == '_' || c == '$' || c == '@' || (c|0x20 >= 'a' && c|0x20 <= 'z') || c >= 0x80
+}
+
+// isDigit reports whether c is an ASCII digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// replaceSpans replaces the spans of query with redactedValue. Overlapping
+// spans are merged.
+func replaceSpans(query string, spans []sqlSpan) string {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var sb strings.Builder
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			continue
+		}
+		sb.WriteString(query[last:s.start])
+		sb.WriteString(redactedValue)
+		last = s.end
+	}
+	sb.WriteString(query[last:])
+	return sb.String()
+}
@@ -0,0 +1,48 @@
+package ezlog
+
+import "testing"
+
+func TestGormRedactColumns(t *testing.T) {
+	l := NewGormLogger().WithRedactedColumns("password", "SSN").Build()
+	tests := []struct {
+		name, in, want string
+	}{
+		{"equal", "SELECT * FROM `users` WHERE `password` = 'hunter2'",
+			"SELECT * FROM `users` WHERE `password` = ?[REDACTED]"},
+		{"qualified", "SELECT * FROM users WHERE users.ssn = 123456789 AND id = 1",
+			"SELECT * FROM users WHERE users.ssn = ?[REDACTED] AND id = 1"},
+		{"not equal", "SELECT * FROM users WHERE password != 'a' OR password <> 'b'",
+			"SELECT * FROM users WHERE password != ?[REDACTED] OR password <> ?[REDACTED]"},
+		{"ordering", "SELECT * FROM users WHERE ssn >= 100 AND ssn < 200",
+			"SELECT * FROM users WHERE ssn >= ?[REDACTED] AND ssn < ?[REDACTED]"},
+		{"like", "SELECT * FROM users WHERE ssn LIKE '123%' OR ssn NOT ILIKE '9%'",
+			"SELECT * FROM users WHERE ssn LIKE ?[REDACTED] OR ssn NOT ILIKE ?[REDACTED]"},
+		{"in", "SELECT * FROM users WHERE `ssn` IN ('1','2',3) AND id IN (1,2)",
+			"SELECT * FROM users WHERE `ssn` IN (?[REDACTED]) AND id IN (1,2)"},
+		{"not in", "SELECT * FROM users WHERE ssn NOT IN ('1')",
+			"SELECT * FROM users WHERE ssn NOT IN (?[REDACTED])"},
+		{"in subquery", "SELECT * FROM users WHERE ssn IN (SELECT ssn FROM banned)",
+			"SELECT * FROM users WHERE ssn IN (SELECT ssn FROM banned)"},
+		{"between", "SELECT * FROM users WHERE ssn BETWEEN 100 AND 200 AND id = 3",
+			"SELECT * FROM users WHERE ssn BETWEEN ?[REDACTED] AND ?[REDACTED] AND id = 3"},
+		{"column comparison", "SELECT * FROM a JOIN b ON a.ssn = b.ssn",
+			"SELECT * FROM a JOIN b ON a.ssn = b.ssn"},
+		{"update", "UPDATE `users` SET `password`='x',`name`='ann' WHERE `id` = 1",
+			"UPDATE `users` SET `password`=?[REDACTED],`name`='ann' WHERE `id` = 1"},
+		{"insert", "INSERT INTO `users` (`name`,`password`) VALUES ('ann','x'),('bob',LOWER('Y'))",
+			"INSERT INTO `users` (`name`,`password`) VALUES ('ann',?[REDACTED]),('bob',?[REDACTED])"},
+		{"doubled quote", "SELECT * FROM users WHERE password = 'it''s' AND name = 'ann'",
+			"SELECT * FROM users WHERE password = ?[REDACTED] AND name = 'ann'"},
+		{"backslash", `SELECT * FROM users WHERE password = 'C:\' AND name = 'ann'`,
+			`SELECT * FROM users WHERE password = ?[REDACTED] AND name = 'ann'`},
+		{"other column", "SELECT * FROM users WHERE name = 'ann'",
+			"SELECT * FROM users WHERE name = 'ann'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.redactColumns(tt.in); got != tt.want {
+				t.Errorf("redactColumns(%q)\n got  %q\n want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
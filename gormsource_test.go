@@ -0,0 +1,77 @@
+// The source field skips the frames of package ezlog, so these tests live
+// outside of it.
+package ezlog_test
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ezydark/ezlog"
+	"gorm.io/gorm/logger"
+)
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// line returns the line of its caller.
+func line() int {
+	_, _, n, _ := runtime.Caller(1)
+	return n
+}
+
+// queryThroughWrapper runs a query through one wrapper function.
+func queryThroughWrapper(l *ezlog.GormLogger) int {
+	n := line() + 1
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	return n
+}
+
+func TestGormSourceField(t *testing.T) {
+	var buf bytes.Buffer
+	l := ezlog.NewGormLogger().WithWriter(&buf).WithLogLevel(logger.Info).WithSourceField("source").Build()
+	n := line() + 1
+	l.Info(context.Background(), "connected")
+	m := line() + 1
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+
+	lines := strings.Split(strings.TrimSpace(ansiEscape.ReplaceAllString(buf.String(), "")), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), lines)
+	}
+	for i, n := range []int{n, m} {
+		if want := `source="ezlog_test.TestGormSourceField:` + strconv.Itoa(n) + `"`; !strings.Contains(lines[i], want) {
+			t.Errorf("line %q lacks %s", lines[i], want)
+		}
+	}
+}
+
+func TestGormSourceFieldDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	l := ezlog.NewGormLogger().WithWriter(&buf).Build()
+	l.Warn(context.Background(), "lagging")
+	if got := buf.String(); strings.Contains(got, "TestGormSourceFieldDisabled") {
+		t.Errorf("output = %q, want no source field", got)
+	}
+}
+
+func TestGormCallerDepth(t *testing.T) {
+	var buf bytes.Buffer
+	l := ezlog.NewGormLogger().WithWriter(&buf).WithLogLevel(logger.Info).WithSourceField("source").Build()
+	n := queryThroughWrapper(l)
+	if want := `source="ezlog_test.queryThroughWrapper:` + strconv.Itoa(n) + `"`; !strings.Contains(ansiEscape.ReplaceAllString(buf.String(), ""), want) {
+		t.Errorf("output = %q, want %s", buf.String(), want)
+	}
+
+	buf.Reset()
+	l = ezlog.NewGormLogger().WithWriter(&buf).WithLogLevel(logger.Info).WithSourceField("source").WithCallerDepth(1).Build()
+	m := line() + 1
+	queryThroughWrapper(l)
+	if want := `source="ezlog_test.TestGormCallerDepth:` + strconv.Itoa(m) + `"`; !strings.Contains(ansiEscape.ReplaceAllString(buf.String(), ""), want) {
+		t.Errorf("output = %q, want %s", buf.String(), want)
+	}
+}
@@ -0,0 +1,126 @@
+package ezlog
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// goroutineFields holds the ambient fields of each goroutine, keyed by
+// goroutine ID.
+var goroutineFields sync.Map
+
+// goroutineFieldCount is the number of goroutines with ambient fields, so
+// the hook can skip looking up the goroutine ID when there are none.
+var goroutineFieldCount atomic.Int64
+
+// fieldSet is an ordered set of fields.
+type fieldSet struct {
+	mu     sync.Mutex
+	keys   []string
+	values map[string]any
+}
+
+// SetGoroutineField adds key with value to every event logged from the
+// calling goroutine by loggers built with WithGoroutineFields, without
+// passing a context around. Setting the same key again replaces its value.
+//
+// The Go runtime reuses the IDs of exited goroutines, so a goroutine that
+// sets fields must clear them before it returns, typically with
+// defer ezlog.ClearAllGoroutineFields(); otherwise a later goroutine may
+// inherit them, and they are never freed.
+func SetGoroutineField(key string, value any) {
+	id := goroutineID()
+	v, loaded := goroutineFields.LoadOrStore(id, &fieldSet{values: map[string]any{}})
+	if !loaded {
+		goroutineFieldCount.Add(1)
+	}
+	set := v.(*fieldSet)
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if _, ok := set.values[key]; !ok {
+		set.keys = append(set.keys, key)
+	}
+	set.values[key] = value
+}
+
+// ClearGoroutineField removes the ambient field key of the calling goroutine.
+func ClearGoroutineField(key string) {
+	id := goroutineID()
+	v, ok := goroutineFields.Load(id)
+	if !ok {
+		return
+	}
+	set := v.(*fieldSet)
+	set.mu.Lock()
+	if _, ok := set.values[key]; ok {
+		delete(set.values, key)
+		for i, k := range set.keys {
+			if k == key {
+				set.keys = append(set.keys[:i], set.keys[i+1:]...)
+				break
+			}
+		}
+	}
+	empty := len(set.keys) == 0
+	set.mu.Unlock()
+	if empty {
+		clearGoroutine(id)
+	}
+}
+
+// ClearAllGoroutineFields removes all ambient fields of the calling goroutine.
+func ClearAllGoroutineFields() {
+	clearGoroutine(goroutineID())
+}
+
+// clearGoroutine drops the fields of goroutine id.
+func clearGoroutine(id uint64) {
+	if _, loaded := goroutineFields.LoadAndDelete(id); loaded {
+		goroutineFieldCount.Add(-1)
+	}
+}
+
+// WithGoroutineFields installs a hook adding the ambient fields set with
+// SetGoroutineField to the events of the goroutine that set them.
+func (b *LogBuilder) WithGoroutineFields() *LogBuilder {
+	b.goroutineFields = true
+	return b
+}
+
+// goroutineHook adds the ambient fields of the logging goroutine to events.
+type goroutineHook struct{}
+
+// Run implements zerolog.Hook.
+func (goroutineHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if goroutineFieldCount.Load() == 0 {
+		return
+	}
+	v, ok := goroutineFields.Load(goroutineID())
+	if !ok {
+		return
+	}
+	set := v.(*fieldSet)
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	for _, k := range set.keys {
+		e.Interface(k, set.values[k])
+	}
+}
+
+// goroutineID returns the ID of the calling goroutine, parsed from the
+// "goroutine N [running]:" header of its stack trace.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
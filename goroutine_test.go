@@ -0,0 +1,79 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGoroutineFields(t *testing.T) {
+	defer ClearAllGoroutineFields()
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithJSONOutput().WithGoroutineFields().Build()
+
+	SetGoroutineField("job", "import")
+	SetGoroutineField("attempt", 1)
+	SetGoroutineField("attempt", 2)
+	l.Info().Msg("with fields")
+	ClearGoroutineField("job")
+	l.Info().Msg("without job")
+	ClearAllGoroutineFields()
+	l.Info().Msg("without fields")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.Contains(lines[0], `"job":"import","attempt":2`) {
+		t.Errorf("first event = %q, want both fields in order", lines[0])
+	}
+	if strings.Contains(lines[1], `"job"`) || !strings.Contains(lines[1], `"attempt":2`) {
+		t.Errorf("second event = %q, want only attempt", lines[1])
+	}
+	if strings.Contains(lines[2], `"attempt"`) {
+		t.Errorf("third event = %q, want no ambient fields", lines[2])
+	}
+	if n := goroutineFieldCount.Load(); n != 0 {
+		t.Errorf("%d goroutines still tracked", n)
+	}
+}
+
+func TestGoroutineFieldsIsolated(t *testing.T) {
+	var buf lockedBuffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithGoroutineFields().Build()
+
+	var wg sync.WaitGroup
+	for _, worker := range []string{"a", "b", "c"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer ClearAllGoroutineFields()
+			SetGoroutineField("worker", worker)
+			l.Info().Str("from", worker).Msg("work")
+		}()
+	}
+	wg.Wait()
+	l.Info().Msg("main")
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		from := line[strings.Index(line, `"from":"`)+8:]
+		if strings.Contains(line, `"main"`) {
+			if strings.Contains(line, "worker") {
+				t.Errorf("main goroutine event = %q has a worker field", line)
+			}
+			continue
+		}
+		if !strings.Contains(line, `"worker":"`+from[:1]+`"`) {
+			t.Errorf("event %q has the field of another goroutine", line)
+		}
+	}
+}
+
+func TestGoroutineFieldsNeedHook(t *testing.T) {
+	defer ClearAllGoroutineFields()
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithJSONOutput().Build()
+	SetGoroutineField("job", "import")
+	l.Info().Msg("plain")
+	if strings.Contains(buf.String(), "job") {
+		t.Errorf("output = %q, want no ambient fields without WithGoroutineFields", buf.String())
+	}
+}
@@ -0,0 +1,26 @@
+package ezlog
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// timestampHook adds the timestamp field using a per-logger clock.
+// zerolog only offers a process-wide TimestampFunc, so the field is added
+// from a hook instead of through Context.Timestamp.
+// A non-empty layout renders the timestamp with it instead of the
+// process-wide zerolog.TimeFieldFormat.
+type timestampHook struct {
+	now    func() time.Time
+	layout string
+}
+
+// Run implements zerolog.Hook.
+func (h timestampHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if h.layout != "" {
+		e.Str(zerolog.TimestampFieldName, h.now().Format(h.layout))
+		return
+	}
+	e.Time(zerolog.TimestampFieldName, h.now())
+}
@@ -0,0 +1,193 @@
+package ezlog
+
+import (
+	"crypto"
+	_ "crypto/sha256" // registers SHA-224 and SHA-256 for WithBodyHash
+	_ "crypto/sha512" // registers the SHA-384 and SHA-512 variants
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// HTTPMiddleware logs one line per HTTP request through a RequestLogger.
+// It should be created using NewHTTPMiddleware.
+type HTTPMiddleware struct {
+	logger        *zerolog.Logger
+	logHeaders    bool
+	headers       []string
+	redactHeaders map[string]struct{}
+	bodyHash      crypto.Hash
+}
+
+// defaultRedactedHeaders are the headers redacted by default, as they carry
+// credentials.
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// NewHTTPMiddleware creates an HTTPMiddleware writing to l.
+func NewHTTPMiddleware(l *zerolog.Logger) *HTTPMiddleware {
+	m := &HTTPMiddleware{logger: l, redactHeaders: make(map[string]struct{})}
+	return m.WithRedactHeaders(defaultRedactedHeaders...)
+}
+
+// WithHeaders adds the named request headers to the request line, under the
+// "headers" field, or all of them if no name is given. The values of
+// credential headers such as Authorization, Cookie and X-API-Key are
+// replaced with "[REDACTED]"; see WithRedactHeaders.
+func (m *HTTPMiddleware) WithHeaders(includeHeaders ...string) *HTTPMiddleware {
+	m.logHeaders = true
+	for _, name := range includeHeaders {
+		m.headers = append(m.headers, http.CanonicalHeaderKey(name))
+	}
+	return m
+}
+
+// WithRedactHeaders replaces the values of the named headers with
+// "[REDACTED]" in the headers logged by WithHeaders, besides the default
+// ones. Names are case insensitive.
+func (m *HTTPMiddleware) WithRedactHeaders(headers ...string) *HTTPMiddleware {
+	for _, name := range headers {
+		m.redactHeaders[http.CanonicalHeaderKey(name)] = struct{}{}
+	}
+	return m
+}
+
+// WithBodyHash adds the hex hash of the request body computed with algo,
+// crypto.SHA256 if 0, to the request line as the "body_hash" field, e.g. as
+// proof of what a webhook receiver got without logging the body itself.
+// Handlers still read the body as usual; up to 1 MiB of the bytes they leave
+// unread are read after them to hash the whole body. When more are left, or
+// reading fails, the hash covers the bytes read and "body_hash_partial" is
+// set. algo must be linked into the binary, as the SHA-2 ones are; SHA-256
+// is used otherwise.
+func (m *HTTPMiddleware) WithBodyHash(algo crypto.Hash) *HTTPMiddleware {
+	if algo == 0 || !algo.Available() {
+		algo = crypto.SHA256
+	}
+	m.bodyHash = algo
+	return m
+}
+
+// maxBodyHashDrain is the number of unread request body bytes read to
+// complete its hash.
+const maxBodyHashDrain = 1 << 20
+
+// hashedBody hashes the bytes read from a request body.
+type hashedBody struct {
+	io.Reader
+	body io.ReadCloser
+	hash hash.Hash
+}
+
+// newHashedBody returns a hashedBody over body hashed with algo.
+func newHashedBody(body io.ReadCloser, algo crypto.Hash) *hashedBody {
+	h := algo.New()
+	return &hashedBody{Reader: io.TeeReader(body, h), body: body, hash: h}
+}
+
+// Close implements io.Closer.
+func (b *hashedBody) Close() error {
+	return b.body.Close()
+}
+
+// sum reads the rest of the body, up to maxBodyHashDrain bytes, and returns
+// its hex hash and whether it covers the whole body.
+func (b *hashedBody) sum() (string, bool) {
+	n, err := io.Copy(io.Discard, io.LimitReader(b.Reader, maxBodyHashDrain+1))
+	return hex.EncodeToString(b.hash.Sum(nil)), err == nil && n <= maxBodyHashDrain
+}
+
+// headerFields returns the logged headers of h.
+func (m *HTTPMiddleware) headerFields(h http.Header) map[string]string {
+	fields := make(map[string]string)
+	add := func(name string, values []string) {
+		if _, ok := m.redactHeaders[name]; ok {
+			fields[name] = "[REDACTED]"
+			return
+		}
+		fields[name] = strings.Join(values, ", ")
+	}
+	if len(m.headers) == 0 {
+		for name, values := range h {
+			add(http.CanonicalHeaderKey(name), values)
+		}
+		return fields
+	}
+	for _, name := range m.headers {
+		if values := h.Values(name); len(values) > 0 {
+			add(name, values)
+		}
+	}
+	return fields
+}
+
+// Handler wraps next. Handlers can add fields to the request line through
+// RequestLoggerFromContext(r.Context()).
+func (m *HTTPMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rl := NewRequestLogger(m.logger)
+		rl.Set("method", r.Method)
+		rl.Set("path", r.URL.Path)
+		if m.logHeaders {
+			rl.Set("headers", m.headerFields(r.Header))
+		}
+
+		var body *hashedBody
+		if m.bodyHash != 0 && r.Body != nil {
+			body = newHashedBody(r.Body, m.bodyHash)
+			r.Body = body
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ContextWithRequestLogger(r.Context(), rl)))
+
+		if body != nil {
+			sum, complete := body.sum()
+			rl.Set("body_hash", sum)
+			if !complete {
+				rl.Set("body_hash_partial", true)
+			}
+		}
+
+		rl.Set("status", rec.status)
+		rl.Set("bytes", rec.bytes)
+		rl.SetDuration(time.Since(start))
+		rl.Flush("http request")
+	})
+}
+
+// statusRecorder captures the status code and body size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+// WriteHeader records the status code.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of bytes written.
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
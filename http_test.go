@@ -0,0 +1,158 @@
+package ezlog
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serve runs one request through m wrapping h and returns the decoded
+// request line.
+func serve(t *testing.T, buf *bytes.Buffer, m *HTTPMiddleware, h http.HandlerFunc, r *http.Request) map[string]any {
+	t.Helper()
+	m.Handler(h).ServeHTTP(httptest.NewRecorder(), r)
+	var evt map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &evt); err != nil {
+		t.Fatalf("request line %q: %v", buf.String(), err)
+	}
+	return evt
+}
+
+func TestHTTPMiddlewareBodyHash(t *testing.T) {
+	body := `{"event":"paid"}`
+	sum := sha256.Sum256([]byte(body))
+
+	for name, h := range map[string]http.HandlerFunc{
+		"read":   func(w http.ResponseWriter, r *http.Request) { io.ReadAll(r.Body) },
+		"unread": func(w http.ResponseWriter, r *http.Request) {},
+		"partly": func(w http.ResponseWriter, r *http.Request) { r.Body.Read(make([]byte, 4)) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+			m := NewHTTPMiddleware(l).WithBodyHash(0)
+			evt := serve(t, &buf, m, h, httptest.NewRequest("POST", "/hook", strings.NewReader(body)))
+
+			if evt["body_hash"] != hex.EncodeToString(sum[:]) {
+				t.Errorf("body_hash = %v, want %x", evt["body_hash"], sum)
+			}
+			if _, ok := evt["body_hash_partial"]; ok {
+				t.Error("complete hash marked partial")
+			}
+		})
+	}
+}
+
+func TestHTTPMiddlewareBodyHashHandlerSeesBody(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+	m := NewHTTPMiddleware(l).WithBodyHash(crypto.SHA512)
+	var got []byte
+	serve(t, &buf, m, func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+	}, httptest.NewRequest("POST", "/hook", strings.NewReader("payload")))
+
+	if string(got) != "payload" {
+		t.Errorf("handler read %q", got)
+	}
+}
+
+func TestHTTPMiddlewareBodyHashBoundsDrain(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+	m := NewHTTPMiddleware(l).WithBodyHash(0)
+	body := &countingReader{r: io.LimitReader(zeroReader{}, 4*maxBodyHashDrain)}
+	r := httptest.NewRequest("POST", "/upload", body)
+	evt := serve(t, &buf, m, func(w http.ResponseWriter, r *http.Request) {}, r)
+
+	if evt["body_hash_partial"] != true {
+		t.Errorf("body_hash_partial = %v, want true", evt["body_hash_partial"])
+	}
+	if body.n > maxBodyHashDrain+32<<10 {
+		t.Errorf("read %d bytes of the unread body, want at most about %d", body.n, maxBodyHashDrain)
+	}
+}
+
+func TestHTTPMiddlewareHeaders(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+	m := NewHTTPMiddleware(l).WithHeaders("User-Agent", "authorization")
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("User-Agent", "curl")
+	r.Header.Set("Authorization", "Bearer secret")
+	evt := serve(t, &buf, m, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("hi"))
+	}, r)
+
+	headers, _ := evt["headers"].(map[string]any)
+	if headers["User-Agent"] != "curl" || headers["Authorization"] != "[REDACTED]" {
+		t.Errorf("headers = %v", evt["headers"])
+	}
+	if evt["status"] != float64(http.StatusTeapot) || evt["bytes"] != float64(2) {
+		t.Errorf("status = %v, bytes = %v", evt["status"], evt["bytes"])
+	}
+}
+
+func TestHTTPMiddlewareRedactHeaders(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header["x-api-key"] = []string{"k1"}
+	r.Header.Set("Cookie", "session=1")
+	r.Header.Set("Proxy-Authorization", "Basic x")
+	r.Header.Set("X-Session", "s1")
+	r.Header.Add("Accept", "text/html")
+	r.Header.Add("Accept", "application/json")
+
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+	evt := serve(t, &buf, NewHTTPMiddleware(l).WithHeaders().WithRedactHeaders("x-SESSION"), func(http.ResponseWriter, *http.Request) {}, r)
+
+	want := map[string]any{
+		"X-Api-Key":           "[REDACTED]",
+		"Cookie":              "[REDACTED]",
+		"Proxy-Authorization": "[REDACTED]",
+		"X-Session":           "[REDACTED]",
+		"Accept":              "text/html, application/json",
+	}
+	headers, _ := evt["headers"].(map[string]any)
+	if len(headers) != len(want) {
+		t.Errorf("headers = %v, want %v", headers, want)
+	}
+	for name, v := range want {
+		if headers[name] != v {
+			t.Errorf("headers[%q] = %v, want %v", name, headers[name], v)
+		}
+	}
+
+	buf.Reset()
+	if evt := serve(t, &buf, NewHTTPMiddleware(l), func(http.ResponseWriter, *http.Request) {}, r); evt["headers"] != nil {
+		t.Errorf("headers logged without WithHeaders: %v", evt["headers"])
+	}
+}
+
+// zeroReader reads zeros forever.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
@@ -0,0 +1,152 @@
+package ezlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// durationFieldSuffixes are the field name endings treated as durations.
+// zerolog encodes durations as plain numbers in zerolog.DurationFieldUnit, so
+// the field name is the only hint left once the event reaches the console.
+var durationFieldSuffixes = []string{"duration", "elapsed", "latency", "took", "_dur", "_eta"}
+
+// durationFieldNames are the field names treated as durations besides the
+// ones with a duration suffix.
+var durationFieldNames = []string{"eta"}
+
+// sizeFieldSuffixes are the field name endings treated as byte counts.
+var sizeFieldSuffixes = []string{"_bytes", "_size"}
+
+// WithHumanizeFields renders duration fields like "1.24s" or "380ms" and byte
+// count fields like "3.4 MiB (3565158)" in console output. Fields are
+// recognized by name: durations end in duration, elapsed, latency, took, _dur
+// or _eta or are named eta, sizes end in _bytes or _size. Only numeric values
+// are rewritten.
+func (b *LogBuilder) WithHumanizeFields() *LogBuilder {
+	b.humanize = true
+	return b
+}
+
+// humanizeFields rewrites the duration and size fields of evt in place.
+// Rewritten values are stored as json.Number so that ConsoleWriter prints
+// them verbatim instead of quoting them.
+func humanizeFields(evt map[string]any) error {
+	for key, value := range evt {
+		n, ok := value.(json.Number)
+		if !ok {
+			continue
+		}
+		switch {
+		case hasAnySuffix(key, durationFieldSuffixes) || slices.Contains(durationFieldNames, key):
+			f, err := n.Float64()
+			if err != nil {
+				continue
+			}
+			d := time.Duration(f * float64(zerolog.DurationFieldUnit))
+			evt[key] = json.Number(humanDuration(d))
+		case hasAnySuffix(key, sizeFieldSuffixes):
+			i, err := n.Int64()
+			if err != nil {
+				continue
+			}
+			evt[key] = json.Number(fmt.Sprintf("%s (%d)", humanBytes(i), i))
+		}
+	}
+	return nil
+}
+
+// humanDuration formats d with about three significant digits in the most
+// fitting unit, e.g. "1.24s", "380ms" or "2m3s".
+func humanDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	switch {
+	case d >= time.Minute:
+		return sign + d.Round(time.Second).String()
+	case d >= time.Second:
+		return sign + formatSignificant(d.Seconds()) + "s"
+	case d >= time.Millisecond:
+		return sign + formatSignificant(float64(d)/float64(time.Millisecond)) + "ms"
+	case d >= time.Microsecond:
+		return sign + formatSignificant(float64(d)/float64(time.Microsecond)) + "µs"
+	default:
+		return sign + strconv.FormatInt(int64(d), 10) + "ns"
+	}
+}
+
+// humanBytes formats n bytes using binary units, e.g. "3.4 MiB".
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	f := float64(n)
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	i := -1
+	for (f >= unit || f <= -unit) && i < len(units)-1 {
+		f /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", f, units[i])
+}
+
+// formatSignificant formats f, a value between 1 and 1000, with three
+// significant digits and no trailing zeros.
+func formatSignificant(f float64) string {
+	decimals := 2
+	switch {
+	case f >= 100:
+		decimals = 0
+	case f >= 10:
+		decimals = 1
+	}
+	s := strconv.FormatFloat(f, 'f', decimals, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// hasAnySuffix reports whether s ends with one of suffixes.
+func hasAnySuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DurationField logs a duration as both a human readable string and a numeric
+// millisecond value, e.g. "elapsed":"1.23ms","elapsed_ms":1.234567.
+// It is meant to be embedded into an event:
+//
+//	log.Info().EmbedObject(ezlog.LogDuration("elapsed", time.Since(start))).Msg("done")
+type DurationField struct {
+	key string
+	d   time.Duration
+}
+
+// LogDuration returns a DurationField for d under key.
+func LogDuration(key string, d time.Duration) *DurationField {
+	return &DurationField{key: key, d: d}
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler.
+func (f *DurationField) MarshalZerologObject(e *zerolog.Event) {
+	e.Str(f.key, humanDuration(f.d)).
+		Float64(f.key+"_ms", float64(f.d)/float64(time.Millisecond))
+}
+
+// String returns the human readable form of the duration.
+func (f *DurationField) String() string {
+	return humanDuration(f.d)
+}
@@ -0,0 +1,115 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestHumanizeFields(t *testing.T) {
+	evt := map[string]any{
+		"elapsed":    json.Number("1240"),
+		"eta":        json.Number("90000"),
+		"job_eta":    json.Number("380"),
+		"beta":       json.Number("42"),
+		"zeta":       json.Number("7"),
+		"body_bytes": json.Number("3565158"),
+		"took":       "fast",
+	}
+	humanizeFields(evt)
+
+	want := map[string]any{
+		"elapsed":    json.Number("1.24s"),
+		"eta":        json.Number("1m30s"),
+		"job_eta":    json.Number("380ms"),
+		"beta":       json.Number("42"),
+		"zeta":       json.Number("7"),
+		"body_bytes": json.Number("3.4 MiB (3565158)"),
+		"took":       "fast",
+	}
+	for key, w := range want {
+		if evt[key] != w {
+			t.Errorf("%s = %v, want %v", key, evt[key], w)
+		}
+	}
+}
+
+func TestHumanDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "0ns"},
+		{d: 850 * time.Nanosecond, want: "850ns"},
+		{d: 1500 * time.Nanosecond, want: "1.5µs"},
+		{d: 42 * time.Microsecond, want: "42µs"},
+		{d: 380 * time.Millisecond, want: "380ms"},
+		{d: 12345 * time.Microsecond, want: "12.3ms"},
+		{d: 1240 * time.Millisecond, want: "1.24s"},
+		{d: 59 * time.Second, want: "59s"},
+		{d: 123 * time.Second, want: "2m3s"},
+		{d: 2*time.Hour + 400*time.Millisecond, want: "2h0m0s"},
+		{d: -380 * time.Millisecond, want: "-380ms"},
+	}
+	for _, tt := range tests {
+		if got := humanDuration(tt.d); got != tt.want {
+			t.Errorf("humanDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{n: 0, want: "0 B"},
+		{n: 1023, want: "1023 B"},
+		{n: 1024, want: "1.0 KiB"},
+		{n: 1536, want: "1.5 KiB"},
+		{n: 3565158, want: "3.4 MiB"},
+		{n: 5 << 30, want: "5.0 GiB"},
+		{n: 3 << 40, want: "3.0 TiB"},
+		{n: -2048, want: "-2.0 KiB"},
+	}
+	for _, tt := range tests {
+		if got := humanBytes(tt.n); got != tt.want {
+			t.Errorf("humanBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestWithHumanizeFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithHumanizeFields().Build()
+	l.Info().
+		Dur("took", 1240*time.Millisecond).
+		Int("body_bytes", 3565158).
+		Int("cache_size", 2048).
+		Int("rows", 3565158).
+		Msg("done")
+
+	got := buf.String()
+	for _, want := range []string{"took=1.24s", "body_bytes=3.4 MiB (3565158)", "cache_size=2.0 KiB (2048)", "rows=3565158"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output %q lacks %q", got, want)
+		}
+	}
+}
+
+func TestLogDuration(t *testing.T) {
+	var buf bytes.Buffer
+	l := zerolog.New(&buf)
+	l.Info().EmbedObject(LogDuration("elapsed", 1234567*time.Nanosecond)).Msg("done")
+
+	if got, want := buf.String(), `{"level":"info","elapsed":"1.23ms","elapsed_ms":1.234567,"message":"done"}`+"\n"; got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+	if got := LogDuration("elapsed", 90*time.Second).String(); got != "1m30s" {
+		t.Errorf("String() = %q, want %q", got, "1m30s")
+	}
+}
@@ -0,0 +1,145 @@
+package ezlog
+
+import (
+	"expvar"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// onWriteErrorInterval is the minimum delay between two OnWriteError callbacks.
+const onWriteErrorInterval = time.Second
+
+// asyncWriter is implemented by writers queueing events in the background.
+type asyncWriter interface {
+	QueueDepth() int
+	Dropped() uint64
+}
+
+// WriterStats holds the counters of an InstrumentedWriter.
+type WriterStats struct {
+	// Events is the number of write calls, one per log event.
+	Events uint64
+	// Bytes is the number of bytes successfully written.
+	Bytes uint64
+	// Errors is the number of failed writes.
+	Errors uint64
+	// QueueDepth is the number of queued events, for asynchronous writers.
+	QueueDepth int
+	// Dropped is the number of dropped events, for asynchronous writers.
+	Dropped uint64
+}
+
+// InstrumentedWriter wraps a writer and counts events, bytes and errors.
+// Write errors are otherwise swallowed by zerolog. It should be created using
+// NewInstrumentedWriter or LogBuilder.WithWriterInstrumentation.
+type InstrumentedWriter struct {
+	out     io.Writer
+	onError func(error)
+
+	events    atomic.Uint64
+	bytes     atomic.Uint64
+	errors    atomic.Uint64
+	lastError atomic.Int64
+}
+
+// NewInstrumentedWriter creates an InstrumentedWriter writing to out.
+func NewInstrumentedWriter(out io.Writer) *InstrumentedWriter {
+	return &InstrumentedWriter{out: out}
+}
+
+// OnError sets a callback invoked when a write fails, at most once per second
+// so that a dead disk does not flood the application.
+func (w *InstrumentedWriter) OnError(fn func(error)) *InstrumentedWriter {
+	w.onError = fn
+	return w
+}
+
+// Write implements io.Writer.
+func (w *InstrumentedWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *InstrumentedWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	w.events.Add(1)
+	n, err := writeLevel(w.out, level, p)
+	w.bytes.Add(uint64(n))
+	if err != nil {
+		w.errors.Add(1)
+		w.reportError(err)
+	}
+	return n, err
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (w *InstrumentedWriter) Close() error {
+	if c, ok := w.out.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Stats returns the writer's counters.
+func (w *InstrumentedWriter) Stats() WriterStats {
+	stats := WriterStats{
+		Events: w.events.Load(),
+		Bytes:  w.bytes.Load(),
+		Errors: w.errors.Load(),
+	}
+	if aw, ok := w.out.(asyncWriter); ok {
+		stats.QueueDepth = aw.QueueDepth()
+		stats.Dropped = aw.Dropped()
+	}
+	return stats
+}
+
+// PublishExpvar publishes the writer's counters as the expvar variable name.
+// Publishing an already published name does nothing.
+func (w *InstrumentedWriter) PublishExpvar(name string) {
+	if expvar.Get(name) != nil {
+		return
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		return w.Stats()
+	}))
+}
+
+// reportError invokes the error callback unless it ran less than
+// onWriteErrorInterval ago.
+func (w *InstrumentedWriter) reportError(err error) {
+	if w.onError == nil {
+		return
+	}
+	now := time.Now().UnixNano()
+	last := w.lastError.Load()
+	if last != 0 && now-last < int64(onWriteErrorInterval) {
+		return
+	}
+	if w.lastError.CompareAndSwap(last, now) {
+		w.onError(err)
+	}
+}
+
+// WithWriterInstrumentation wraps the writer in an InstrumentedWriter,
+// available from Instrumentation once the logger is built.
+func (b *LogBuilder) WithWriterInstrumentation() *LogBuilder {
+	b.instrument = true
+	return b
+}
+
+// WithOnWriteError calls fn when writing an event fails, at most once per
+// second. It enables writer instrumentation.
+func (b *LogBuilder) WithOnWriteError(fn func(error)) *LogBuilder {
+	b.instrument = true
+	b.onWriteError = fn
+	return b
+}
+
+// Instrumentation returns the InstrumentedWriter created by Build, or nil if
+// instrumentation is not enabled.
+func (b *LogBuilder) Instrumentation() *InstrumentedWriter {
+	return b.instrumented
+}
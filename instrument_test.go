@@ -0,0 +1,76 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestInstrumentedWriterStats(t *testing.T) {
+	var buf bytes.Buffer
+	b := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithWriterInstrumentation()
+	l := b.Build()
+	l.Info().Msg("one")
+	l.Warn().Msg("two")
+
+	stats := b.Instrumentation().Stats()
+	if stats.Events != 2 || stats.Bytes != uint64(buf.Len()) || stats.Errors != 0 {
+		t.Errorf("stats = %+v, want 2 events of %d bytes", stats, buf.Len())
+	}
+	if New().Instrumentation() != nil {
+		t.Error("Instrumentation() is not nil without instrumentation")
+	}
+}
+
+func TestOnWriteErrorThrottled(t *testing.T) {
+	var calls []error
+	b := New().AsLocal().WithWriter(failingWriter{}).WithJSONOutput().
+		WithOnWriteError(func(err error) { calls = append(calls, err) })
+	l := b.Build()
+	for range 100 {
+		l.Info().Msg("lost")
+	}
+
+	if stats := b.Instrumentation().Stats(); stats.Events != 100 || stats.Errors != 100 || stats.Bytes != 0 {
+		t.Errorf("stats = %+v, want 100 failed events", stats)
+	}
+	if len(calls) != 1 || calls[0].Error() != "disk full" {
+		t.Errorf("callback calls = %v, want a single disk full error", calls)
+	}
+
+	// Once the interval has passed, the callback runs again.
+	b.Instrumentation().lastError.Add(-int64(onWriteErrorInterval))
+	l.Info().Msg("lost")
+	if len(calls) != 2 {
+		t.Errorf("got %d callback calls after the interval, want 2", len(calls))
+	}
+}
+
+// queueWriter reports a fixed queue depth and drop count.
+type queueWriter struct{ bytes.Buffer }
+
+func (*queueWriter) QueueDepth() int { return 3 }
+func (*queueWriter) Dropped() uint64 { return 7 }
+
+func TestInstrumentedWriterAsyncStats(t *testing.T) {
+	w := NewInstrumentedWriter(&queueWriter{})
+	if stats := w.Stats(); stats.QueueDepth != 3 || stats.Dropped != 7 {
+		t.Errorf("stats = %+v, want the queue depth and drops of the writer", stats)
+	}
+}
+
+func TestInstrumentedWriterPublishExpvar(t *testing.T) {
+	w := NewInstrumentedWriter(&bytes.Buffer{})
+	w.Write([]byte("event\n"))
+	w.PublishExpvar("ezlog_test_writer")
+	NewInstrumentedWriter(&bytes.Buffer{}).PublishExpvar("ezlog_test_writer")
+
+	var stats WriterStats
+	if err := json.Unmarshal([]byte(expvar.Get("ezlog_test_writer").String()), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Events != 1 || stats.Bytes != 6 {
+		t.Errorf("published stats = %+v, want those of the first writer", stats)
+	}
+}
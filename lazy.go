@@ -0,0 +1,68 @@
+package ezlog
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// LazyValue is a field value computed only when an enabled event is written,
+// for fields that are expensive to build. The function runs at most once,
+// even when the value is logged several times. Use it with Event.Interface
+// or, for maps, Event.Object:
+//
+//	l.Debug().Interface("state", ezlog.Lazy(func() any { return dump(s) })).Msg("state")
+//
+// zerolog returns nil events for disabled levels, which never marshal their
+// values, so the function is not called at all then.
+// It should be created using Lazy.
+type LazyValue struct {
+	fn   func() any
+	once sync.Once
+	v    any
+}
+
+// Lazy returns a LazyValue computed by fn.
+func Lazy(fn func() any) *LazyValue {
+	return &LazyValue{fn: fn}
+}
+
+// Value computes the value on first use and returns it.
+func (l *LazyValue) Value() any {
+	l.once.Do(func() {
+		l.v = l.fn()
+	})
+	return l.v
+}
+
+// MarshalJSON implements json.Marshaler.
+func (l *LazyValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.Value())
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler. Maps become
+// the fields of the object; other values are logged under "value".
+func (l *LazyValue) MarshalZerologObject(e *zerolog.Event) {
+	switch v := l.Value().(type) {
+	case map[string]any:
+		e.Fields(v)
+	case zerolog.LogObjectMarshaler:
+		v.MarshalZerologObject(e)
+	default:
+		e.Interface("value", v)
+	}
+}
+
+// IfEnabled calls fn with an event at level only when l would write it, so
+// the fields fn computes cost nothing at disabled levels. fn must send the
+// event:
+//
+//	ezlog.IfEnabled(l, zerolog.DebugLevel, func(e *zerolog.Event) {
+//		e.Str("digest", digest(payload)).Msg("payload")
+//	})
+func IfEnabled(l *zerolog.Logger, level zerolog.Level, fn func(e *zerolog.Event)) {
+	if e := l.WithLevel(level); e.Enabled() {
+		fn(e)
+	}
+}
@@ -0,0 +1,103 @@
+package ezlog
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLazyEvaluatedOnceWhenEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithJSONOutput().Build()
+	calls := 0
+	v := Lazy(func() any {
+		calls++
+		return map[string]any{"size": 3}
+	})
+	l.Info().Interface("state", v).Object("obj", v).Msg("dump")
+	l.Info().Interface("again", v).Msg("dump")
+
+	if calls != 1 {
+		t.Errorf("evaluated %d times, want once", calls)
+	}
+	got := buf.String()
+	if !strings.Contains(got, `"state":{"size":3}`) || !strings.Contains(got, `"obj":{"size":3}`) || !strings.Contains(got, `"again":{"size":3}`) {
+		t.Errorf("output = %q", got)
+	}
+}
+
+func TestLazyObjectScalar(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithJSONOutput().Build()
+	l.Info().Object("digest", Lazy(func() any { return "abc" })).Msg("done")
+	if got := buf.String(); !strings.Contains(got, `"digest":{"value":"abc"}`) {
+		t.Errorf("output = %q", got)
+	}
+}
+
+func TestLazyNotEvaluatedWhenDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).Build().Level(zerolog.InfoLevel)
+	calls := 0
+	l.Debug().Interface("state", Lazy(func() any {
+		calls++
+		return "expensive"
+	})).Msg("skipped")
+	IfEnabled(&l, zerolog.DebugLevel, func(e *zerolog.Event) {
+		calls++
+		e.Msg("skipped")
+	})
+
+	if calls != 0 || buf.Len() != 0 {
+		t.Errorf("evaluated %d times, output %q, want nothing at a disabled level", calls, buf.String())
+	}
+
+	IfEnabled(&l, zerolog.WarnLevel, func(e *zerolog.Event) {
+		calls++
+		e.Str("digest", "abc").Msg("kept")
+	})
+	if calls != 1 || !strings.Contains(buf.String(), `kept digest="abc"`) {
+		t.Errorf("evaluated %d times, output %q, want the enabled event", calls, buf.String())
+	}
+}
+
+// BenchmarkLazyDisabled shows that neither Lazy values nor IfEnabled
+// closures are evaluated for disabled events; evals/op stays 0.
+func BenchmarkLazyDisabled(b *testing.B) {
+	l := New().AsLocal().WithWriter(io.Discard).WithJSONOutput().Build().Level(zerolog.InfoLevel)
+	evals := 0
+	expensive := func() any {
+		evals++
+		return strings.Repeat("x", 1024)
+	}
+
+	b.Run("lazy", func(b *testing.B) {
+		evals = 0
+		b.ReportAllocs()
+		for b.Loop() {
+			l.Debug().Interface("state", Lazy(expensive)).Msg("state")
+		}
+		b.ReportMetric(float64(evals)/float64(b.N), "evals/op")
+	})
+	b.Run("if-enabled", func(b *testing.B) {
+		evals = 0
+		b.ReportAllocs()
+		for b.Loop() {
+			IfEnabled(&l, zerolog.DebugLevel, func(e *zerolog.Event) {
+				e.Interface("state", expensive()).Msg("state")
+			})
+		}
+		b.ReportMetric(float64(evals)/float64(b.N), "evals/op")
+	})
+	b.Run("eager", func(b *testing.B) {
+		evals = 0
+		b.ReportAllocs()
+		for b.Loop() {
+			l.Debug().Interface("state", expensive()).Msg("state")
+		}
+		b.ReportMetric(float64(evals)/float64(b.N), "evals/op")
+	})
+}
@@ -0,0 +1,239 @@
+package ezlog
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog"
+)
+
+// knownLevels lists the levels rendered by the console formatter, in order of severity.
+var knownLevels = []zerolog.Level{
+	zerolog.TraceLevel,
+	zerolog.DebugLevel,
+	zerolog.InfoLevel,
+	zerolog.WarnLevel,
+	zerolog.ErrorLevel,
+	zerolog.FatalLevel,
+	zerolog.PanicLevel,
+}
+
+// shortLevelNames holds the three-letter level labels used by WithShortLevels.
+var shortLevelNames = map[zerolog.Level]string{
+	zerolog.TraceLevel: "TRC",
+	zerolog.DebugLevel: "DBG",
+	zerolog.InfoLevel:  "INF",
+	zerolog.WarnLevel:  "WRN",
+	zerolog.ErrorLevel: "ERR",
+	zerolog.FatalLevel: "FTL",
+	zerolog.PanicLevel: "PNC",
+}
+
+// levelAliases maps accepted level names, in lower case, to their level.
+var levelAliases = map[string]zerolog.Level{
+	"trace":    zerolog.TraceLevel,
+	"debug":    zerolog.DebugLevel,
+	"info":     zerolog.InfoLevel,
+	"warn":     zerolog.WarnLevel,
+	"warning":  zerolog.WarnLevel,
+	"error":    zerolog.ErrorLevel,
+	"fatal":    zerolog.FatalLevel,
+	"panic":    zerolog.PanicLevel,
+	"disabled": zerolog.Disabled,
+}
+
+// ParseLevel converts a level name such as "warn", "WARNING" or "Trace", or a
+// numeric level from "-1" (trace) to "5" (panic), into a zerolog.Level.
+func ParseLevel(s string) (zerolog.Level, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if level, ok := levelAliases[name]; ok {
+		return level, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= int(zerolog.TraceLevel) && n <= int(zerolog.PanicLevel) {
+		return zerolog.Level(n), nil
+	}
+	return zerolog.NoLevel, fmt.Errorf("ezlog: unknown level %q", s)
+}
+
+// LevelString returns the lower case name of l, e.g. "warn".
+func LevelString(l zerolog.Level) string {
+	switch l {
+	case zerolog.TraceLevel:
+		return "trace"
+	case zerolog.DebugLevel:
+		return "debug"
+	case zerolog.InfoLevel:
+		return "info"
+	case zerolog.WarnLevel:
+		return "warn"
+	case zerolog.ErrorLevel:
+		return "error"
+	case zerolog.FatalLevel:
+		return "fatal"
+	case zerolog.PanicLevel:
+		return "panic"
+	case zerolog.Disabled:
+		return "disabled"
+	case zerolog.NoLevel:
+		return ""
+	default:
+		return strconv.Itoa(int(l))
+	}
+}
+
+// defaultLevelIcons holds the icons used by WithLevelIcons.
+var defaultLevelIcons = map[zerolog.Level]string{
+	zerolog.TraceLevel: "🔍",
+	zerolog.DebugLevel: "🐞",
+	zerolog.InfoLevel:  "ℹ",
+	zerolog.WarnLevel:  "⚠",
+	zerolog.ErrorLevel: "✖",
+	zerolog.FatalLevel: "💀",
+	zerolog.PanicLevel: "💥",
+}
+
+// WithLevel sets the minimum level of the logger's events.
+func (b *LogBuilder) WithLevel(level zerolog.Level) *LogBuilder {
+	b.level = level
+	return b
+}
+
+// WithLevelIcons prefixes each level with an icon in console output. Icons are
+// left out when colors are disabled, in tview mode, or when the writer is not
+// a terminal.
+func (b *LogBuilder) WithLevelIcons() *LogBuilder {
+	if b.levelIcons == nil {
+		b.levelIcons = make(map[zerolog.Level]string, len(defaultLevelIcons))
+	}
+	for level, icon := range defaultLevelIcons {
+		if _, ok := b.levelIcons[level]; !ok {
+			b.levelIcons[level] = icon
+		}
+	}
+	return b
+}
+
+// WithLevelIcon sets the icon printed for level and enables level icons.
+func (b *LogBuilder) WithLevelIcon(level zerolog.Level, icon string) *LogBuilder {
+	b.WithLevelIcons()
+	b.levelIcons[level] = icon
+	return b
+}
+
+// WithLevelNames overrides the labels printed for the given levels, e.g.
+// "WARNING" instead of "WARN", and can name custom levels such as a NOTICE
+// level sitting between info and warn. Levels missing from names keep their default label.
+// Labels are padded to the widest one so the message column stays aligned.
+func (b *LogBuilder) WithLevelNames(names map[zerolog.Level]string) *LogBuilder {
+	if b.levelNames == nil {
+		b.levelNames = make(map[zerolog.Level]string, len(names))
+	}
+	for level, name := range names {
+		b.levelNames[level] = name
+	}
+	return b
+}
+
+// WithShortLevels uses three-letter level labels such as "INF" and "WRN".
+func (b *LogBuilder) WithShortLevels() *LogBuilder {
+	return b.WithLevelNames(shortLevelNames)
+}
+
+// WithJSONLevelNames makes the custom level names also appear in the level
+// field of the JSON event. This sets zerolog.LevelFieldMarshalFunc, which is
+// shared by the whole process, so it only takes effect for global loggers.
+func (b *LogBuilder) WithJSONLevelNames() *LogBuilder {
+	b.jsonLevels = true
+	return b
+}
+
+// levelLabel returns the label printed for level.
+func (b *LogBuilder) levelLabel(level zerolog.Level) string {
+	if name, ok := b.levelNames[level]; ok {
+		return name
+	}
+	return strings.ToUpper(LevelString(level))
+}
+
+// levelLabelWidth returns the width of the widest level label.
+func (b *LogBuilder) levelLabelWidth() int {
+	width := 0
+	for _, level := range knownLevels {
+		if w := runewidth.StringWidth(b.levelLabel(level)); w > width {
+			width = w
+		}
+	}
+	for _, name := range b.levelNames {
+		if w := runewidth.StringWidth(name); w > width {
+			width = w
+		}
+	}
+	return width
+}
+
+// parseLevelValue resolves the level field of an event, which may hold a
+// zerolog level name, one of the custom names, or the number zerolog writes
+// for custom levels.
+func (b *LogBuilder) parseLevelValue(s string) (zerolog.Level, bool) {
+	for level, name := range b.levelNames {
+		if name == s {
+			return level, true
+		}
+	}
+	if level, err := ParseLevel(s); err == nil {
+		return level, true
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= math.MinInt8 && n <= math.MaxInt8 {
+		return zerolog.Level(n), true
+	}
+	return zerolog.NoLevel, false
+}
+
+// formatLevel returns the console formatter for the level part.
+func (b *LogBuilder) formatLevel() zerolog.Formatter {
+	width := 0
+	if len(b.levelNames) > 0 || b.aligned {
+		width = b.levelLabelWidth() + 2
+	}
+
+	icons := len(b.levelIcons) > 0 && !color.NoColor && !b.tviewCompat && isTerminal(b.writer)
+	iconWidth := 0
+	if icons {
+		for _, icon := range b.levelIcons {
+			iconWidth = max(iconWidth, runewidth.StringWidth(icon))
+		}
+	}
+
+	colors := b.scheme().Levels
+
+	return func(i any) string {
+		levelStr := fmt.Sprintf("%s", i)
+		label := strings.ToUpper(levelStr)
+		c := whiteColor
+		if level, ok := b.parseLevelValue(levelStr); ok {
+			label = b.levelLabel(level)
+			if lc, ok := colors[level]; ok {
+				c = lc
+			}
+		}
+
+		coloredLevel := padRight(c.Sprintf("[%s]", label), width)
+		if icons {
+			icon := ""
+			if level, ok := b.parseLevelValue(levelStr); ok {
+				icon = b.levelIcons[level]
+			}
+			coloredLevel = padRight(icon, iconWidth) + " " + coloredLevel
+		}
+
+		if b.tviewCompat {
+			return tview.Escape(coloredLevel)
+		}
+		return coloredLevel
+	}
+}
@@ -0,0 +1,60 @@
+package ezlog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-runewidth"
+	"github.com/rs/zerolog"
+)
+
+// enableColor turns fatih/color output on until t ends, as it is off when
+// the tests' standard output is not a terminal.
+func enableColor(t *testing.T) {
+	t.Helper()
+	noColor := color.NoColor
+	color.NoColor = false
+	t.Cleanup(func() { color.NoColor = noColor })
+}
+
+func TestWithLevelIconsOnTerminal(t *testing.T) {
+	enableColor(t)
+	term := openTerminal(t)
+	l := New().AsLocal().WithWriter(term.File).WithLevelIcons().WithLevelIcon(zerolog.WarnLevel, "!").Build()
+	l.Debug().Msg("a")
+	l.Info().Msg("b")
+	l.Warn().Msg("c")
+	l.Error().Msg("d")
+
+	lines := strings.Split(strings.TrimSpace(ansiEscape.ReplaceAllString(term.Output(), "")), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4: %q", len(lines), lines)
+	}
+	column := -1
+	for i, icon := range []string{"🐞", "ℹ", "!", "✖"} {
+		if !strings.Contains(lines[i], icon+" ") {
+			t.Errorf("line %q lacks icon %q", lines[i], icon)
+		}
+		// The double-width bug icon sets the width of the icon column.
+		level := runewidth.StringWidth(lines[i][:strings.Index(lines[i], "[")])
+		if column >= 0 && level != column {
+			t.Errorf("line %q: level at column %d, want %d", lines[i], level, column)
+		}
+		column = level
+	}
+}
+
+func TestWithLevelIconsNotInJSON(t *testing.T) {
+	enableColor(t)
+	term := openTerminal(t)
+	l := New().AsLocal().WithWriter(term.File).WithJSONOutput().WithLevelIcons().Build()
+	l.Error().Msg("failed")
+
+	got := term.Output()
+	for _, icon := range defaultLevelIcons {
+		if strings.Contains(got, icon) {
+			t.Errorf("JSON output %q contains icon %q", got, icon)
+		}
+	}
+}
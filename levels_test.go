@@ -0,0 +1,141 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// noticeLevel is a custom level between info and warn.
+const noticeLevel = zerolog.Level(10)
+
+// restoreGlobalLogger restores the global logger state changed by building
+// a global logger once t ends.
+func restoreGlobalLogger(t *testing.T) {
+	t.Helper()
+	logger, global, marshal := log.Logger, globalLogger, zerolog.LevelFieldMarshalFunc
+	t.Cleanup(func() {
+		log.Logger, globalLogger, zerolog.LevelFieldMarshalFunc = logger, global, marshal
+	})
+}
+
+func TestWithShortLevels(t *testing.T) {
+	var buf bytes.Buffer
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	l := newTestBuilder(&buf).WithShortLevels().WithTimestampFunc(fixedClock(ts)).Build()
+	l.Info().Str("k", "v").Msg("started")
+	l.Warn().Msg("slow")
+	l.Error().Msg("failed")
+
+	want := "09:26:53.000 [INF] started k=\"v\"\n" +
+		"09:26:53.000 [WRN] slow\n" +
+		"09:26:53.000 [ERR] failed\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWithLevelNames(t *testing.T) {
+	var buf bytes.Buffer
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	l := newTestBuilder(&buf).
+		WithLevelNames(map[zerolog.Level]string{zerolog.WarnLevel: "WARNING", noticeLevel: "NOTICE"}).
+		WithTimestampFunc(fixedClock(ts)).
+		Build()
+	l.Info().Msg("started")
+	l.WithLevel(noticeLevel).Msg("config reloaded")
+	l.Warn().Msg("slow")
+
+	// INFO is not renamed and keeps its default label, padded to WARNING.
+	want := "09:26:53.000 [INFO]    started\n" +
+		"09:26:53.000 [NOTICE]  config reloaded\n" +
+		"09:26:53.000 [WARNING] slow\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWithJSONLevelNames(t *testing.T) {
+	restoreGlobalLogger(t)
+
+	var buf bytes.Buffer
+	l := New().WithWriter(&buf).WithJSONOutput().
+		WithLevelNames(map[zerolog.Level]string{zerolog.WarnLevel: "WARNING"}).
+		WithJSONLevelNames().
+		Build()
+	l.Warn().Msg("slow")
+	l.Info().Msg("started")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"level":"WARNING"`) || !strings.Contains(lines[1], `"level":"info"`) {
+		t.Errorf("output = %q", buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    zerolog.Level
+		wantErr bool
+	}{
+		{in: "trace", want: zerolog.TraceLevel},
+		{in: "DEBUG", want: zerolog.DebugLevel},
+		{in: " Info ", want: zerolog.InfoLevel},
+		{in: "warn", want: zerolog.WarnLevel},
+		{in: "WARNING", want: zerolog.WarnLevel},
+		{in: "error", want: zerolog.ErrorLevel},
+		{in: "fatal", want: zerolog.FatalLevel},
+		{in: "PANIC", want: zerolog.PanicLevel},
+		{in: "disabled", want: zerolog.Disabled},
+		{in: "-1", want: zerolog.TraceLevel},
+		{in: "0", want: zerolog.DebugLevel},
+		{in: "5", want: zerolog.PanicLevel},
+		{in: "6", wantErr: true},
+		{in: "verbose", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLevelString(t *testing.T) {
+	for _, level := range knownLevels {
+		s := LevelString(level)
+		if s != strings.ToLower(s) {
+			t.Errorf("LevelString(%v) = %q, want lower case", level, s)
+		}
+		if got, err := ParseLevel(s); err != nil || got != level {
+			t.Errorf("ParseLevel(LevelString(%v)) = %v, %v", level, got, err)
+		}
+	}
+	if got := LevelString(zerolog.NoLevel); got != "" {
+		t.Errorf("LevelString(NoLevel) = %q, want empty", got)
+	}
+	if got := LevelString(noticeLevel); got != "10" {
+		t.Errorf("LevelString(10) = %q, want %q", got, "10")
+	}
+}
+
+func TestWithLevelIconsNotOnPipes(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithLevelIcons().Build()
+	l.Error().Msg("failed")
+
+	for _, icon := range defaultLevelIcons {
+		if strings.Contains(buf.String(), icon) {
+			t.Errorf("output %q contains icon %q", buf.String(), icon)
+		}
+	}
+}
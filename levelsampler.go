@@ -0,0 +1,60 @@
+package ezlog
+
+import (
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// WithSamplerPerLevel logs only 1 in N events of each level, N being the
+// rate of the level in levelRates, e.g. to keep all errors but only 1 in 10
+// info and 1 in 100 debug events:
+//
+//	WithSamplerPerLevel(map[zerolog.Level]uint32{
+//		zerolog.InfoLevel:  10,
+//		zerolog.DebugLevel: 100,
+//	})
+//
+// Levels missing from levelRates, or with a rate of 0 or 1, are all logged.
+func (b *LogBuilder) WithSamplerPerLevel(levelRates map[zerolog.Level]uint32) *LogBuilder {
+	b.levelRates = levelRates
+	return b
+}
+
+// levelSamplingWriter drops events according to the sampler of their level.
+type levelSamplingWriter struct {
+	out      io.Writer
+	samplers map[zerolog.Level]*zerolog.BasicSampler
+}
+
+// newLevelSamplingWriter creates a levelSamplingWriter writing to out.
+func newLevelSamplingWriter(out io.Writer, levelRates map[zerolog.Level]uint32) *levelSamplingWriter {
+	w := &levelSamplingWriter{out: out, samplers: make(map[zerolog.Level]*zerolog.BasicSampler)}
+	for level, n := range levelRates {
+		if n > 1 {
+			w.samplers[level] = &zerolog.BasicSampler{N: n}
+		}
+	}
+	return w
+}
+
+// Write implements io.Writer. Events written without their level are kept.
+func (w *levelSamplingWriter) Write(p []byte) (int, error) {
+	return w.out.Write(p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *levelSamplingWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if s, ok := w.samplers[level]; ok && !s.Sample(level) {
+		return len(p), nil
+	}
+	return writeLevel(w.out, level, p)
+}
+
+// writeLevel writes p to w, passing level on if w is a zerolog.LevelWriter.
+func writeLevel(w io.Writer, level zerolog.Level, p []byte) (int, error) {
+	if lw, ok := w.(zerolog.LevelWriter); ok {
+		return lw.WriteLevel(level, p)
+	}
+	return w.Write(p)
+}
@@ -0,0 +1,59 @@
+package ezlog
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestWithSamplerPerLevel(t *testing.T) {
+	c := &levelCounter{}
+	l := sampledLogger(c, func(b *LogBuilder) *LogBuilder {
+		return b.WithLevel(zerolog.DebugLevel).WithSamplerPerLevel(map[zerolog.Level]uint32{
+			zerolog.DebugLevel: 100,
+			zerolog.InfoLevel:  10,
+			zerolog.WarnLevel:  1,
+			zerolog.ErrorLevel: 0,
+		})
+	})
+	for range 1000 {
+		l.Debug().Msg("sampled")
+		l.Info().Msg("sampled")
+		l.Warn().Msg("kept")
+		l.Error().Msg("kept")
+	}
+
+	for _, tt := range []struct {
+		level zerolog.Level
+		want  int64
+	}{
+		{zerolog.DebugLevel, 10},
+		{zerolog.InfoLevel, 100},
+		{zerolog.WarnLevel, 1000},
+		{zerolog.ErrorLevel, 1000},
+	} {
+		if n := c.count(tt.level); n != tt.want {
+			t.Errorf("kept %d of 1000 %s events, want %d", n, tt.level, tt.want)
+		}
+	}
+}
+
+func TestLevelSamplingWriterKeepsUnleveledWrites(t *testing.T) {
+	c := &levelCounter{}
+	w := newLevelSamplingWriter(c, map[zerolog.Level]uint32{zerolog.InfoLevel: 1000})
+	for range 10 {
+		if n, err := w.Write([]byte("raw\n")); n != 4 || err != nil {
+			t.Fatalf("Write() = %d, %v", n, err)
+		}
+	}
+	// The first event of a sampled level is kept, the next N-1 are dropped
+	// but still reported as written.
+	for range 10 {
+		if n, err := w.WriteLevel(zerolog.InfoLevel, []byte("x\n")); n != 2 || err != nil {
+			t.Fatalf("WriteLevel() = %d, %v", n, err)
+		}
+	}
+	if n := c.count(zerolog.InfoLevel); n != 1 {
+		t.Errorf("kept %d of 10 info events at 1 in 1000, want 1", n)
+	}
+}
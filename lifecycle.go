@@ -0,0 +1,236 @@
+package ezlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// shutdownTimeout bounds how long the signal handler waits for writers to close.
+const shutdownTimeout = 5 * time.Second
+
+// signalHandler is the graceful shutdown handler, started by the first Build
+// asking for it. done is canceled once the writers are closed.
+var signalHandler struct {
+	once       sync.Once
+	ch         chan os.Signal
+	done       context.Context
+	cancelDone context.CancelFunc
+}
+
+func init() {
+	signalHandler.ch = make(chan os.Signal, 1)
+	signalHandler.done, signalHandler.cancelDone = context.WithCancel(context.Background())
+}
+
+// closerRegistry tracks the writers that must be closed on Shutdown.
+var closerRegistry = struct {
+	sync.Mutex
+	names   []string
+	closers map[string]io.Closer
+}{closers: make(map[string]io.Closer)}
+
+// RegisterCloser registers c to be closed by Shutdown under the given name.
+// Registering a second closer under the same name replaces the first one.
+func RegisterCloser(name string, c io.Closer) {
+	closerRegistry.Lock()
+	defer closerRegistry.Unlock()
+
+	if _, ok := closerRegistry.closers[name]; !ok {
+		closerRegistry.names = append(closerRegistry.names, name)
+	}
+	closerRegistry.closers[name] = c
+}
+
+// Shutdown closes every registered writer in registration order, giving up
+// once ctx is done. It returns the joined errors of all failed closers.
+func Shutdown(ctx context.Context) error {
+	closerRegistry.Lock()
+	names := closerRegistry.names
+	closers := closerRegistry.closers
+	closerRegistry.names = nil
+	closerRegistry.closers = make(map[string]io.Closer)
+	closerRegistry.Unlock()
+
+	var errs []error
+	for _, name := range names {
+		if err := closeWithContext(ctx, closers[name]); err != nil {
+			errs = append(errs, fmt.Errorf("ezlog: close %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes every registered writer that buffers events, then closes
+// them all like Shutdown, giving up once ctx is done. The global logger is
+// then replaced by one writing to the standard error, so events logged after
+// Close are not lost to closed writers. Calling Close again only closes the
+// writers built since. It returns the joined errors of all failed flushes and
+// closers.
+func Close(ctx context.Context) error {
+	closerRegistry.Lock()
+	names := append([]string(nil), closerRegistry.names...)
+	closers := make([]io.Closer, len(names))
+	for i, name := range names {
+		closers[i] = closerRegistry.closers[name]
+	}
+	closerRegistry.Unlock()
+
+	var errs []error
+	for i, c := range closers {
+		if err := flushWithContext(ctx, c); err != nil {
+			errs = append(errs, fmt.Errorf("ezlog: flush %s: %w", names[i], err))
+		}
+	}
+	if err := Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	fallback := zerolog.New(os.Stderr).With().Timestamp().Logger()
+	log.Logger = fallback
+	globalLogger = &fallback
+	return errors.Join(errs...)
+}
+
+// flushWithContext flushes w if it has a Flush method, returning early with
+// ctx.Err() when ctx is done before Flush returns.
+func flushWithContext(ctx context.Context, w any) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return runWithContext(ctx, f.Flush)
+	case interface{ Flush() }:
+		return runWithContext(ctx, func() error {
+			f.Flush()
+			return nil
+		})
+	default:
+		return nil
+	}
+}
+
+// closeWithContext calls c.Close, returning early with ctx.Err() when ctx is
+// done before Close returns.
+func closeWithContext(ctx context.Context, c io.Closer) error {
+	return runWithContext(ctx, c.Close)
+}
+
+// runWithContext calls fn, returning early with ctx.Err() when ctx is done
+// before fn returns.
+func runWithContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// registerWriter registers w with Shutdown if it needs closing, replacing any
+// earlier registration of the same writer so it is only closed once.
+// The standard streams are never closed.
+func registerWriter(name string, w io.Writer) {
+	if w == os.Stdout || w == os.Stderr {
+		return
+	}
+	c, ok := w.(io.Closer)
+	if !ok {
+		return
+	}
+	if !reflect.TypeOf(c).Comparable() {
+		RegisterCloser(name, c)
+		return
+	}
+
+	closerRegistry.Lock()
+	for i, n := range closerRegistry.names {
+		if closerRegistry.closers[n] == c {
+			delete(closerRegistry.closers, n)
+			closerRegistry.names = append(closerRegistry.names[:i:i], closerRegistry.names[i+1:]...)
+			break
+		}
+	}
+	closerRegistry.Unlock()
+
+	RegisterCloser(name, c)
+}
+
+// WithGracefulShutdown closes all registered writers when the process receives
+// one of sigs, SIGTERM and SIGINT when none is given, then restores the
+// default behavior of the signal and raises it again, so the process ends as
+// it would have without the handler; signals Go ignores by default, such as
+// SIGUSR1, are then ignored. Closing is bounded by a 5 second
+// timeout. The signals of every builder asking for a graceful shutdown are
+// merged. Other signal handlers of the application are left untouched, but
+// the application is no longer notified of the signal received once the
+// writers are closed.
+func (b *LogBuilder) WithGracefulShutdown(sigs ...os.Signal) *LogBuilder {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGTERM, syscall.SIGINT}
+	}
+	b.shutdownSignals = sigs
+	return b
+}
+
+// ShutdownContext returns a context canceled once a WithGracefulShutdown
+// signal was received and the writers closed, right before the signal is
+// raised again, e.g. for the application to stop accepting work:
+//
+//	go func() {
+//		<-ezlog.ShutdownContext().Done()
+//		server.Close()
+//	}()
+func ShutdownContext() context.Context {
+	return signalHandler.done
+}
+
+// installSignalHandler makes the graceful shutdown handler listen to sigs,
+// starting it if needed.
+func installSignalHandler(sigs []os.Signal) {
+	signal.Notify(signalHandler.ch, sigs...)
+	signalHandler.once.Do(func() {
+		go func() {
+			sig := <-signalHandler.ch
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			if err := Shutdown(ctx); err != nil {
+				diag(zerolog.ErrorLevel, "shutdown", "shutdown on %s: %v", sig, err)
+			}
+			cancel()
+
+			signal.Stop(signalHandler.ch)
+			signalHandler.cancelDone()
+			signal.Reset(sig)
+			if err := raise(sig); err != nil {
+				diag(zerolog.ErrorLevel, "shutdown", "raise %s: %v", sig, err)
+				os.Exit(1)
+			}
+		}()
+	})
+}
+
+// raise sends sig to the current process.
+func raise(sig os.Signal) error {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		return err
+	}
+	return p.Signal(sig)
+}
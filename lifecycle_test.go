@@ -0,0 +1,294 @@
+package ezlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// closeRecorder records whether it was closed.
+type closeRecorder struct {
+	bytes.Buffer
+	closed chan struct{}
+}
+
+func newCloseRecorder() *closeRecorder {
+	return &closeRecorder{closed: make(chan struct{})}
+}
+
+// Close implements io.Closer.
+func (c *closeRecorder) Close() error {
+	close(c.closed)
+	return nil
+}
+
+// closeFunc is an io.Closer calling itself.
+type closeFunc func() error
+
+// Close implements io.Closer.
+func (f closeFunc) Close() error { return f() }
+
+func TestShutdownClosesInRegistrationOrder(t *testing.T) {
+	_ = Shutdown(context.Background())
+
+	var closed []string
+	for _, name := range []string{"a", "b", "c"} {
+		RegisterCloser(name, closeFunc(func() error {
+			closed = append(closed, name)
+			return nil
+		}))
+	}
+	RegisterCloser("b", closeFunc(func() error {
+		closed = append(closed, "b2")
+		return nil
+	}))
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(closed, ","), "a,b2,c"; got != want {
+		t.Errorf("closed %s, want %s", got, want)
+	}
+	if err := Shutdown(context.Background()); err != nil || len(closed) != 3 {
+		t.Errorf("second Shutdown closed %v, err %v", closed[3:], err)
+	}
+}
+
+func TestShutdownJoinsErrors(t *testing.T) {
+	_ = Shutdown(context.Background())
+
+	errA, errB := errors.New("disk full"), errors.New("broken pipe")
+	RegisterCloser("a", closeFunc(func() error { return errA }))
+	RegisterCloser("ok", closeFunc(func() error { return nil }))
+	RegisterCloser("b", closeFunc(func() error { return errB }))
+
+	err := Shutdown(context.Background())
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("Shutdown() = %v, want both errors", err)
+	}
+	if !strings.Contains(err.Error(), "ezlog: close a: disk full") {
+		t.Errorf("Shutdown() = %v, want the closer name", err)
+	}
+}
+
+func TestShutdownGivesUpAtDeadline(t *testing.T) {
+	_ = Shutdown(context.Background())
+
+	block := make(chan struct{})
+	defer close(block)
+	RegisterCloser("stuck", closeFunc(func() error {
+		<-block
+		return nil
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestBuildRegistersClosableWriter(t *testing.T) {
+	_ = Shutdown(context.Background())
+
+	w := newCloseRecorder()
+	New().AsLocal().WithTag("first").WithWriter(w).Build()
+	// The same writer built again is only registered, and closed, once.
+	New().AsLocal().WithTag("second").WithWriter(w).Build()
+	New().AsLocal().WithTag("stdout").WithWriter(os.Stdout).Build()
+
+	closerRegistry.Lock()
+	names := append([]string(nil), closerRegistry.names...)
+	closerRegistry.Unlock()
+	if len(names) != 1 || names[0] != "second" {
+		t.Errorf("registered %v, want [second]", names)
+	}
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.closed:
+	default:
+		t.Error("writer not closed")
+	}
+}
+
+func TestGormLoggerRegistersWriter(t *testing.T) {
+	_ = Shutdown(context.Background())
+
+	w := newCloseRecorder()
+	NewGormLogger().WithWriter(w).Build()
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.closed:
+	default:
+		t.Error("GormLogger writer not closed")
+	}
+}
+
+// lifecycleRecorder records its flushes and closes, along with the bytes
+// written before each, in a shared log.
+type lifecycleRecorder struct {
+	name string
+	log  *[]string
+	mu   *sync.Mutex
+	n    int
+}
+
+func (r *lifecycleRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.n += len(p)
+	return len(p), nil
+}
+
+func (r *lifecycleRecorder) record(op string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*r.log = append(*r.log, fmt.Sprintf("%s %s written=%t", op, r.name, r.n > 0))
+}
+
+// Flush records the flush.
+func (r *lifecycleRecorder) Flush() error {
+	r.record("flush")
+	return nil
+}
+
+// Close implements io.Closer.
+func (r *lifecycleRecorder) Close() error {
+	r.record("close")
+	return nil
+}
+
+// captureStderr redirects os.Stderr to a file for the rest of the test and
+// returns a function reading what was written to it.
+func captureStderr(t *testing.T) func() string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = f
+	t.Cleanup(func() {
+		os.Stderr = stderr
+		f.Close()
+	})
+	return func() string {
+		data, err := os.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+}
+
+func TestCloseFlushesThenClosesEveryWriter(t *testing.T) {
+	_ = Shutdown(context.Background())
+	restoreGlobalLogger(t)
+
+	var (
+		mu  sync.Mutex
+		ops []string
+	)
+	a := &lifecycleRecorder{name: "a", log: &ops, mu: &mu}
+	buffered := &lifecycleRecorder{name: "buffered", log: &ops, mu: &mu}
+	la := New().AsLocal().WithTag("a").WithWriter(a).WithJSONOutput().Build()
+	lb := New().AsLocal().WithTag("buffered").WithWriter(buffered).WithJSONOutput().WithOutputBuffer(4096).Build()
+	New().WithTag("global").WithWriter(&bytes.Buffer{}).WithJSONOutput().Build()
+	la.Info().Msg("one")
+	lb.Info().Msg("two")
+
+	readStderr := captureStderr(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The buffered output is flushed into its writer before any writer is
+	// closed, and closes it.
+	want := []string{
+		"flush a written=true",
+		"close a written=true",
+		"close buffered written=true",
+	}
+	if got := strings.Join(ops, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("operations:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+
+	// The global logger writes to the standard error from now on.
+	log.Info().Msg("late event")
+	if got := readStderr(); !strings.Contains(got, "late event") {
+		t.Errorf("stderr = %q, want the late event", got)
+	}
+
+	// Closing again is safe and closes nothing.
+	if err := Close(ctx); err != nil || len(ops) != len(want) {
+		t.Errorf("second Close: err = %v, operations %v", err, ops[len(want):])
+	}
+}
+
+// stuckFlusher never returns from Flush until released.
+type stuckFlusher struct {
+	closeRecorder
+	release chan struct{}
+}
+
+// Flush blocks until the flusher is released.
+func (s *stuckFlusher) Flush() { <-s.release }
+
+func TestCloseGivesUpAtDeadline(t *testing.T) {
+	_ = Shutdown(context.Background())
+	restoreGlobalLogger(t)
+	captureStderr(t)
+
+	s := &stuckFlusher{closeRecorder: *newCloseRecorder(), release: make(chan struct{})}
+	defer close(s.release)
+	RegisterCloser("stuck", s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := Close(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "ezlog: flush stuck") {
+		t.Errorf("Close() = %v, want the flush deadline", err)
+	}
+}
+
+// failingFlusher fails to flush.
+type failingFlusher struct{ closeRecorder }
+
+// Flush always fails.
+func (*failingFlusher) Flush() error { return errors.New("broken pipe") }
+
+func TestCloseJoinsErrors(t *testing.T) {
+	_ = Shutdown(context.Background())
+	restoreGlobalLogger(t)
+	captureStderr(t)
+
+	errA := errors.New("disk full")
+	RegisterCloser("failing", closeFunc(func() error { return errA }))
+	f := &failingFlusher{closeRecorder: *newCloseRecorder()}
+	RegisterCloser("flaky", f)
+
+	err := Close(context.Background())
+	if !errors.Is(err, errA) || !strings.Contains(err.Error(), "ezlog: flush flaky: broken pipe") {
+		t.Errorf("Close() = %v, want the flush and close errors", err)
+	}
+	select {
+	case <-f.closed:
+	default:
+		t.Error("writer not closed after its flush failed")
+	}
+}
@@ -0,0 +1,78 @@
+//go:build unix
+
+package ezlog
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// markerCloser appends to a file when closed.
+type markerCloser struct{ path string }
+
+func (m markerCloser) Write(p []byte) (int, error) { return len(p), nil }
+
+// Close implements io.Closer.
+func (m markerCloser) Close() error {
+	f, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString("writer closed\n")
+	return err
+}
+
+// TestGracefulShutdownHelper is run in a subprocess by
+// TestGracefulShutdownClosesWritersAndReraises, as the signal ends it.
+func TestGracefulShutdownHelper(t *testing.T) {
+	marker := os.Getenv("EZLOG_GRACEFUL_MARKER")
+	if marker == "" {
+		t.Skip("only run by TestGracefulShutdownClosesWritersAndReraises")
+	}
+	appCh := make(chan os.Signal, 1)
+	signal.Notify(appCh, syscall.SIGHUP)
+
+	New().AsLocal().WithTag("graceful").WithWriter(markerCloser{marker}).WithGracefulShutdown(syscall.SIGUSR1).Build()
+	New().AsLocal().WithTag("graceful2").WithWriter(markerCloser{marker + ".2"}).WithGracefulShutdown().Build()
+
+	// The handlers of the application keep working.
+	syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	select {
+	case <-appCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("application signal handler did not receive the signal")
+	}
+
+	// A default signal of the second builder closes the writer of the first
+	// one, then ends the process.
+	syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	time.Sleep(5 * time.Second)
+	t.Fatal("process still running after the signal")
+}
+
+func TestGracefulShutdownClosesWritersAndReraises(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "marker")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestGracefulShutdownHelper$")
+	cmd.Env = append(os.Environ(), "EZLOG_GRACEFUL_MARKER="+marker)
+	out, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("err = %v, want the process killed by SIGTERM:\n%s", err, out)
+	}
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); !ok || !ws.Signaled() || ws.Signal() != syscall.SIGTERM {
+		t.Fatalf("process ended with %v, want SIGTERM:\n%s", exitErr, out)
+	}
+	for _, path := range []string{marker, marker + ".2"} {
+		if data, err := os.ReadFile(path); err != nil || string(data) != "writer closed\n" {
+			t.Errorf("%s = %q, %v, want the writer closed once", filepath.Base(path), data, err)
+		}
+	}
+}
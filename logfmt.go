@@ -0,0 +1,161 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// defaultLogfmtDepth is how deep nested objects are flattened into dotted keys.
+const defaultLogfmtDepth = 3
+
+// logfmtTimeKey is the key of the event time in logfmt lines.
+const logfmtTimeKey = "ts"
+
+// WithLogfmtOutput writes logfmt lines (key=value pairs) instead of colored
+// console output. The time, as "ts" in RFC 3339 with nanoseconds, level, tag
+// and message come first, followed by the other fields in key order. Nested
+// objects are flattened with dotted keys. WithTimeZone applies to the time.
+func (b *LogBuilder) WithLogfmtOutput() *LogBuilder {
+	b.logfmt = true
+	return b
+}
+
+// logfmtWriter re-encodes zerolog's JSON events as logfmt.
+type logfmtWriter struct {
+	out      io.Writer
+	tag      string
+	loc      *time.Location
+	maxDepth int
+}
+
+// newLogfmtWriter creates a logfmtWriter writing to out, rendering times in
+// loc when set.
+func newLogfmtWriter(out io.Writer, tag string, loc *time.Location) *logfmtWriter {
+	return &logfmtWriter{out: out, tag: tag, loc: loc, maxDepth: defaultLogfmtDepth}
+}
+
+// Write implements io.Writer.
+func (w *logfmtWriter) Write(p []byte) (int, error) {
+	var evt map[string]any
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if v, ok := evt[zerolog.TimestampFieldName]; ok {
+		if t, ok := parseEventTime(v); ok {
+			if w.loc != nil {
+				t = t.In(w.loc)
+			}
+			v = t.Format(time.RFC3339Nano)
+		}
+		w.writePair(&buf, logfmtTimeKey, v, 0)
+		delete(evt, zerolog.TimestampFieldName)
+	}
+	if v, ok := evt[zerolog.LevelFieldName]; ok {
+		w.writePair(&buf, zerolog.LevelFieldName, v, 0)
+		delete(evt, zerolog.LevelFieldName)
+	}
+	if w.tag != "" {
+		w.writePair(&buf, "tag", w.tag, 0)
+	}
+	if v, ok := evt[zerolog.MessageFieldName]; ok {
+		w.writePair(&buf, zerolog.MessageFieldName, v, 0)
+		delete(evt, zerolog.MessageFieldName)
+	}
+
+	keys := make([]string, 0, len(evt))
+	for key := range evt {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		w.writePair(&buf, key, evt[key], 0)
+	}
+	buf.WriteByte('\n')
+
+	if _, err := buf.WriteTo(w.out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writePair appends key=value to buf, flattening nested objects.
+func (w *logfmtWriter) writePair(buf *bytes.Buffer, key string, value any, depth int) {
+	if m, ok := value.(map[string]any); ok && depth < w.maxDepth {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			w.writePair(buf, key+"."+k, m[k], depth+1)
+		}
+		return
+	}
+
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(logfmtKey(key))
+	buf.WriteByte('=')
+	buf.WriteString(logfmtValue(value))
+}
+
+// logfmtKey replaces the characters not allowed in a logfmt key.
+func logfmtKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtValue renders value, quoting it only when needed.
+func logfmtValue(value any) string {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		s = v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			s = fmt.Sprint(v)
+		} else {
+			s = string(b)
+		}
+	}
+	if logfmtNeedsQuote(s) {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+// logfmtNeedsQuote reports whether s must be quoted as a logfmt value.
+func logfmtNeedsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
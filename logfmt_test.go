@@ -0,0 +1,78 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLogfmtOutput(t *testing.T) {
+	var buf bytes.Buffer
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 500_000_000, time.UTC)
+	l := New().AsLocal().WithWriter(&buf).WithLogfmtOutput().WithTag("api").
+		WithUTC().WithTimestampFunc(fixedClock(ts)).Build()
+	l.Info().Str("user", "ann lee").Int("n", 3).Dict("req", zerolog.Dict().Str("method", "GET")).Msg("done")
+
+	want := `ts=2025-03-14T09:26:53.5Z level=info tag=api message=done n=3 req.method=GET user="ann lee"` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestLogfmtValue(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{"plain", "plain"},
+		{"", `""`},
+		{"a b", `"a b"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"k=v", `"k=v"`},
+		{nil, "null"},
+		{true, "true"},
+		{[]any{"a", "b"}, `"[\"a\",\"b\"]"`},
+	}
+	for _, tt := range tests {
+		if got := logfmtValue(tt.in); got != tt.want {
+			t.Errorf("logfmtValue(%#v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLogfmtNestedDepth(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithLogfmtOutput().Build()
+	l.Info().
+		Dict("a", zerolog.Dict().Str("z", "1").Dict("b", zerolog.Dict().Dict("c", zerolog.Dict().Dict("d", zerolog.Dict().Str("e", "deep"))))).
+		Msg("nested")
+
+	// Three levels of objects are flattened, deeper ones are kept as JSON.
+	if got, want := buf.String(), `a.b.c.d="{\"e\":\"deep\"}" a.z=1`; !strings.Contains(got, want) {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestLogfmtSpecialCharacters(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithLogfmtOutput().Build()
+	l.Info().
+		Str("path", `C:\logs`).
+		Str("multi", "a\nb").
+		Str("unicode", "héllo").
+		Str("odd key=x", "v").
+		Msg("special")
+
+	got := buf.String()
+	for _, want := range []string{`path="C:\\logs"`, `multi="a\nb"`, `unicode=héllo`, `odd_key_x=v`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output %q lacks %q", got, want)
+		}
+	}
+	if strings.Count(got, "\n") != 1 {
+		t.Errorf("output %q spans several lines", got)
+	}
+}
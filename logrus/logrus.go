@@ -0,0 +1,78 @@
+// Package logrus forwards logrus entries to ezlog. It is a separate package
+// to keep logrus out of programs that do not import it.
+//
+// The examples import it as ezlogrus, as the package shares its name with the
+// library it adapts:
+//
+//	import ezlogrus "github.com/ezydark/ezlog/logrus"
+package logrus
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"github.com/sirupsen/logrus"
+)
+
+// LogrusHook is a logrus.Hook forwarding logrus entries to a zerolog logger,
+// fields included, so a logrus code base can move to ezlog incrementally.
+// logrus still writes entries to its own output, which should be discarded
+// to only keep ezlog's:
+//
+//	logrus.SetOutput(io.Discard)
+//	logrus.AddHook(ezlogrus.NewLogrusHook(log))
+//
+// Entries are logged with WithLevel, so panic and fatal entries, both
+// logged at fatal level, panic and exit through logrus only.
+// It should be created using NewLogrusHook.
+type LogrusHook struct {
+	zl *zerolog.Logger
+}
+
+// NewLogrusHook creates a LogrusHook writing to l.
+func NewLogrusHook(l *zerolog.Logger) *LogrusHook {
+	return &LogrusHook{zl: l}
+}
+
+// Levels implements logrus.Hook.
+func (h *LogrusHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook.
+func (h *LogrusHook) Fire(entry *logrus.Entry) error {
+	e := h.zl.WithLevel(logrusLevel(entry.Level))
+	if !e.Enabled() {
+		return nil
+	}
+	if entry.HasCaller() {
+		e = e.Str(zerolog.CallerFieldName, fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line))
+	}
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok && k == logrus.ErrorKey {
+			e = e.Err(err)
+			continue
+		}
+		e = e.Interface(k, v)
+	}
+	e.Msg(entry.Message)
+	return nil
+}
+
+// logrusLevel returns the zerolog level of a logrus level.
+func logrusLevel(level logrus.Level) zerolog.Level {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return zerolog.FatalLevel
+	case logrus.ErrorLevel:
+		return zerolog.ErrorLevel
+	case logrus.WarnLevel:
+		return zerolog.WarnLevel
+	case logrus.InfoLevel:
+		return zerolog.InfoLevel
+	case logrus.DebugLevel:
+		return zerolog.DebugLevel
+	default:
+		return zerolog.TraceLevel
+	}
+}
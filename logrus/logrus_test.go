@@ -0,0 +1,90 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/sirupsen/logrus"
+)
+
+// decodeEvents decodes the JSON events of buf.
+func decodeEvents(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var evt map[string]any
+		if err := json.Unmarshal([]byte(line), &evt); err != nil {
+			t.Fatalf("invalid event %q: %v", line, err)
+		}
+		events = append(events, evt)
+	}
+	return events
+}
+
+func TestLogrusHook(t *testing.T) {
+	var buf bytes.Buffer
+	zl := zerolog.New(&buf).Level(zerolog.DebugLevel)
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	l.SetLevel(logrus.TraceLevel)
+	l.AddHook(NewLogrusHook(&zl))
+
+	l.Trace("below the zerolog level")
+	l.WithFields(logrus.Fields{"user": "ada", "id": 7}).Info("login")
+	l.WithError(errors.New("boom")).Error("failed")
+	func() {
+		defer func() { recover() }()
+		l.Panic("panicked")
+	}()
+
+	events := decodeEvents(t, &buf)
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %s", len(events), buf.String())
+	}
+	if e := events[0]; e["level"] != "info" || e["message"] != "login" || e["user"] != "ada" || e["id"] != float64(7) {
+		t.Errorf("info event = %v", e)
+	}
+	if e := events[1]; e["level"] != "error" || e["error"] != "boom" {
+		t.Errorf("error event = %v", e)
+	}
+	if e := events[2]; e["level"] != "fatal" || e["message"] != "panicked" {
+		t.Errorf("panic event = %v", e)
+	}
+}
+
+func TestLogrusHookCaller(t *testing.T) {
+	var buf bytes.Buffer
+	zl := zerolog.New(&buf)
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	l.SetReportCaller(true)
+	l.AddHook(NewLogrusHook(&zl))
+
+	l.Warn("called")
+	caller, _ := decodeEvents(t, &buf)[0]["caller"].(string)
+	if !strings.Contains(caller, "logrus_test.go:") {
+		t.Errorf("caller = %q, want this file", caller)
+	}
+}
+
+func TestLogrusLevel(t *testing.T) {
+	want := map[logrus.Level]zerolog.Level{
+		logrus.PanicLevel: zerolog.FatalLevel,
+		logrus.FatalLevel: zerolog.FatalLevel,
+		logrus.ErrorLevel: zerolog.ErrorLevel,
+		logrus.WarnLevel:  zerolog.WarnLevel,
+		logrus.InfoLevel:  zerolog.InfoLevel,
+		logrus.DebugLevel: zerolog.DebugLevel,
+		logrus.TraceLevel: zerolog.TraceLevel,
+	}
+	for from, to := range want {
+		if got := logrusLevel(from); got != to {
+			t.Errorf("logrusLevel(%v) = %v, want %v", from, got, to)
+		}
+	}
+}
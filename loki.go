@@ -0,0 +1,201 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// LokiOption configures a LokiWriter.
+type LokiOption func(*LokiWriter)
+
+// WithLokiBatchSize sets the maximum number of events per push, 100 by default.
+func WithLokiBatchSize(n int) LokiOption {
+	return func(w *LokiWriter) {
+		w.batchSize = n
+	}
+}
+
+// WithLokiBatchWait sets how long events wait for a batch to fill, 1s by default.
+func WithLokiBatchWait(d time.Duration) LokiOption {
+	return func(w *LokiWriter) {
+		w.batchWait = d
+	}
+}
+
+// WithLokiQueueSize sets how many events may wait for delivery before new
+// ones are dropped, 10000 by default.
+func WithLokiQueueSize(n int) LokiOption {
+	return func(w *LokiWriter) {
+		w.queueSize = n
+	}
+}
+
+// WithLokiRetry sets the number of retries of a failed push and the initial
+// backoff, doubled after every attempt.
+func WithLokiRetry(maxRetries int, backoff time.Duration) LokiOption {
+	return func(w *LokiWriter) {
+		w.retry = retryPolicy{maxRetries: maxRetries, backoff: backoff}
+	}
+}
+
+// WithLokiHTTPClient sets the HTTP client used to push events.
+func WithLokiHTTPClient(c *http.Client) LokiOption {
+	return func(w *LokiWriter) {
+		w.client = c
+	}
+}
+
+// WithLokiHeader adds a header, such as X-Scope-OrgID, to every push.
+func WithLokiHeader(key, value string) LokiOption {
+	return func(w *LokiWriter) {
+		w.header.Add(key, value)
+	}
+}
+
+// LokiWriter pushes zerolog JSON events to Grafana Loki in batches using the
+// JSON push API, or the protobuf one with WithLokiProtobuf. Each event goes to the stream made of the configured labels
+// plus its level. It should be created using NewLokiWriter.
+type LokiWriter struct {
+	url       string
+	labels    map[string]string
+	client    *http.Client
+	header    http.Header
+	retry     retryPolicy
+	batchSize int
+	batchWait time.Duration
+	queueSize int
+	protobuf  bool
+
+	batcher *batcher
+	failed  atomic.Uint64
+}
+
+// NewLokiWriter creates a LokiWriter pushing to the Loki server at url.
+// The push path /loki/api/v1/push is appended unless url already ends with it.
+func NewLokiWriter(url string, labels map[string]string, opts ...LokiOption) *LokiWriter {
+	if !strings.HasSuffix(url, "/loki/api/v1/push") {
+		url = strings.TrimSuffix(url, "/") + "/loki/api/v1/push"
+	}
+
+	w := &LokiWriter{
+		url:       url,
+		labels:    maps.Clone(labels),
+		client:    &http.Client{Timeout: 10 * time.Second},
+		header:    http.Header{"Content-Type": {"application/json"}},
+		retry:     retryPolicy{maxRetries: 5, backoff: 500 * time.Millisecond},
+		batchSize: 100,
+		batchWait: time.Second,
+		queueSize: 10000,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	w.batcher = newBatcher(w.queueSize, w.batchSize, w.batchWait, w.push)
+	return w
+}
+
+// Write implements io.Writer. It never blocks; events are dropped when the
+// queue is full.
+func (w *LokiWriter) Write(p []byte) (int, error) {
+	if err := w.batcher.add(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close pushes the remaining events and stops the writer.
+func (w *LokiWriter) Close() error {
+	w.batcher.close()
+	return nil
+}
+
+// Dropped returns the number of events dropped because the queue was full or
+// because their push failed after all retries.
+func (w *LokiWriter) Dropped() uint64 {
+	return w.batcher.dropped.Load() + w.failed.Load()
+}
+
+// lokiStream is a Loki stream in the push API.
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// push sends batch to Loki, grouping events by level.
+func (w *LokiWriter) push(batch []batchItem) {
+	streams := make(map[string]*lokiStream)
+	var order []string
+
+	for _, item := range batch {
+		level := eventLevel(item.p)
+		s, ok := streams[level]
+		if !ok {
+			labels := maps.Clone(w.labels)
+			if labels == nil {
+				labels = make(map[string]string, 1)
+			}
+			labels["level"] = level
+			s = &lokiStream{Stream: labels}
+			streams[level] = s
+			order = append(order, level)
+		}
+		line := string(bytes.TrimRight(item.p, "\n"))
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(item.at.UnixNano(), 10), line})
+	}
+
+	payload := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, level := range order {
+		payload.Streams = append(payload.Streams, streams[level])
+	}
+
+	var body []byte
+	var err error
+	if w.protobuf {
+		body = snappyEncode(encodeLokiProtobuf(payload.Streams))
+	} else {
+		body, err = json.Marshal(payload)
+	}
+	if err == nil {
+		err = w.retry.post(w.client, w.url, w.header, body)
+	}
+	if err != nil {
+		w.failed.Add(uint64(len(batch)))
+		diag(zerolog.ErrorLevel, "loki", "loki push failed, %d events lost: %v", len(batch), err)
+	}
+}
+
+// eventLevel returns the level of the JSON event p, "unknown" if it has none.
+func eventLevel(p []byte) string {
+	var evt struct {
+		Level string `json:"level"`
+	}
+	if zerolog.LevelFieldName != "level" {
+		var m map[string]any
+		if json.Unmarshal(p, &m) == nil {
+			if s, ok := m[zerolog.LevelFieldName].(string); ok {
+				return s
+			}
+		}
+		return "unknown"
+	}
+	if json.Unmarshal(p, &evt) != nil || evt.Level == "" {
+		return "unknown"
+	}
+	return evt.Level
+}
+
+// QueueDepth returns the number of events waiting to be sent.
+func (w *LokiWriter) QueueDepth() int {
+	return len(w.batcher.queue)
+}
@@ -0,0 +1,228 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+)
+
+// lokiServer records the bodies pushed to it.
+type lokiServer struct {
+	*httptest.Server
+	mu          sync.Mutex
+	bodies      [][]byte
+	contentType string
+}
+
+// newLokiServer starts a lokiServer answering with statuses in turn, then
+// with 204 No Content.
+func newLokiServer(t *testing.T, statuses ...int) *lokiServer {
+	s := &lokiServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.bodies = append(s.bodies, body)
+		s.contentType = r.Header.Get("Content-Type")
+		status := http.StatusNoContent
+		if len(s.bodies) <= len(statuses) {
+			status = statuses[len(s.bodies)-1]
+		}
+		s.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestLokiWriterJSON(t *testing.T) {
+	s := newLokiServer(t)
+	w := NewLokiWriter(s.URL, map[string]string{"app": "api"})
+	w.Write([]byte(`{"level":"info","message":"a"}` + "\n"))
+	w.Write([]byte(`{"level":"error","message":"b"}` + "\n"))
+	w.Close()
+
+	if len(s.bodies) != 1 {
+		t.Fatalf("got %d pushes, want 1", len(s.bodies))
+	}
+	var payload struct {
+		Streams []lokiStream `json:"streams"`
+	}
+	if err := json.Unmarshal(s.bodies[0], &payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Streams) != 2 {
+		t.Fatalf("got %d streams, want 2", len(payload.Streams))
+	}
+	for i, level := range []string{"info", "error"} {
+		st := payload.Streams[i]
+		if st.Stream["app"] != "api" || st.Stream["level"] != level || len(st.Values) != 1 {
+			t.Errorf("stream %d = %+v", i, st)
+		}
+	}
+}
+
+func TestLokiWriterBatchSize(t *testing.T) {
+	s := newLokiServer(t)
+	w := NewLokiWriter(s.URL, nil, WithLokiBatchSize(2), WithLokiBatchWait(time.Hour))
+	for range 5 {
+		w.Write([]byte(`{"level":"info","message":"a"}` + "\n"))
+	}
+	w.Close()
+
+	var sizes []int
+	for _, body := range s.bodies {
+		var payload struct {
+			Streams []lokiStream `json:"streams"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(payload.Streams[0].Values))
+	}
+	if fmt.Sprint(sizes) != "[2 2 1]" {
+		t.Errorf("batch sizes = %v, want [2 2 1]", sizes)
+	}
+}
+
+func TestLokiWriterRetries(t *testing.T) {
+	s := newLokiServer(t, http.StatusTooManyRequests, http.StatusServiceUnavailable)
+	w := NewLokiWriter(s.URL, nil, WithLokiRetry(3, time.Millisecond))
+	w.Write([]byte(`{"level":"info","message":"a"}` + "\n"))
+	w.Close()
+
+	if len(s.bodies) != 3 || !bytes.Equal(s.bodies[0], s.bodies[2]) {
+		t.Errorf("got %d pushes, want the batch sent 3 times", len(s.bodies))
+	}
+	if n := w.Dropped(); n != 0 {
+		t.Errorf("Dropped() = %d, want 0", n)
+	}
+}
+
+func TestLokiWriterGivesUp(t *testing.T) {
+	s := newLokiServer(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	w := NewLokiWriter(s.URL, nil, WithLokiRetry(1, time.Millisecond))
+	w.Write([]byte(`{"level":"info","message":"a"}` + "\n"))
+	w.Write([]byte(`{"level":"info","message":"b"}` + "\n"))
+	w.Close()
+
+	if len(s.bodies) != 2 {
+		t.Errorf("got %d pushes, want 2 with one retry", len(s.bodies))
+	}
+	if n := w.Dropped(); n != 2 {
+		t.Errorf("Dropped() = %d, want 2", n)
+	}
+}
+
+func TestLokiWriterNoRetryOnClientError(t *testing.T) {
+	s := newLokiServer(t, http.StatusBadRequest)
+	w := NewLokiWriter(s.URL, nil, WithLokiRetry(3, time.Millisecond))
+	w.Write([]byte(`{"level":"info","message":"a"}` + "\n"))
+	w.Close()
+
+	if len(s.bodies) != 1 || w.Dropped() != 1 {
+		t.Errorf("got %d pushes and %d drops, want 1 and 1", len(s.bodies), w.Dropped())
+	}
+}
+
+func TestLokiWriterDropsWhenQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+
+	w := NewLokiWriter(srv.URL, nil, WithLokiQueueSize(1), WithLokiBatchSize(1))
+	for range 20 {
+		w.Write([]byte(`{"level":"info","message":"a"}` + "\n"))
+	}
+	close(release)
+	w.Close()
+
+	if n := w.Dropped(); n < 17 {
+		t.Errorf("Dropped() = %d, want at least 17 of 20 with room for 3", n)
+	}
+}
+
+func TestLokiWriterProtobuf(t *testing.T) {
+	s := newLokiServer(t)
+	w := NewLokiWriter(s.URL, map[string]string{"app": "api"}, WithLokiProtobuf())
+	line := `{"level":"info","message":"` + strings.Repeat("compressible ", 20) + `"}`
+	w.Write([]byte(line + "\n"))
+	w.Close()
+
+	if len(s.bodies) != 1 {
+		t.Fatalf("got %d pushes, want 1", len(s.bodies))
+	}
+	if s.contentType != "application/x-protobuf" {
+		t.Errorf("Content-Type = %q", s.contentType)
+	}
+	raw, err := snappy.Decode(nil, s.bodies[0])
+	if err != nil {
+		t.Fatalf("invalid snappy body: %v", err)
+	}
+	if len(s.bodies[0]) >= len(raw) {
+		t.Errorf("body of %d bytes not compressed from %d", len(s.bodies[0]), len(raw))
+	}
+
+	stream := protoField(t, raw, 1)
+	if got, want := string(protoField(t, stream, 1)), `{app="api", level="info"}`; got != want {
+		t.Errorf("labels = %s, want %s", got, want)
+	}
+	entry := protoField(t, stream, 2)
+	if got := string(protoField(t, entry, 2)); got != line {
+		t.Errorf("line = %s, want %s", got, line)
+	}
+	if len(protoField(t, entry, 1)) == 0 {
+		t.Error("entry has no timestamp")
+	}
+}
+
+func TestSnappyEncode(t *testing.T) {
+	for _, src := range [][]byte{
+		nil,
+		[]byte("abc"),
+		bytes.Repeat([]byte("a"), 1000),
+		bytes.Repeat([]byte("0123456789abcdef"), 10000),
+	} {
+		got, err := snappy.Decode(nil, snappyEncode(src))
+		if err != nil || !bytes.Equal(got, src) {
+			t.Errorf("round trip of %d bytes failed: %v", len(src), err)
+		}
+	}
+}
+
+// protoField returns the first length-delimited field of the protobuf
+// message b, skipping varint fields.
+func protoField(t *testing.T, b []byte, field int) []byte {
+	t.Helper()
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		b = b[n:]
+		switch key & 7 {
+		case 0:
+			_, n = binary.Uvarint(b)
+			b = b[n:]
+		case 2:
+			l, n := binary.Uvarint(b)
+			data := b[n : n+int(l)]
+			b = b[n+int(l):]
+			if int(key>>3) == field {
+				return data
+			}
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+	}
+	t.Fatalf("field %d not found", field)
+	return nil
+}
@@ -0,0 +1,140 @@
+package ezlog
+
+import (
+	"encoding/binary"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WithLokiProtobuf pushes events with the snappy-compressed protobuf push
+// API instead of the JSON one, which is smaller and cheaper for Loki to
+// ingest.
+func WithLokiProtobuf() LokiOption {
+	return func(w *LokiWriter) {
+		w.protobuf = true
+		w.header.Set("Content-Type", "application/x-protobuf")
+	}
+}
+
+// encodeLokiProtobuf encodes streams as a logproto.PushRequest:
+//
+//	PushRequest   { repeated StreamAdapter streams = 1; }
+//	StreamAdapter { string labels = 1; repeated EntryAdapter entries = 2; }
+//	EntryAdapter  { Timestamp timestamp = 1; string line = 2; }
+//	Timestamp     { int64 seconds = 1; int32 nanos = 2; }
+func encodeLokiProtobuf(streams []*lokiStream) []byte {
+	var req, stream, entry, ts []byte
+	for _, s := range streams {
+		stream = appendProtoBytes(stream[:0], 1, []byte(lokiLabels(s.Stream)))
+		for _, v := range s.Values {
+			nanos, _ := strconv.ParseInt(v[0], 10, 64)
+			ts = appendProtoVarint(ts[:0], 1, uint64(nanos/1e9))
+			ts = appendProtoVarint(ts, 2, uint64(nanos%1e9))
+			entry = appendProtoBytes(entry[:0], 1, ts)
+			entry = appendProtoBytes(entry, 2, []byte(v[1]))
+			stream = appendProtoBytes(stream, 2, entry)
+		}
+		req = appendProtoBytes(req, 1, stream)
+	}
+	return req
+}
+
+// lokiLabels renders labels as a Prometheus label set, such as
+// {app="api", level="info"}.
+func lokiLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(name)
+		sb.WriteByte('=')
+		sb.WriteString(strconv.Quote(labels[name]))
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// appendProtoVarint appends the varint field to b.
+func appendProtoVarint(b []byte, field int, v uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3)
+	return binary.AppendUvarint(b, v)
+}
+
+// appendProtoBytes appends the length-delimited field to b.
+func appendProtoBytes(b []byte, field int, data []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+// snappyEncode compresses src in the snappy block format expected by Loki,
+// with a greedy search for repeated 4-byte sequences.
+func snappyEncode(src []byte) []byte {
+	dst := binary.AppendUvarint(make([]byte, 0, len(src)/2+16), uint64(len(src)))
+
+	// table holds, per hash of 4 bytes, the position after their last
+	// occurrence, 0 meaning none.
+	var table [1 << 14]int32
+	lit := 0
+	for i := 0; i+4 <= len(src); {
+		cur := binary.LittleEndian.Uint32(src[i:])
+		h := (cur * 0x1e35a7bd) >> 18
+		cand := int(table[h]) - 1
+		table[h] = int32(i + 1)
+		if cand < 0 || i-cand > 0xffff || binary.LittleEndian.Uint32(src[cand:]) != cur {
+			i++
+			continue
+		}
+
+		n := 4
+		for i+n < len(src) && src[cand+n] == src[i+n] {
+			n++
+		}
+		dst = appendSnappyLiteral(dst, src[lit:i])
+		dst = appendSnappyCopy(dst, i-cand, n)
+		i += n
+		lit = i
+	}
+	return appendSnappyLiteral(dst, src[lit:])
+}
+
+// appendSnappyLiteral appends the literal lit to dst.
+func appendSnappyLiteral(dst, lit []byte) []byte {
+	if len(lit) == 0 {
+		return dst
+	}
+	n := uint32(len(lit) - 1)
+	switch {
+	case n < 60:
+		dst = append(dst, byte(n)<<2)
+	case n < 1<<8:
+		dst = append(dst, 60<<2, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(dst, lit...)
+}
+
+// appendSnappyCopy appends copies of length bytes at offset to dst, as
+// copies with a 2-byte offset of at most 64 bytes each.
+func appendSnappyCopy(dst []byte, offset, length int) []byte {
+	for length > 0 {
+		n := min(length, 64)
+		dst = append(dst, byte(n-1)<<2|2, byte(offset), byte(offset>>8))
+		length -= n
+	}
+	return dst
+}
@@ -0,0 +1,224 @@
+package ezlog
+
+import (
+	"expvar"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// Metric names used with a MetricsExporter.
+const (
+	// MetricLogEvents counts the log events, labeled by level and tag.
+	MetricLogEvents = "log_events_total"
+	// MetricLogLevel is the minimum level of the loggers, from -1 for trace
+	// to 5 for panic, labeled by tag.
+	MetricLogLevel = "log_level"
+	// MetricGormQueries counts the gorm queries, labeled by status, "ok" or
+	// "error", and slow, "true" or "false".
+	MetricGormQueries = "gorm_queries_total"
+	// MetricGormQueryDuration records the duration in seconds of the gorm
+	// queries, labeled by status.
+	MetricGormQueryDuration = "gorm_query_duration_seconds"
+)
+
+// MetricsExporter receives the metrics of loggers and GormLoggers, so they
+// can be exported to any metrics backend. A metric is always given the same
+// label names. Implementations must be safe for concurrent use.
+type MetricsExporter interface {
+	// IncrCounter increments the counter name.
+	IncrCounter(name string, labels map[string]string)
+	// RecordHistogram records value in the histogram name.
+	RecordHistogram(name string, value float64, labels map[string]string)
+}
+
+// GaugeFuncExporter is implemented by the MetricsExporters supporting
+// gauges computed when metrics are collected, such as MetricLogLevel.
+type GaugeFuncExporter interface {
+	// RegisterGaugeFunc registers the gauge name, reading its value from fn.
+	RegisterGaugeFunc(name string, labels map[string]string, fn func() float64)
+}
+
+// NoopMetricsExporter is a MetricsExporter discarding everything.
+type NoopMetricsExporter struct{}
+
+// IncrCounter implements MetricsExporter.
+func (NoopMetricsExporter) IncrCounter(name string, labels map[string]string) {}
+
+// RecordHistogram implements MetricsExporter.
+func (NoopMetricsExporter) RecordHistogram(name string, value float64, labels map[string]string) {}
+
+// ExpvarMetricsExporter is a MetricsExporter publishing metrics as expvar
+// maps, served on /debug/vars by expvar's handler. Each metric is a map
+// keyed by its labels, such as "level=info,tag=api"; counters are integers
+// and histograms maps of their count and sum.
+// It should be created using NewExpvarMetricsExporter.
+type ExpvarMetricsExporter struct {
+	prefix string
+	mu     sync.Mutex
+	maps   map[string]*expvar.Map
+}
+
+// NewExpvarMetricsExporter creates an ExpvarMetricsExporter publishing its
+// metrics under their name prefixed with prefix, e.g. "myapp_".
+func NewExpvarMetricsExporter(prefix string) *ExpvarMetricsExporter {
+	return &ExpvarMetricsExporter{prefix: prefix, maps: make(map[string]*expvar.Map)}
+}
+
+// IncrCounter implements MetricsExporter.
+func (e *ExpvarMetricsExporter) IncrCounter(name string, labels map[string]string) {
+	e.metric(name).Add(labelKey(labels), 1)
+}
+
+// RecordHistogram implements MetricsExporter.
+func (e *ExpvarMetricsExporter) RecordHistogram(name string, value float64, labels map[string]string) {
+	m := e.metric(name)
+	key := labelKey(labels)
+	h, ok := m.Get(key).(*expvar.Map)
+	if !ok {
+		e.mu.Lock()
+		if h, ok = m.Get(key).(*expvar.Map); !ok {
+			h = new(expvar.Map).Init()
+			m.Set(key, h)
+		}
+		e.mu.Unlock()
+	}
+	h.Add("count", 1)
+	h.AddFloat("sum", value)
+}
+
+// metric returns the map of the metric name, publishing it if needed. A
+// variable already published under the name by someone else is replaced by
+// an unpublished map.
+func (e *ExpvarMetricsExporter) metric(name string) *expvar.Map {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if m, ok := e.maps[name]; ok {
+		return m
+	}
+	full := e.prefix + name
+	m, ok := expvar.Get(full).(*expvar.Map)
+	if !ok {
+		m = new(expvar.Map).Init()
+		if expvar.Get(full) == nil {
+			expvar.Publish(full, m)
+		}
+	}
+	e.maps[name] = m
+	return m
+}
+
+// labelKey returns the labels as sorted "name=value" pairs joined by commas.
+func labelKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// WithMetrics counts the events written by the logger in e, as
+// MetricLogEvents labeled by level and tag. Events dropped by the levels,
+// samplers, rate limit or deduplication are not counted. When e is a
+// GaugeFuncExporter, the minimum level of the logger is exported too, as
+// MetricLogLevel.
+func (b *LogBuilder) WithMetrics(e MetricsExporter) *LogBuilder {
+	b.metrics = e
+	return b
+}
+
+// registerLevelGauge exports the minimum level of the logger, if supported
+// by the metrics exporter.
+func (b *LogBuilder) registerLevelGauge() {
+	g, ok := b.metrics.(GaugeFuncExporter)
+	if !ok {
+		return
+	}
+	level := b.levelFunc()
+	g.RegisterGaugeFunc(MetricLogLevel, map[string]string{"tag": b.tag}, func() float64 {
+		return float64(level())
+	})
+}
+
+// levelFunc returns a function computing the current minimum level of the
+// logger, from its level, the global level and its dynamic or live levels.
+func (b *LogBuilder) levelFunc() func() zerolog.Level {
+	level, tag, dl, lc := b.level, b.tag, b.dynamicLevel, b.liveConfig
+	return func() zerolog.Level {
+		min := max(level, zerolog.GlobalLevel())
+		if dl != nil {
+			min = max(min, dl.Level())
+		}
+		if lc != nil {
+			min = max(min, lc.state.Load().minLevel(tag))
+		}
+		return min
+	}
+}
+
+// metricsWriter counts the JSON events reaching out, which went through
+// every filter.
+type metricsWriter struct {
+	out      io.Writer
+	exporter MetricsExporter
+	tag      string
+	b        *LogBuilder
+}
+
+// Write implements io.Writer.
+func (w *metricsWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *metricsWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	n, err := writeLevel(w.out, level, p)
+	if err == nil {
+		level := eventLevel(p)
+		if l, ok := w.b.parseLevelValue(level); ok {
+			level = LevelString(l)
+		}
+		w.exporter.IncrCounter(MetricLogEvents, map[string]string{
+			"level": level,
+			"tag":   w.tag,
+		})
+	}
+	return n, err
+}
+
+// chainMetricsExporter fans metrics out to several exporters.
+type chainMetricsExporter []MetricsExporter
+
+// ChainMetricsExporter returns a MetricsExporter passing every metric to all
+// of exporters, e.g. to both scrape metrics with Prometheus and push them to
+// StatsD. Gauges are registered with the exporters supporting them.
+func ChainMetricsExporter(exporters ...MetricsExporter) MetricsExporter {
+	return chainMetricsExporter(exporters)
+}
+
+// IncrCounter implements MetricsExporter.
+func (c chainMetricsExporter) IncrCounter(name string, labels map[string]string) {
+	for _, e := range c {
+		e.IncrCounter(name, labels)
+	}
+}
+
+// RecordHistogram implements MetricsExporter.
+func (c chainMetricsExporter) RecordHistogram(name string, value float64, labels map[string]string) {
+	for _, e := range c {
+		e.RecordHistogram(name, value, labels)
+	}
+}
+
+// RegisterGaugeFunc implements GaugeFuncExporter.
+func (c chainMetricsExporter) RegisterGaugeFunc(name string, labels map[string]string, fn func() float64) {
+	for _, e := range c {
+		if g, ok := e.(GaugeFuncExporter); ok {
+			g.RegisterGaugeFunc(name, labels, fn)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package ezlog
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm/logger"
+)
+
+// metricsRecorder is a MetricsExporter and GaugeFuncExporter keeping what it
+// receives.
+type metricsRecorder struct {
+	mu         sync.Mutex
+	counters   map[string]int
+	histograms map[string][]float64
+	gauges     map[string]func() float64
+}
+
+func newMetricsRecorder() *metricsRecorder {
+	return &metricsRecorder{
+		counters:   make(map[string]int),
+		histograms: make(map[string][]float64),
+		gauges:     make(map[string]func() float64),
+	}
+}
+
+func (r *metricsRecorder) IncrCounter(name string, labels map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[name+"{"+labelKey(labels)+"}"]++
+}
+
+func (r *metricsRecorder) RecordHistogram(name string, value float64, labels map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := name + "{" + labelKey(labels) + "}"
+	r.histograms[key] = append(r.histograms[key], value)
+}
+
+func (r *metricsRecorder) RegisterGaugeFunc(name string, labels map[string]string, fn func() float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name+"{"+labelKey(labels)+"}"] = fn
+}
+
+func TestWithMetrics(t *testing.T) {
+	rec := newMetricsRecorder()
+	l := New().AsLocal().WithWriter(io.Discard).WithJSONOutput().WithTag("api").
+		WithLevel(zerolog.InfoLevel).WithMetrics(rec).Build()
+
+	l.Debug().Msg("dropped")
+	l.Info().Msg("a")
+	l.Info().Msg("b")
+	l.Error().Msg("c")
+
+	want := map[string]int{
+		"log_events_total{level=info,tag=api}":  2,
+		"log_events_total{level=error,tag=api}": 1,
+	}
+	if fmt.Sprint(rec.counters) != fmt.Sprint(want) {
+		t.Errorf("counters = %v, want %v", rec.counters, want)
+	}
+
+}
+
+func TestWithMetricsCountsSuccessfulWritesOnly(t *testing.T) {
+	rec := newMetricsRecorder()
+	out := &flakyWriter{fail: true}
+	l := New().AsLocal().WithWriter(out).WithJSONOutput().WithMetrics(rec).Build()
+	l.Info().Msg("lost")
+	out.fail = false
+	l.Info().Msg("written")
+
+	if n := rec.counters["log_events_total{level=info,tag=}"]; n != 1 {
+		t.Errorf("counted %d events, want 1: %v", n, rec.counters)
+	}
+}
+
+func TestGormMetrics(t *testing.T) {
+	rec := newMetricsRecorder()
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	l := NewGormLogger().WithLogLevel(logger.Silent).WithMetrics(rec).WithSlowThreshold(time.Second).
+		WithClock(func() time.Time { return now }).Build()
+	ctx := context.Background()
+	sql := func() (string, int64) { return "SELECT 1", 1 }
+
+	l.Trace(ctx, now.Add(-250*time.Millisecond), sql, nil)
+	l.Trace(ctx, now.Add(-2*time.Second), sql, nil)
+	l.Trace(ctx, now.Add(-500*time.Millisecond), sql, errors.New("locked"))
+
+	wantCounters := map[string]int{
+		"gorm_queries_total{slow=false,status=ok}":    1,
+		"gorm_queries_total{slow=true,status=ok}":     1,
+		"gorm_queries_total{slow=false,status=error}": 1,
+	}
+	if fmt.Sprint(rec.counters) != fmt.Sprint(wantCounters) {
+		t.Errorf("counters = %v, want %v", rec.counters, wantCounters)
+	}
+	wantHistograms := map[string][]float64{
+		"gorm_query_duration_seconds{status=ok}":    {0.25, 2},
+		"gorm_query_duration_seconds{status=error}": {0.5},
+	}
+	if fmt.Sprint(rec.histograms) != fmt.Sprint(wantHistograms) {
+		t.Errorf("histograms = %v, want %v", rec.histograms, wantHistograms)
+	}
+}
+
+// expvarPrefixes makes the expvar prefixes of the tests unique, as
+// variables cannot be unpublished.
+var expvarPrefixes atomic.Int64
+
+func TestExpvarMetricsExporter(t *testing.T) {
+	prefix := fmt.Sprintf("ezlog_test%d_", expvarPrefixes.Add(1))
+	e := NewExpvarMetricsExporter(prefix)
+	e.IncrCounter("events", map[string]string{"tag": "api", "level": "info"})
+	e.IncrCounter("events", map[string]string{"level": "info", "tag": "api"})
+	e.RecordHistogram("duration", 0.5, map[string]string{"status": "ok"})
+	e.RecordHistogram("duration", 1.25, map[string]string{"status": "ok"})
+
+	events, _ := expvar.Get(prefix + "events").(*expvar.Map)
+	if events == nil || events.Get("level=info,tag=api").String() != "2" {
+		t.Errorf("events = %v", events)
+	}
+	duration, _ := expvar.Get(prefix + "duration").(*expvar.Map)
+	h, _ := duration.Get("status=ok").(*expvar.Map)
+	if h == nil || h.Get("count").String() != "2" || h.Get("sum").String() != "1.75" {
+		t.Errorf("duration = %v", duration)
+	}
+
+	// A second exporter with the same prefix shares the variables.
+	NewExpvarMetricsExporter(prefix).IncrCounter("events", map[string]string{"level": "info", "tag": "api"})
+	if got := events.Get("level=info,tag=api").String(); got != "3" {
+		t.Errorf("events after a second exporter = %s, want 3", got)
+	}
+
+	// A variable of another type is left alone.
+	expvar.NewString(prefix + "taken")
+	NewExpvarMetricsExporter(prefix).IncrCounter("taken", nil)
+	if _, ok := expvar.Get(prefix + "taken").(*expvar.String); !ok {
+		t.Error("the exporter replaced a variable it does not own")
+	}
+}
+
+func TestChainMetricsExporter(t *testing.T) {
+	a, b := newMetricsRecorder(), newMetricsRecorder()
+	c := ChainMetricsExporter(a, NoopMetricsExporter{}, b)
+	c.IncrCounter("n", map[string]string{"k": "v"})
+	c.RecordHistogram("h", 1, nil)
+	c.(GaugeFuncExporter).RegisterGaugeFunc("g", nil, func() float64 { return 1 })
+
+	for _, r := range []*metricsRecorder{a, b} {
+		if r.counters["n{k=v}"] != 1 || len(r.histograms["h{}"]) != 1 || r.gauges["g{}"] == nil {
+			t.Errorf("recorder = %v, %v, %v", r.counters, r.histograms, r.gauges)
+		}
+	}
+}
+
+func TestWithMetricsLevelGauge(t *testing.T) {
+	rec := newMetricsRecorder()
+	dl := NewDynamicLevel(zerolog.InfoLevel)
+	New().AsLocal().WithWriter(io.Discard).WithTag("api").WithDynamicLevel(dl).WithMetrics(rec).Build()
+
+	gauge := rec.gauges["log_level{tag=api}"]
+	if gauge == nil {
+		t.Fatalf("gauges = %v, want the api level", rec.gauges)
+	}
+	if got := gauge(); got != float64(zerolog.InfoLevel) {
+		t.Errorf("level gauge = %v, want info", got)
+	}
+	dl.SetLevel(zerolog.WarnLevel)
+	if got := gauge(); got != float64(zerolog.WarnLevel) {
+		t.Errorf("level gauge after SetLevel = %v, want warn", got)
+	}
+}
@@ -0,0 +1,119 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// MockEvent is a log event recorded by a MockLogger.
+type MockEvent struct {
+	Level   zerolog.Level
+	Message string
+	Fields  map[string]any
+}
+
+// MockLogger records the events written to its logger so tests can inspect
+// them. It is safe for concurrent use.
+type MockLogger struct {
+	mu     sync.Mutex
+	events []MockEvent
+}
+
+// NewMockLogger creates a MockLogger and the logger to inject into the code
+// under test.
+func NewMockLogger() (*MockLogger, *zerolog.Logger) {
+	m := &MockLogger{}
+	l := zerolog.New(m)
+	return m, &l
+}
+
+// Write implements io.Writer by parsing and recording a JSON event.
+func (m *MockLogger) Write(p []byte) (int, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(p, &fields); err != nil {
+		return 0, err
+	}
+
+	evt := MockEvent{Level: zerolog.NoLevel, Fields: fields}
+	if lvl, ok := fields[zerolog.LevelFieldName].(string); ok {
+		if level, err := zerolog.ParseLevel(lvl); err == nil {
+			evt.Level = level
+		}
+		delete(fields, zerolog.LevelFieldName)
+	}
+	if msg, ok := fields[zerolog.MessageFieldName].(string); ok {
+		evt.Message = msg
+		delete(fields, zerolog.MessageFieldName)
+	}
+
+	m.mu.Lock()
+	m.events = append(m.events, evt)
+	m.mu.Unlock()
+	return len(p), nil
+}
+
+// Events returns the recorded events in order.
+func (m *MockLogger) Events() []MockEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MockEvent(nil), m.events...)
+}
+
+// EventsAtLevel returns the recorded events at level l.
+func (m *MockLogger) EventsAtLevel(l zerolog.Level) []MockEvent {
+	var events []MockEvent
+	for _, evt := range m.Events() {
+		if evt.Level == l {
+			events = append(events, evt)
+		}
+	}
+	return events
+}
+
+// HasMessage reports whether an event with message msg was recorded.
+func (m *MockLogger) HasMessage(msg string) bool {
+	for _, evt := range m.Events() {
+		if evt.Message == msg {
+			return true
+		}
+	}
+	return false
+}
+
+// HasField reports whether an event with the field key set to value was
+// recorded. value is compared after a JSON round trip, so HasField("n", 3)
+// matches a field logged with Int("n", 3).
+func (m *MockLogger) HasField(key string, value any) bool {
+	want, err := jsonRoundTrip(value)
+	if err != nil {
+		return false
+	}
+	for _, evt := range m.Events() {
+		if got, ok := evt.Fields[key]; ok && reflect.DeepEqual(got, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset forgets all recorded events.
+func (m *MockLogger) Reset() {
+	m.mu.Lock()
+	m.events = nil
+	m.mu.Unlock()
+}
+
+// jsonRoundTrip encodes and decodes v the way recorded fields are decoded.
+func jsonRoundTrip(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.NewDecoder(bytes.NewReader(b)).Decode(&out)
+	return out, err
+}
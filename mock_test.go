@@ -0,0 +1,39 @@
+package ezlog
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestMockLogger(t *testing.T) {
+	m, l := NewMockLogger()
+	l.Info().Str("user", "ada").Int("n", 3).Msg("login")
+	l.Warn().Bool("retry", true).Msg("slow")
+	l.Info().Msg("logout")
+
+	events := m.Events()
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	if e := events[0]; e.Level != zerolog.InfoLevel || e.Message != "login" || e.Fields["user"] != "ada" {
+		t.Errorf("first event = %+v", e)
+	}
+	if _, ok := events[0].Fields[zerolog.LevelFieldName]; ok {
+		t.Error("the level is kept among the fields")
+	}
+	if got := m.EventsAtLevel(zerolog.WarnLevel); len(got) != 1 || got[0].Message != "slow" {
+		t.Errorf("EventsAtLevel(warn) = %+v", got)
+	}
+	if !m.HasMessage("logout") || m.HasMessage("crash") {
+		t.Error("HasMessage does not match the recorded messages")
+	}
+	if !m.HasField("n", 3) || !m.HasField("retry", true) || m.HasField("n", 4) || m.HasField("missing", nil) {
+		t.Error("HasField does not match the recorded fields")
+	}
+
+	m.Reset()
+	if len(m.Events()) != 0 || m.HasMessage("login") {
+		t.Error("Reset kept events")
+	}
+}
@@ -0,0 +1,104 @@
+package ezlog
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog"
+)
+
+// continuationKey is the private event key holding the continuation lines
+// between FormatPrepare and FormatExtra. It is excluded from field output.
+const continuationKey = "\x00ezlog_continuation"
+
+// continuationGutter prefixes every continuation line.
+const continuationGutter = "  │ "
+
+// WithMultiline renders messages and string fields containing newlines on
+// several lines: the first line stays in place and the following lines are
+// printed indented beneath the log line behind a "│ " gutter.
+func (b *LogBuilder) WithMultiline() *LogBuilder {
+	b.multiline = true
+	return b
+}
+
+// applyContinuation configures the console writer to print continuation lines
+// beneath the log line, for multi-line values and error chains.
+func (b *LogBuilder) applyContinuation(w *zerolog.ConsoleWriter) {
+	w.FieldsExclude = append(w.FieldsExclude, continuationKey)
+	if b.multiline {
+		addFormatPrepare(w, splitMultiline)
+	}
+	if b.errorChain {
+		w.FieldsExclude = append(w.FieldsExclude, ErrorChainFieldName)
+		addFormatPrepare(w, renderErrorChain)
+	}
+	if b.tableMinFields > 0 {
+		addFormatPrepare(w, b.renderFieldTable(w.FormatFieldValue))
+	}
+	addFormatExtra(w, func(evt map[string]any, buf *bytes.Buffer) error {
+		lines, _ := evt[continuationKey].([]string)
+		for _, line := range lines {
+			if b.tviewCompat {
+				line = tview.Escape(line)
+			}
+			buf.WriteByte('\n')
+			buf.WriteString(continuationGutter)
+			buf.WriteString(line)
+		}
+		return nil
+	})
+}
+
+// splitMultiline keeps the first line of the message and of every string field
+// in evt, and stores the remaining lines under continuationKey. The lines of
+// the fields are sanitized, as their first line is quoted.
+func splitMultiline(evt map[string]any) error {
+	var lines []string
+
+	if msg, ok := evt[zerolog.MessageFieldName].(string); ok && strings.Contains(msg, "\n") {
+		first, rest := splitLines(msg)
+		evt[zerolog.MessageFieldName] = first
+		lines = append(lines, rest...)
+	}
+
+	keys := make([]string, 0, len(evt))
+	for key, value := range evt {
+		if s, ok := value.(string); ok && key != zerolog.MessageFieldName && strings.Contains(s, "\n") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		first, rest := splitLines(evt[key].(string))
+		evt[key] = first
+		label := cyanColor.Sprintf("%s:", key)
+		for _, line := range rest {
+			lines = append(lines, label+" "+sanitize(line, false))
+		}
+	}
+
+	appendContinuation(evt, lines...)
+	return nil
+}
+
+// appendContinuation adds lines to the continuation lines of evt.
+func appendContinuation(evt map[string]any, lines ...string) {
+	if len(lines) == 0 {
+		return
+	}
+	prev, _ := evt[continuationKey].([]string)
+	evt[continuationKey] = append(prev, lines...)
+}
+
+// splitLines returns the first line of s and the remaining ones.
+func splitLines(s string) (string, []string) {
+	lines := strings.Split(strings.TrimRight(s, "\r\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines[0], lines[1:]
+}
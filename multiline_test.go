@@ -0,0 +1,49 @@
+package ezlog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWithMultiline(t *testing.T) {
+	var buf bytes.Buffer
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	l := newTestBuilder(&buf).WithTag("db").WithMultiline().WithTimestampFunc(fixedClock(ts)).Build()
+	l.Info().Msg("query failed\nSELECT *\nFROM users")
+	l.Warn().Str("stack", "main.run\nmain.main\r\n").Int("n", 1).Msg("slow")
+
+	want := "09:26:53.000 [INFO] [db] query failed\n" +
+		"  │ SELECT *\n" +
+		"  │ FROM users\n" +
+		"09:26:53.000 [WARN] [db] slow n=1 stack=\"main.run\"\n" +
+		"  │ stack: main.main\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWithMultilineTview(t *testing.T) {
+	var buf bytes.Buffer
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	l := New().AsLocal().WithWriter(&buf).WithTviewCompat().WithMultiline().WithTimestampFunc(fixedClock(ts)).Build()
+	l.Info().Msg("first\n[red]second[white]")
+
+	if got, want := buf.String(), "09:26:53.000 [INFO[] first\n  │ [red[]second[white[]\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestWithMultilineSanitizesFields(t *testing.T) {
+	var buf bytes.Buffer
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	l := newTestBuilder(&buf).WithMultiline().WithTimestampFunc(fixedClock(ts)).Build()
+	l.Info().Str("input", "a\n\x1b[2Jforged\n\x07bell").Msg("received")
+
+	want := "09:26:53.000 [INFO] received input=\"a\"\n" +
+		"  │ input: \\x1b[2Jforged\n" +
+		"  │ input: \\x07bell\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
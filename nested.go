@@ -0,0 +1,105 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// defaultNestedDepth is the nesting depth rendered by default.
+const defaultNestedDepth = 3
+
+// WithNestedDepth sets how many levels of nested objects and arrays, such as
+// zerolog's Dict fields, are rendered as key=value groups in console output,
+// e.g. db=(host="x" port=5432) and ids=[1, 2, 3]. Deeper values are rendered
+// as compact JSON. The default is 3; 0 renders all nested values as JSON.
+func (b *LogBuilder) WithNestedDepth(depth int) *LogBuilder {
+	b.nestedDepth = &depth
+	return b
+}
+
+// maxNestedDepth returns the configured nesting depth.
+func (b *LogBuilder) maxNestedDepth() int {
+	if b.nestedDepth == nil {
+		return defaultNestedDepth
+	}
+	return *b.nestedDepth
+}
+
+// formatNested renders the JSON object or array raw, as marshaled by the
+// console writer, up to maxDepth levels deep. ok is false when raw is not
+// a JSON object or array.
+func formatNested(raw []byte, maxDepth int, scheme *ColorScheme) (s string, ok bool) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || (raw[0] != '{' && raw[0] != '[') {
+		return "", false
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", false
+	}
+	var sb strings.Builder
+	writeNested(&sb, v, 1, maxDepth, scheme)
+	return sb.String(), true
+}
+
+// writeNested renders v at depth into sb.
+func writeNested(sb *strings.Builder, v any, depth, maxDepth int, scheme *ColorScheme) {
+	switch vv := v.(type) {
+	case map[string]any:
+		if depth > maxDepth {
+			writeCompactJSON(sb, vv)
+			return
+		}
+		keys := make([]string, 0, len(vv))
+		for k := range vv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		sb.WriteByte('(')
+		for i, k := range keys {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(scheme.FieldName.Sprintf("%s=", k))
+			writeNested(sb, vv[k], depth+1, maxDepth, scheme)
+		}
+		sb.WriteByte(')')
+	case []any:
+		if depth > maxDepth {
+			writeCompactJSON(sb, vv)
+			return
+		}
+		sb.WriteByte('[')
+		for i, elem := range vv {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			writeNested(sb, elem, depth+1, maxDepth, scheme)
+		}
+		sb.WriteByte(']')
+	case string:
+		sb.WriteString(scheme.String.Sprintf("%q", vv))
+	case json.Number:
+		sb.WriteString(scheme.Number.Sprint(vv))
+	case bool:
+		sb.WriteString(scheme.Bool.Sprint(vv))
+	case nil:
+		sb.WriteString(scheme.Nil.Sprint("nil"))
+	default:
+		writeCompactJSON(sb, vv)
+	}
+}
+
+// writeCompactJSON renders v as compact JSON into sb.
+func writeCompactJSON(sb *strings.Builder, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		sb.WriteString(redColor.Sprintf("[error: %v]", err))
+		return
+	}
+	sb.Write(b)
+}
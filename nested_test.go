@@ -0,0 +1,86 @@
+package ezlog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/rs/zerolog"
+)
+
+func TestNestedConsoleRendering(t *testing.T) {
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	tests := []struct {
+		name  string
+		depth *int
+		event func(*zerolog.Logger)
+		want  string
+	}{
+		{
+			name: "two-level dict",
+			event: func(l *zerolog.Logger) {
+				l.Info().Dict("db", zerolog.Dict().Str("host", "x").Int("port", 5432).
+					Dict("pool", zerolog.Dict().Int("max", 10).Bool("idle", true))).Msg("connected")
+			},
+			want: `09:26:53.000 [INFO] connected db=(host="x" pool=(idle=true max=10) port=5432)` + "\n",
+		},
+		{
+			name:  "array of ints",
+			event: func(l *zerolog.Logger) { l.Info().Ints("ids", []int{1, 2, 3}).Msg("loaded") },
+			want:  `09:26:53.000 [INFO] loaded ids=[1, 2, 3]` + "\n",
+		},
+		{
+			name: "depth exceeded",
+			event: func(l *zerolog.Logger) {
+				l.Info().Dict("a", zerolog.Dict().Dict("b", zerolog.Dict().Dict("c", zerolog.Dict().
+					Dict("d", zerolog.Dict().Str("e", "deep"))))).Msg("nested")
+			},
+			want: `09:26:53.000 [INFO] nested a=(b=(c=(d={"e":"deep"})))` + "\n",
+		},
+		{
+			name:  "depth zero",
+			depth: new(int),
+			event: func(l *zerolog.Logger) {
+				l.Info().Dict("db", zerolog.Dict().Str("host", "x")).Ints("ids", []int{1}).Msg("raw")
+			},
+			want: `09:26:53.000 [INFO] raw db={"host":"x"} ids=[1]` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			b := newTestBuilder(&buf).WithTimestampFunc(fixedClock(ts))
+			if tt.depth != nil {
+				b.WithNestedDepth(*tt.depth)
+			}
+			tt.event(b.Build())
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNestedElementColors(t *testing.T) {
+	s := ColorScheme{
+		FieldName: color.New(color.FgCyan),
+		String:    color.New(color.FgGreen),
+		Number:    color.New(color.FgYellow),
+		Bool:      color.New(color.FgMagenta),
+		Nil:       color.New(color.FgRed),
+	}
+	for _, c := range []*color.Color{s.FieldName, s.String, s.Number, s.Bool, s.Nil} {
+		c.EnableColor()
+	}
+
+	got, ok := formatNested([]byte(`{"n":1,"s":"x","v":[true,null]}`), 3, &s)
+	want := "(\x1b[36mn=\x1b[0m\x1b[33m1\x1b[0m \x1b[36ms=\x1b[0m\x1b[32m\"x\"\x1b[0m " +
+		"\x1b[36mv=\x1b[0m[\x1b[35mtrue\x1b[0m, \x1b[31mnil\x1b[0m])"
+	if !ok || got != want {
+		t.Errorf("formatNested = %q, %v, want %q", got, ok, want)
+	}
+	if _, ok := formatNested([]byte(`"plain"`), 3, &s); ok {
+		t.Error("formatNested accepted a string")
+	}
+}
@@ -0,0 +1,100 @@
+package ezlog
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// onceState records when each key of the log-once helpers last fired.
+var onceState sync.Map // map[string]*onceEntry
+
+// onceEntry is the state of one log-once key.
+type onceEntry struct {
+	mu   sync.Mutex
+	last time.Time
+	shot bool
+}
+
+// OnceLogger returns events that are only emitted the first time per key,
+// e.g. for a deprecation warning in a request path. Keys are shared by the
+// whole process; an empty key stands for the file and line of the call.
+// It should be created using Once.
+type OnceLogger struct {
+	l *zerolog.Logger
+}
+
+// Once returns a OnceLogger writing to l.
+func Once(l *zerolog.Logger) OnceLogger {
+	return OnceLogger{l: l}
+}
+
+// Warn returns a warning event the first time it is called for key, and a
+// disabled event, which costs nothing to fill, afterwards.
+func (o OnceLogger) Warn(key string) *zerolog.Event {
+	return o.once(key, zerolog.WarnLevel, 0)
+}
+
+// Info is like Warn at the info level.
+func (o OnceLogger) Info(key string) *zerolog.Event {
+	return o.once(key, zerolog.InfoLevel, 0)
+}
+
+// PerInterval returns a warning event the first time it is called for key,
+// then again once d has elapsed since the last emitted one.
+func (o OnceLogger) PerInterval(key string, d time.Duration) *zerolog.Event {
+	return o.once(key, zerolog.WarnLevel, d)
+}
+
+// once returns an event at level if key is due, and nil otherwise. A zero
+// interval never re-arms.
+func (o OnceLogger) once(key string, level zerolog.Level, interval time.Duration) *zerolog.Event {
+	if key == "" {
+		key = onceCallSite(3)
+	}
+	v, _ := onceState.LoadOrStore(key, &onceEntry{})
+	entry := v.(*onceEntry)
+
+	now := time.Now()
+	entry.mu.Lock()
+	due := !entry.shot || (interval > 0 && now.Sub(entry.last) >= interval)
+	if due {
+		entry.shot = true
+		entry.last = now
+	}
+	entry.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return o.l.WithLevel(level)
+}
+
+// OnceWarn is Once(l).Warn(key) on the global logger.
+func OnceWarn(key string) *zerolog.Event {
+	if key == "" {
+		key = onceCallSite(2)
+	}
+	return Once(&log.Logger).Warn(key)
+}
+
+// OncePerInterval is Once(l).PerInterval(key, d) on the global logger.
+func OncePerInterval(key string, d time.Duration) *zerolog.Event {
+	if key == "" {
+		key = onceCallSite(2)
+	}
+	return Once(&log.Logger).PerInterval(key, d)
+}
+
+// onceCallSite returns the file and line skip frames above it.
+func onceCallSite(skip int) string {
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
@@ -0,0 +1,92 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// resetOnce forgets the keys of the log-once helpers around the test.
+func resetOnce(t *testing.T) {
+	onceState.Clear()
+	t.Cleanup(onceState.Clear)
+}
+
+func TestOnceConcurrentFirstEmission(t *testing.T) {
+	resetOnce(t)
+	var buf lockedBuffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Once(l).Warn("test-concurrent").Msg("deprecated option")
+		}()
+	}
+	wg.Wait()
+
+	if n := strings.Count(buf.String(), "deprecated option"); n != 1 {
+		t.Errorf("emitted %d times, want exactly once", n)
+	}
+}
+
+func TestOncePerIntervalRearms(t *testing.T) {
+	resetOnce(t)
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithJSONOutput().Build()
+	emit := func() { Once(l).PerInterval("test-interval", 20*time.Millisecond).Msg("tick") }
+
+	emit()
+	emit()
+	if n := strings.Count(buf.String(), "tick"); n != 1 {
+		t.Fatalf("emitted %d times within the interval, want 1", n)
+	}
+	time.Sleep(25 * time.Millisecond)
+	emit()
+	if n := strings.Count(buf.String(), "tick"); n != 2 {
+		t.Errorf("emitted %d times after the interval, want 2", n)
+	}
+	if !strings.Contains(buf.String(), `"level":"warn"`) {
+		t.Errorf("output = %q, want warnings", buf.String())
+	}
+}
+
+func TestOnceDefaultsToCallSite(t *testing.T) {
+	resetOnce(t)
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithJSONOutput().Build()
+	for range 3 {
+		Once(l).Info("").Msg("first site")
+		Once(l).Info("").Msg("second site")
+	}
+
+	got := buf.String()
+	if strings.Count(got, "first site") != 1 || strings.Count(got, "second site") != 1 {
+		t.Errorf("output = %q, want each call site once", got)
+	}
+	if !strings.Contains(got, `"level":"info"`) {
+		t.Errorf("output = %q, want info events", got)
+	}
+}
+
+func TestOnceWarnGlobal(t *testing.T) {
+	restoreGlobalLogger(t)
+	resetOnce(t)
+	var buf bytes.Buffer
+	log.Logger = zerolog.New(&buf)
+	for range 3 {
+		OnceWarn("").Msg("global")
+		OncePerInterval("", time.Hour).Msg("global interval")
+	}
+
+	if got := buf.String(); strings.Count(got, `"global"`) != 1 || strings.Count(got, "global interval") != 1 {
+		t.Errorf("output = %q, want each call site once", got)
+	}
+}
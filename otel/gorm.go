@@ -0,0 +1,58 @@
+package otel
+
+import (
+	"context"
+
+	"github.com/ezydark/ezlog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanEventRecorder is an ezlog.QueryRecorder adding the queries of a
+// GormLogger as "db.query" events to the span of their context, when it is
+// recording, with the db.statement, db.rows, db.elapsed_ms and, for slow
+// queries, db.slow and db.critical_slow attributes. Failed queries also
+// record the error and set the span status to error.
+type SpanEventRecorder struct{}
+
+// NewSpanEventRecorder creates a SpanEventRecorder.
+func NewSpanEventRecorder() SpanEventRecorder {
+	return SpanEventRecorder{}
+}
+
+// WithOtelSpanEvents makes the GormLogger built by b add its queries to the
+// spans of their context, as b.WithQueryRecorder(NewSpanEventRecorder())
+// does.
+func WithOtelSpanEvents(b *ezlog.GormLoggerBuilder) *ezlog.GormLoggerBuilder {
+	return b.WithQueryRecorder(NewSpanEventRecorder())
+}
+
+// RecordQuery implements ezlog.QueryRecorder.
+func (SpanEventRecorder) RecordQuery(ctx context.Context, q ezlog.GormQuery) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("db.statement", q.SQL),
+		attribute.Int64("db.rows", q.Rows),
+		attribute.Float64("db.elapsed_ms", float64(q.Elapsed.Microseconds())/1000),
+	}
+	if q.Slow {
+		attrs = append(attrs, attribute.Bool("db.slow", true))
+	}
+	if q.CriticalSlow {
+		attrs = append(attrs, attribute.Bool("db.critical_slow", true))
+	}
+	span.AddEvent("db.query", trace.WithAttributes(attrs...))
+
+	if q.Err != nil {
+		span.RecordError(q.Err)
+		span.SetStatus(codes.Error, q.Err.Error())
+	}
+}
+
+// SpanEventRecorder must satisfy ezlog.QueryRecorder.
+var _ ezlog.QueryRecorder = SpanEventRecorder{}
@@ -0,0 +1,91 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezydark/ezlog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm/logger"
+)
+
+// eventAttrs returns the attributes of a span event by key.
+func eventAttrs(e sdktrace.Event) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value, len(e.Attributes))
+	for _, kv := range e.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestSpanEvents(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)).Tracer("test")
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	l := WithOtelSpanEvents(ezlog.NewGormLogger().WithLogLevel(logger.Silent).
+		WithClock(func() time.Time { return now }).
+		WithSlowThreshold(100 * time.Millisecond).WithCriticalSlowThreshold(time.Second).
+		WithRedactedColumns("password")).Build()
+
+	ctx, span := tracer.Start(context.Background(), "handler")
+	l.Trace(ctx, now.Add(-1500*time.Microsecond), func() (string, int64) { return "SELECT * FROM users", 3 }, nil)
+	l.Trace(ctx, now.Add(-2*time.Second), func() (string, int64) {
+		return "SELECT * FROM users WHERE password = 'hunter2'", 1
+	}, nil)
+	l.Trace(ctx, now, func() (string, int64) { return "DELETE FROM users", 0 }, errors.New("locked"))
+	l.Trace(context.Background(), now, func() (string, int64) { return "SELECT 1", 1 }, nil)
+	span.End()
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	var queries []sdktrace.Event
+	for _, e := range spans[0].Events() {
+		if e.Name == "db.query" {
+			queries = append(queries, e)
+		}
+	}
+	if len(queries) != 3 {
+		t.Fatalf("got %d db.query events, want 3", len(queries))
+	}
+
+	fast := eventAttrs(queries[0])
+	if fast["db.statement"].AsString() != "SELECT * FROM users" || fast["db.rows"].AsInt64() != 3 ||
+		fast["db.elapsed_ms"].AsFloat64() != 1.5 || fast["db.slow"].Type() != attribute.INVALID {
+		t.Errorf("fast query attributes = %v", fast)
+	}
+	slow := eventAttrs(queries[1])
+	if slow["db.statement"].AsString() != "SELECT * FROM users WHERE password = ?[REDACTED]" ||
+		!slow["db.slow"].AsBool() || !slow["db.critical_slow"].AsBool() {
+		t.Errorf("critical slow query attributes = %v", slow)
+	}
+
+	if st := spans[0].Status(); st.Code != codes.Error || st.Description != "locked" {
+		t.Errorf("span status = %+v, want the query error", st)
+	}
+	if last := spans[0].Events()[len(spans[0].Events())-1]; last.Name != "exception" {
+		t.Errorf("last event = %q, want the recorded error", last.Name)
+	}
+}
+
+func TestSpanEventsIgnoredErrors(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)).Tracer("test")
+	errConflict := errors.New("conflict")
+	l := WithOtelSpanEvents(ezlog.NewGormLogger().WithLogLevel(logger.Silent).WithIgnoredErrors(errConflict)).Build()
+
+	ctx, span := tracer.Start(context.Background(), "handler")
+	l.Trace(ctx, time.Now(), func() (string, int64) { return "INSERT INTO t VALUES (1)", 0 }, errConflict)
+	span.End()
+
+	s := sr.Ended()[0]
+	if s.Status().Code == codes.Error || len(s.Events()) != 1 {
+		t.Errorf("status = %+v, events = %d, want an unset status and only the query", s.Status(), len(s.Events()))
+	}
+}
@@ -0,0 +1,188 @@
+// Package otel exports ezlog events as OpenTelemetry log records, so the
+// processors and exporters of an OpenTelemetry LoggerProvider batch and ship
+// them, e.g. over OTLP. It is a separate package to keep the OpenTelemetry
+// dependencies out of programs that do not import it.
+package otel
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ezydark/ezlog"
+	"github.com/rs/zerolog"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Field names read as the trace context of records instead of attributes.
+const (
+	TraceIDFieldName = "trace_id"
+	SpanIDFieldName  = "span_id"
+)
+
+// scopeName is the instrumentation scope of the records.
+const scopeName = "github.com/ezydark/ezlog"
+
+// Writer converts zerolog JSON events into OpenTelemetry log records:
+// the level becomes the severity, the message the body and the other fields
+// attributes. The "trace_id" and "span_id" fields, as added by TraceHook or
+// by a context extractor, become the trace context of the record.
+// It should be created using NewOtelWriter.
+type Writer struct {
+	logger otellog.Logger
+}
+
+// NewOtelWriter creates a Writer emitting records through a logger of
+// provider. It expects JSON events, so it is used along the usual output,
+// e.g.
+//
+//	ezlog.New().WithJSONOutput().WithWriter(io.MultiWriter(os.Stdout, otel.NewOtelWriter(provider)))
+func NewOtelWriter(provider otellog.LoggerProvider) *Writer {
+	return &Writer{logger: provider.Logger(scopeName)}
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	return w.Write(p)
+}
+
+// Write implements io.Writer by emitting the JSON event p as a record.
+func (w *Writer) Write(p []byte) (int, error) {
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	var event map[string]any
+	if err := d.Decode(&event); err != nil {
+		return 0, errors.New("ezlog/otel: event is not a JSON object")
+	}
+
+	ctx := context.Background()
+	var r otellog.Record
+	r.SetObservedTimestamp(time.Now())
+	for key, value := range event {
+		switch key {
+		case zerolog.LevelFieldName:
+			s, _ := value.(string)
+			r.SetSeverityText(s)
+			if level, err := ezlog.ParseLevel(s); err == nil {
+				r.SetSeverity(severity(level))
+			}
+		case zerolog.TimestampFieldName:
+			if t, ok := parseTime(value); ok {
+				r.SetTimestamp(t)
+			}
+		case zerolog.MessageFieldName:
+			r.SetBody(toValue(value))
+		case TraceIDFieldName, SpanIDFieldName:
+		default:
+			r.AddAttributes(otellog.KeyValue{Key: key, Value: toValue(value)})
+		}
+	}
+	if sc := spanContext(event); sc.IsValid() {
+		ctx = trace.ContextWithSpanContext(ctx, sc)
+	}
+
+	w.logger.Emit(ctx, r)
+	return len(p), nil
+}
+
+// parseTime parses the timestamp of an event, which is RFC 3339 in ezlog's
+// JSON output. Timestamps without a date are not kept, as the record then
+// falls back to its observed time.
+func parseTime(v any) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t, err == nil
+}
+
+// spanContext returns the span context of the trace and span IDs of event,
+// which is invalid when they are missing or malformed.
+func spanContext(event map[string]any) trace.SpanContext {
+	var cfg trace.SpanContextConfig
+	if s, ok := event[TraceIDFieldName].(string); ok {
+		if b, err := hex.DecodeString(s); err == nil && len(b) == len(cfg.TraceID) {
+			copy(cfg.TraceID[:], b)
+		}
+	}
+	if s, ok := event[SpanIDFieldName].(string); ok {
+		if b, err := hex.DecodeString(s); err == nil && len(b) == len(cfg.SpanID) {
+			copy(cfg.SpanID[:], b)
+		}
+	}
+	return trace.NewSpanContext(cfg)
+}
+
+// severity returns the OpenTelemetry severity of a zerolog level.
+func severity(level zerolog.Level) otellog.Severity {
+	switch level {
+	case zerolog.TraceLevel:
+		return otellog.SeverityTrace
+	case zerolog.DebugLevel:
+		return otellog.SeverityDebug
+	case zerolog.InfoLevel:
+		return otellog.SeverityInfo
+	case zerolog.WarnLevel:
+		return otellog.SeverityWarn
+	case zerolog.ErrorLevel:
+		return otellog.SeverityError
+	case zerolog.FatalLevel:
+		return otellog.SeverityFatal
+	case zerolog.PanicLevel:
+		return otellog.SeverityFatal4
+	default:
+		return otellog.SeverityUndefined
+	}
+}
+
+// toValue converts a decoded JSON value to a log value.
+func toValue(v any) otellog.Value {
+	switch vv := v.(type) {
+	case string:
+		return otellog.StringValue(vv)
+	case json.Number:
+		if n, err := vv.Int64(); err == nil {
+			return otellog.Int64Value(n)
+		}
+		f, _ := vv.Float64()
+		return otellog.Float64Value(f)
+	case bool:
+		return otellog.BoolValue(vv)
+	case []any:
+		values := make([]otellog.Value, len(vv))
+		for i, e := range vv {
+			values[i] = toValue(e)
+		}
+		return otellog.SliceValue(values...)
+	case map[string]any:
+		kvs := make([]otellog.KeyValue, 0, len(vv))
+		for k, e := range vv {
+			kvs = append(kvs, otellog.KeyValue{Key: k, Value: toValue(e)})
+		}
+		return otellog.MapValue(kvs...)
+	default:
+		return otellog.Value{}
+	}
+}
+
+// TraceHook adds the trace and span IDs of the span in the context of
+// events, set with zerolog.Event.Ctx or zerolog.Context.Ctx, as the
+// "trace_id" and "span_id" fields Writer reads.
+type TraceHook struct{}
+
+// Run implements zerolog.Hook.
+func (TraceHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	sc := trace.SpanContextFromContext(e.GetCtx())
+	if !sc.IsValid() {
+		return
+	}
+	e.Str(TraceIDFieldName, sc.TraceID().String()).Str(SpanIDFieldName, sc.SpanID().String())
+}
+
+// Writer must satisfy zerolog.LevelWriter.
+var _ zerolog.LevelWriter = (*Writer)(nil)
@@ -0,0 +1,165 @@
+package otel
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ezydark/ezlog"
+	"github.com/rs/zerolog"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// memoryExporter keeps the exported records in memory.
+type memoryExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *memoryExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *memoryExporter) Shutdown(context.Context) error   { return nil }
+func (e *memoryExporter) ForceFlush(context.Context) error { return nil }
+
+func (e *memoryExporter) Records() []sdklog.Record {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]sdklog.Record(nil), e.records...)
+}
+
+// newProvider returns a provider exporting records to an in-memory exporter
+// as soon as they are emitted.
+func newProvider(t *testing.T) (*sdklog.LoggerProvider, *memoryExporter) {
+	t.Helper()
+	exp := &memoryExporter{}
+	p := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exp)))
+	t.Cleanup(func() { p.Shutdown(context.Background()) })
+	return p, exp
+}
+
+// attributes returns the attributes of r by key.
+func attributes(r sdklog.Record) map[string]otellog.Value {
+	attrs := make(map[string]otellog.Value)
+	r.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	return attrs
+}
+
+var (
+	traceID = trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanID  = trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+)
+
+func TestOtelWriter(t *testing.T) {
+	p, exp := newProvider(t)
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+	l := ezlog.New().AsLocal().WithWriter(NewOtelWriter(p)).WithJSONOutput().
+		WithTimestampFunc(func() time.Time { return ts }).Build()
+
+	l.Warn().
+		Str("user", "ada").Int("id", 7).Float64("ratio", 0.5).Bool("admin", true).
+		Strs("roles", []string{"a", "b"}).Dict("req", zerolog.Dict().Str("path", "/")).
+		Str(TraceIDFieldName, traceID.String()).Str(SpanIDFieldName, spanID.String()).
+		Msg("slow")
+
+	records := exp.Records()
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	r := records[0]
+	if r.Severity() != otellog.SeverityWarn || r.SeverityText() != "warn" || r.Body().AsString() != "slow" {
+		t.Errorf("severity = %v %q, body = %v", r.Severity(), r.SeverityText(), r.Body())
+	}
+	if !r.Timestamp().Equal(ts) || r.ObservedTimestamp().IsZero() {
+		t.Errorf("timestamp = %v, observed = %v", r.Timestamp(), r.ObservedTimestamp())
+	}
+	if r.TraceID() != traceID || r.SpanID() != spanID {
+		t.Errorf("trace = %v, span = %v", r.TraceID(), r.SpanID())
+	}
+	if r.InstrumentationScope().Name != scopeName {
+		t.Errorf("scope = %q", r.InstrumentationScope().Name)
+	}
+
+	attrs := attributes(r)
+	if len(attrs) != 6 {
+		t.Errorf("attributes = %v, want 6", attrs)
+	}
+	if attrs["user"].AsString() != "ada" || attrs["id"].AsInt64() != 7 || attrs["ratio"].AsFloat64() != 0.5 || !attrs["admin"].AsBool() {
+		t.Errorf("scalar attributes = %v", attrs)
+	}
+	if roles := attrs["roles"].AsSlice(); len(roles) != 2 || roles[1].AsString() != "b" {
+		t.Errorf("roles = %v", attrs["roles"])
+	}
+	if req := attrs["req"].AsMap(); len(req) != 1 || req[0].Key != "path" || req[0].Value.AsString() != "/" {
+		t.Errorf("req = %v", attrs["req"])
+	}
+}
+
+func TestOtelWriterInvalidEvents(t *testing.T) {
+	p, exp := newProvider(t)
+	w := NewOtelWriter(p)
+	if n, err := w.Write([]byte("not json\n")); n != 0 || err == nil {
+		t.Errorf("Write(not json) = %d, %v", n, err)
+	}
+	w.Write([]byte(`{"level":"info","time":"09:26:53","trace_id":"xyz","message":"m"}`))
+
+	records := exp.Records()
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if r := records[0]; !r.Timestamp().IsZero() || r.TraceID().IsValid() || len(attributes(r)) != 0 {
+		t.Errorf("record = time %v, trace %v, attributes %v", r.Timestamp(), r.TraceID(), attributes(r))
+	}
+}
+
+func TestTraceHook(t *testing.T) {
+	p, exp := newProvider(t)
+	l := zerolog.New(NewOtelWriter(p)).Hook(TraceHook{})
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	l.Info().Ctx(ctx).Msg("in span")
+	l.Info().Ctx(context.Background()).Msg("no span")
+
+	records := exp.Records()
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0].TraceID() != traceID || records[0].SpanID() != spanID {
+		t.Errorf("in span: trace = %v, span = %v", records[0].TraceID(), records[0].SpanID())
+	}
+	if records[1].TraceID().IsValid() {
+		t.Errorf("no span: trace = %v", records[1].TraceID())
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	for level, want := range map[zerolog.Level]otellog.Severity{
+		zerolog.TraceLevel: otellog.SeverityTrace,
+		zerolog.DebugLevel: otellog.SeverityDebug,
+		zerolog.InfoLevel:  otellog.SeverityInfo,
+		zerolog.WarnLevel:  otellog.SeverityWarn,
+		zerolog.ErrorLevel: otellog.SeverityError,
+		zerolog.FatalLevel: otellog.SeverityFatal,
+		zerolog.PanicLevel: otellog.SeverityFatal4,
+		zerolog.NoLevel:    otellog.SeverityUndefined,
+	} {
+		if got := severity(level); got != want {
+			t.Errorf("severity(%v) = %v, want %v", level, got, want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package ezlog
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// outputFlushInterval is how long events may stay in the output buffer.
+const outputFlushInterval = 100 * time.Millisecond
+
+// WithOutputBuffer buffers up to size bytes of output in memory, cutting the
+// number of write calls under high event rates. The buffer is written out
+// when it is full, every 100ms, right after error, fatal and panic events,
+// and on Shutdown.
+func (b *LogBuilder) WithOutputBuffer(size int) *LogBuilder {
+	b.outputBuffer = size
+	return b
+}
+
+// bufferedOutput is an io.Writer buffering the output of a logger.
+type bufferedOutput struct {
+	mu  sync.Mutex
+	out io.Writer
+	buf *bufio.Writer
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// newBufferedOutput creates a bufferedOutput of size bytes writing to out.
+func newBufferedOutput(out io.Writer, size int) *bufferedOutput {
+	w := &bufferedOutput{
+		out:  out,
+		buf:  bufio.NewWriterSize(out, size),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write implements io.Writer. The level of events written without it, as
+// ConsoleWriter does, is read from the event.
+func (w *bufferedOutput) Write(p []byte) (int, error) {
+	level := zerolog.NoLevel
+	if isLevelAtLeast(p, zerolog.ErrorLevel) {
+		level = zerolog.ErrorLevel
+	}
+	return w.WriteLevel(level, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *bufferedOutput) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n, err := w.buf.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if level >= zerolog.ErrorLevel && level <= zerolog.PanicLevel {
+		return n, w.buf.Flush()
+	}
+	return n, nil
+}
+
+// Flush writes the buffered output out.
+func (w *bufferedOutput) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Flush()
+}
+
+// Close flushes the buffer, stops the flush timer and closes the underlying
+// writer if it implements io.Closer and is not the standard output or error.
+func (w *bufferedOutput) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if w.out == os.Stdout || w.out == os.Stderr {
+		return nil
+	}
+	if c, ok := w.out.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// run flushes the buffer every outputFlushInterval until the writer is
+// closed.
+func (w *bufferedOutput) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(outputFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.Flush(); err != nil {
+				diag(zerolog.ErrorLevel, "flush", "buffered flush failed: %v", err)
+			}
+		}
+	}
+}
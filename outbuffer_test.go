@@ -0,0 +1,144 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestBufferedOutputFlushesByLevel(t *testing.T) {
+	var out lockedBuffer
+	w := newBufferedOutput(&out, 4096)
+	defer w.Close()
+
+	w.WriteLevel(zerolog.InfoLevel, []byte("first\n"))
+	if got := out.String(); got != "" {
+		t.Fatalf("info event flushed: %q", got)
+	}
+	w.WriteLevel(zerolog.ErrorLevel, []byte("second\n"))
+	if got := out.String(); got != "first\nsecond\n" {
+		t.Fatalf("after an error event: output = %q", got)
+	}
+	w.WriteLevel(zerolog.NoLevel, []byte(`{"level":"error"}`+"\n"))
+	if got := out.String(); got != "first\nsecond\n" {
+		t.Fatalf("event without level flushed: %q", got)
+	}
+}
+
+func TestBufferedOutputWriteReadsLevel(t *testing.T) {
+	var out lockedBuffer
+	w := newBufferedOutput(&out, 4096)
+	defer w.Close()
+
+	w.Write([]byte(`{"level":"info","message":"a"}` + "\n"))
+	if got := out.String(); got != "" {
+		t.Fatalf("info event flushed: %q", got)
+	}
+	w.Write([]byte(`{"level":"fatal","message":"b"}` + "\n"))
+	if got := out.String(); got == "" {
+		t.Fatal("fatal event not flushed")
+	}
+}
+
+func TestOutputBufferFlushesErrors(t *testing.T) {
+	var out lockedBuffer
+	l := New().AsLocal().WithWriter(&out).WithJSONOutput().WithOutputBuffer(4096).Build()
+
+	l.Info().Msg("buffered")
+	if got := out.String(); got != "" {
+		t.Fatalf("info event flushed: %q", got)
+	}
+	l.Error().Msg("failed")
+	if got := out.String(); !strings.Contains(got, "buffered") || !strings.Contains(got, "failed") {
+		t.Errorf("output = %q, want both events", got)
+	}
+	Shutdown(t.Context())
+}
+
+func TestBufferedOutputFlushesOnTimer(t *testing.T) {
+	var out lockedBuffer
+	w := newBufferedOutput(&out, 4096)
+	defer w.Close()
+
+	w.WriteLevel(zerolog.InfoLevel, []byte("idle\n"))
+	deadline := time.Now().Add(10 * outputFlushInterval)
+	for out.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(outputFlushInterval / 10)
+	}
+	if got := out.String(); got != "idle\n" {
+		t.Errorf("output = %q, want the event flushed by the timer", got)
+	}
+}
+
+func TestBufferedOutputCloseFlushes(t *testing.T) {
+	out := &closeBuffer{}
+	w := newBufferedOutput(out, 4096)
+	w.WriteLevel(zerolog.InfoLevel, []byte("pending\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "pending\n" || !out.closed {
+		t.Errorf("after Close: output = %q, closed = %v", got, out.closed)
+	}
+}
+
+// writeCounter counts the write calls made to it.
+type writeCounter struct {
+	writes atomic.Int64
+}
+
+func (c *writeCounter) Write(p []byte) (int, error) {
+	c.writes.Add(1)
+	return len(p), nil
+}
+
+func BenchmarkOutputBuffer(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		size int
+	}{
+		{"unbuffered", 0},
+		{"4KiB", 4096},
+		{"64KiB", 64 << 10},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var out writeCounter
+			builder := New().AsLocal().WithWriter(&out).WithJSONOutput()
+			if bc.size > 0 {
+				builder = builder.WithOutputBuffer(bc.size)
+			}
+			l := builder.Build()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				l.Info().Str("path", "/api/items").Int("status", 200).Msg("request served")
+			}
+			b.StopTimer()
+			Shutdown(b.Context())
+			b.ReportMetric(float64(out.writes.Load())/float64(b.N), "writes/op")
+		})
+	}
+}
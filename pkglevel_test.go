@@ -0,0 +1,92 @@
+package ezlog
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestMatchPackage(t *testing.T) {
+	tests := []struct {
+		pkg, key string
+		want     bool
+	}{
+		{"github.com/acme/app/internal/chatty", "internal/chatty", true},
+		{"github.com/acme/app/internal/chatty/sub", "internal/chatty", true},
+		{"github.com/acme/app/internal/chattybox", "internal/chatty", false},
+		{"github.com/acme/app/xinternal/chatty", "internal/chatty", false},
+		{"internal/chatty", "internal/chatty", true},
+	}
+	for _, tt := range tests {
+		if got := matchPackage(tt.pkg, tt.key); got != tt.want {
+			t.Errorf("matchPackage(%q, %q) = %v, want %v", tt.pkg, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestPackageLevelLongestMatch(t *testing.T) {
+	h := newPackageLevelHook(map[string]zerolog.Level{
+		"internal/chatty":     zerolog.WarnLevel,
+		"internal/chatty/sub": zerolog.DebugLevel,
+	})
+	if level, _ := h.match("github.com/acme/app/internal/chatty/sub"); level != zerolog.DebugLevel {
+		t.Errorf("sub level = %v, want debug", level)
+	}
+	if level, _ := h.match("github.com/acme/app/internal/chatty"); level != zerolog.WarnLevel {
+		t.Errorf("chatty level = %v, want warn", level)
+	}
+	if _, ok := h.match("github.com/acme/app/api"); ok {
+		t.Error("unrelated package matched")
+	}
+}
+
+func TestFuncPackage(t *testing.T) {
+	for fn, want := range map[string]string{
+		"github.com/acme/app/db.(*DB).Query": "github.com/acme/app/db",
+		"github.com/acme/app.main":           "github.com/acme/app",
+		"main.main.func1":                    "main",
+	} {
+		if got := funcPackage(fn); got != want {
+			t.Errorf("funcPackage(%q) = %q, want %q", fn, got, want)
+		}
+	}
+}
+
+func TestWithPackageLevels(t *testing.T) {
+	// ezlog frames, tests included, are skipped, so events logged here are
+	// attributed to the testing package running the test.
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().
+		WithPackageLevels(map[string]zerolog.Level{"testing": zerolog.WarnLevel}).Build()
+	l.Info().Msg("dropped")
+	l.Warn().Msg("kept")
+
+	if got := buf.String(); strings.Contains(got, "dropped") || !strings.Contains(got, "kept") {
+		t.Errorf("output = %s", got)
+	}
+}
+
+func BenchmarkPackageLevels(b *testing.B) {
+	for _, tt := range []struct {
+		name   string
+		levels map[string]zerolog.Level
+	}{
+		{"none", nil},
+		{"levels", map[string]zerolog.Level{"internal/chatty": zerolog.WarnLevel, "testing": zerolog.DebugLevel}},
+	} {
+		b.Run(tt.name, func(b *testing.B) {
+			lb := New().AsLocal().WithWriter(io.Discard).WithJSONOutput()
+			if tt.levels != nil {
+				lb = lb.WithPackageLevels(tt.levels)
+			}
+			l := lb.Build()
+			b.ReportAllocs()
+			for b.Loop() {
+				l.Info().Str("k", "v").Msg("event")
+			}
+		})
+	}
+}
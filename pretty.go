@@ -0,0 +1,210 @@
+package ezlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// PrettyOption configures Pretty.
+type PrettyOption func(*prettyConfig)
+
+// prettyConfig holds the settings of Pretty.
+type prettyConfig struct {
+	builder  *LogBuilder
+	minLevel zerolog.Level
+	from, to time.Time
+}
+
+// WithPrettyBuilder renders events with the console configuration of b, such
+// as its time format, level names and field humanization. Its writer and tag
+// are ignored. A New builder is used by default.
+func WithPrettyBuilder(b *LogBuilder) PrettyOption {
+	return func(c *prettyConfig) {
+		c.builder = b
+	}
+}
+
+// WithPrettyMinLevel skips events below level.
+func WithPrettyMinLevel(level zerolog.Level) PrettyOption {
+	return func(c *prettyConfig) {
+		c.minLevel = level
+	}
+}
+
+// WithPrettyTimeRange skips events outside [from, to). A zero bound is open.
+func WithPrettyTimeRange(from, to time.Time) PrettyOption {
+	return func(c *prettyConfig) {
+		c.from = from
+		c.to = to
+	}
+}
+
+// Pretty re-renders the NDJSON events read from r, such as a file written
+// with WithJSONOutput, as colored console lines on w. The "tag" field becomes
+// the [tag] prefix again. Lines that are not JSON objects are echoed verbatim.
+// Events without a parseable time or level are never filtered out.
+func Pretty(r io.Reader, w io.Writer, opts ...PrettyOption) error {
+	pr := newPrettyRenderer(w, opts)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for sc.Scan() {
+		if err := pr.renderLine(sc.Bytes()); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// PrettyPrinter is an io.Writer re-rendering the NDJSON events written to it
+// as colored console lines, like Pretty, e.g. to pretty-print the output of
+// an application logging zerolog JSON. Partial lines are kept until their
+// newline is written or the printer is flushed.
+// It should be created using NewPrettyPrinter.
+type PrettyPrinter struct {
+	mu  sync.Mutex
+	pr  *prettyRenderer
+	buf []byte
+}
+
+// NewPrettyPrinter creates a PrettyPrinter writing to out.
+func NewPrettyPrinter(out io.Writer, opts ...PrettyOption) *PrettyPrinter {
+	return &PrettyPrinter{pr: newPrettyRenderer(out, opts)}
+}
+
+// Write implements io.Writer.
+func (p *PrettyPrinter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := p.buf[:i]
+		p.buf = p.buf[i+1:]
+		if err := p.pr.renderLine(line); err != nil {
+			return len(b), err
+		}
+	}
+	if len(p.buf) == 0 {
+		p.buf = nil
+	}
+	return len(b), nil
+}
+
+// Flush renders the pending partial line, if any.
+func (p *PrettyPrinter) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.buf) == 0 {
+		return nil
+	}
+	line := p.buf
+	p.buf = nil
+	return p.pr.renderLine(line)
+}
+
+// Close flushes the printer.
+func (p *PrettyPrinter) Close() error {
+	return p.Flush()
+}
+
+// prettyRenderer renders NDJSON lines as console lines.
+type prettyRenderer struct {
+	cfg     prettyConfig
+	w       io.Writer
+	out     io.Writer
+	writers map[string]zerolog.ConsoleWriter
+}
+
+// newPrettyRenderer creates a prettyRenderer writing to w.
+func newPrettyRenderer(w io.Writer, opts []PrettyOption) *prettyRenderer {
+	cfg := prettyConfig{builder: New(), minLevel: zerolog.TraceLevel}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	out := w
+	if cfg.builder.colorLevel.resolve() == ColorLevelNone {
+		out = stripANSIWriter{out}
+	}
+	return &prettyRenderer{cfg: cfg, w: w, out: out, writers: make(map[string]zerolog.ConsoleWriter)}
+}
+
+// writerFor returns the console writer of tag.
+func (pr *prettyRenderer) writerFor(tag string) zerolog.ConsoleWriter {
+	cw, ok := pr.writers[tag]
+	if !ok {
+		b := *pr.cfg.builder
+		b.tag = tag
+		b.writer = pr.w
+		cw = b.consoleWriter(pr.out)
+		pr.writers[tag] = cw
+	}
+	return cw
+}
+
+// renderLine renders the event line, echoing it verbatim if it is not a JSON
+// object.
+func (pr *prettyRenderer) renderLine(line []byte) error {
+	var evt map[string]any
+	d := json.NewDecoder(bytes.NewReader(line))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		_, err := pr.w.Write(append(line, '\n'))
+		return err
+	}
+	if !pr.cfg.keep(evt) {
+		return nil
+	}
+
+	tag, _ := evt["tag"].(string)
+	delete(evt, "tag")
+	p, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, err = pr.writerFor(tag).Write(p)
+	return err
+}
+
+// keep reports whether evt passes the level and time filters.
+func (c *prettyConfig) keep(evt map[string]any) bool {
+	if s, ok := evt[zerolog.LevelFieldName].(string); ok {
+		if level, err := ParseLevel(s); err == nil && level < c.minLevel {
+			return false
+		}
+	}
+	if c.from.IsZero() && c.to.IsZero() {
+		return true
+	}
+	t, ok := parseEventTime(evt[zerolog.TimestampFieldName])
+	if !ok {
+		return true
+	}
+	return (c.from.IsZero() || !t.Before(c.from)) && (c.to.IsZero() || t.Before(c.to))
+}
+
+// parseEventTime parses the timestamp of a decoded event, written either with
+// RFC 3339 or with zerolog.TimeFieldFormat by other zerolog loggers.
+func parseEventTime(v any) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{time.RFC3339Nano, zerolog.TimeFieldFormat} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
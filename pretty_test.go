@@ -0,0 +1,118 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// logPrettyEvents logs the events used to compare Pretty with console output.
+func logPrettyEvents(l *zerolog.Logger) {
+	l.Info().Str("user", "ada").Msg("login")
+	l.Warn().Int("attempts", 3).Msg("retrying")
+	l.Error().Str("path", "/api").Msg("failed")
+}
+
+func TestPrettyMatchesConsoleOutput(t *testing.T) {
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	var jsonOut, console bytes.Buffer
+	logPrettyEvents(newTestBuilder(&jsonOut).WithTag("api").WithJSONOutput().WithTimestampFunc(fixedClock(ts)).Build())
+	logPrettyEvents(newTestBuilder(&console).WithTag("api").WithTimestampFunc(fixedClock(ts)).Build())
+
+	var pretty bytes.Buffer
+	b := New().WithColorSupport(ColorLevelNone)
+	if err := Pretty(&jsonOut, &pretty, WithPrettyBuilder(b)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(console.String(), "[api]") {
+		t.Fatalf("console output %q lacks the tag", console.String())
+	}
+	if pretty.String() != console.String() {
+		t.Errorf("Pretty output:\n%s\nconsole output:\n%s", pretty.String(), console.String())
+	}
+}
+
+func TestPrettyFilters(t *testing.T) {
+	input := strings.Join([]string{
+		`{"level":"debug","time":"2025-03-14T09:00:00Z","message":"early debug"}`,
+		`{"level":"warn","time":"2025-03-14T09:00:00Z","message":"early warn"}`,
+		`{"level":"error","time":"2025-03-14T10:00:00Z","message":"late error"}`,
+		`{"level":"error","message":"no time"}`,
+		`plain text line`,
+	}, "\n")
+
+	tests := []struct {
+		name string
+		opts []PrettyOption
+		want []string
+	}{
+		{"min level", []PrettyOption{WithPrettyMinLevel(zerolog.WarnLevel)},
+			[]string{"early warn", "late error", "no time", "plain text line"}},
+		{"time range", []PrettyOption{WithPrettyTimeRange(time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC), time.Time{})},
+			[]string{"late error", "no time", "plain text line"}},
+		{"closed range", []PrettyOption{WithPrettyTimeRange(time.Time{}, time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC))},
+			[]string{"early debug", "early warn", "no time", "plain text line"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			opts := append([]PrettyOption{WithPrettyBuilder(New().WithColorSupport(ColorLevelNone))}, tt.opts...)
+			if err := Pretty(strings.NewReader(input), &out, opts...); err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("output = %q, want %d lines", out.String(), len(tt.want))
+			}
+			for i, msg := range tt.want {
+				if !strings.HasSuffix(lines[i], msg) {
+					t.Errorf("line %d = %q, want %q", i, lines[i], msg)
+				}
+			}
+		})
+	}
+}
+
+func TestPrettyPrinterPartialLines(t *testing.T) {
+	var out bytes.Buffer
+	p := NewPrettyPrinter(&out, WithPrettyBuilder(New().WithColorSupport(ColorLevelNone)))
+	p.Write([]byte(`{"level":"info","tag":"db","mess`))
+	if out.Len() != 0 {
+		t.Fatalf("partial line rendered: %q", out.String())
+	}
+	p.Write([]byte(`age":"done"}` + "\n" + `{"level":"warn","message":"tail"}`))
+	if !strings.Contains(out.String(), "[INFO] [db] done") || strings.Contains(out.String(), "tail") {
+		t.Fatalf("output = %q", out.String())
+	}
+	p.Close()
+	if !strings.Contains(out.String(), "[WARN] tail") {
+		t.Errorf("output after Close = %q", out.String())
+	}
+}
+
+func TestPrettyPrinterMatchesPretty(t *testing.T) {
+	var jsonOut bytes.Buffer
+	logPrettyEvents(newTestBuilder(&jsonOut).WithTag("api").WithJSONOutput().Build())
+	jsonOut.WriteString("not json\n")
+	opts := []PrettyOption{WithPrettyBuilder(New().WithColorSupport(ColorLevelNone)), WithPrettyMinLevel(zerolog.WarnLevel)}
+
+	var want bytes.Buffer
+	if err := Pretty(bytes.NewReader(jsonOut.Bytes()), &want, opts...); err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	p := NewPrettyPrinter(&got, opts...)
+	for _, c := range jsonOut.Bytes() {
+		if _, err := p.Write([]byte{c}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Close()
+
+	if got.String() != want.String() || strings.Count(got.String(), "\n") != 3 {
+		t.Errorf("PrettyPrinter output:\n%s\nPretty output:\n%s", got.String(), want.String())
+	}
+}
@@ -0,0 +1,179 @@
+package ezlog
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"weak"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// progressBarWidth is the number of cells of the progress bar.
+	progressBarWidth = 30
+	// clearLine moves to the start of the line and erases it.
+	clearLine = "\r\x1b[2K"
+)
+
+// spinnerFrames are the frames of the spinner shown when the total is unknown.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// progressWriters maps the loggers built for a terminal to the writer that
+// keeps their progress line below the log events. Loggers are held weakly
+// and their entry is removed once they are garbage collected.
+var progressWriters sync.Map // map[weak.Pointer[zerolog.Logger]]*progressWriter
+
+// storeProgressWriter registers w as the progress writer of l.
+func storeProgressWriter(l *zerolog.Logger, w *progressWriter) {
+	key := weak.Make(l)
+	progressWriters.Store(key, w)
+	if l == &log.Logger {
+		// The global logger is never collected, its entry is just replaced.
+		return
+	}
+	runtime.AddCleanup(l, func(key weak.Pointer[zerolog.Logger]) {
+		progressWriters.Delete(key)
+	}, key)
+}
+
+// progressWriter writes log events to a terminal while a progress line is
+// shown: the line is erased before each event and drawn again after it.
+type progressWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+	bar string
+}
+
+// Write implements io.Writer.
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.bar == "" {
+		return w.out.Write(p)
+	}
+	if _, err := io.WriteString(w.out, clearLine); err != nil {
+		return 0, err
+	}
+	n, err := w.out.Write(p)
+	if err != nil {
+		return n, err
+	}
+	_, err = io.WriteString(w.out, w.bar)
+	return n, err
+}
+
+// draw replaces the progress line with bar; an empty bar removes it.
+func (w *progressWriter) draw(bar string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if bar == w.bar {
+		return
+	}
+	_, _ = io.WriteString(w.out, clearLine+bar)
+	w.bar = bar
+}
+
+// Progress reports the progress of a long task. On a terminal it draws a
+// colored bar, or a spinner when the total is unknown, updated in place below
+// the log events. Otherwise it logs an info event every 10%.
+// It should be created using NewProgressEvent.
+type Progress struct {
+	mu      sync.Mutex
+	l       *zerolog.Logger
+	w       *progressWriter
+	msg     string
+	total   int
+	current int
+	step    int
+	frame   int
+	start   time.Time
+	done    bool
+}
+
+// NewProgressEvent starts reporting the progress of msg towards total on l.
+// A total <= 0 means the total is unknown.
+func NewProgressEvent(l *zerolog.Logger, total int, msg string) *Progress {
+	p := &Progress{l: l, msg: msg, total: total, start: time.Now()}
+	if w, ok := progressWriters.Load(weak.Make(l)); ok {
+		p.w = w.(*progressWriter)
+		p.w.draw(p.render())
+	}
+	return p
+}
+
+// Increment advances the progress by n.
+func (p *Progress) Increment(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done {
+		return
+	}
+	p.current += n
+	if p.total > 0 {
+		p.current = min(p.current, p.total)
+	}
+
+	if p.w != nil {
+		p.frame++
+		p.w.draw(p.render())
+		return
+	}
+	if p.total <= 0 {
+		return
+	}
+	if step := p.current * 10 / p.total; step > p.step {
+		p.step = step
+		p.l.Info().
+			Int("current", p.current).
+			Int("total", p.total).
+			Int("percent", step*10).
+			Msg(p.msg)
+	}
+}
+
+// Done removes the progress line and logs an info event with the final count
+// and the elapsed time.
+func (p *Progress) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done {
+		return
+	}
+	p.done = true
+	if p.w != nil {
+		p.w.draw("")
+	}
+
+	e := p.l.Info().Int("current", p.current)
+	if p.total > 0 {
+		e = e.Int("total", p.total)
+	}
+	e.Dur("elapsed", time.Since(p.start)).Msg(p.msg + " done")
+}
+
+// render returns the progress line.
+func (p *Progress) render() string {
+	if p.total <= 0 {
+		return fmt.Sprintf("%s %s %s",
+			cyanColor.Sprint(spinnerFrames[p.frame%len(spinnerFrames)]),
+			p.msg,
+			grayColor.Sprintf("(%d)", p.current))
+	}
+
+	filled := p.current * progressBarWidth / p.total
+	return fmt.Sprintf("%s %s%s %s %s",
+		p.msg,
+		greenColor.Sprint(strings.Repeat("█", filled)),
+		grayColor.Sprint(strings.Repeat("░", progressBarWidth-filled)),
+		yellowColor.Sprintf("%3d%%", p.current*100/p.total),
+		grayColor.Sprintf("(%d/%d)", p.current, p.total))
+}
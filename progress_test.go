@@ -0,0 +1,65 @@
+package ezlog
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"weak"
+
+	"github.com/rs/zerolog"
+)
+
+func TestProgressLogsEveryTenPercent(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+
+	p := NewProgressEvent(l, 40, "import")
+	for range 40 {
+		p.Increment(1)
+	}
+	p.Done()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 11 {
+		t.Fatalf("got %d events, want 10 steps and done:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], `"percent":10`) || !strings.Contains(lines[9], `"percent":100`) {
+		t.Errorf("steps = %s ... %s", lines[0], lines[9])
+	}
+	if !strings.Contains(lines[10], `"message":"import done"`) || !strings.Contains(lines[10], `"current":40`) {
+		t.Errorf("done = %s", lines[10])
+	}
+}
+
+func TestProgressWriterRedrawsBar(t *testing.T) {
+	var buf bytes.Buffer
+	w := &progressWriter{out: &buf}
+	w.draw("bar")
+	w.Write([]byte("event\n"))
+	w.draw("")
+
+	if want := clearLine + "bar" + clearLine + "event\nbar" + clearLine; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestProgressWritersDropCollectedLoggers(t *testing.T) {
+	l := zerolog.New(nil)
+	key := weak.Make(&l)
+	storeProgressWriter(&l, &progressWriter{})
+	if _, ok := progressWriters.Load(key); !ok {
+		t.Fatal("progress writer not stored")
+	}
+
+	runtime.KeepAlive(&l)
+	for range 50 {
+		runtime.GC()
+		if _, ok := progressWriters.Load(key); !ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("progress writer kept after the logger was collected")
+}
@@ -0,0 +1,59 @@
+package prometheus
+
+import (
+	"sync"
+
+	"github.com/ezydark/ezlog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultMaxTags is the default WithMaxTags limit of WithEventMetrics.
+const DefaultMaxTags = 100
+
+// EventMetricsOption configures WithEventMetrics.
+type EventMetricsOption func(*PrometheusMetricsExporter)
+
+// WithMaxTags bounds the number of distinct tag labels to n, DefaultMaxTags
+// by default; see PrometheusMetricsExporter.WithMaxTags.
+func WithMaxTags(n int) EventMetricsOption {
+	return func(e *PrometheusMetricsExporter) {
+		e.WithMaxTags(n)
+	}
+}
+
+// eventExporters holds the exporter of each registry used with
+// WithEventMetrics, so the loggers registered with a registry share their
+// metrics and tag limit.
+var eventExporters struct {
+	sync.Mutex
+	m map[prometheus.Registerer]*PrometheusMetricsExporter
+}
+
+// WithEventMetrics makes the logger built by b count its written events in
+// reg, prometheus.DefaultRegisterer if nil, as the ezlog.MetricLogEvents
+// counter labeled by level and tag, and export its minimum level as the
+// ezlog.MetricLogLevel gauge. Events dropped by the levels, samplers, rate
+// limit or deduplication are not counted. Alerting on the rate of error
+// events is then a matter of:
+//
+//	rate(log_events_total{level="error"}[5m])
+func WithEventMetrics(b *ezlog.LogBuilder, reg prometheus.Registerer, opts ...EventMetricsOption) *ezlog.LogBuilder {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	eventExporters.Lock()
+	if eventExporters.m == nil {
+		eventExporters.m = make(map[prometheus.Registerer]*PrometheusMetricsExporter)
+	}
+	e, ok := eventExporters.m[reg]
+	if !ok {
+		e = NewPrometheusMetricsExporter(reg).WithMaxTags(DefaultMaxTags)
+		eventExporters.m[reg] = e
+	}
+	eventExporters.Unlock()
+
+	for _, opt := range opts {
+		opt(e)
+	}
+	return b.WithMetrics(e)
+}
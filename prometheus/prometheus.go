@@ -0,0 +1,174 @@
+// Package prometheus exports ezlog metrics to Prometheus. It is a separate
+// package to keep the Prometheus client out of programs that do not import
+// it.
+package prometheus
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ezydark/ezlog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OtherTag is the tag label value of the tags beyond the WithMaxTags limit.
+const OtherTag = "other"
+
+// PrometheusMetricsExporter is an ezlog.MetricsExporter registering a
+// counter or histogram vector for each metric the first time it is used,
+// with the label names it is first used with. Uses of a metric with other
+// label names are ignored, as Prometheus requires fixed label names.
+// It should be created using NewPrometheusMetricsExporter.
+type PrometheusMetricsExporter struct {
+	reg     prometheus.Registerer
+	buckets []float64
+
+	mu         sync.Mutex
+	counters   map[string]*prometheus.CounterVec
+	histograms map[string]*prometheus.HistogramVec
+	maxTags    int
+	tags       map[string]struct{}
+}
+
+// NewPrometheusMetricsExporter creates a PrometheusMetricsExporter
+// registering its metrics with reg, prometheus.DefaultRegisterer if nil.
+// Histograms use buckets, prometheus.DefBuckets if none are given.
+func NewPrometheusMetricsExporter(reg prometheus.Registerer, buckets ...float64) *PrometheusMetricsExporter {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+	return &PrometheusMetricsExporter{
+		reg:        reg,
+		buckets:    buckets,
+		counters:   make(map[string]*prometheus.CounterVec),
+		histograms: make(map[string]*prometheus.HistogramVec),
+		tags:       make(map[string]struct{}),
+	}
+}
+
+// WithMaxTags bounds the number of distinct values of the "tag" label to n,
+// counting the other tags under OtherTag, so that loggers created with
+// dynamic tags cannot create an unbounded number of series. 0 means no
+// limit.
+func (e *PrometheusMetricsExporter) WithMaxTags(n int) *PrometheusMetricsExporter {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.maxTags = n
+	return e
+}
+
+// IncrCounter implements ezlog.MetricsExporter.
+func (e *PrometheusMetricsExporter) IncrCounter(name string, labels map[string]string) {
+	e.mu.Lock()
+	labels = e.limitTags(labels)
+	vec, ok := e.counters[name]
+	if !ok {
+		vec = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: name,
+			Help: "ezlog counter " + name + ".",
+		}, labelNames(labels))
+		vec = register(e.reg, vec)
+		e.counters[name] = vec
+	}
+	e.mu.Unlock()
+
+	if c, err := vec.GetMetricWith(labels); err == nil {
+		c.Inc()
+	}
+}
+
+// RecordHistogram implements ezlog.MetricsExporter.
+func (e *PrometheusMetricsExporter) RecordHistogram(name string, value float64, labels map[string]string) {
+	e.mu.Lock()
+	labels = e.limitTags(labels)
+	vec, ok := e.histograms[name]
+	if !ok {
+		vec = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    name,
+			Help:    "ezlog histogram " + name + ".",
+			Buckets: e.buckets,
+		}, labelNames(labels))
+		vec = register(e.reg, vec)
+		e.histograms[name] = vec
+	}
+	e.mu.Unlock()
+
+	if h, err := vec.GetMetricWith(labels); err == nil {
+		h.Observe(value)
+	}
+}
+
+// RegisterGaugeFunc implements ezlog.GaugeFuncExporter. A gauge registered
+// again with the same labels replaces the previous one.
+func (e *PrometheusMetricsExporter) RegisterGaugeFunc(name string, labels map[string]string, fn func() float64) {
+	e.mu.Lock()
+	labels = e.limitTags(labels)
+	e.mu.Unlock()
+
+	g := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        name,
+		Help:        "ezlog gauge " + name + ".",
+		ConstLabels: labels,
+	}, fn)
+	if err := e.reg.Register(g); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			e.reg.Unregister(are.ExistingCollector)
+			_ = e.reg.Register(g)
+		}
+	}
+}
+
+// limitTags returns labels with the tag replaced by OtherTag if it is beyond
+// the WithMaxTags limit. It must be called with e.mu held.
+func (e *PrometheusMetricsExporter) limitTags(labels map[string]string) map[string]string {
+	tag, ok := labels["tag"]
+	if !ok || e.maxTags <= 0 {
+		return labels
+	}
+	if _, seen := e.tags[tag]; seen {
+		return labels
+	}
+	if len(e.tags) < e.maxTags {
+		e.tags[tag] = struct{}{}
+		return labels
+	}
+	limited := make(map[string]string, len(labels))
+	for name, value := range labels {
+		limited[name] = value
+	}
+	limited["tag"] = OtherTag
+	return limited
+}
+
+// register registers c with reg, returning the collector already registered
+// in its place if any, e.g. by another exporter over the same registry.
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	if err := reg.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if existing, ok := are.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+	}
+	return c
+}
+
+// labelNames returns the sorted names of labels.
+func labelNames(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PrometheusMetricsExporter must satisfy ezlog.MetricsExporter and
+// ezlog.GaugeFuncExporter.
+var (
+	_ ezlog.MetricsExporter   = (*PrometheusMetricsExporter)(nil)
+	_ ezlog.GaugeFuncExporter = (*PrometheusMetricsExporter)(nil)
+)
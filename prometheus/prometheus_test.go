@@ -0,0 +1,132 @@
+package prometheus
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ezydark/ezlog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+)
+
+func TestPrometheusMetricsExporter(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	e := NewPrometheusMetricsExporter(reg, 0.1, 1)
+	e.IncrCounter("queries_total", map[string]string{"status": "ok", "slow": "false"})
+	e.IncrCounter("queries_total", map[string]string{"status": "ok", "slow": "false"})
+	e.IncrCounter("queries_total", map[string]string{"status": "error", "slow": "true"})
+	e.IncrCounter("queries_total", map[string]string{"status": "ok"})
+	e.RecordHistogram("query_duration_seconds", 0.05, map[string]string{"status": "ok"})
+	e.RecordHistogram("query_duration_seconds", 0.5, map[string]string{"status": "ok"})
+
+	want := `
+# HELP queries_total ezlog counter queries_total.
+# TYPE queries_total counter
+queries_total{slow="false",status="ok"} 2
+queries_total{slow="true",status="error"} 1
+# HELP query_duration_seconds ezlog histogram query_duration_seconds.
+# TYPE query_duration_seconds histogram
+query_duration_seconds_bucket{status="ok",le="0.1"} 1
+query_duration_seconds_bucket{status="ok",le="1"} 2
+query_duration_seconds_bucket{status="ok",le="+Inf"} 2
+query_duration_seconds_sum{status="ok"} 0.55
+query_duration_seconds_count{status="ok"} 2
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPrometheusMetricsExportersShareRegistry(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	NewPrometheusMetricsExporter(reg).IncrCounter("n_total", map[string]string{"k": "a"})
+	NewPrometheusMetricsExporter(reg).IncrCounter("n_total", map[string]string{"k": "a"})
+
+	want := `
+# HELP n_total ezlog counter n_total.
+# TYPE n_total counter
+n_total{k="a"} 2
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPrometheusGormMetrics(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	var e ezlog.MetricsExporter = NewPrometheusMetricsExporter(reg)
+	e.IncrCounter(ezlog.MetricGormQueries, map[string]string{"status": "ok", "slow": "false"})
+	if n, err := testutil.GatherAndCount(reg, ezlog.MetricGormQueries); err != nil || n != 1 {
+		t.Errorf("%s series = %d, %v", ezlog.MetricGormQueries, n, err)
+	}
+}
+
+func TestWithEventMetrics(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	for _, tag := range []string{"api", "db", "cache", "queue"} {
+		l := WithEventMetrics(ezlog.New().AsLocal().WithWriter(io.Discard).WithJSONOutput().WithTag(tag).
+			WithLevel(zerolog.InfoLevel), reg, WithMaxTags(2)).Build()
+		l.Debug().Msg("dropped")
+		l.Info().Msg("served")
+		if tag == "api" {
+			l.Error().Msg("failed")
+			l.Error().Msg("failed")
+		}
+	}
+
+	want := `
+# HELP log_events_total ezlog counter log_events_total.
+# TYPE log_events_total counter
+log_events_total{level="error",tag="api"} 2
+log_events_total{level="info",tag="api"} 1
+log_events_total{level="info",tag="db"} 1
+log_events_total{level="info",tag="other"} 2
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), ezlog.MetricLogEvents); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithEventMetricsLevelGauge(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	dl := ezlog.NewDynamicLevel(zerolog.InfoLevel)
+	WithEventMetrics(ezlog.New().AsLocal().WithWriter(io.Discard).WithTag("api").WithDynamicLevel(dl), reg).Build()
+	// Building the logger again replaces its gauge.
+	WithEventMetrics(ezlog.New().AsLocal().WithWriter(io.Discard).WithTag("api").WithDynamicLevel(dl), reg).Build()
+
+	gauge := func(want string) string {
+		return `
+# HELP log_level ezlog gauge log_level.
+# TYPE log_level gauge
+log_level{tag="api"} ` + want + "\n"
+	}
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(gauge("1")), ezlog.MetricLogLevel); err != nil {
+		t.Error(err)
+	}
+	dl.SetLevel(zerolog.ErrorLevel)
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(gauge("3")), ezlog.MetricLogLevel); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMaxTags(t *testing.T) {
+	e := NewPrometheusMetricsExporter(prometheus.NewPedanticRegistry()).WithMaxTags(1)
+	labels := map[string]string{"tag": "b", "level": "info"}
+	for _, tt := range []struct {
+		labels map[string]string
+		want   string
+	}{
+		{map[string]string{"tag": "a"}, "a"},
+		{labels, OtherTag},
+		{map[string]string{"tag": "a"}, "a"},
+	} {
+		if got := e.limitTags(tt.labels)["tag"]; got != tt.want {
+			t.Errorf("limitTags(%v) tag = %q, want %q", tt.labels, got, tt.want)
+		}
+	}
+	if labels["tag"] != "b" {
+		t.Error("limitTags modified the labels of its caller")
+	}
+}
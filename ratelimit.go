@@ -0,0 +1,187 @@
+package ezlog
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/time/rate"
+)
+
+// defaultRateLimitKeys is the default number of keys tracked by WithRateLimit.
+const defaultRateLimitKeys = 10000
+
+// SuppressedFieldName is the field counting the events dropped by
+// WithRateLimit since the previous event of the same key.
+const SuppressedFieldName = "suppressed"
+
+// RateLimitOption configures WithRateLimit.
+type RateLimitOption func(*rateLimitWriter)
+
+// WithRateLimitMaxKeys sets how many keys are tracked at once, 10000 by
+// default. The least recently seen key is forgotten first, which resets its
+// bucket.
+func WithRateLimitMaxKeys(n int) RateLimitOption {
+	return func(w *rateLimitWriter) {
+		w.maxKeys = n
+	}
+}
+
+// WithRateLimitClock sets the clock used to refill the buckets, mostly so
+// tests can control it.
+func WithRateLimitClock(now func() time.Time) RateLimitOption {
+	return func(w *rateLimitWriter) {
+		w.now = now
+	}
+}
+
+// WithRateLimit lets through at most burst events at once, then limit events
+// per second, for every distinct value of keyField; e.g.
+// WithRateLimit("user_id", rate.Every(time.Minute), 1) logs at most one event
+// per user per minute. The next event let through for a key carries the
+// number of events dropped in between in the "suppressed" field. Events
+// without keyField are never limited.
+func (b *LogBuilder) WithRateLimit(keyField string, limit rate.Limit, burst int, opts ...RateLimitOption) *LogBuilder {
+	b.rateLimit = &rateLimitConfig{key: keyField, limit: limit, burst: burst, opts: opts}
+	return b
+}
+
+// rateLimitConfig holds the WithRateLimit settings until Build.
+type rateLimitConfig struct {
+	key   string
+	limit rate.Limit
+	burst int
+	opts  []RateLimitOption
+}
+
+// rateLimitWriter drops JSON events whose key is over its token bucket. It
+// works on the encoded events because zerolog hooks cannot read the fields of
+// the event they run on.
+type rateLimitWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	key     string
+	limit   rate.Limit
+	burst   int
+	maxKeys int
+	now     func() time.Time
+
+	lru     *list.List
+	buckets map[string]*list.Element
+}
+
+// tokenBucket is the state of one key. It lives in the LRU list.
+type tokenBucket struct {
+	key        string
+	tokens     float64
+	last       time.Time
+	suppressed int
+}
+
+// newRateLimitWriter creates a rateLimitWriter writing to out.
+func newRateLimitWriter(out io.Writer, cfg *rateLimitConfig) *rateLimitWriter {
+	w := &rateLimitWriter{
+		out:     out,
+		key:     cfg.key,
+		limit:   cfg.limit,
+		burst:   cfg.burst,
+		maxKeys: defaultRateLimitKeys,
+		now:     time.Now,
+		lru:     list.New(),
+		buckets: make(map[string]*list.Element),
+	}
+	for _, opt := range cfg.opts {
+		opt(w)
+	}
+	return w
+}
+
+// Write implements io.Writer.
+func (w *rateLimitWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *rateLimitWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	var evt map[string]any
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return writeLevel(w.out, level, p)
+	}
+	v, ok := evt[w.key]
+	if !ok {
+		return writeLevel(w.out, level, p)
+	}
+
+	allowed, suppressed := w.take(fmt.Sprint(v))
+	if !allowed {
+		return len(p), nil
+	}
+	if suppressed > 0 {
+		if _, err := writeLevel(w.out, level, withSuppressed(p, suppressed)); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	return writeLevel(w.out, level, p)
+}
+
+// take consumes a token of key. It returns whether the event is allowed and,
+// if so, how many events of the key were dropped since the last allowed one.
+func (w *rateLimitWriter) take(key string) (bool, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	var b *tokenBucket
+	if el, ok := w.buckets[key]; ok {
+		w.lru.MoveToFront(el)
+		b = el.Value.(*tokenBucket)
+		if w.limit == rate.Inf {
+			b.tokens = float64(w.burst)
+		} else {
+			b.tokens = min(float64(w.burst), b.tokens+now.Sub(b.last).Seconds()*float64(w.limit))
+		}
+		b.last = now
+	} else {
+		b = &tokenBucket{key: key, tokens: float64(w.burst), last: now}
+		w.buckets[key] = w.lru.PushFront(b)
+		for w.maxKeys > 0 && w.lru.Len() > w.maxKeys {
+			oldest := w.lru.Back()
+			w.lru.Remove(oldest)
+			delete(w.buckets, oldest.Value.(*tokenBucket).key)
+		}
+	}
+
+	if b.tokens < 1 {
+		b.suppressed++
+		return false, 0
+	}
+	b.tokens--
+	suppressed := b.suppressed
+	b.suppressed = 0
+	return true, suppressed
+}
+
+// withSuppressed appends the suppressed field to the JSON event p.
+func withSuppressed(p []byte, n int) []byte {
+	event := bytes.TrimRight(p, " \r\n")
+	if len(event) == 0 || event[len(event)-1] != '}' {
+		return p
+	}
+	out := make([]byte, 0, len(event)+32)
+	out = append(out, event[:len(event)-1]...)
+	if len(event) > 2 {
+		out = append(out, ',')
+	}
+	out = append(out, `"`+SuppressedFieldName+`":`...)
+	out = strconv.AppendInt(out, int64(n), 10)
+	return append(out, "}\n"...)
+}
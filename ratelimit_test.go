@@ -0,0 +1,94 @@
+package ezlog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitRefill(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	l := newTestBuilder(&buf).WithJSONOutput().
+		WithRateLimit("user_id", rate.Every(time.Minute), 1, WithRateLimitClock(func() time.Time { return now })).Build()
+
+	logAt := func(offset time.Duration, user string) {
+		now = time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC).Add(offset)
+		l.Warn().Str("user_id", user).Msg("slow down")
+	}
+	logAt(0, "ada")
+	logAt(time.Second, "ada")
+	logAt(2*time.Second, "ada")
+	logAt(3*time.Second, "bob")
+	logAt(30*time.Second, "ada")
+	logAt(61*time.Second, "ada")
+	l.Warn().Msg("no key")
+	l.Warn().Msg("no key")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("output = %q, want 5 events", lines)
+	}
+	if !strings.Contains(lines[1], `"user_id":"bob"`) || strings.Contains(lines[1], SuppressedFieldName) {
+		t.Errorf("event of another key = %q", lines[1])
+	}
+	if !strings.Contains(lines[2], `"user_id":"ada"`) || !strings.HasSuffix(lines[2], `"suppressed":3}`) {
+		t.Errorf("refilled event = %q, want 3 suppressed events", lines[2])
+	}
+	if strings.Contains(lines[3], SuppressedFieldName) || strings.Contains(lines[4], SuppressedFieldName) {
+		t.Errorf("events without the key were limited: %q", lines[3:])
+	}
+}
+
+func TestRateLimitLRUEviction(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithJSONOutput().
+		WithRateLimit("user_id", rate.Every(time.Hour), 1, WithRateLimitMaxKeys(2)).Build()
+	for _, user := range []string{"a", "b", "a", "c", "b", "a"} {
+		l.Info().Str("user_id", user).Msg("event")
+	}
+
+	// a and b are tracked; c evicts b, the least recently seen, so b gets a
+	// fresh bucket and in turn evicts a.
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		for _, user := range []string{"a", "b", "c"} {
+			if strings.Contains(line, fmt.Sprintf(`"user_id":"%s"`, user)) {
+				got = append(got, user)
+			}
+		}
+	}
+	if want := "a b c b a"; strings.Join(got, " ") != want {
+		t.Errorf("logged users = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestWithSuppressed(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`{"level":"warn"}` + "\n", `{"level":"warn","suppressed":2}` + "\n"},
+		{"{}\n", `{"suppressed":2}` + "\n"},
+		{"not json\n", "not json\n"},
+	}
+	for _, tt := range tests {
+		if got := string(withSuppressed([]byte(tt.in), 2)); got != tt.want {
+			t.Errorf("withSuppressed(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitBoundedKeys(t *testing.T) {
+	w := newRateLimitWriter(&bytes.Buffer{}, &rateLimitConfig{
+		key: "user_id", limit: rate.Every(time.Minute), burst: 1,
+		opts: []RateLimitOption{WithRateLimitMaxKeys(100)},
+	})
+	for i := range 10000 {
+		fmt.Fprintf(w, `{"user_id":%d}`+"\n", i)
+	}
+	if len(w.buckets) != 100 || w.lru.Len() != 100 {
+		t.Errorf("tracking %d buckets and %d LRU entries, want 100", len(w.buckets), w.lru.Len())
+	}
+}
@@ -0,0 +1,94 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// redactedField replaces the values of redacted fields.
+const redactedField = `"[REDACTED]"`
+
+// WithRedactedFields replaces the values of the named top-level fields with
+// "[REDACTED]" in every output, e.g. for passwords or tokens. Names are case
+// insensitive.
+func (b *LogBuilder) WithRedactedFields(names ...string) *LogBuilder {
+	if b.redactedFields == nil {
+		b.redactedFields = make(map[string]struct{}, len(names))
+	}
+	for _, name := range names {
+		b.redactedFields[strings.ToLower(name)] = struct{}{}
+	}
+	return b
+}
+
+// redactWriter replaces the values of redacted fields of JSON events before
+// they reach out. The fields of live, if any, are redacted too.
+type redactWriter struct {
+	out    io.Writer
+	fields map[string]struct{}
+	live   *LiveConfig
+}
+
+// Write implements io.Writer. Events that are not JSON objects are written
+// unchanged.
+func (w *redactWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *redactWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	var live map[string]struct{}
+	if w.live != nil {
+		live = w.live.state.Load().redact
+	}
+	if len(w.fields) == 0 && len(live) == 0 {
+		return writeLevel(w.out, level, p)
+	}
+	redacted, ok := w.redact(p, live)
+	if !ok {
+		return writeLevel(w.out, level, p)
+	}
+	if _, err := writeLevel(w.out, level, redacted); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// redact returns p with the values of the redacted fields and of the live
+// ones replaced.
+func (w *redactWriter) redact(p []byte, live map[string]struct{}) ([]byte, bool) {
+	d := json.NewDecoder(bytes.NewReader(p))
+	if t, err := d.Token(); err != nil || t != json.Delim('{') {
+		return nil, false
+	}
+
+	var out []byte
+	last := 0
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return nil, false
+		}
+		start := int(d.InputOffset())
+		var v json.RawMessage
+		if err := d.Decode(&v); err != nil {
+			return nil, false
+		}
+		name := strings.ToLower(t.(string))
+		_, redacted := w.fields[name]
+		if _, ok := live[name]; ok || redacted {
+			out = append(out, p[last:start]...)
+			out = append(out, ':')
+			out = append(out, redactedField...)
+			last = int(d.InputOffset())
+		}
+	}
+	if out == nil {
+		return p, true
+	}
+	return append(out, p[last:]...), true
+}
@@ -0,0 +1,94 @@
+package ezlog
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// requestLoggerKey is the context key holding the RequestLogger.
+type requestLoggerKey struct{}
+
+// RequestLogger accumulates fields while a request is handled and writes them
+// as a single event when flushed. It is safe for concurrent use.
+type RequestLogger struct {
+	mu       sync.Mutex
+	base     *zerolog.Logger
+	fields   []any
+	err      error
+	duration time.Duration
+	hasDur   bool
+	flushed  bool
+}
+
+// NewRequestLogger creates a RequestLogger writing to base.
+func NewRequestLogger(base *zerolog.Logger) *RequestLogger {
+	return &RequestLogger{base: base}
+}
+
+// Set records a field. Setting the same key twice keeps both values in order.
+func (r *RequestLogger) Set(key string, value any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fields = append(r.fields, key, value)
+}
+
+// SetError records the request error. The event is written at error level
+// when an error is set, and at info level otherwise.
+func (r *RequestLogger) SetError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+// SetDuration records the request duration.
+func (r *RequestLogger) SetDuration(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.duration = d
+	r.hasDur = true
+}
+
+// Flush writes the accumulated fields as one event with msg. Only the first
+// call writes anything.
+func (r *RequestLogger) Flush(msg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.flushed {
+		return
+	}
+	r.flushed = true
+
+	var e *zerolog.Event
+	if r.err != nil {
+		e = r.base.Error().Err(r.err)
+	} else {
+		e = r.base.Info()
+	}
+	if len(r.fields) > 0 {
+		e = e.Fields(r.fields)
+	}
+	if r.hasDur {
+		e = e.Dur("duration", r.duration)
+	}
+	e.Msg(msg)
+}
+
+// ContextWithRequestLogger returns a copy of ctx carrying r.
+func ContextWithRequestLogger(ctx context.Context, r *RequestLogger) context.Context {
+	return context.WithValue(ctx, requestLoggerKey{}, r)
+}
+
+// RequestLoggerFromContext returns the RequestLogger stored in ctx. When there
+// is none, a RequestLogger writing nowhere is returned so callers never need
+// to check for nil.
+func RequestLoggerFromContext(ctx context.Context) *RequestLogger {
+	if r, ok := ctx.Value(requestLoggerKey{}).(*RequestLogger); ok {
+		return r
+	}
+	nop := zerolog.Nop()
+	return NewRequestLogger(&nop)
+}
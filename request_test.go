@@ -0,0 +1,92 @@
+package ezlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRequestLoggerFlush(t *testing.T) {
+	var buf bytes.Buffer
+	l := zerolog.New(&buf)
+	rl := NewRequestLogger(&l)
+	rl.Set("user", "ada")
+	rl.Set("items", 3)
+	rl.SetDuration(1500 * time.Millisecond)
+	rl.Flush("checkout")
+	rl.Flush("checkout")
+
+	want := `{"level":"info","user":"ada","items":3,"duration":1500,"message":"checkout"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestRequestLoggerError(t *testing.T) {
+	var buf bytes.Buffer
+	l := zerolog.New(&buf)
+	rl := NewRequestLogger(&l)
+	rl.Set("user", "ada")
+	rl.SetError(errors.New("card declined"))
+	rl.Flush("checkout")
+
+	want := `{"level":"error","error":"card declined","user":"ada","message":"checkout"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestRequestLoggerFromContext(t *testing.T) {
+	// Without a RequestLogger, calls are accepted and discarded.
+	RequestLoggerFromContext(context.Background()).Set("ignored", true)
+
+	l := zerolog.Nop()
+	rl := NewRequestLogger(&l)
+	if got := RequestLoggerFromContext(ContextWithRequestLogger(context.Background(), rl)); got != rl {
+		t.Errorf("RequestLoggerFromContext() = %p, want %p", got, rl)
+	}
+}
+
+func TestHTTPMiddlewareRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+	h := NewHTTPMiddleware(l).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rl := RequestLoggerFromContext(r.Context())
+		rl.Set("order", 42)
+		rl.SetError(errors.New("out of stock"))
+		w.WriteHeader(http.StatusConflict)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/orders", nil))
+
+	if n := strings.Count(buf.String(), "\n"); n != 1 {
+		t.Fatalf("got %d lines, want one per request: %s", n, buf.String())
+	}
+	var evt map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &evt); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]any{
+		"level":   "error",
+		"error":   "out of stock",
+		"method":  "POST",
+		"path":    "/orders",
+		"order":   float64(42),
+		"status":  float64(http.StatusConflict),
+		"message": "http request",
+	} {
+		if evt[key] != want {
+			t.Errorf("%s = %v, want %v", key, evt[key], want)
+		}
+	}
+	if _, ok := evt["duration"]; !ok {
+		t.Error("request line has no duration")
+	}
+}
@@ -0,0 +1,54 @@
+package ezlog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// retryPolicy describes how failed HTTP deliveries are retried.
+type retryPolicy struct {
+	maxRetries int
+	backoff    time.Duration
+}
+
+// post sends body to url, retrying on network errors, 429 and 5xx responses
+// with an exponential backoff and jitter.
+func (p retryPolicy) post(client *http.Client, url string, header http.Header, body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt <= p.maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := p.backoff << (attempt - 1)
+			time.Sleep(delay/2 + rand.N(delay/2+1))
+		}
+
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode < 300:
+			return nil
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			lastErr = fmt.Errorf("ezlog: post %s: %s", url, resp.Status)
+		default:
+			return fmt.Errorf("ezlog: post %s: %s", url, resp.Status)
+		}
+	}
+	return lastErr
+}
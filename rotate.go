@@ -0,0 +1,313 @@
+package ezlog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// RotationPolicy decides when a RotatingFileWriter switches to a new file and
+// how the files are named. Use HourlyRotation or DailyRotation.
+type RotationPolicy interface {
+	// nextRotationTime returns the start of the period following the one
+	// containing now.
+	nextRotationTime(now time.Time) time.Time
+	// periodStart returns the start of the period containing now.
+	periodStart(now time.Time) time.Time
+	// layout is the time layout of the period in file names.
+	layout() string
+	// location is the time zone periods are computed in.
+	location() *time.Location
+}
+
+// hourlyRotation rotates at the top of every hour.
+type hourlyRotation struct {
+	loc *time.Location
+}
+
+// HourlyRotation returns a policy rotating at the top of every hour in loc,
+// local time when loc is nil. Files are named prefix-2006-01-02-15.ext.
+func HourlyRotation(loc *time.Location) RotationPolicy {
+	if loc == nil {
+		loc = time.Local
+	}
+	return hourlyRotation{loc: loc}
+}
+
+func (p hourlyRotation) periodStart(now time.Time) time.Time {
+	now = now.In(p.loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, p.loc)
+}
+
+func (p hourlyRotation) nextRotationTime(now time.Time) time.Time {
+	return p.periodStart(now).Add(time.Hour)
+}
+
+func (p hourlyRotation) layout() string           { return "2006-01-02-15" }
+func (p hourlyRotation) location() *time.Location { return p.loc }
+
+// dailyRotation rotates at midnight.
+type dailyRotation struct {
+	loc *time.Location
+}
+
+// DailyRotation returns a policy rotating at midnight in loc, local time when
+// loc is nil. Files are named prefix-2006-01-02.ext.
+func DailyRotation(loc *time.Location) RotationPolicy {
+	if loc == nil {
+		loc = time.Local
+	}
+	return dailyRotation{loc: loc}
+}
+
+func (p dailyRotation) periodStart(now time.Time) time.Time {
+	now = now.In(p.loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, p.loc)
+}
+
+func (p dailyRotation) nextRotationTime(now time.Time) time.Time {
+	start := p.periodStart(now)
+	return time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, p.loc)
+}
+
+func (p dailyRotation) layout() string           { return "2006-01-02" }
+func (p dailyRotation) location() *time.Location { return p.loc }
+
+// RotatingFileWriter writes to one file per period, as decided by its
+// RotationPolicy, and deletes the files of periods older than the retention.
+// A background goroutine switches files at each boundary, and writes check
+// the boundary too so no event lands in the wrong file.
+// It should be created using NewRotatingFileWriter.
+type RotatingFileWriter struct {
+	mu     sync.Mutex
+	dir    string
+	prefix string
+	ext    string
+	policy RotationPolicy
+	keep   int
+	now    func() time.Time
+
+	compression CompressionAlgo
+
+	file  *os.File
+	out   io.Writer
+	start time.Time
+	next  time.Time
+
+	timerOnce sync.Once
+	stop      chan struct{}
+	closed    bool
+}
+
+// RotatingFileOption configures a RotatingFileWriter.
+type RotatingFileOption func(*RotatingFileWriter)
+
+// WithRotationCompression compresses every file with algo and adds the
+// matching extension, e.g. "app-2024-06-01.log.gz". Each file is a complete
+// stream, finalized before the next one is opened.
+func WithRotationCompression(algo CompressionAlgo) RotatingFileOption {
+	return func(w *RotatingFileWriter) {
+		w.compression = algo
+	}
+}
+
+// WithRotationClock sets the clock used to pick the current file, mostly so
+// tests can force a rollover.
+func WithRotationClock(now func() time.Time) RotatingFileOption {
+	return func(w *RotatingFileWriter) {
+		w.now = now
+	}
+}
+
+// NewRotatingFileWriter creates a writer for files named
+// dir/prefix-<period>.ext. Files of the keep most recent periods, the current
+// one included, are kept; keep <= 0 keeps all files. The directory and file
+// are created on the first write.
+func NewRotatingFileWriter(dir, prefix, ext string, policy RotationPolicy, keep int, opts ...RotatingFileOption) *RotatingFileWriter {
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	w := &RotatingFileWriter{
+		dir:    dir,
+		prefix: prefix,
+		ext:    ext,
+		policy: policy,
+		keep:   keep,
+		now:    time.Now,
+		stop:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Write implements io.Writer.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	now := w.now()
+	if w.file == nil || !now.Before(w.next) {
+		if err := w.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+	w.timerOnce.Do(func() {
+		go w.rotateLoop()
+	})
+	return w.out.Write(p)
+}
+
+// Close closes the current file and stops the rotation goroutine.
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	close(w.stop)
+	return w.closeFile()
+}
+
+// Filename returns the path of the file for the period containing t.
+func (w *RotatingFileWriter) Filename(t time.Time) string {
+	name := fmt.Sprintf("%s-%s%s", w.prefix, w.policy.periodStart(t).Format(w.policy.layout()), w.fileExt())
+	return filepath.Join(w.dir, name)
+}
+
+// rotateLoop switches files at every period boundary until the writer is closed.
+func (w *RotatingFileWriter) rotateLoop() {
+	for {
+		w.mu.Lock()
+		wait := w.next.Sub(w.now())
+		w.mu.Unlock()
+
+		timer := time.NewTimer(max(wait, 0))
+		select {
+		case <-w.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		w.mu.Lock()
+		if !w.closed {
+			if now := w.now(); !now.Before(w.next) {
+				if err := w.rotate(now); err != nil {
+					diag(zerolog.ErrorLevel, "rotate", "rotating %s failed: %v", w.dir, err)
+				}
+			}
+		}
+		w.mu.Unlock()
+	}
+}
+
+// rotate closes the current file, opens the one for the period containing
+// now and prunes expired files. It must be called with w.mu held.
+func (w *RotatingFileWriter) rotate(now time.Time) error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(w.dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.Filename(now), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	w.file = f
+	w.out = f
+	if w.compression != CompressionNone {
+		w.out = newCompressor(f, w.compression)
+	}
+	w.start = w.policy.periodStart(now)
+	w.next = w.policy.nextRotationTime(now)
+
+	w.prune()
+	return nil
+}
+
+// closeFile finalizes the compressed stream, if any, and closes the current
+// file. It must be called with w.mu held.
+func (w *RotatingFileWriter) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	var err error
+	if c, ok := w.out.(io.Closer); ok && w.out != io.Writer(w.file) {
+		err = c.Close()
+	}
+	err = errors.Join(err, w.file.Close())
+	w.file = nil
+	w.out = nil
+	return err
+}
+
+// fileExt returns the extension of the files, compression included.
+func (w *RotatingFileWriter) fileExt() string {
+	return w.ext + w.compression.ext()
+}
+
+// prune deletes the files of periods older than the retention.
+func (w *RotatingFileWriter) prune() {
+	if w.keep <= 0 {
+		return
+	}
+
+	oldest := w.start
+	for i := 1; i < w.keep; i++ {
+		oldest = w.policy.periodStart(oldest.Add(-time.Nanosecond))
+	}
+
+	ext := w.fileExt()
+	matches, err := filepath.Glob(filepath.Join(w.dir, w.prefix+"-*"+ext))
+	if err != nil {
+		return
+	}
+	for _, path := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), w.prefix+"-"), ext)
+		t, err := time.ParseInLocation(w.policy.layout(), stamp, w.policy.location())
+		if err != nil {
+			continue
+		}
+		if t.Before(oldest) {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// WithHourlyRotatingFile writes to one file per hour named
+// dir/prefix-2006-01-02-15.extension, keeping the files of the last keepHours
+// hours.
+func (b *LogBuilder) WithHourlyRotatingFile(dir, prefix, extension string, keepHours int) *LogBuilder {
+	return b.WithWriter(NewRotatingFileWriter(dir, prefix, extension, HourlyRotation(nil), keepHours))
+}
+
+// WithDailyFiles writes to one file per day named dir/prefix-2006-01-02.log,
+// switching over at midnight in UTC or local time. Files older than
+// retainDays days are deleted on rollover; retainDays <= 0 keeps them all.
+//
+// The current file is cached until the day changes, and the switch happens
+// under the same lock as writes, so events around midnight always land in
+// the file of the day they were written.
+func (b *LogBuilder) WithDailyFiles(dir, prefix string, utc bool, retainDays int) *LogBuilder {
+	loc := time.Local
+	if utc {
+		loc = time.UTC
+	}
+	return b.WithWriter(NewRotatingFileWriter(dir, prefix, "log", DailyRotation(loc), retainDays))
+}
@@ -0,0 +1,169 @@
+package ezlog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// rotationClock is a settable clock for WithRotationClock.
+type rotationClock struct{ nanos atomic.Int64 }
+
+func newRotationClock(t time.Time) *rotationClock {
+	c := &rotationClock{}
+	c.set(t)
+	return c
+}
+
+func (c *rotationClock) set(t time.Time) { c.nanos.Store(t.UnixNano()) }
+func (c *rotationClock) now() time.Time  { return time.Unix(0, c.nanos.Load()).UTC() }
+
+// dirFiles returns the sorted names of the files in dir.
+func dirFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestRotationPolicies(t *testing.T) {
+	now := time.Date(2025, 3, 14, 23, 26, 53, 0, time.UTC)
+	tests := []struct {
+		name   string
+		policy RotationPolicy
+		start  time.Time
+		next   time.Time
+		file   string
+	}{
+		{"hourly", HourlyRotation(time.UTC),
+			time.Date(2025, 3, 14, 23, 0, 0, 0, time.UTC), time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), "app-2025-03-14-23.log"},
+		{"daily", DailyRotation(time.UTC),
+			time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), "app-2025-03-14.log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.periodStart(now); !got.Equal(tt.start) {
+				t.Errorf("periodStart = %v, want %v", got, tt.start)
+			}
+			if got := tt.policy.nextRotationTime(now); !got.Equal(tt.next) {
+				t.Errorf("nextRotationTime = %v, want %v", got, tt.next)
+			}
+			w := NewRotatingFileWriter("logs", "app", "log", tt.policy, 0)
+			if got := w.Filename(now); got != filepath.Join("logs", tt.file) {
+				t.Errorf("Filename = %q, want %q", got, tt.file)
+			}
+		})
+	}
+}
+
+func TestHourlyRotatingFileRollover(t *testing.T) {
+	dir := t.TempDir()
+	clock := newRotationClock(time.Date(2025, 3, 14, 9, 59, 0, 0, time.UTC))
+	w := NewRotatingFileWriter(dir, "app", "log", HourlyRotation(time.UTC), 2, WithRotationClock(clock.now))
+	defer w.Close()
+
+	for _, hour := range []int{9, 10, 11} {
+		clock.set(time.Date(2025, 3, 14, hour, 30, 0, 0, time.UTC))
+		if _, err := w.Write([]byte("event\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"app-2025-03-14-10.log", "app-2025-03-14-11.log"}
+	if got := dirFiles(t, dir); !slices.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestDailyFilesRolloverAndRetention(t *testing.T) {
+	dir := t.TempDir()
+	clock := newRotationClock(time.Date(2025, 3, 11, 12, 0, 0, 0, time.UTC))
+	w := NewRotatingFileWriter(dir, "app", "log", DailyRotation(time.UTC), 2, WithRotationClock(clock.now))
+	defer w.Close()
+
+	for day := 11; day <= 14; day++ {
+		clock.set(time.Date(2025, 3, day, 23, 59, 59, 0, time.UTC))
+		if _, err := w.Write([]byte("late\n")); err != nil {
+			t.Fatal(err)
+		}
+		clock.set(time.Date(2025, 3, day+1, 0, 0, 0, 0, time.UTC))
+		if _, err := w.Write([]byte("early\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"app-2025-03-14.log", "app-2025-03-15.log"}
+	if got := dirFiles(t, dir); !slices.Equal(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	for name, content := range map[string]string{
+		"app-2025-03-14.log": "early\nlate\n",
+		"app-2025-03-15.log": "early\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+}
+
+func TestDailyFilesConcurrentMidnight(t *testing.T) {
+	dir := t.TempDir()
+	clock := newRotationClock(time.Date(2025, 3, 14, 23, 59, 59, 0, time.UTC))
+	w := NewRotatingFileWriter(dir, "app", "log", DailyRotation(time.UTC), 0, WithRotationClock(clock.now))
+
+	const writers, events = 8, 200
+	line := []byte(`{"level":"info","message":"a complete event"}` + "\n")
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range events {
+				if i == 0 && j == events/2 {
+					clock.set(time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC))
+				}
+				w.Write(line)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	total := 0
+	for _, name := range []string{"app-2025-03-14.log", "app-2025-03-15.log"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, l := range bytes.SplitAfter(data, []byte("\n")) {
+			if len(l) == 0 {
+				continue
+			}
+			if !bytes.Equal(l, line) {
+				t.Fatalf("%s has an interleaved line %q", name, l)
+			}
+			total++
+		}
+	}
+	if total != writers*events {
+		t.Errorf("got %d events, want %d", total, writers*events)
+	}
+}
@@ -0,0 +1,167 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TagRouter is a writer that forwards each event to the destination whose
+// pattern matches the event's tag. It reads the "tag" field of JSON events,
+// or the bracketed tag following the level of console lines. Patterns use
+// path.Match syntax, so "db*" matches both "db" and "db:migrations". Exact
+// matches win over patterns, and longer patterns over shorter ones. Events
+// without a tag or without a matching route go to the fallback.
+// It should be created using NewTagRouter.
+type TagRouter struct {
+	exact    map[string]io.Writer
+	patterns []tagRoute
+	fallback io.Writer
+
+	mu    sync.Mutex
+	cache map[string]io.Writer
+}
+
+// tagRoute is a glob pattern and its destination.
+type tagRoute struct {
+	pattern string
+	w       io.Writer
+}
+
+// NewTagRouter creates a TagRouter. A nil fallback discards unroutable events.
+func NewTagRouter(routes map[string]io.Writer, fallback io.Writer) *TagRouter {
+	if fallback == nil {
+		fallback = io.Discard
+	}
+	r := &TagRouter{
+		exact:    make(map[string]io.Writer),
+		fallback: fallback,
+		cache:    make(map[string]io.Writer),
+	}
+	for pattern, w := range routes {
+		if strings.ContainsAny(pattern, `*?[\`) {
+			r.patterns = append(r.patterns, tagRoute{pattern: pattern, w: w})
+		} else {
+			r.exact[pattern] = w
+		}
+	}
+	sort.Slice(r.patterns, func(i, j int) bool {
+		if len(r.patterns[i].pattern) != len(r.patterns[j].pattern) {
+			return len(r.patterns[i].pattern) > len(r.patterns[j].pattern)
+		}
+		return r.patterns[i].pattern < r.patterns[j].pattern
+	})
+	return r
+}
+
+// Write implements io.Writer.
+func (r *TagRouter) Write(p []byte) (int, error) {
+	return r.route(eventTag(p)).Write(p)
+}
+
+// Close closes every destination that implements io.Closer, except stdout and
+// stderr, and returns the joined errors.
+func (r *TagRouter) Close() error {
+	seen := make(map[io.Writer]bool)
+	var errs []error
+	closeOnce := func(w io.Writer) {
+		if w == os.Stdout || w == os.Stderr || seen[w] {
+			return
+		}
+		seen[w] = true
+		if c, ok := w.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	for _, w := range r.exact {
+		closeOnce(w)
+	}
+	for _, route := range r.patterns {
+		closeOnce(route.w)
+	}
+	closeOnce(r.fallback)
+	return errors.Join(errs...)
+}
+
+// route returns the destination for tag.
+func (r *TagRouter) route(tag string) io.Writer {
+	if tag == "" {
+		return r.fallback
+	}
+	if w, ok := r.exact[tag]; ok {
+		return w
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if w, ok := r.cache[tag]; ok {
+		return w
+	}
+	w := r.fallback
+	for _, route := range r.patterns {
+		if ok, _ := path.Match(route.pattern, tag); ok {
+			w = route.w
+			break
+		}
+	}
+	r.cache[tag] = w
+	return w
+}
+
+// eventTag extracts the tag of a JSON event or console line.
+func eventTag(p []byte) string {
+	if trimmed := bytes.TrimSpace(p); len(trimmed) > 0 && trimmed[0] == '{' {
+		var evt struct {
+			Tag string `json:"tag"`
+		}
+		if json.Unmarshal(trimmed, &evt) != nil {
+			return ""
+		}
+		return evt.Tag
+	}
+
+	_, tag := consolePrefix(p)
+	return tag
+}
+
+// consolePrefix returns the bracketed level and tag at the start of a console
+// line, without brackets. Either is empty when missing.
+func consolePrefix(p []byte) (level, tag string) {
+	line, _, _ := bytes.Cut(p, []byte{'\n'})
+	tokens := strings.Fields(ansiEscape.ReplaceAllString(string(line), ""))
+	i := slices.IndexFunc(tokens, func(tok string) bool {
+		return strings.HasPrefix(tok, "[")
+	})
+	if i < 0 {
+		return "", ""
+	}
+	level = unbracket(tokens[i])
+	if i+1 < len(tokens) {
+		tag = unbracket(tokens[i+1])
+	}
+	return level, tag
+}
+
+// unbracket returns the text of a "[text]" token, also accepting the "[text[]"
+// form produced by tview escaping, or "" for other tokens.
+func unbracket(tok string) string {
+	if len(tok) < 2 || tok[0] != '[' || tok[len(tok)-1] != ']' {
+		return ""
+	}
+	return strings.TrimSuffix(tok[1:len(tok)-1], "[")
+}
+
+// WithTagRouting sends the logger's output through a TagRouter, so loggers
+// sharing the same routes write to the destination matching their tag. A
+// builder whose writer is a TagRouter resolves its destination once at Build
+// from its own tag, which also covers tags shortened by WithTagWidth.
+func (b *LogBuilder) WithTagRouting(routes map[string]io.Writer, fallback io.Writer) *LogBuilder {
+	return b.WithWriter(NewTagRouter(routes, fallback))
+}
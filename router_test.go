@@ -0,0 +1,88 @@
+package ezlog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTagRoutingToFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := make(map[string]*os.File)
+	for _, name := range []string{"http", "db", "app"} {
+		f, err := os.Create(filepath.Join(dir, name+".log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = f
+	}
+	r := NewTagRouter(map[string]io.Writer{"http": files["http"], "db*": files["db"]}, files["app"])
+
+	for _, tag := range []string{"http", "db", "db:migrations", "cache", ""} {
+		l := New().AsLocal().WithTag(tag).WithJSONOutput().WithWriter(r).Build()
+		l.Info().Msg("from " + tag)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"http": {"from http"},
+		"db":   {"from db", "from db:migrations"},
+		"app":  {"from cache", "from "},
+	}
+	for name, msgs := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name+".log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) != len(msgs) {
+			t.Fatalf("%s.log = %q, want %d events", name, data, len(msgs))
+		}
+		for i, msg := range msgs {
+			if !strings.Contains(lines[i], `"message":"`+msg+`"`) {
+				t.Errorf("%s.log line %d = %q, want message %q", name, i, lines[i], msg)
+			}
+		}
+	}
+}
+
+func TestTagRouterWrite(t *testing.T) {
+	var http, db, dbMigrations, fallback bytes.Buffer
+	r := NewTagRouter(map[string]io.Writer{
+		"http":  &http,
+		"db*":   &db,
+		"db:m*": &dbMigrations,
+	}, &fallback)
+
+	events := []string{
+		`{"level":"info","tag":"http","message":"json"}` + "\n",
+		"09:26:53.000 [INFO] [db:migrations] console\n",
+		"\x1b[90m09:26:53.000\x1b[0m [WARN] [db] colored\n",
+		`{"level":"info","message":"untagged"}` + "\n",
+		"not an event\n",
+	}
+	for _, evt := range events {
+		if _, err := r.Write([]byte(evt)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, tt := range map[string]struct {
+		buf  *bytes.Buffer
+		want string
+	}{
+		"http":          {&http, events[0]},
+		"db:migrations": {&dbMigrations, events[1]},
+		"db":            {&db, events[2]},
+		"fallback":      {&fallback, events[3] + events[4]},
+	} {
+		if got := tt.buf.String(); got != tt.want {
+			t.Errorf("%s = %q, want %q", name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package ezlog
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/rs/zerolog"
+)
+
+// WithErrorSampling keeps only the fraction rate of error events, dropping
+// the others at random, to keep incident cascades from flooding the logs.
+// rate must be in (0,1]; 1 keeps all error events. Fatal and panic events
+// are never dropped. When the logger also has a DynamicLevel, the rate can
+// be changed at runtime with SetErrorSamplingRate.
+func (b *LogBuilder) WithErrorSampling(rate float64) *LogBuilder {
+	b.errorSampling = rate
+	return b
+}
+
+// WithSamplingFunc keeps only the events for which fn returns true, for
+// sampling logic of any kind, such as token buckets or sampling by message
+// hash. fn is called with the level and message of the events passing the
+// level filters, including the dynamic, live and package levels, before they
+// are written. It is called concurrently by the goroutines sharing the
+// logger, so it must be safe for concurrent use.
+func (b *LogBuilder) WithSamplingFunc(fn func(level zerolog.Level, msg string) bool) *LogBuilder {
+	b.samplingFunc = fn
+	return b
+}
+
+// SetErrorSamplingRate changes the error sampling rate of the loggers built
+// with WithDynamicLevel(dl) and WithErrorSampling. A rate of 0 reverts to the
+// rate given to WithErrorSampling.
+func SetErrorSamplingRate(dl *DynamicLevel, rate float64) {
+	dl.errorSampling.Store(math.Float64bits(rate))
+}
+
+// ErrorSamplingRate returns the error sampling rate set on dl with
+// SetErrorSamplingRate, or 0 if there is none.
+func (d *DynamicLevel) ErrorSamplingRate() float64 {
+	return math.Float64frombits(d.errorSampling.Load())
+}
+
+// errorSamplingHook drops error events at random.
+type errorSamplingHook struct {
+	rate float64
+	dl   *DynamicLevel
+}
+
+// Run implements zerolog.Hook.
+func (h errorSamplingHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level != zerolog.ErrorLevel {
+		return
+	}
+	rate := h.rate
+	if h.dl != nil {
+		if r := h.dl.ErrorSamplingRate(); r != 0 {
+			rate = r
+		}
+	}
+	if rate > 0 && rate < 1 && rand.Float64() >= rate {
+		e.Discard()
+	}
+}
+
+// samplingFuncHook drops the events rejected by a WithSamplingFunc function.
+type samplingFuncHook func(zerolog.Level, string) bool
+
+// Run implements zerolog.Hook.
+func (h samplingFuncHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	// Events discarded by a previous hook are disabled.
+	if level != zerolog.Disabled && !h(level, msg) {
+		e.Discard()
+	}
+}
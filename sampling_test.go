@@ -0,0 +1,112 @@
+package ezlog
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// levelCounter counts the events written at each level.
+type levelCounter struct {
+	counts [8]atomic.Int64
+}
+
+func (c *levelCounter) Write(p []byte) (int, error) { return len(p), nil }
+
+func (c *levelCounter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	c.counts[level+1].Add(1)
+	return len(p), nil
+}
+
+func (c *levelCounter) count(level zerolog.Level) int64 { return c.counts[level+1].Load() }
+
+// sampledLogger returns a JSON logger writing to c, configured by setup.
+func sampledLogger(c *levelCounter, setup func(*LogBuilder) *LogBuilder) *zerolog.Logger {
+	return setup(New().AsLocal().WithWriter(c).WithJSONOutput()).Build()
+}
+
+func TestErrorSampling(t *testing.T) {
+	c := &levelCounter{}
+	l := sampledLogger(c, func(b *LogBuilder) *LogBuilder { return b.WithErrorSampling(0.5) })
+	for range 2000 {
+		l.Error().Msg("cascade")
+		l.Warn().Msg("kept")
+		l.WithLevel(zerolog.FatalLevel).Msg("kept")
+	}
+
+	if n := c.count(zerolog.ErrorLevel); n < 800 || n > 1200 {
+		t.Errorf("kept %d of 2000 error events, want about 1000", n)
+	}
+	if c.count(zerolog.WarnLevel) != 2000 || c.count(zerolog.FatalLevel) != 2000 {
+		t.Errorf("kept %d warnings and %d fatal events, want all 2000", c.count(zerolog.WarnLevel), c.count(zerolog.FatalLevel))
+	}
+}
+
+func TestSetErrorSamplingRate(t *testing.T) {
+	dl := NewDynamicLevel(zerolog.DebugLevel)
+	c := &levelCounter{}
+	l := sampledLogger(c, func(b *LogBuilder) *LogBuilder { return b.WithDynamicLevel(dl).WithErrorSampling(0.01) })
+
+	SetErrorSamplingRate(dl, 1)
+	if dl.ErrorSamplingRate() != 1 {
+		t.Fatalf("ErrorSamplingRate() = %v", dl.ErrorSamplingRate())
+	}
+	for range 100 {
+		l.Error().Msg("all kept")
+	}
+	if n := c.count(zerolog.ErrorLevel); n != 100 {
+		t.Fatalf("kept %d of 100 error events at rate 1", n)
+	}
+
+	SetErrorSamplingRate(dl, 0)
+	for range 1000 {
+		l.Error().Msg("mostly dropped")
+	}
+	if n := c.count(zerolog.ErrorLevel) - 100; n > 50 {
+		t.Errorf("kept %d of 1000 error events after reverting to 0.01", n)
+	}
+}
+
+func TestSamplingFunc(t *testing.T) {
+	c := &levelCounter{}
+	var calls atomic.Int64
+	l := sampledLogger(c, func(b *LogBuilder) *LogBuilder {
+		return b.WithLevel(zerolog.InfoLevel).WithSamplingFunc(func(level zerolog.Level, msg string) bool {
+			calls.Add(1)
+			return level >= zerolog.WarnLevel || msg == "kept"
+		})
+	})
+	for range 10 {
+		l.Debug().Msg("kept")
+		l.Info().Msg("kept")
+		l.Info().Msg("dropped")
+		l.Warn().Msg("dropped")
+	}
+
+	if c.count(zerolog.InfoLevel) != 10 || c.count(zerolog.WarnLevel) != 10 {
+		t.Errorf("kept %d info and %d warn events, want 10 each", c.count(zerolog.InfoLevel), c.count(zerolog.WarnLevel))
+	}
+	// The function only sees the events passing the level filter.
+	if c.count(zerolog.DebugLevel) != 0 || calls.Load() != 30 {
+		t.Errorf("kept %d debug events and got %d calls, want 0 and 30", c.count(zerolog.DebugLevel), calls.Load())
+	}
+}
+
+func TestSamplingFuncAfterDynamicLevel(t *testing.T) {
+	c := &levelCounter{}
+	dl := NewDynamicLevel(zerolog.ErrorLevel)
+	var calls atomic.Int64
+	l := sampledLogger(c, func(b *LogBuilder) *LogBuilder {
+		return b.WithDynamicLevel(dl).WithSamplingFunc(func(zerolog.Level, string) bool {
+			calls.Add(1)
+			return true
+		})
+	})
+	l.Info().Msg("filtered")
+	l.Error().Msg("kept")
+
+	if calls.Load() != 1 || c.count(zerolog.ErrorLevel) != 1 {
+		t.Errorf("got %d calls and %d error events, want 1 each", calls.Load(), c.count(zerolog.ErrorLevel))
+	}
+}
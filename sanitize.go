@@ -0,0 +1,157 @@
+package ezlog
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+)
+
+// WithRawMessages disables the sanitization of control characters and ANSI
+// escapes in messages. Only use it when the messages are fully trusted.
+func (b *LogBuilder) WithRawMessages() *LogBuilder {
+	b.rawMessages = true
+	return b
+}
+
+// SafeString returns s.String(), or "!PANIC(type)" if String panics.
+// Use it for values whose String method might not be trustworthy:
+//
+//	log.Info().Str("user", ezlog.SafeString(u)).Msg("login")
+func SafeString(s fmt.Stringer) (str string) {
+	defer func() {
+		if r := recover(); r != nil {
+			str = panicValue(s)
+		}
+	}()
+	return s.String()
+}
+
+// panicValue is the placeholder rendered for a value that panicked.
+func panicValue(v any) string {
+	return fmt.Sprintf("!PANIC(%T)", v)
+}
+
+// safeFormatter wraps f so that a panic while the console renders a value,
+// e.g. in a formatter set by WithConsoleWriterTweak, is replaced by
+// "!PANIC(type)" instead of escaping from the logging call. The console
+// renders the values decoded from the JSON event, so String and marshaling
+// methods panicking while the event is built need SafeStringer or SafeObject.
+// A nil f is left to the console's default rendering.
+func safeFormatter(f zerolog.Formatter) zerolog.Formatter {
+	if f == nil {
+		return nil
+	}
+	return func(i any) (s string) {
+		defer func() {
+			if r := recover(); r != nil {
+				s = panicValue(i)
+			}
+		}()
+		return f(i)
+	}
+}
+
+// SafeStringer wraps s so that its String method returns "!PANIC(type)"
+// instead of panicking, for Event.Stringer, which calls String while the
+// event is built:
+//
+//	log.Info().Stringer("user", ezlog.SafeStringer(u)).Msg("login")
+func SafeStringer(s fmt.Stringer) fmt.Stringer {
+	return safeStringer{s}
+}
+
+// safeStringer is the fmt.Stringer returned by SafeStringer.
+type safeStringer struct {
+	s fmt.Stringer
+}
+
+// String implements fmt.Stringer.
+func (s safeStringer) String() string {
+	return SafeString(s.s)
+}
+
+// SafeObject wraps m so that a panic of its MarshalZerologObject method is
+// recovered, for Event.Object, which calls it while the event is built. The
+// fields added before the panic are kept, followed by an error field
+// holding "!PANIC(type)":
+//
+//	log.Info().Object("order", ezlog.SafeObject(o)).Msg("placed")
+func SafeObject(m zerolog.LogObjectMarshaler) zerolog.LogObjectMarshaler {
+	return safeObject{m}
+}
+
+// safeObject is the zerolog.LogObjectMarshaler returned by SafeObject.
+type safeObject struct {
+	m zerolog.LogObjectMarshaler
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler.
+func (o safeObject) MarshalZerologObject(e *zerolog.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.Str(zerolog.ErrorFieldName, panicValue(o.m))
+		}
+	}()
+	o.m.MarshalZerologObject(e)
+}
+
+// sanitizeMessage escapes the control characters of the message in evt so that
+// user input cannot forge extra lines or inject terminal escapes. Newlines are
+// kept when keepNewlines is set, for WithMultiline to split them.
+func sanitizeMessage(keepNewlines bool) func(map[string]any) error {
+	return func(evt map[string]any) error {
+		if msg, ok := evt[zerolog.MessageFieldName].(string); ok {
+			evt[zerolog.MessageFieldName] = sanitize(msg, keepNewlines)
+		}
+		return nil
+	}
+}
+
+// sanitize escapes the control characters of s, including the escape
+// character starting ANSI sequences, and invalid UTF-8 bytes.
+func sanitize(s string, keepNewlines bool) string {
+	if !needsSanitize(s, keepNewlines) {
+		return s
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(s) + 8)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&sb, `\x%02x`, s[i])
+		case r == '\n' && keepNewlines:
+			sb.WriteRune(r)
+		case r == '\t':
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+			fmt.Fprintf(&sb, `\x%02x`, r)
+		default:
+			sb.WriteRune(r)
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+// needsSanitize reports whether s contains anything sanitize would escape.
+func needsSanitize(s string, keepNewlines bool) bool {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			return true
+		}
+		if (r < 0x20 && r != '\t' && (r != '\n' || !keepNewlines)) || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
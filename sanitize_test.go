@@ -0,0 +1,124 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// panickingStringer panics in String.
+type panickingStringer struct{}
+
+func (panickingStringer) String() string { panic("boom") }
+
+// panickingObject adds a field, then panics.
+type panickingObject struct{}
+
+func (panickingObject) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("id", "42")
+	panic("boom")
+}
+
+func TestSafeString(t *testing.T) {
+	if got, want := SafeString(panickingStringer{}), "!PANIC(ezlog.panickingStringer)"; got != want {
+		t.Errorf("SafeString() = %q, want %q", got, want)
+	}
+}
+
+func TestSafeStringerAndObjectRecoverPanics(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithJSONOutput().WithWriter(&buf).Build()
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("logging panicked: %v", r)
+			}
+		}()
+		l.Info().
+			Stringer("user", SafeStringer(panickingStringer{})).
+			Object("order", SafeObject(panickingObject{})).
+			Msg("login")
+	}()
+
+	var evt struct {
+		User  string         `json:"user"`
+		Order map[string]any `json:"order"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &evt); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if want := "!PANIC(ezlog.panickingStringer)"; evt.User != want {
+		t.Errorf("user = %q, want %q", evt.User, want)
+	}
+	if evt.Order["id"] != "42" || evt.Order["error"] != "!PANIC(ezlog.panickingObject)" {
+		t.Errorf("order = %v, want the id and the panic", evt.Order)
+	}
+}
+
+func TestSanitizeMessage(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).Build()
+	l.Info().Msg("user\nforged line \x1b[31mred")
+
+	out := strings.TrimSuffix(buf.String(), "\n")
+	if strings.Contains(out, "\n") || strings.Contains(out, "\x1b") {
+		t.Errorf("output %q keeps control characters", out)
+	}
+	if !strings.Contains(out, `user\nforged line \x1b[31mred`) {
+		t.Errorf("output %q does not escape the control characters", out)
+	}
+}
+
+func TestSanitizeForgedLine(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).Build()
+	l.Info().Str("user", "ada\n[ERROR] forged").Msg("login\n[ERROR] forged")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("output %q has %d lines, want 1", buf.String(), len(lines))
+	}
+	if strings.Count(lines[0], `\n[ERROR] forged`) != 2 {
+		t.Errorf("output %q does not escape the message and the field", lines[0])
+	}
+}
+
+func TestWithRawMessages(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithRawMessages().Build()
+	l.Info().Msg("first\nsecond")
+
+	if !strings.Contains(buf.String(), "first\nsecond") {
+		t.Errorf("output %q is sanitized despite WithRawMessages", buf.String())
+	}
+}
+
+func TestConsoleRecoversValueRenderingPanics(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithConsoleWriterTweak(func(w *zerolog.ConsoleWriter) {
+		format := w.FormatFieldValue
+		w.FormatFieldValue = func(i any) string {
+			if i == "bad" {
+				panic("boom")
+			}
+			return format(i)
+		}
+	}).Build()
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("logging panicked: %v", r)
+			}
+		}()
+		l.Info().Str("user", "bad").Int("n", 1).Msg("login")
+	}()
+
+	if got := buf.String(); !strings.Contains(got, "n=1 user=!PANIC(string)") {
+		t.Errorf("output = %q, want the placeholder and the other fields", got)
+	}
+}
@@ -0,0 +1,167 @@
+package ezlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog"
+	"golang.org/x/term"
+)
+
+// SectionFieldName is the field holding the title of section events.
+const SectionFieldName = "section"
+
+// sectionRule is the character the section rules are drawn with.
+const sectionRule = "─"
+
+// defaultSectionWidth is the section width used when the terminal width is
+// unknown.
+const defaultSectionWidth = 80
+
+// Section logs title at info level as a separator between the phases of a
+// program, such as the steps of a long startup. The console output renders
+// it as a ruled line with the title centered:
+//
+//	12:00:00.000 [INFO] ─────────────── database ───────────────
+//
+// while JSON output gets a normal event with the title as message and in the
+// section field.
+func Section(l *zerolog.Logger, title string) {
+	l.Info().CallerSkipFrame(1).Str(SectionFieldName, title).Msg(title)
+}
+
+// SectionScope logs Section(l, title) and returns a function logging the
+// end of the section with its duration, rendered on the console as
+// "=== title done (1.2s) ===". Calls after the first are no-ops:
+//
+//	defer ezlog.SectionScope(l, "migrations")()
+func SectionScope(l *zerolog.Logger, title string) func() {
+	l.Info().CallerSkipFrame(1).Str(SectionFieldName, title).Msg(title)
+	start := time.Now()
+	var done atomic.Bool
+	return func() {
+		if done.CompareAndSwap(false, true) {
+			l.Info().CallerSkipFrame(1).Str(SectionFieldName, title).Dur("elapsed", time.Since(start)).Msg(title + " done")
+		}
+	}
+}
+
+// WithSectionWidth sets the width of the section rules, including the
+// timestamp and level columns. By default the rules span the terminal, or 80
+// columns when the writer is not a terminal. The width is clamped to the
+// terminal width.
+func (b *LogBuilder) WithSectionWidth(width int) *LogBuilder {
+	b.sectionWidth = width
+	return b
+}
+
+// WithSectionColor sets the color of the section rules. They are bold cyan
+// by default.
+func (b *LogBuilder) WithSectionColor(c *color.Color) *LogBuilder {
+	b.sectionColor = c
+	return b
+}
+
+// formatSections returns the console FormatPrepare turning section events
+// into ruled lines.
+func (b *LogBuilder) formatSections() func(map[string]any) error {
+	c := b.sectionColor
+	if c == nil {
+		c = color.New(color.FgCyan, color.Bold)
+	}
+	width := b.sectionRuleWidth()
+	return func(evt map[string]any) error {
+		title, ok := evt[SectionFieldName].(string)
+		if !ok {
+			return nil
+		}
+		msg, _ := evt[zerolog.MessageFieldName].(string)
+		var line string
+		switch msg {
+		case title:
+			line = centerRule(title, width)
+		case title + " done":
+			elapsed, ok := sectionElapsed(evt["elapsed"])
+			if !ok {
+				return nil
+			}
+			line = fmt.Sprintf("=== %s done (%s) ===", title, elapsed)
+			delete(evt, "elapsed")
+		default:
+			return nil
+		}
+		line = c.Sprint(line)
+		if b.tviewCompat {
+			line = tview.Escape(line)
+		}
+		evt[zerolog.MessageFieldName] = line
+		delete(evt, SectionFieldName)
+		return nil
+	}
+}
+
+// sectionRuleWidth returns the width of the section rules, without the
+// timestamp, level and tag columns.
+func (b *LogBuilder) sectionRuleWidth() int {
+	width := b.sectionWidth
+	if cols, ok := terminalWidth(b.writer); ok && (width <= 0 || width > cols) {
+		width = cols
+	}
+	if width <= 0 {
+		width = defaultSectionWidth
+	}
+	prefix := len(b.timeFormat) + 1 + b.levelLabelWidth() + 3
+	if b.tag != "" {
+		prefix += visibleWidth(b.tagLabel()) + 3
+	}
+	return width - prefix
+}
+
+// centerRule returns title centered in a rule of width cells, with at least
+// three rule characters on each side.
+func centerRule(title string, width int) string {
+	side := (width - visibleWidth(title) - 2) / 2
+	side = max(side, 3)
+	rule := strings.Repeat(sectionRule, side)
+	line := rule + " " + title + " " + rule
+	if pad := width - visibleWidth(line); pad > 0 {
+		line += strings.Repeat(sectionRule, pad)
+	}
+	return line
+}
+
+// sectionElapsed renders the elapsed field of a section end event, either a
+// number of zerolog.DurationFieldUnit or a string already humanized.
+func sectionElapsed(v any) (string, bool) {
+	switch vv := v.(type) {
+	case string:
+		return vv, true
+	case json.Number:
+		f, err := strconv.ParseFloat(string(vv), 64)
+		if err != nil {
+			return "", false
+		}
+		return humanDuration(time.Duration(f * float64(zerolog.DurationFieldUnit))), true
+	}
+	return "", false
+}
+
+// terminalWidth returns the number of columns of w, if it is a terminal.
+func terminalWidth(w any) (int, bool) {
+	f, ok := w.(*os.File)
+	if !ok || !isTerminal(f) {
+		return 0, false
+	}
+	cols, _, err := term.GetSize(int(f.Fd()))
+	if err != nil || cols <= 0 {
+		return 0, false
+	}
+	return cols, true
+}
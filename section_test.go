@@ -0,0 +1,112 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func TestSectionConsole(t *testing.T) {
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithSectionWidth(60).WithTimestampFunc(fixedClock(ts)).Build()
+	Section(l, "database")
+	l.Info().Msg("plain")
+
+	want := "09:26:53.000 [INFO] ────────────── database ───────────────\n" +
+		"09:26:53.000 [INFO] plain\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestSectionWidthClamped(t *testing.T) {
+	for _, width := range []int{60, 80, 120} {
+		var buf bytes.Buffer
+		l := newTestBuilder(&buf).WithTag("boot").WithSectionWidth(width).Build()
+		Section(l, "config")
+		line := strings.TrimSuffix(buf.String(), "\n")
+		if w := runewidth.StringWidth(line); w > width || w < width-2 {
+			t.Errorf("width %d: line %q is %d cells wide", width, line, w)
+		}
+	}
+
+	// Narrow widths keep three rule characters on each side of the title.
+	if got := centerRule("title", 4); got != "─── title ───" {
+		t.Errorf("centerRule on a narrow width = %q", got)
+	}
+}
+
+func TestSectionScopeConsole(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithSectionWidth(60).Build()
+	done := SectionScope(l, "migrations")
+	done()
+	done()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "─ migrations ─") {
+		t.Fatalf("output = %q, want the rule and one end line", lines)
+	}
+	end := regexp.MustCompile(`\[INFO\] === migrations done \([0-9.]+[µn]?s\) ===$`)
+	if !end.MatchString(lines[1]) {
+		t.Errorf("end line = %q", lines[1])
+	}
+}
+
+func TestSectionJSON(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithJSONOutput().Build()
+	SectionScope(l, "migrations")()
+
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var evt map[string]any
+		if err := json.Unmarshal([]byte(line), &evt); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, evt)
+	}
+	if e := events[0]; e["section"] != "migrations" || e["message"] != "migrations" || e["level"] != "info" {
+		t.Errorf("start event = %v", e)
+	}
+	if e := events[1]; e["section"] != "migrations" || e["message"] != "migrations done" || e["elapsed"] == nil {
+		t.Errorf("end event = %v", e)
+	}
+	if strings.Contains(buf.String(), sectionRule) {
+		t.Errorf("JSON output has rule characters: %s", buf.String())
+	}
+}
+
+func TestSectionTviewEscapes(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithTviewCompat().WithSectionWidth(60).Build()
+	Section(l, "[red]setup")
+	if got := buf.String(); !strings.Contains(got, "─ [red[]setup ─") {
+		t.Errorf("output = %q, want the title escaped for tview", got)
+	}
+}
+
+func TestSectionElapsed(t *testing.T) {
+	tests := []struct {
+		v    any
+		want string
+		ok   bool
+	}{
+		{json.Number("1200"), "1.2s", true},
+		{"3m", "3m", true},
+		{json.Number("x"), "", false},
+		{nil, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := sectionElapsed(tt.v)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("sectionElapsed(%v) = %q, %v, want %q, %v", tt.v, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Package sentry forwards ezlog events to Sentry, for crash reporting
+// without a separate integration layer. It is a separate package to keep the
+// Sentry SDK out of programs that do not import it.
+package sentry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ezydark/ezlog"
+	sentrygo "github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+)
+
+// Option configures a Writer.
+type Option func(*Writer)
+
+// WithBreadcrumbs records the events below the minimum level as breadcrumbs
+// of the hub's scope instead of dropping them, so they are attached to the
+// next event sent to Sentry. The scope's breadcrumbs are cleared once sent,
+// so each event only carries the ones logged since the previous one.
+func WithBreadcrumbs() Option {
+	return func(w *Writer) {
+		w.breadcrumbs = true
+	}
+}
+
+// WithFlushTimeout sets how long fatal and panic events wait for Sentry to
+// receive them, 2s by default.
+func WithFlushTimeout(d time.Duration) Option {
+	return func(w *Writer) {
+		w.flushTimeout = d
+	}
+}
+
+// Writer sends zerolog JSON events at or above a minimum level to Sentry:
+// the message and level become the ones of the Sentry event, the "tag"
+// field a Sentry tag, the error, its error_chain and stack an exception,
+// and the other fields extra data. Fatal and panic events are flushed
+// before Write returns, as the program is about to exit.
+// It should be created using NewSentryWriter.
+type Writer struct {
+	hub          *sentrygo.Hub
+	minLevel     zerolog.Level
+	breadcrumbs  bool
+	flushTimeout time.Duration
+}
+
+// NewSentryWriter creates a Writer sending the events at or above minLevel
+// through hub. As it only handles JSON events, it is typically one of the
+// outputs of a JSON logger:
+//
+//	w := zerolog.MultiLevelWriter(os.Stdout, sentry.NewSentryWriter(sentrygo.CurrentHub(), zerolog.ErrorLevel))
+//	logger := ezlog.New().WithJSONOutput().WithWriter(w).Build()
+func NewSentryWriter(hub *sentrygo.Hub, minLevel zerolog.Level, opts ...Option) *Writer {
+	w := &Writer{
+		hub:          hub,
+		minLevel:     minLevel,
+		flushTimeout: 2 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Write implements io.Writer, reading the level from the event.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	var fields map[string]any
+	if err := d.Decode(&fields); err != nil {
+		return 0, errors.New("ezlog/sentry: event is not a JSON object")
+	}
+	if s, ok := fields[zerolog.LevelFieldName].(string); ok {
+		if l, err := ezlog.ParseLevel(s); err == nil {
+			level = l
+		}
+	}
+	if level == zerolog.NoLevel || level == zerolog.Disabled {
+		return len(p), nil
+	}
+
+	if level < w.minLevel {
+		if w.breadcrumbs {
+			w.hub.AddBreadcrumb(breadcrumb(level, fields), nil)
+		}
+		return len(p), nil
+	}
+
+	w.hub.CaptureEvent(event(level, fields))
+	if w.breadcrumbs {
+		w.hub.Scope().ClearBreadcrumbs()
+	}
+	if level >= zerolog.FatalLevel {
+		w.hub.Flush(w.flushTimeout)
+	}
+	return len(p), nil
+}
+
+// event converts the fields of an event at level to a Sentry event.
+func event(level zerolog.Level, fields map[string]any) *sentrygo.Event {
+	e := sentrygo.NewEvent()
+	e.Level = sentryLevel(level)
+	e.Logger = "ezlog"
+	e.Timestamp = timestamp(fields)
+
+	errMsg, hasErr := fields[zerolog.ErrorFieldName]
+	chain, _ := fields[ezlog.ErrorChainFieldName].([]any)
+	stack, _ := fields[zerolog.ErrorStackFieldName].([]any)
+	for key, value := range fields {
+		switch key {
+		case zerolog.LevelFieldName, zerolog.TimestampFieldName,
+			zerolog.ErrorFieldName, ezlog.ErrorChainFieldName, zerolog.ErrorStackFieldName:
+		case zerolog.MessageFieldName:
+			e.Message, _ = value.(string)
+		case "tag":
+			e.Tags["tag"] = fmt.Sprint(value)
+		default:
+			e.Extra[key] = value
+		}
+	}
+
+	if hasErr {
+		// Sentry lists exceptions from the root cause to the reported error.
+		for i := len(chain) - 1; i >= 0; i-- {
+			e.Exception = append(e.Exception, sentrygo.Exception{Type: "cause", Value: fmt.Sprint(chain[i])})
+		}
+		e.Exception = append(e.Exception, sentrygo.Exception{
+			Type:       "error",
+			Value:      fmt.Sprint(errMsg),
+			Stacktrace: stacktrace(stack),
+		})
+	}
+	return e
+}
+
+// breadcrumb converts the fields of an event at level to a breadcrumb.
+func breadcrumb(level zerolog.Level, fields map[string]any) *sentrygo.Breadcrumb {
+	b := &sentrygo.Breadcrumb{
+		Type:      "default",
+		Category:  "log",
+		Level:     sentryLevel(level),
+		Timestamp: timestamp(fields),
+		Data:      make(map[string]any, len(fields)),
+	}
+	for key, value := range fields {
+		switch key {
+		case zerolog.LevelFieldName, zerolog.TimestampFieldName:
+		case zerolog.MessageFieldName:
+			b.Message, _ = value.(string)
+		case "tag":
+			b.Category = fmt.Sprint(value)
+		default:
+			b.Data[key] = value
+		}
+	}
+	return b
+}
+
+// stacktrace converts the stack field written by zerolog's pkgerrors
+// marshaler, innermost frame first, to a Sentry stack trace, outermost
+// frame first.
+func stacktrace(stack []any) *sentrygo.Stacktrace {
+	if len(stack) == 0 {
+		return nil
+	}
+	st := &sentrygo.Stacktrace{}
+	for i := len(stack) - 1; i >= 0; i-- {
+		frame, ok := stack[i].(map[string]any)
+		if !ok {
+			continue
+		}
+		f := sentrygo.Frame{InApp: true}
+		f.Function, _ = frame["func"].(string)
+		f.Filename, _ = frame["source"].(string)
+		switch line := frame["line"].(type) {
+		case string:
+			f.Lineno, _ = strconv.Atoi(line)
+		case json.Number:
+			n, _ := line.Int64()
+			f.Lineno = int(n)
+		}
+		st.Frames = append(st.Frames, f)
+	}
+	return st
+}
+
+// timestamp returns the time of an event, or now if it has none.
+func timestamp(fields map[string]any) time.Time {
+	if s, ok := fields[zerolog.TimestampFieldName].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t
+		}
+	}
+	return time.Now()
+}
+
+// sentryLevel returns the Sentry level of a zerolog level.
+func sentryLevel(level zerolog.Level) sentrygo.Level {
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return sentrygo.LevelDebug
+	case zerolog.InfoLevel:
+		return sentrygo.LevelInfo
+	case zerolog.WarnLevel:
+		return sentrygo.LevelWarning
+	case zerolog.ErrorLevel:
+		return sentrygo.LevelError
+	default:
+		return sentrygo.LevelFatal
+	}
+}
+
+// Writer must satisfy zerolog.LevelWriter.
+var _ zerolog.LevelWriter = (*Writer)(nil)
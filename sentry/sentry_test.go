@@ -0,0 +1,171 @@
+package sentry
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ezydark/ezlog"
+	sentrygo "github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+)
+
+// stubTransport captures the events sent to Sentry.
+type stubTransport struct {
+	mu      sync.Mutex
+	events  []*sentrygo.Event
+	flushes int
+}
+
+func (t *stubTransport) Configure(sentrygo.ClientOptions) {}
+func (t *stubTransport) Close()                           {}
+
+func (t *stubTransport) SendEvent(e *sentrygo.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, e)
+}
+
+func (t *stubTransport) Flush(time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.flushes++
+	return true
+}
+
+func (t *stubTransport) FlushWithContext(context.Context) bool { return t.Flush(0) }
+
+// newHub returns a hub sending its events to a stubTransport.
+func newHub(t *testing.T) (*sentrygo.Hub, *stubTransport) {
+	t.Helper()
+	tr := &stubTransport{}
+	client, err := sentrygo.NewClient(sentrygo.ClientOptions{Dsn: "https://key@sentry.example.com/1", Transport: tr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sentrygo.NewHub(client, sentrygo.NewScope()), tr
+}
+
+func TestSentryWriterError(t *testing.T) {
+	hub, tr := newHub(t)
+	l := ezlog.New().AsLocal().WithJSONOutput().
+		WithWriter(NewSentryWriter(hub, zerolog.ErrorLevel)).Build()
+
+	l.Warn().Msg("below the minimum level")
+	l.Error().Str("tag", "api").Str("user", "ada").Int("id", 7).Str("error", "boom").Msg("failed")
+
+	if len(tr.events) != 1 {
+		t.Fatalf("got %d events, want 1", len(tr.events))
+	}
+	e := tr.events[0]
+	if e.Message != "failed" || e.Level != sentrygo.LevelError || e.Logger != "ezlog" || e.Tags["tag"] != "api" {
+		t.Errorf("event = message %q, level %q, logger %q, tags %v", e.Message, e.Level, e.Logger, e.Tags)
+	}
+	if e.Extra["user"] != "ada" || e.Extra["id"] == nil || len(e.Extra) != 2 {
+		t.Errorf("extra = %v", e.Extra)
+	}
+	if len(e.Exception) != 1 || e.Exception[0].Value != "boom" {
+		t.Errorf("exception = %+v", e.Exception)
+	}
+	if tr.flushes != 0 {
+		t.Errorf("error event flushed %d times", tr.flushes)
+	}
+}
+
+func TestSentryWriterErrorChainAndStack(t *testing.T) {
+	hub, tr := newHub(t)
+	w := NewSentryWriter(hub, zerolog.ErrorLevel)
+	w.Write([]byte(`{"level":"error","time":"2025-03-14T09:26:53Z","error":"save: db: locked",` +
+		`"error_chain":["save: db: locked","db: locked","locked"],` +
+		`"stack":[{"func":"inner","source":"db.go","line":"10"},{"func":"outer","source":"main.go","line":20}],` +
+		`"message":"failed"}`))
+
+	e := tr.events[0]
+	if !e.Timestamp.Equal(time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)) {
+		t.Errorf("timestamp = %v", e.Timestamp)
+	}
+	var values []string
+	for _, ex := range e.Exception {
+		values = append(values, ex.Value)
+	}
+	if len(values) != 4 || values[0] != "locked" || values[2] != "save: db: locked" || values[3] != "save: db: locked" {
+		t.Errorf("exceptions = %q, want the chain from the root cause, then the error", values)
+	}
+	st := e.Exception[3].Stacktrace
+	if st == nil || len(st.Frames) != 2 || st.Frames[0].Function != "outer" || st.Frames[0].Lineno != 20 ||
+		st.Frames[1].Function != "inner" || st.Frames[1].Filename != "db.go" || st.Frames[1].Lineno != 10 {
+		t.Errorf("stack trace = %+v, want the outermost frame first", st)
+	}
+	if len(e.Extra) != 0 {
+		t.Errorf("extra = %v, want none", e.Extra)
+	}
+}
+
+func TestSentryWriterFatalFlushes(t *testing.T) {
+	hub, tr := newHub(t)
+	l := zerolog.New(NewSentryWriter(hub, zerolog.ErrorLevel, WithFlushTimeout(time.Second)))
+	l.WithLevel(zerolog.FatalLevel).Msg("exiting")
+	l.WithLevel(zerolog.PanicLevel).Msg("panicking")
+
+	if len(tr.events) != 2 || tr.events[0].Level != sentrygo.LevelFatal || tr.flushes != 2 {
+		t.Errorf("got %d events, %d flushes", len(tr.events), tr.flushes)
+	}
+}
+
+func TestSentryWriterBreadcrumbs(t *testing.T) {
+	hub, tr := newHub(t)
+	l := zerolog.New(NewSentryWriter(hub, zerolog.ErrorLevel, WithBreadcrumbs()))
+	l.Info().Str("tag", "db").Str("table", "users").Msg("connected")
+	l.Warn().Msg("slow")
+	l.Error().Msg("first")
+	l.Error().Msg("second")
+
+	if len(tr.events) != 2 {
+		t.Fatalf("got %d events, want 2", len(tr.events))
+	}
+	crumbs := tr.events[0].Breadcrumbs
+	if len(crumbs) != 2 {
+		t.Fatalf("first event has %d breadcrumbs, want 2", len(crumbs))
+	}
+	if c := crumbs[0]; c.Message != "connected" || c.Category != "db" || c.Level != sentrygo.LevelInfo || c.Data["table"] != "users" {
+		t.Errorf("breadcrumb = %+v", c)
+	}
+	if c := crumbs[1]; c.Message != "slow" || c.Category != "log" || c.Level != sentrygo.LevelWarning {
+		t.Errorf("breadcrumb = %+v", c)
+	}
+	if n := len(tr.events[1].Breadcrumbs); n != 0 {
+		t.Errorf("second event has %d breadcrumbs, want those since the first only", n)
+	}
+}
+
+func TestSentryWriterWithoutBreadcrumbs(t *testing.T) {
+	hub, tr := newHub(t)
+	l := zerolog.New(NewSentryWriter(hub, zerolog.ErrorLevel))
+	l.Info().Msg("dropped")
+	l.Log().Msg("no level")
+	l.Error().Msg("failed")
+
+	if len(tr.events) != 1 || len(tr.events[0].Breadcrumbs) != 0 {
+		t.Errorf("events = %d, breadcrumbs = %v", len(tr.events), tr.events[0].Breadcrumbs)
+	}
+	if n, err := NewSentryWriter(hub, zerolog.ErrorLevel).Write([]byte("not json")); n != 0 || err == nil {
+		t.Errorf("Write(not json) = %d, %v", n, err)
+	}
+}
+
+func TestSentryLevel(t *testing.T) {
+	for level, want := range map[zerolog.Level]sentrygo.Level{
+		zerolog.TraceLevel: sentrygo.LevelDebug,
+		zerolog.DebugLevel: sentrygo.LevelDebug,
+		zerolog.InfoLevel:  sentrygo.LevelInfo,
+		zerolog.WarnLevel:  sentrygo.LevelWarning,
+		zerolog.ErrorLevel: sentrygo.LevelError,
+		zerolog.FatalLevel: sentrygo.LevelFatal,
+		zerolog.PanicLevel: sentrygo.LevelFatal,
+	} {
+		if got := sentryLevel(level); got != want {
+			t.Errorf("sentryLevel(%v) = %q, want %q", level, got, want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package ezlog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// SizeRotatingFileWriter writes to a file and renames it to a numbered
+// backup when it would grow past a size limit: path becomes path.1, path.1
+// becomes path.2 and so on, keeping a fixed number of backups.
+// It should be created using NewSizeRotatingFileWriter.
+type SizeRotatingFileWriter struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	compress   bool
+
+	file     *os.File
+	size     int64
+	compacts sync.WaitGroup
+	closed   bool
+}
+
+// NewSizeRotatingFileWriter creates a SizeRotatingFileWriter writing to
+// path, rotating it once it reaches maxSizeMB megabytes and keeping
+// maxBackups backups, all of them when maxBackups <= 0. With compress, the
+// backups are gzip-compressed in the background and named path.N.gz. The file
+// is opened, and its directory created, on the first write.
+func NewSizeRotatingFileWriter(path string, maxSizeMB, maxBackups int, compress bool) *SizeRotatingFileWriter {
+	return &SizeRotatingFileWriter{
+		path:       path,
+		maxSize:    int64(maxSizeMB) << 20,
+		maxBackups: maxBackups,
+		compress:   compress,
+	}
+}
+
+// Write implements io.Writer.
+func (w *SizeRotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the file and waits for pending backup compressions.
+func (w *SizeRotatingFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.compacts.Wait()
+	return err
+}
+
+// open opens the file for appending. It must be called with w.mu held.
+func (w *SizeRotatingFileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// rotate shifts the backups, moves the file to the first backup and opens a
+// new file. It must be called with w.mu held.
+func (w *SizeRotatingFileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	// Compressions still running would race with the renames.
+	w.compacts.Wait()
+	if w.maxBackups > 0 {
+		_ = os.Remove(w.backupName(w.maxBackups))
+	}
+	for i := w.highestBackup(); i >= 1; i-- {
+		if err := os.Rename(w.backupName(i), w.backupName(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	first := fmt.Sprintf("%s.1", w.path)
+	if err := os.Rename(w.path, first); err != nil {
+		return err
+	}
+	if w.compress {
+		w.compacts.Add(1)
+		go func() {
+			defer w.compacts.Done()
+			if err := gzipFile(first); err != nil {
+				diag(zerolog.WarnLevel, "compress", "compressing %s failed: %v", first, err)
+			}
+		}()
+	}
+	return w.open()
+}
+
+// highestBackup returns the number of the oldest existing backup.
+func (w *SizeRotatingFileWriter) highestBackup() int {
+	n := 0
+	for {
+		if _, err := os.Stat(w.backupName(n + 1)); err != nil {
+			return n
+		}
+		n++
+	}
+}
+
+// backupName returns the path of backup i.
+func (w *SizeRotatingFileWriter) backupName(i int) string {
+	name := fmt.Sprintf("%s.%d", w.path, i)
+	if w.compress {
+		name += ".gz"
+	}
+	return name
+}
+
+// gzipFile compresses path into path.gz and removes path.
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := errors.Join(zw.Close(), out.Close()); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,160 @@
+package ezlog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"github.com/rs/zerolog"
+)
+
+// LogBuilderOption configures the LogBuilder of NewSlogHandler, e.g.
+//
+//	func(b *ezlog.LogBuilder) { b.WithTag("api").WithCaller() }
+type LogBuilderOption func(*LogBuilder)
+
+// EzlogSlogHandler is a slog.Handler rendering records with a local ezlog
+// logger, so code using log/slog gets the same colored console output,
+// tags and field formatting as zerolog code. Groups are rendered as dotted
+// field names, like slog.TextHandler does.
+// It should be created using NewSlogHandler.
+type EzlogSlogHandler struct {
+	zl     *zerolog.Logger
+	caller bool
+	attrs  []slog.Attr
+	group  string
+}
+
+// NewSlogHandler creates an EzlogSlogHandler over a local logger configured
+// by opts, writing to the standard output by default:
+//
+//	slog.SetDefault(slog.New(ezlog.NewSlogHandler(func(b *ezlog.LogBuilder) {
+//		b.WithTag("api")
+//	})))
+//
+// With WithCaller, the caller is the one of the slog call.
+func NewSlogHandler(opts ...LogBuilderOption) slog.Handler {
+	b := New().AsLocal()
+	for _, opt := range opts {
+		opt(b)
+	}
+	// zerolog would report the caller inside slog, so it is taken from the
+	// record instead.
+	caller := b.caller
+	b.caller = false
+	return &EzlogSlogHandler{zl: b.Build(), caller: caller}
+}
+
+// Enabled implements slog.Handler.
+func (h *EzlogSlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	l := slogLevel(level)
+	return l >= h.zl.GetLevel() && l >= zerolog.GlobalLevel()
+}
+
+// Handle implements slog.Handler.
+func (h *EzlogSlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	e := h.zl.WithLevel(slogLevel(r.Level))
+	if !e.Enabled() {
+		return nil
+	}
+	e = e.Ctx(ctx)
+	if h.caller && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		e = e.Str(zerolog.CallerFieldName, zerolog.CallerMarshalFunc(f.PC, f.File, f.Line))
+	}
+	for _, a := range h.attrs {
+		e = addSlogAttr(e, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		e = addSlogAttr(e, h.group, a)
+		return true
+	})
+	e.Msg(r.Message)
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *EzlogSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = make([]slog.Attr, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(h2.attrs, h.attrs)
+	for _, a := range attrs {
+		if h.group != "" {
+			a.Key = h.group + a.Key
+		}
+		h2.attrs = append(h2.attrs, a)
+	}
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *EzlogSlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.group = h.group + name + "."
+	return &h2
+}
+
+// addSlogAttr adds a to e, its key prefixed with prefix.
+func addSlogAttr(e *zerolog.Event, prefix string, a slog.Attr) *zerolog.Event {
+	v := a.Value.Resolve()
+	if a.Key == "" && (v.Kind() != slog.KindGroup || len(v.Group()) == 0) {
+		return e
+	}
+	key := prefix + a.Key
+	switch v.Kind() {
+	case slog.KindString:
+		return e.Str(key, v.String())
+	case slog.KindInt64:
+		return e.Int64(key, v.Int64())
+	case slog.KindUint64:
+		return e.Uint64(key, v.Uint64())
+	case slog.KindFloat64:
+		return e.Float64(key, v.Float64())
+	case slog.KindBool:
+		return e.Bool(key, v.Bool())
+	case slog.KindDuration:
+		return e.Dur(key, v.Duration())
+	case slog.KindTime:
+		return e.Time(key, v.Time())
+	case slog.KindGroup:
+		// Groups with an empty key are inlined.
+		if a.Key != "" {
+			prefix = key + "."
+		}
+		for _, ga := range v.Group() {
+			e = addSlogAttr(e, prefix, ga)
+		}
+		return e
+	default:
+		if err, ok := v.Any().(error); ok {
+			return e.AnErr(key, err)
+		}
+		return e.Interface(key, v.Any())
+	}
+}
+
+// slogLevel returns the zerolog level of a slog level. Levels above error
+// map to error, so that they never exit the program.
+func slogLevel(level slog.Level) zerolog.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return zerolog.TraceLevel
+	case level < slog.LevelInfo:
+		return zerolog.DebugLevel
+	case level < slog.LevelWarn:
+		return zerolog.InfoLevel
+	case level < slog.LevelError:
+		return zerolog.WarnLevel
+	default:
+		return zerolog.ErrorLevel
+	}
+}
+
+// EzlogSlogHandler must satisfy slog.Handler.
+var _ slog.Handler = (*EzlogSlogHandler)(nil)
@@ -0,0 +1,117 @@
+package ezlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/rs/zerolog"
+)
+
+func TestSlogHandlerMatchesZerolog(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	var slogOut, zlOut bytes.Buffer
+	opt := func(buf *bytes.Buffer) LogBuilderOption {
+		return func(b *LogBuilder) { b.WithWriter(buf).WithTag("api").WithTimestampFunc(fixedClock(ts)) }
+	}
+	l := slog.New(NewSlogHandler(opt(&slogOut)))
+	l.Info("msg", "key", "val", "n", 3)
+	l.Warn("slow", "elapsed", 1500*time.Millisecond)
+	l.Error("failed", "error", errors.New("boom"))
+
+	b := New().AsLocal()
+	opt(&zlOut)(b)
+	zl := b.Build()
+	zl.Info().Str("key", "val").Int64("n", 3).Msg("msg")
+	zl.Warn().Dur("elapsed", 1500*time.Millisecond).Msg("slow")
+	zl.Error().AnErr("error", errors.New("boom")).Msg("failed")
+
+	if !strings.Contains(slogOut.String(), "\x1b[") {
+		t.Errorf("slog output %q is not colored", slogOut.String())
+	}
+	if slogOut.String() != zlOut.String() {
+		t.Errorf("slog output =\n%q\nwant the zerolog output\n%q", slogOut.String(), zlOut.String())
+	}
+}
+
+func TestSlogHandlerAttrsAndGroups(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewSlogHandler(func(b *LogBuilder) { b.WithWriter(&buf).WithJSONOutput() })
+	l := slog.New(h).With("service", "api").WithGroup("req").With("id", 7)
+	l.Info("served",
+		slog.Group("user", "name", "ada", slog.Bool("admin", true)),
+		slog.Group("", "inline", 1.5),
+		slog.Group("empty"),
+		slog.Time("at", time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)),
+		slog.Uint64("bytes", 42),
+		slog.Any("tags", []string{"a"}),
+	)
+
+	evt := decodeLines(t, &buf)[0]
+	want := map[string]any{
+		"service":        "api",
+		"req.id":         float64(7),
+		"req.user.name":  "ada",
+		"req.user.admin": true,
+		"req.inline":     1.5,
+		"req.at":         "2025-03-14T09:00:00Z",
+		"req.bytes":      float64(42),
+		"message":        "served",
+		"level":          "info",
+	}
+	for k, v := range want {
+		if evt[k] != v {
+			t.Errorf("event[%q] = %v, want %v", k, evt[k], v)
+		}
+	}
+	if tags, _ := evt["req.tags"].([]any); len(tags) != 1 || tags[0] != "a" {
+		t.Errorf("event[req.tags] = %v", evt["req.tags"])
+	}
+	if _, ok := evt["req.empty"]; ok {
+		t.Errorf("event %v has the empty group", evt)
+	}
+}
+
+func TestSlogHandlerLevels(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewSlogHandler(func(b *LogBuilder) { b.WithWriter(&buf).WithJSONOutput().WithLevel(zerolog.InfoLevel) })
+	if h.Enabled(context.Background(), slog.LevelDebug) || !h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Enabled does not follow the builder's level")
+	}
+
+	for _, tt := range []struct {
+		slog    slog.Level
+		zerolog zerolog.Level
+	}{
+		{slog.LevelDebug - 4, zerolog.TraceLevel},
+		{slog.LevelDebug, zerolog.DebugLevel},
+		{slog.LevelInfo + 2, zerolog.InfoLevel},
+		{slog.LevelWarn, zerolog.WarnLevel},
+		{slog.LevelError, zerolog.ErrorLevel},
+		{slog.LevelError + 8, zerolog.ErrorLevel},
+	} {
+		if got := slogLevel(tt.slog); got != tt.zerolog {
+			t.Errorf("slogLevel(%v) = %v, want %v", tt.slog, got, tt.zerolog)
+		}
+	}
+}
+
+func TestSlogHandlerCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(NewSlogHandler(func(b *LogBuilder) { b.WithWriter(&buf).WithJSONOutput().WithCaller() }))
+	l.Info("called")
+
+	caller, _ := decodeLines(t, &buf)[0]["caller"].(string)
+	if !strings.Contains(caller, "sloghandler_test.go:") {
+		t.Errorf("caller = %q, want the slog call", caller)
+	}
+}
@@ -0,0 +1,310 @@
+package ezlog
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// sqliteSchema creates the logs table and its indices.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS logs (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	ts        INTEGER NOT NULL,
+	level     TEXT NOT NULL,
+	level_num INTEGER NOT NULL,
+	tag       TEXT NOT NULL,
+	message   TEXT NOT NULL,
+	fields    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS logs_ts ON logs (ts);
+CREATE INDEX IF NOT EXISTS logs_level ON logs (level_num);
+`
+
+// SQLiteOption configures a SQLiteSink.
+type SQLiteOption func(*SQLiteSink)
+
+// WithSQLiteDriver sets the database/sql driver name, "sqlite" by default
+// (modernc.org/sqlite). Use "sqlite3" for github.com/mattn/go-sqlite3.
+func WithSQLiteDriver(name string) SQLiteOption {
+	return func(s *SQLiteSink) {
+		s.driver = name
+	}
+}
+
+// WithSQLiteBatch sets how many events are inserted per transaction and how
+// long events wait for a batch to fill. Defaults are 200 events and 1s.
+func WithSQLiteBatch(size int, wait time.Duration) SQLiteOption {
+	return func(s *SQLiteSink) {
+		s.batchSize = size
+		s.batchWait = wait
+	}
+}
+
+// WithSQLiteRetention deletes the oldest rows beyond maxRows, and rows older
+// than maxAge. Zero disables the corresponding limit.
+func WithSQLiteRetention(maxRows int, maxAge time.Duration) SQLiteOption {
+	return func(s *SQLiteSink) {
+		s.maxRows = maxRows
+		s.maxAge = maxAge
+	}
+}
+
+// LogRecord is a log event stored by a SQLiteSink.
+type LogRecord struct {
+	ID int64
+	// Time is the time of the event, or the time it was written to the sink
+	// when it has no timestamp.
+	Time    time.Time
+	Level   zerolog.Level
+	Tag     string
+	Message string
+	Fields  map[string]any
+}
+
+// LogFilter selects the records returned by SQLiteSink.Query.
+// Zero values do not filter.
+type LogFilter struct {
+	// MinLevel excludes the records below the level, if set.
+	MinLevel *zerolog.Level
+	Tag      string
+	Since    time.Time
+	Until    time.Time
+	Contains string
+	Limit    int
+	Offset   int
+}
+
+// SQLiteSink stores zerolog JSON events in a SQLite database so they can be
+// searched later, e.g. by a TUI. The SQLite driver must be imported by the
+// application. It should be created using NewSQLiteSink.
+type SQLiteSink struct {
+	db        *sql.DB
+	driver    string
+	batchSize int
+	batchWait time.Duration
+	maxRows   int
+	maxAge    time.Duration
+
+	batcher *batcher
+	failed  atomic.Uint64
+}
+
+// NewSQLiteSink opens or creates the database at path.
+func NewSQLiteSink(path string, opts ...SQLiteOption) (*SQLiteSink, error) {
+	s := &SQLiteSink{
+		driver:    "sqlite",
+		batchSize: 200,
+		batchWait: time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	db, err := sql.Open(s.driver, path)
+	if err != nil {
+		return nil, fmt.Errorf("ezlog: open %s: %w", path, err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ezlog: create logs table: %w", err)
+	}
+	s.db = db
+
+	s.batcher = newBatcher(10*s.batchSize, s.batchSize, s.batchWait, s.insert)
+	return s, nil
+}
+
+// Write implements io.Writer. Events are inserted in the background.
+func (s *SQLiteSink) Write(p []byte) (int, error) {
+	if err := s.batcher.add(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close inserts the remaining events and closes the database.
+func (s *SQLiteSink) Close() error {
+	s.batcher.close()
+	return s.db.Close()
+}
+
+// Dropped returns the number of events that could not be stored.
+func (s *SQLiteSink) Dropped() uint64 {
+	return s.batcher.dropped.Load() + s.failed.Load()
+}
+
+// insert stores batch in one transaction, then prunes old rows.
+func (s *SQLiteSink) insert(batch []batchItem) {
+	if err := s.insertBatch(batch); err != nil {
+		s.failed.Add(uint64(len(batch)))
+		diag(zerolog.ErrorLevel, "sqlite", "sqlite insert failed, %d events lost: %v", len(batch), err)
+		return
+	}
+	s.prune()
+}
+
+// insertBatch stores batch in one transaction.
+func (s *SQLiteSink) insertBatch(batch []batchItem) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO logs (ts, level, level_num, tag, message, fields) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, item := range batch {
+		var evt map[string]any
+		dec := json.NewDecoder(bytes.NewReader(item.p))
+		dec.UseNumber()
+		if err := dec.Decode(&evt); err != nil {
+			s.failed.Add(1)
+			continue
+		}
+
+		levelStr, _ := evt[zerolog.LevelFieldName].(string)
+		level, err := ParseLevel(levelStr)
+		if err != nil {
+			level = zerolog.NoLevel
+		}
+		msg, _ := evt[zerolog.MessageFieldName].(string)
+		tag, _ := evt["tag"].(string)
+		ts, ok := eventTimestamp(evt[zerolog.TimestampFieldName])
+		if !ok {
+			ts = item.at
+		}
+		for _, key := range []string{zerolog.LevelFieldName, zerolog.MessageFieldName, zerolog.TimestampFieldName, "tag"} {
+			delete(evt, key)
+		}
+		fields, err := json.Marshal(evt)
+		if err != nil {
+			fields = []byte("{}")
+		}
+
+		if _, err := stmt.Exec(ts.UnixNano(), levelStr, int(level), tag, msg, string(fields)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// eventTimestamp returns the time held by the timestamp field v of an event
+// decoded with json.Decoder.UseNumber, formatted as zerolog.TimeFieldFormat.
+func eventTimestamp(v any) (time.Time, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return parseEventTime(v)
+	}
+	i, err := n.Int64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	switch zerolog.TimeFieldFormat {
+	case zerolog.TimeFormatUnixMs:
+		return time.UnixMilli(i), true
+	case zerolog.TimeFormatUnixMicro:
+		return time.UnixMicro(i), true
+	case zerolog.TimeFormatUnixNano:
+		return time.Unix(0, i), true
+	default:
+		return time.Unix(i, 0), true
+	}
+}
+
+// prune deletes the rows beyond the retention limits.
+func (s *SQLiteSink) prune() {
+	if s.maxRows > 0 {
+		_, _ = s.db.Exec(`DELETE FROM logs WHERE id <= (SELECT id FROM logs ORDER BY id DESC LIMIT 1 OFFSET ?)`, s.maxRows)
+	}
+	if s.maxAge > 0 {
+		_, _ = s.db.Exec(`DELETE FROM logs WHERE ts < ?`, time.Now().Add(-s.maxAge).UnixNano())
+	}
+}
+
+// Query returns the stored records matching filter, newest first.
+func (s *SQLiteSink) Query(ctx context.Context, filter LogFilter) ([]LogRecord, error) {
+	query, args := filter.query()
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []LogRecord
+	for rows.Next() {
+		var rec LogRecord
+		var ts int64
+		var level, fields string
+		if err := rows.Scan(&rec.ID, &ts, &level, &rec.Tag, &rec.Message, &fields); err != nil {
+			return nil, err
+		}
+		rec.Time = time.Unix(0, ts)
+		if rec.Level, err = ParseLevel(level); err != nil {
+			rec.Level = zerolog.NoLevel
+		}
+		_ = json.Unmarshal([]byte(fields), &rec.Fields)
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// QueueDepth returns the number of events waiting to be stored.
+func (s *SQLiteSink) QueueDepth() int {
+	return len(s.batcher.queue)
+}
+
+// query returns the SQL query selecting the records matching f, and its
+// arguments.
+func (f LogFilter) query() (string, []any) {
+	var where []string
+	var args []any
+
+	if f.MinLevel != nil {
+		where = append(where, "level_num >= ?")
+		args = append(args, int(*f.MinLevel))
+	}
+	if f.Tag != "" {
+		where = append(where, "tag = ?")
+		args = append(args, f.Tag)
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "ts >= ?")
+		args = append(args, f.Since.UnixNano())
+	}
+	if !f.Until.IsZero() {
+		where = append(where, "ts < ?")
+		args = append(args, f.Until.UnixNano())
+	}
+	if f.Contains != "" {
+		where = append(where, "instr(message, ?) > 0")
+		args = append(args, f.Contains)
+	}
+
+	query := "SELECT id, ts, level, tag, message, fields FROM logs"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	switch {
+	case f.Limit > 0:
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, f.Limit, f.Offset)
+	case f.Offset > 0:
+		// SQLite only accepts OFFSET after a LIMIT, -1 meaning none.
+		query += " LIMIT -1 OFFSET ?"
+		args = append(args, f.Offset)
+	}
+	return query, args
+}
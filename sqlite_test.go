@@ -0,0 +1,221 @@
+package ezlog
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	_ "github.com/glebarez/go-sqlite"
+	"github.com/rs/zerolog"
+)
+
+func TestLogFilterQuery(t *testing.T) {
+	trace := zerolog.TraceLevel
+	warn := zerolog.WarnLevel
+	since := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	const sel = "SELECT id, ts, level, tag, message, fields FROM logs"
+
+	tests := []struct {
+		name     string
+		filter   LogFilter
+		wantSQL  string
+		wantArgs []any
+	}{
+		{"empty", LogFilter{}, sel + " ORDER BY id DESC", nil},
+		{"trace level", LogFilter{MinLevel: &trace}, sel + " WHERE level_num >= ? ORDER BY id DESC", []any{-1}},
+		{"level and tag", LogFilter{MinLevel: &warn, Tag: "api"},
+			sel + " WHERE level_num >= ? AND tag = ? ORDER BY id DESC", []any{2, "api"}},
+		{"since", LogFilter{Since: since}, sel + " WHERE ts >= ? ORDER BY id DESC", []any{since.UnixNano()}},
+		{"limit", LogFilter{Limit: 10, Offset: 5}, sel + " ORDER BY id DESC LIMIT ? OFFSET ?", []any{10, 5}},
+		{"offset only", LogFilter{Offset: 5}, sel + " ORDER BY id DESC LIMIT -1 OFFSET ?", []any{5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSQL, gotArgs := tt.filter.query()
+			if gotSQL != tt.wantSQL {
+				t.Errorf("query = %q, want %q", gotSQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+// sqliteEvent returns a JSON event as written by a tagged ezlog logger.
+func sqliteEvent(at time.Time, level, tag, msg string) []byte {
+	return fmt.Appendf(nil, `{"level":%q,"tag":%q,"time":%q,"n":1,"message":%q}`+"\n",
+		level, tag, at.Format(time.RFC3339Nano), msg)
+}
+
+// openSQLiteSink opens a sink on path, closing it when the test ends.
+func openSQLiteSink(t *testing.T, path string, opts ...SQLiteOption) *SQLiteSink {
+	t.Helper()
+	s, err := NewSQLiteSink(path, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// queryMessages returns the messages of the records matching filter.
+func queryMessages(t *testing.T, s *SQLiteSink, filter LogFilter) []string {
+	t.Helper()
+	records, err := s.Query(context.Background(), filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, rec := range records {
+		msgs = append(msgs, rec.Message)
+	}
+	return msgs
+}
+
+func TestSQLiteSinkBatching(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.db")
+	s := openSQLiteSink(t, path, WithSQLiteBatch(3, time.Hour))
+	now := time.Now()
+	s.Write(sqliteEvent(now, "info", "api", "one"))
+	s.Write(sqliteEvent(now, "info", "api", "two"))
+
+	// The batch is not full yet and the wait is an hour.
+	time.Sleep(50 * time.Millisecond)
+	if got := queryMessages(t, s, LogFilter{}); len(got) != 0 {
+		t.Fatalf("stored %q before the batch filled, want nothing", got)
+	}
+
+	s.Write(sqliteEvent(now, "info", "api", "three"))
+	deadline := time.Now().Add(5 * time.Second)
+	for len(queryMessages(t, s, LogFilter{})) != 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("full batch not stored")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Close stores the incomplete batch.
+	s.Write(sqliteEvent(now, "info", "api", "four"))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s = openSQLiteSink(t, path)
+	if got, want := queryMessages(t, s, LogFilter{}), []string{"four", "three", "two", "one"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stored %q, want %q", got, want)
+	}
+	if s.Dropped() != 0 {
+		t.Errorf("dropped %d events, want none", s.Dropped())
+	}
+}
+
+func TestSQLiteSinkPruneMaxRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.db")
+	s := openSQLiteSink(t, path, WithSQLiteBatch(1, time.Hour), WithSQLiteRetention(3, 0))
+	for i := range 5 {
+		s.Write(sqliteEvent(time.Now(), "info", "api", fmt.Sprint(i)))
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openSQLiteSink(t, path)
+	if got, want := queryMessages(t, s, LogFilter{}), []string{"4", "3", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("kept %q, want the 3 newest %q", got, want)
+	}
+}
+
+func TestSQLiteSinkPruneMaxAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.db")
+	s := openSQLiteSink(t, path, WithSQLiteBatch(10, time.Hour), WithSQLiteRetention(0, time.Hour))
+	now := time.Now()
+	s.Write(sqliteEvent(now.Add(-3*time.Hour), "info", "api", "old"))
+	s.Write(sqliteEvent(now.Add(-2*time.Hour), "info", "api", "older than an hour"))
+	s.Write(sqliteEvent(now.Add(-time.Minute), "info", "api", "recent"))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openSQLiteSink(t, path)
+	if got, want := queryMessages(t, s, LogFilter{}), []string{"recent"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("kept %q, want %q", got, want)
+	}
+}
+
+func TestSQLiteSinkQueryFilters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.db")
+	s := openSQLiteSink(t, path, WithSQLiteBatch(10, time.Hour))
+	base := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	s.Write(sqliteEvent(base, "debug", "api", "request started"))
+	s.Write(sqliteEvent(base.Add(time.Minute), "warn", "db", "slow query"))
+	s.Write(sqliteEvent(base.Add(2*time.Minute), "error", "api", "request failed"))
+	s.Write(sqliteEvent(base.Add(3*time.Minute), "info", "db", "connected"))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s = openSQLiteSink(t, path)
+
+	warn := zerolog.WarnLevel
+	tests := []struct {
+		name   string
+		filter LogFilter
+		want   []string
+	}{
+		{"all", LogFilter{}, []string{"connected", "request failed", "slow query", "request started"}},
+		{"min level", LogFilter{MinLevel: &warn}, []string{"request failed", "slow query"}},
+		{"tag", LogFilter{Tag: "api"}, []string{"request failed", "request started"}},
+		{"since", LogFilter{Since: base.Add(2 * time.Minute)}, []string{"connected", "request failed"}},
+		{"until", LogFilter{Until: base.Add(time.Minute)}, []string{"request started"}},
+		{"contains", LogFilter{Contains: "request"}, []string{"request failed", "request started"}},
+		{"level and tag", LogFilter{MinLevel: &warn, Tag: "db"}, []string{"slow query"}},
+		{"limit", LogFilter{Limit: 2}, []string{"connected", "request failed"}},
+		{"limit and offset", LogFilter{Limit: 2, Offset: 1}, []string{"request failed", "slow query"}},
+		{"offset only", LogFilter{Offset: 3}, []string{"request started"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := queryMessages(t, s, tt.filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	records, err := s.Query(context.Background(), LogFilter{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := records[0]
+	if !rec.Time.Equal(base.Add(3*time.Minute)) || rec.Level != zerolog.InfoLevel || rec.Tag != "db" {
+		t.Errorf("record = %+v, want the event time, level and tag", rec)
+	}
+	if want := map[string]any{"n": float64(1)}; !reflect.DeepEqual(rec.Fields, want) {
+		t.Errorf("fields = %v, want %v", rec.Fields, want)
+	}
+}
+
+func TestSQLiteSinkLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.db")
+	s := openSQLiteSink(t, path, WithSQLiteBatch(10, time.Hour))
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+	l := New().AsLocal().WithTag("api").WithWriter(s).WithJSONOutput().WithTimestampFunc(fixedClock(ts)).Build()
+	l.Warn().Str("user", "ada").Msg("login failed")
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openSQLiteSink(t, path)
+	records, err := s.Query(context.Background(), LogFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	rec := records[0]
+	if !rec.Time.Equal(ts) || rec.Level != zerolog.WarnLevel || rec.Tag != "api" || rec.Message != "login failed" || rec.Fields["user"] != "ada" {
+		t.Errorf("record = %+v", rec)
+	}
+}
@@ -0,0 +1,175 @@
+package ezlog
+
+import (
+	"expvar"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// Stats is a handle on logger statistics, published by PublishExpvar.
+type Stats interface {
+	// Snapshot returns the current statistics.
+	Snapshot() StatsSnapshot
+}
+
+// StatsSnapshot holds logger statistics at a point in time.
+type StatsSnapshot struct {
+	// Events is the number of written events per level name, for every
+	// level from trace to panic.
+	Events map[string]uint64
+	// Dropped is the number of events dropped by asynchronous writers.
+	Dropped uint64
+	// WriteErrors is the number of failed writes.
+	WriteErrors uint64
+	// Level is the current minimum level, e.g. "info".
+	Level string
+}
+
+// LoggerStats counts the events written by the loggers built with
+// WithStats, per level, along with their write errors, the events dropped
+// by their asynchronous writers and their minimum level. It is safe for
+// concurrent use.
+// It should be created using NewLoggerStats.
+type LoggerStats struct {
+	levels      [zerolog.PanicLevel - zerolog.TraceLevel + 1]atomic.Uint64
+	writeErrors atomic.Uint64
+	level       atomic.Pointer[func() zerolog.Level]
+
+	mu    sync.Mutex
+	async []asyncWriter
+}
+
+// NewLoggerStats creates a LoggerStats.
+func NewLoggerStats() *LoggerStats {
+	return &LoggerStats{}
+}
+
+// Snapshot implements Stats.
+func (s *LoggerStats) Snapshot() StatsSnapshot {
+	snap := StatsSnapshot{
+		Events:      make(map[string]uint64, len(s.levels)),
+		WriteErrors: s.writeErrors.Load(),
+	}
+	for i := range s.levels {
+		snap.Events[LevelString(zerolog.TraceLevel+zerolog.Level(i))] = s.levels[i].Load()
+	}
+	s.mu.Lock()
+	for _, aw := range s.async {
+		snap.Dropped += aw.Dropped()
+	}
+	s.mu.Unlock()
+	if level := s.level.Load(); level != nil {
+		snap.Level = LevelString((*level)())
+	}
+	return snap
+}
+
+// WithStats counts the events written by the logger in s. Events dropped by
+// the levels, samplers, rate limit or deduplication are not counted.
+func (b *LogBuilder) WithStats(s *LoggerStats) *LogBuilder {
+	b.stats = s
+	return b
+}
+
+// attachStats makes the stats follow the logger's minimum level and its
+// asynchronous writer, if any.
+func (b *LogBuilder) attachStats() {
+	level := b.levelFunc()
+	b.stats.level.Store(&level)
+	if aw, ok := b.writer.(asyncWriter); ok {
+		b.stats.mu.Lock()
+		b.stats.async = append(b.stats.async, aw)
+		b.stats.mu.Unlock()
+	}
+}
+
+// statsWriter counts the JSON events reaching out, which went through every
+// filter, and its write errors.
+type statsWriter struct {
+	out   io.Writer
+	stats *LoggerStats
+	b     *LogBuilder
+}
+
+// Write implements io.Writer.
+func (w *statsWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *statsWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	n, err := writeLevel(w.out, level, p)
+	if err != nil {
+		w.stats.writeErrors.Add(1)
+		return n, err
+	}
+	if level, ok := w.b.parseLevelValue(eventLevel(p)); ok && level >= zerolog.TraceLevel && level <= zerolog.PanicLevel {
+		w.stats.levels[level-zerolog.TraceLevel].Add(1)
+	}
+	return n, nil
+}
+
+// expvarStats holds the handles published under each PublishExpvar prefix.
+var expvarStats struct {
+	sync.Mutex
+	handles map[string]*atomic.Pointer[[]Stats]
+}
+
+// PublishExpvar publishes the statistics of handles as expvar variables,
+// served on /debug/vars by expvar's handler: prefix+"events", the written
+// events per level, prefix+"dropped", prefix+"write_errors" and
+// prefix+"level", the lowest minimum level of the handles. The values are
+// summed over the handles and read when the variables are. Publishing
+// under a prefix again replaces its handles.
+func PublishExpvar(prefix string, handles ...Stats) {
+	expvarStats.Lock()
+	defer expvarStats.Unlock()
+	if expvarStats.handles == nil {
+		expvarStats.handles = make(map[string]*atomic.Pointer[[]Stats])
+	}
+	hs := append([]Stats(nil), handles...)
+	if p, ok := expvarStats.handles[prefix]; ok {
+		p.Store(&hs)
+		return
+	}
+	p := new(atomic.Pointer[[]Stats])
+	p.Store(&hs)
+	expvarStats.handles[prefix] = p
+
+	snapshot := func() StatsSnapshot {
+		return mergeSnapshots(*p.Load())
+	}
+	publish := func(name string, fn func() any) {
+		if expvar.Get(prefix+name) == nil {
+			expvar.Publish(prefix+name, expvar.Func(fn))
+		}
+	}
+	publish("events", func() any { return snapshot().Events })
+	publish("dropped", func() any { return snapshot().Dropped })
+	publish("write_errors", func() any { return snapshot().WriteErrors })
+	publish("level", func() any { return snapshot().Level })
+}
+
+// mergeSnapshots sums the snapshots of handles, keeping the lowest level.
+func mergeSnapshots(handles []Stats) StatsSnapshot {
+	merged := StatsSnapshot{Events: make(map[string]uint64)}
+	minLevel := zerolog.Disabled
+	for _, h := range handles {
+		snap := h.Snapshot()
+		for level, n := range snap.Events {
+			merged.Events[level] += n
+		}
+		merged.Dropped += snap.Dropped
+		merged.WriteErrors += snap.WriteErrors
+		if level, err := ParseLevel(snap.Level); err == nil && level < minLevel {
+			minLevel = level
+		}
+	}
+	if minLevel != zerolog.Disabled {
+		merged.Level = LevelString(minLevel)
+	}
+	return merged
+}
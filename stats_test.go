@@ -0,0 +1,133 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// droppingWriter is an asynchronous writer whose drop count is set by the
+// test.
+type droppingWriter struct {
+	bytes.Buffer
+	dropped uint64
+}
+
+func (*droppingWriter) QueueDepth() int   { return 0 }
+func (w *droppingWriter) Dropped() uint64 { return w.dropped }
+
+// expvarValue decodes the expvar variable name into v.
+func expvarValue(t *testing.T, name string, v any) {
+	t.Helper()
+	ev := expvar.Get(name)
+	if ev == nil {
+		t.Fatalf("expvar %q is not published", name)
+	}
+	if err := json.Unmarshal([]byte(ev.String()), v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoggerStatsSnapshot(t *testing.T) {
+	stats := NewLoggerStats()
+	w := &droppingWriter{dropped: 2}
+	l := New().AsLocal().WithWriter(w).WithJSONOutput().WithLevel(zerolog.InfoLevel).WithStats(stats).Build()
+	l.Debug().Msg("filtered")
+	l.Info().Msg("one")
+	l.Info().Msg("two")
+	l.Error().Msg("three")
+
+	snap := stats.Snapshot()
+	if len(snap.Events) != 7 {
+		t.Errorf("got %d levels, want one per level from trace to panic", len(snap.Events))
+	}
+	if snap.Events["debug"] != 0 || snap.Events["info"] != 2 || snap.Events["error"] != 1 {
+		t.Errorf("events = %v, want 2 info and 1 error", snap.Events)
+	}
+	if snap.Dropped != 2 || snap.WriteErrors != 0 || snap.Level != "info" {
+		t.Errorf("snapshot = %+v, want 2 dropped at level info", snap)
+	}
+}
+
+func TestLoggerStatsWriteErrors(t *testing.T) {
+	stats := NewLoggerStats()
+	l := New().AsLocal().WithWriter(failingWriter{}).WithJSONOutput().WithStats(stats).Build()
+	l.Info().Msg("lost")
+	l.Warn().Msg("lost")
+
+	if snap := stats.Snapshot(); snap.WriteErrors != 2 || snap.Events["info"] != 0 || snap.Events["warn"] != 0 {
+		t.Errorf("snapshot = %+v, want 2 write errors and no events", snap)
+	}
+}
+
+func TestLoggerStatsFollowsDynamicLevel(t *testing.T) {
+	stats := NewLoggerStats()
+	dl := NewDynamicLevel(zerolog.WarnLevel)
+	New().AsLocal().WithWriter(&bytes.Buffer{}).WithDynamicLevel(dl).WithStats(stats).Build()
+	if level := stats.Snapshot().Level; level != "warn" {
+		t.Errorf("level = %q, want warn", level)
+	}
+	dl.SetLevel(zerolog.ErrorLevel)
+	if level := stats.Snapshot().Level; level != "error" {
+		t.Errorf("level = %q after SetLevel, want error", level)
+	}
+}
+
+func TestPublishExpvar(t *testing.T) {
+	api, worker := NewLoggerStats(), NewLoggerStats()
+	w := &droppingWriter{}
+	apiLog := New().AsLocal().WithWriter(&bytes.Buffer{}).WithJSONOutput().WithLevel(zerolog.WarnLevel).WithStats(api).Build()
+	workerLog := New().AsLocal().WithWriter(w).WithJSONOutput().WithStats(worker).Build()
+	PublishExpvar("ezlog_test_stats_", api, worker)
+
+	apiLog.Error().Msg("one")
+	workerLog.Info().Msg("two")
+	workerLog.Error().Msg("three")
+	w.dropped = 4
+
+	var events map[string]uint64
+	expvarValue(t, "ezlog_test_stats_events", &events)
+	if events["info"] != 1 || events["error"] != 2 {
+		t.Errorf("events = %v, want 1 info and 2 errors over both loggers", events)
+	}
+	var dropped, writeErrors uint64
+	var level string
+	expvarValue(t, "ezlog_test_stats_dropped", &dropped)
+	expvarValue(t, "ezlog_test_stats_write_errors", &writeErrors)
+	expvarValue(t, "ezlog_test_stats_level", &level)
+	if dropped != 4 || writeErrors != 0 || level != "debug" {
+		t.Errorf("dropped = %d, write errors = %d, level = %q, want 4, 0 and debug", dropped, writeErrors, level)
+	}
+
+	// The values are read when the variables are.
+	workerLog.Info().Msg("four")
+	w.dropped = 5
+	expvarValue(t, "ezlog_test_stats_events", &events)
+	expvarValue(t, "ezlog_test_stats_dropped", &dropped)
+	if events["info"] != 2 || dropped != 5 {
+		t.Errorf("events = %v, dropped = %d, want 2 info and 5 dropped", events, dropped)
+	}
+}
+
+func TestPublishExpvarReplacesHandles(t *testing.T) {
+	first, second := NewLoggerStats(), NewLoggerStats()
+	New().AsLocal().WithWriter(&bytes.Buffer{}).WithJSONOutput().WithStats(first).Build().Info().Msg("one")
+	PublishExpvar("ezlog_test_stats_again_", first)
+	// Publishing again under the same prefix does not panic and replaces
+	// the handles.
+	PublishExpvar("ezlog_test_stats_again_", second)
+
+	var events map[string]uint64
+	expvarValue(t, "ezlog_test_stats_again_events", &events)
+	if events["info"] != 0 {
+		t.Errorf("events = %v, want those of the second handle", events)
+	}
+	var level string
+	expvarValue(t, "ezlog_test_stats_again_level", &level)
+	if level != "" {
+		t.Errorf("level = %q, want none without a built logger", level)
+	}
+}
@@ -0,0 +1,125 @@
+package ezlog
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StatsDOption configures a StatsDMetricsExporter.
+type StatsDOption func(*StatsDMetricsExporter)
+
+// WithStatsDPrefix prefixes metric names with prefix, e.g. "myapp.".
+func WithStatsDPrefix(prefix string) StatsDOption {
+	return func(e *StatsDMetricsExporter) {
+		e.prefix = prefix
+	}
+}
+
+// WithStatsDTags sends labels as DogStatsD tags, "|#level:error,tag:api",
+// as supported by Datadog and Telegraf, instead of appending them to the
+// metric name.
+func WithStatsDTags() StatsDOption {
+	return func(e *StatsDMetricsExporter) {
+		e.tags = true
+	}
+}
+
+// StatsDMetricsExporter is a MetricsExporter pushing metrics to a StatsD
+// server over UDP, one datagram per metric. Counters are sent as "c" and
+// histograms as "h" metrics. Labels are appended to the metric name, as in
+// "log_events_total.level.error.tag.api", unless WithStatsDTags is used.
+// Send errors are ignored, as with any UDP StatsD client.
+// It should be created using NewStatsDMetricsExporter.
+type StatsDMetricsExporter struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	prefix string
+	tags   bool
+	buf    []byte
+}
+
+// NewStatsDMetricsExporter creates a StatsDMetricsExporter sending to the
+// StatsD server at addr, such as "127.0.0.1:8125".
+func NewStatsDMetricsExporter(addr string, opts ...StatsDOption) (*StatsDMetricsExporter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	e := &StatsDMetricsExporter{conn: conn}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
+}
+
+// IncrCounter implements MetricsExporter.
+func (e *StatsDMetricsExporter) IncrCounter(name string, labels map[string]string) {
+	e.send(name, "1", "c", labels)
+}
+
+// RecordHistogram implements MetricsExporter.
+func (e *StatsDMetricsExporter) RecordHistogram(name string, value float64, labels map[string]string) {
+	e.send(name, strconv.FormatFloat(value, 'f', -1, 64), "h", labels)
+}
+
+// Close closes the connection.
+func (e *StatsDMetricsExporter) Close() error {
+	return e.conn.Close()
+}
+
+// send sends a metric of type typ.
+func (e *StatsDMetricsExporter) send(name, value, typ string, labels map[string]string) {
+	names := make([]string, 0, len(labels))
+	for label := range labels {
+		names = append(names, label)
+	}
+	sort.Strings(names)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b := append(e.buf[:0], e.prefix...)
+	b = appendStatsDName(b, name)
+	if !e.tags {
+		for _, label := range names {
+			b = append(b, '.')
+			b = appendStatsDName(b, label)
+			b = append(b, '.')
+			b = appendStatsDName(b, labels[label])
+		}
+	}
+	b = append(b, ':')
+	b = append(b, value...)
+	b = append(b, '|')
+	b = append(b, typ...)
+	if e.tags && len(names) > 0 {
+		b = append(b, "|#"...)
+		for i, label := range names {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendStatsDName(b, label)
+			b = append(b, ':')
+			b = appendStatsDName(b, labels[label])
+		}
+	}
+	e.buf = b
+	_, _ = e.conn.Write(b)
+}
+
+// appendStatsDName appends s to b with the characters of the StatsD syntax
+// replaced by underscores.
+func appendStatsDName(b []byte, s string) []byte {
+	if s == "" {
+		return append(b, "none"...)
+	}
+	return append(b, strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', '@', '#', ',', ' ', '\n':
+			return '_'
+		}
+		return r
+	}, s)...)
+}
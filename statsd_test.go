@@ -0,0 +1,105 @@
+package ezlog
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// statsdServer returns a UDP listener standing in for a StatsD server.
+func statsdServer(t *testing.T) net.PacketConn {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readDatagrams reads n datagrams from conn.
+func readDatagrams(t *testing.T, conn net.PacketConn, n int) []string {
+	t.Helper()
+	buf := make([]byte, 1500)
+	var got []string
+	for range n {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		m, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(buf[:m]))
+	}
+	return got
+}
+
+func TestStatsDMetricsExporter(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []StatsDOption
+		want []string
+	}{
+		{"name labels", []StatsDOption{WithStatsDPrefix("app.")}, []string{
+			"app.log_events_total.level.error.tag.api:1|c",
+			"app.gorm_query_duration_seconds.status.ok:0.25|h",
+			"app.log_events_total.level.info.tag.none:1|c",
+			"app.odd_name_.k_1.v_1:1|c",
+		}},
+		{"tags", []StatsDOption{WithStatsDTags()}, []string{
+			"log_events_total:1|c|#level:error,tag:api",
+			"gorm_query_duration_seconds:0.25|h|#status:ok",
+			"log_events_total:1|c|#level:info,tag:none",
+			"odd_name_:1|c|#k_1:v_1",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := statsdServer(t)
+			e, err := NewStatsDMetricsExporter(srv.LocalAddr().String(), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer e.Close()
+
+			e.IncrCounter(MetricLogEvents, map[string]string{"tag": "api", "level": "error"})
+			e.RecordHistogram(MetricGormQueryDuration, 0.25, map[string]string{"status": "ok"})
+			e.IncrCounter(MetricLogEvents, map[string]string{"level": "info", "tag": ""})
+			e.IncrCounter("odd name|", map[string]string{"k:1": "v#1"})
+
+			got := readDatagrams(t, srv, len(tt.want))
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("datagram %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestChainMetricsExporterWithStatsD(t *testing.T) {
+	srv := statsdServer(t)
+	statsd, err := NewStatsDMetricsExporter(srv.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer statsd.Close()
+	rec := newMetricsRecorder()
+
+	l := New().AsLocal().WithWriter(io.Discard).WithJSONOutput().WithTag("api").
+		WithMetrics(ChainMetricsExporter(rec, statsd)).Build()
+	l.Warn().Msg("both")
+
+	if got := readDatagrams(t, srv, 1)[0]; got != "log_events_total.level.warn.tag.api:1|c" {
+		t.Errorf("StatsD datagram = %q", got)
+	}
+	if rec.counters["log_events_total{level=warn,tag=api}"] != 1 || rec.gauges["log_level{tag=api}"] == nil {
+		t.Errorf("recorder = %v, gauges %v", rec.counters, rec.gauges)
+	}
+}
+
+func TestNewStatsDMetricsExporterInvalidAddress(t *testing.T) {
+	if _, err := NewStatsDMetricsExporter("not an address"); err == nil {
+		t.Error("NewStatsDMetricsExporter accepted an invalid address")
+	}
+}
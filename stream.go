@@ -0,0 +1,19 @@
+package ezlog
+
+import "io"
+
+// StreamServer broadcasts the output of loggers to remote clients. It is
+// implemented by stream.LogStreamServer, from the ezlog/stream package.
+type StreamServer interface {
+	// StreamWriter returns the writer feeding the server.
+	StreamWriter() io.Writer
+}
+
+// WithStreamServer also sends the logger's output to the clients of ls:
+//
+//	ls, _ := stream.NewLogStreamServer(100)
+//	http.Handle("/logs", ls.Handler())
+//	logger := ezlog.New().WithStreamServer(ls).Build()
+func (b *LogBuilder) WithStreamServer(ls StreamServer) *LogBuilder {
+	return b.WithTeeWriter(ls.StreamWriter())
+}
@@ -0,0 +1,126 @@
+// Package stream serves ezlog output to browsers over WebSocket. It is a
+// separate package to keep the WebSocket library out of programs that do not
+// import it.
+package stream
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ezydark/ezlog"
+	"github.com/gorilla/websocket"
+)
+
+// streamWriteTimeout bounds how long a single frame may take to reach a client.
+const streamWriteTimeout = 10 * time.Second
+
+// LogStreamServer broadcasts log lines to browsers connected over WebSocket,
+// for tailing logs during development. It should be created using
+// NewLogStreamServer.
+type LogStreamServer struct {
+	mu       sync.Mutex
+	clients  map[*streamClient]struct{}
+	capacity int
+	upgrader websocket.Upgrader
+}
+
+// streamClient is a connected WebSocket client.
+type streamClient struct {
+	lines chan []byte
+}
+
+// NewLogStreamServer creates a LogStreamServer and the writer feeding it.
+// Each client buffers up to capacity lines; lines are dropped for clients
+// that fall further behind, so writing never blocks.
+func NewLogStreamServer(capacity int) (*LogStreamServer, io.Writer) {
+	ls := &LogStreamServer{
+		clients:  make(map[*streamClient]struct{}),
+		capacity: capacity,
+	}
+	return ls, streamWriter{ls}
+}
+
+// Handler returns the HTTP handler upgrading requests to WebSocket
+// connections. Every line written afterwards is sent as a text frame.
+func (ls *LogStreamServer) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := ls.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		c := &streamClient{lines: make(chan []byte, ls.capacity)}
+		ls.mu.Lock()
+		ls.clients[c] = struct{}{}
+		ls.mu.Unlock()
+		defer func() {
+			ls.mu.Lock()
+			delete(ls.clients, c)
+			ls.mu.Unlock()
+		}()
+
+		// Read and discard client frames to notice when the client goes away.
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+
+		for {
+			select {
+			case line := <-c.lines:
+				_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+				if err := conn.WriteMessage(websocket.TextMessage, line); err != nil {
+					return
+				}
+			case <-closed:
+				return
+			}
+		}
+	})
+}
+
+// broadcast sends a copy of line to every client with room in its buffer.
+func (ls *LogStreamServer) broadcast(line []byte) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	for c := range ls.clients {
+		select {
+		case c.lines <- append([]byte(nil), line...):
+		default:
+		}
+	}
+}
+
+// streamWriter is the io.Writer feeding a LogStreamServer.
+type streamWriter struct {
+	ls *LogStreamServer
+}
+
+// Write implements io.Writer, broadcasting each line of p.
+func (w streamWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		if len(line) > 0 {
+			w.ls.broadcast(line)
+		}
+	}
+	return len(p), nil
+}
+
+// StreamWriter returns a writer feeding ls, like the one returned by
+// NewLogStreamServer. It implements ezlog.StreamServer, for
+// LogBuilder.WithStreamServer.
+func (ls *LogStreamServer) StreamWriter() io.Writer {
+	return streamWriter{ls}
+}
+
+var _ ezlog.StreamServer = (*LogStreamServer)(nil)
@@ -0,0 +1,107 @@
+package stream
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ezydark/ezlog"
+	"github.com/gorilla/websocket"
+)
+
+// dial connects a WebSocket client to s and waits until ls registered it.
+func dial(t *testing.T, s *httptest.Server, ls *LogStreamServer) *websocket.Conn {
+	t.Helper()
+	before := clientCount(ls)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	for deadline := time.Now().Add(2 * time.Second); clientCount(ls) == before; {
+		if time.Now().After(deadline) {
+			t.Fatal("client not registered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return conn
+}
+
+// clientCount returns the number of clients connected to ls.
+func clientCount(ls *LogStreamServer) int {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return len(ls.clients)
+}
+
+// readLine reads one text frame from conn.
+func readLine(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	kind, msg, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind != websocket.TextMessage {
+		t.Errorf("frame type = %d, want text", kind)
+	}
+	return string(msg)
+}
+
+func TestWithStreamServer(t *testing.T) {
+	ls, _ := NewLogStreamServer(16)
+	s := httptest.NewServer(ls.Handler())
+	defer s.Close()
+	a, b := dial(t, s, ls), dial(t, s, ls)
+
+	var buf bytes.Buffer
+	l := ezlog.New().AsLocal().WithWriter(&buf).WithJSONOutput().WithStreamServer(ls).Build()
+	l.Info().Msg("first")
+	l.Warn().Msg("second")
+
+	for _, conn := range []*websocket.Conn{a, b} {
+		if line := readLine(t, conn); !strings.Contains(line, `"message":"first"`) || !strings.HasSuffix(line, "\n") {
+			t.Errorf("first line = %q", line)
+		}
+		if line := readLine(t, conn); !strings.Contains(line, `"message":"second"`) {
+			t.Errorf("second line = %q", line)
+		}
+	}
+	if strings.Count(buf.String(), "\n") != 2 {
+		t.Errorf("logger output = %q, want both lines", buf.String())
+	}
+}
+
+func TestStreamWriterSplitsLines(t *testing.T) {
+	ls, w := NewLogStreamServer(16)
+	s := httptest.NewServer(ls.Handler())
+	defer s.Close()
+	conn := dial(t, s, ls)
+
+	w.Write([]byte("one\ntwo\n"))
+	if got := readLine(t, conn) + readLine(t, conn); got != "one\ntwo\n" {
+		t.Errorf("lines = %q, want one frame per line", got)
+	}
+}
+
+func TestStreamServerDropsForSlowClients(t *testing.T) {
+	ls, w := NewLogStreamServer(1)
+	s := httptest.NewServer(ls.Handler())
+	defer s.Close()
+	dial(t, s, ls)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 10000 {
+			w.Write([]byte("line\n"))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("writes blocked on a client not reading")
+	}
+}
@@ -0,0 +1,76 @@
+package ezlog
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// WithTableFormat prints the fields of events having at least minFields fields
+// as an indented table beneath the message, one "key = value" row per field,
+// instead of on the log line. Events with fewer fields are unchanged.
+func (b *LogBuilder) WithTableFormat(minFields int) *LogBuilder {
+	b.tableMinFields = minFields
+	return b
+}
+
+// renderFieldTable moves the fields of large events to table rows in the
+// continuation lines, rendering values with fv.
+func (b *LogBuilder) renderFieldTable(fv zerolog.Formatter) func(map[string]any) error {
+	return func(evt map[string]any) error {
+		keys := make([]string, 0, len(evt))
+		width := 0
+		for key := range evt {
+			if !isTableField(key) {
+				continue
+			}
+			keys = append(keys, key)
+			width = max(width, visibleWidth(key))
+		}
+		if len(keys) < b.tableMinFields {
+			return nil
+		}
+		sort.Strings(keys)
+
+		name := cyanColor
+		rows := make([]string, 0, len(keys))
+		for _, key := range keys {
+			rows = append(rows, name.Sprint(padRight(key, width))+"  =  "+renderValue(fv, evt[key]))
+			delete(evt, key)
+		}
+		appendContinuation(evt, rows...)
+		return nil
+	}
+}
+
+// isTableField reports whether key is a regular field that may go in the table.
+func isTableField(key string) bool {
+	switch key {
+	case zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName,
+		zerolog.CallerFieldName, zerolog.ErrorFieldName, continuationKey, ErrorChainFieldName:
+		return false
+	}
+	return !strings.HasPrefix(key, "\x00")
+}
+
+// renderValue renders a decoded field value with fv the way ConsoleWriter does.
+func renderValue(fv zerolog.Formatter, value any) string {
+	switch v := value.(type) {
+	case string:
+		if needsQuote(v) {
+			return fv(strconv.Quote(v))
+		}
+		return fv(v)
+	case json.Number:
+		return fv(v)
+	default:
+		b, err := zerolog.InterfaceMarshalFunc(v)
+		if err != nil {
+			return redColor.Sprintf("[error: %v]", err)
+		}
+		return fv(b)
+	}
+}
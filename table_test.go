@@ -0,0 +1,24 @@
+package ezlog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWithTableFormat(t *testing.T) {
+	var buf bytes.Buffer
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.Local)
+	l := newTestBuilder(&buf).WithTableFormat(3).WithTimestampFunc(fixedClock(ts)).Build()
+	l.Info().Str("method", "GET").Int("status", 200).Bool("cached", true).Msg("request")
+	l.Info().Str("method", "GET").Int("status", 200).Msg("short")
+
+	want := "09:26:53.000 [INFO] request\n" +
+		"  │ cached  =  true\n" +
+		"  │ method  =  \"GET\"\n" +
+		"  │ status  =  200\n" +
+		"09:26:53.000 [INFO] short method=\"GET\" status=200\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
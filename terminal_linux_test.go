@@ -0,0 +1,76 @@
+package ezlog
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminal is a pseudo-terminal whose output is collected for inspection.
+type terminal struct {
+	*os.File // the terminal side, to write to
+
+	mu   sync.Mutex
+	out  bytes.Buffer
+	done chan struct{}
+}
+
+// openTerminal opens a pseudo-terminal, skipping t when none is available.
+// It is closed when t ends.
+func openTerminal(t *testing.T) *terminal {
+	t.Helper()
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("no pseudo-terminal: %v", err)
+	}
+	fd := int(ptmx.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		ptmx.Close()
+		t.Skipf("unlock pseudo-terminal: %v", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		ptmx.Close()
+		t.Skipf("pseudo-terminal number: %v", err)
+	}
+	tty, err := os.OpenFile("/dev/pts/"+strconv.Itoa(n), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		ptmx.Close()
+		t.Skipf("open pseudo-terminal: %v", err)
+	}
+
+	term := &terminal{File: tty, done: make(chan struct{})}
+	go func() {
+		defer close(term.done)
+		buf := make([]byte, 4096)
+		for {
+			n, err := ptmx.Read(buf)
+			term.mu.Lock()
+			term.out.Write(buf[:n])
+			term.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		tty.Close()
+		ptmx.Close()
+		<-term.done
+	})
+	return term
+}
+
+// Output closes the terminal side and returns everything written to it, with
+// the terminal's CRLF line endings turned back into LF.
+func (term *terminal) Output() string {
+	term.File.Close()
+	<-term.done
+	term.mu.Lock()
+	defer term.mu.Unlock()
+	return string(bytes.ReplaceAll(term.out.Bytes(), []byte("\r\n"), []byte("\n")))
+}
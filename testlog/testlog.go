@@ -0,0 +1,61 @@
+// Package testlog captures log output in tests. It is a separate package to
+// keep the testing package out of programs that do not import it.
+package testlog
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// capture is the buffer a test logger writes to. It is safe for concurrent
+// use.
+type capture struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write implements io.Writer.
+func (c *capture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.Write(p)
+}
+
+// lines returns the captured events, one JSON object per line.
+func (c *capture) lines() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := strings.TrimSuffix(c.buf.String(), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// NewTestCaptureLogger creates a logger writing JSON events at every level to
+// an internal buffer, and a function returning the events captured so far,
+// one per line. If the test fails, the captured events are logged when it
+// ends, so they show up with the failure:
+//
+//	logger, lines := testlog.NewTestCaptureLogger(t)
+//	doWork(logger)
+//	if got := lines(); len(got) != 1 {
+//		t.Fatalf("got %d events, want 1", len(got))
+//	}
+//
+// For assertions on levels and fields, see ezlog.MockLogger.
+func NewTestCaptureLogger(t *testing.T) (*zerolog.Logger, func() []string) {
+	t.Helper()
+	c := &capture{}
+	l := zerolog.New(c).Level(zerolog.TraceLevel).With().Timestamp().Logger()
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("captured log output:\n%s", strings.Join(c.lines(), "\n"))
+		}
+	})
+	return &l, c.lines
+}
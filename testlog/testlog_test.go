@@ -0,0 +1,85 @@
+package testlog
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNewTestCaptureLogger(t *testing.T) {
+	logger, lines := NewTestCaptureLogger(t)
+	if got := lines(); got != nil {
+		t.Errorf("lines() = %q before logging, want none", got)
+	}
+	logger.Trace().Msg("trace")
+	logger.Info().Str("k", "v").Msg("hello")
+
+	got := lines()
+	if len(got) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(got), got)
+	}
+	var event map[string]any
+	if err := json.Unmarshal([]byte(got[1]), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event["level"] != "info" || event["message"] != "hello" || event["k"] != "v" || event["time"] == nil {
+		t.Errorf("event = %v, want the info event with its field and timestamp", event)
+	}
+}
+
+func TestNewTestCaptureLoggerConcurrent(t *testing.T) {
+	logger, lines := NewTestCaptureLogger(t)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				logger.Info().Msg("event")
+			}
+		}()
+	}
+	wg.Wait()
+	if got := len(lines()); got != 100 {
+		t.Errorf("got %d lines, want 100", got)
+	}
+}
+
+// TestCaptureFailingHelper fails when run by TestNewTestCaptureLoggerLogsOnFailure.
+func TestCaptureFailingHelper(t *testing.T) {
+	if os.Getenv("TESTLOG_FAIL") == "" {
+		t.Skip("only run by TestNewTestCaptureLoggerLogsOnFailure")
+	}
+	logger, _ := NewTestCaptureLogger(t)
+	logger.Warn().Msg("logged before the failure")
+	if os.Getenv("TESTLOG_FAIL") == "1" {
+		t.Fail()
+	}
+}
+
+func TestNewTestCaptureLoggerLogsOnFailure(t *testing.T) {
+	for _, tt := range []struct {
+		fail string
+		want bool
+	}{
+		{"1", true},
+		{"0", false},
+	} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestCaptureFailingHelper$", "-test.v")
+		cmd.Env = append(os.Environ(), "TESTLOG_FAIL="+tt.fail)
+		out, err := cmd.CombinedOutput()
+		var exitErr *exec.ExitError
+		if failed := errors.As(err, &exitErr); failed != tt.want {
+			t.Fatalf("TESTLOG_FAIL=%s: err = %v, output:\n%s", tt.fail, err, out)
+		}
+		logged := strings.Contains(string(out), "captured log output:") &&
+			strings.Contains(string(out), "logged before the failure")
+		if logged != tt.want {
+			t.Errorf("TESTLOG_FAIL=%s: captured output logged = %v, want %v:\n%s", tt.fail, logged, tt.want, out)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package ezlog
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/rs/zerolog"
+	"golang.org/x/term"
+)
+
+// Theme is the background brightness of a terminal.
+type Theme int
+
+const (
+	// ThemeDark is a dark background. This is the default.
+	ThemeDark Theme = iota
+	// ThemeLight is a light background.
+	ThemeLight
+)
+
+// String implements fmt.Stringer.
+func (t Theme) String() string {
+	if t == ThemeLight {
+		return "light"
+	}
+	return "dark"
+}
+
+// ColorScheme holds the colors of the console output. Nil colors keep the
+// colors of the dark theme.
+type ColorScheme struct {
+	// Levels are the colors of the level labels.
+	Levels map[zerolog.Level]*color.Color
+	// Tag is the color of the tag.
+	Tag *color.Color
+	// FieldName is the color of field names.
+	FieldName *color.Color
+	// String, Number, Bool and Nil are the colors of field values.
+	String *color.Color
+	Number *color.Color
+	Bool   *color.Color
+	Nil    *color.Color
+}
+
+// DarkTheme returns the default color scheme, suited to dark backgrounds.
+func DarkTheme() ColorScheme {
+	return ColorScheme{
+		Levels:    ColorLevelBasic.levelColors(),
+		Tag:       magentaColor,
+		FieldName: cyanColor,
+		String:    greenColor,
+		Number:    yellowColor,
+		Bool:      magentaColor,
+		Nil:       redColor,
+	}
+}
+
+// LightTheme returns a color scheme readable on light backgrounds, avoiding
+// yellow and white text.
+func LightTheme() ColorScheme {
+	return ColorScheme{
+		Levels: map[zerolog.Level]*color.Color{
+			zerolog.TraceLevel: color.New(color.FgHiBlack),
+			zerolog.DebugLevel: color.New(color.FgBlue),
+			zerolog.InfoLevel:  color.New(color.FgGreen),
+			zerolog.WarnLevel:  color.New(color.FgRed),
+			zerolog.ErrorLevel: color.New(color.FgRed, color.Bold),
+			zerolog.FatalLevel: color.New(color.FgHiWhite, color.BgRed, color.Bold),
+			zerolog.PanicLevel: color.New(color.FgHiWhite, color.BgMagenta, color.Bold),
+			zerolog.NoLevel:    color.New(color.FgBlack),
+		},
+		Tag:       color.New(color.FgMagenta),
+		FieldName: color.New(color.FgBlue),
+		String:    color.New(color.FgGreen),
+		Number:    color.New(color.FgMagenta),
+		Bool:      color.New(color.FgMagenta),
+		Nil:       color.New(color.FgRed),
+	}
+}
+
+// merge returns s with its nil colors taken from base.
+func (s ColorScheme) merge(base ColorScheme) ColorScheme {
+	levels := make(map[zerolog.Level]*color.Color, len(base.Levels)+len(s.Levels))
+	for level, c := range base.Levels {
+		levels[level] = c
+	}
+	for level, c := range s.Levels {
+		levels[level] = c
+	}
+	s.Levels = levels
+	pick := func(c, fallback *color.Color) *color.Color {
+		if c == nil {
+			return fallback
+		}
+		return c
+	}
+	s.Tag = pick(s.Tag, base.Tag)
+	s.FieldName = pick(s.FieldName, base.FieldName)
+	s.String = pick(s.String, base.String)
+	s.Number = pick(s.Number, base.Number)
+	s.Bool = pick(s.Bool, base.Bool)
+	s.Nil = pick(s.Nil, base.Nil)
+	return s
+}
+
+// WithColorScheme renders console output with the colors of s.
+func (b *LogBuilder) WithColorScheme(s ColorScheme) *LogBuilder {
+	b.colorScheme = &s
+	return b
+}
+
+// WithAutoTheme picks LightTheme or DarkTheme at Build time from the theme
+// reported by DetectTheme. A scheme given to WithColorScheme is applied on
+// top of it.
+func (b *LogBuilder) WithAutoTheme() *LogBuilder {
+	b.autoTheme = true
+	return b
+}
+
+// WithThemeQuery makes WithAutoTheme ask the terminal for its background
+// color with an OSC 11 query, waiting at most timeout for the answer, before
+// falling back to DetectTheme. The query briefly puts the terminal in raw
+// mode and may swallow keys typed meanwhile, so it is off by default.
+func (b *LogBuilder) WithThemeQuery(timeout time.Duration) *LogBuilder {
+	b.autoTheme = true
+	b.themeQuery = timeout
+	return b
+}
+
+// scheme returns the color scheme of the console output, detecting the
+// theme on first use.
+func (b *LogBuilder) scheme() *ColorScheme {
+	if b.resolvedScheme != nil {
+		return b.resolvedScheme
+	}
+	base := DarkTheme()
+	base.Levels = b.colorLevel.resolve().levelColors()
+	if b.autoTheme && b.detectTheme() == ThemeLight {
+		base = LightTheme().merge(base)
+	}
+	if b.colorScheme != nil {
+		base = b.colorScheme.merge(base)
+	}
+	b.resolvedScheme = &base
+	return b.resolvedScheme
+}
+
+// detectTheme returns the theme of the terminal, querying it if enabled.
+func (b *LogBuilder) detectTheme() Theme {
+	if t, ok := envTheme(); ok {
+		return t
+	}
+	if b.themeQuery > 0 {
+		if t, err := QueryTheme(b.themeQuery); err == nil {
+			return t
+		}
+	}
+	return DetectTheme()
+}
+
+// DetectTheme guesses the theme of the terminal without querying it. The
+// EZLOG_THEME environment variable, set to "light" or "dark", takes
+// precedence; then the COLORFGBG variable set by rxvt, Konsole and others is
+// used, then known terminal defaults. The theme is assumed dark otherwise.
+func DetectTheme() Theme {
+	if t, ok := envTheme(); ok {
+		return t
+	}
+	if t, ok := parseColorFGBG(os.Getenv("COLORFGBG")); ok {
+		return t
+	}
+	// Terminal.app ships with a light default profile.
+	if os.Getenv("TERM_PROGRAM") == "Apple_Terminal" {
+		return ThemeLight
+	}
+	return ThemeDark
+}
+
+// envTheme returns the theme set with EZLOG_THEME.
+func envTheme() (Theme, bool) {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("EZLOG_THEME"))) {
+	case "light":
+		return ThemeLight, true
+	case "dark":
+		return ThemeDark, true
+	}
+	return ThemeDark, false
+}
+
+// parseColorFGBG returns the theme described by a COLORFGBG value, such as
+// "15;0" or "0;default;15", whose last element is the background color
+// index. Indexes 7 and 9 to 15 are light colors.
+func parseColorFGBG(v string) (Theme, bool) {
+	if v == "" {
+		return ThemeDark, false
+	}
+	parts := strings.Split(v, ";")
+	bg, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil || bg < 0 || bg > 15 {
+		return ThemeDark, false
+	}
+	if bg == 7 || bg >= 9 {
+		return ThemeLight, true
+	}
+	return ThemeDark, true
+}
+
+// QueryTheme asks the controlling terminal for its background color with an
+// OSC 11 query and returns its theme. It waits at most timeout for the
+// answer, and fails when there is no terminal or it does not answer.
+func QueryTheme(timeout time.Duration) (Theme, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return ThemeDark, fmt.Errorf("ezlog: open terminal: %w", err)
+	}
+	defer tty.Close()
+
+	// tty.Fd would switch the terminal to blocking mode, disabling the read
+	// deadline, so the descriptor is taken from the raw connection.
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return ThemeDark, fmt.Errorf("ezlog: terminal descriptor: %w", err)
+	}
+	var fd int
+	if err := conn.Control(func(f uintptr) { fd = int(f) }); err != nil {
+		return ThemeDark, fmt.Errorf("ezlog: terminal descriptor: %w", err)
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return ThemeDark, fmt.Errorf("ezlog: raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	if err := tty.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return ThemeDark, fmt.Errorf("ezlog: terminal deadline: %w", err)
+	}
+	if _, err := tty.WriteString("\x1b]11;?\x1b\\"); err != nil {
+		return ThemeDark, fmt.Errorf("ezlog: query terminal: %w", err)
+	}
+
+	var resp []byte
+	buf := make([]byte, 64)
+	for !strings.HasSuffix(string(resp), "\x1b\\") && !strings.HasSuffix(string(resp), "\a") {
+		n, err := tty.Read(buf)
+		if err != nil {
+			return ThemeDark, fmt.Errorf("ezlog: read terminal answer: %w", err)
+		}
+		resp = append(resp, buf[:n]...)
+	}
+	return parseOSC11(string(resp))
+}
+
+// parseOSC11 returns the theme of an OSC 11 answer such as
+// "\x1b]11;rgb:ffff/ffff/ffff\x1b\\", from the luminance of the color.
+func parseOSC11(resp string) (Theme, error) {
+	i := strings.Index(resp, "rgb:")
+	if i < 0 {
+		return ThemeDark, fmt.Errorf("ezlog: unexpected terminal answer %q", resp)
+	}
+	rgb := strings.TrimRight(resp[i+len("rgb:"):], "\x1b\\\a")
+	parts := strings.Split(rgb, "/")
+	if len(parts) != 3 {
+		return ThemeDark, fmt.Errorf("ezlog: unexpected terminal answer %q", resp)
+	}
+	var c [3]float64
+	for j, p := range parts {
+		v, err := strconv.ParseUint(p, 16, 16)
+		if err != nil || len(p) == 0 || len(p) > 4 {
+			return ThemeDark, fmt.Errorf("ezlog: unexpected terminal answer %q", resp)
+		}
+		c[j] = float64(v) / float64(uint64(1)<<(4*len(p))-1)
+	}
+	if 0.2126*c[0]+0.7152*c[1]+0.0722*c[2] > 0.5 {
+		return ThemeLight, nil
+	}
+	return ThemeDark, nil
+}
@@ -0,0 +1,94 @@
+package ezlog
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/rs/zerolog"
+)
+
+func TestParseColorFGBG(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Theme
+		ok    bool
+	}{
+		{"", ThemeDark, false},
+		{"15;0", ThemeDark, true},
+		{"0;15", ThemeLight, true},
+		{"0;default;15", ThemeLight, true},
+		{"0;7", ThemeLight, true},
+		{"7;8", ThemeDark, true},
+		{"0;9", ThemeLight, true},
+		{"15;default", ThemeDark, false},
+		{"0;16", ThemeDark, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseColorFGBG(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseColorFGBG(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDetectTheme(t *testing.T) {
+	tests := []struct {
+		env, colorFGBG, termProgram string
+		want                        Theme
+	}{
+		{"light", "15;0", "", ThemeLight},
+		{" Dark ", "0;15", "Apple_Terminal", ThemeDark},
+		{"", "0;15", "", ThemeLight},
+		{"", "15;0", "Apple_Terminal", ThemeDark},
+		{"", "", "Apple_Terminal", ThemeLight},
+		{"", "", "iTerm.app", ThemeDark},
+		{"sepia", "", "", ThemeDark},
+	}
+	for _, tt := range tests {
+		t.Setenv("EZLOG_THEME", tt.env)
+		t.Setenv("COLORFGBG", tt.colorFGBG)
+		t.Setenv("TERM_PROGRAM", tt.termProgram)
+		if got := DetectTheme(); got != tt.want {
+			t.Errorf("DetectTheme() with %q %q %q = %v, want %v", tt.env, tt.colorFGBG, tt.termProgram, got, tt.want)
+		}
+	}
+}
+
+func TestParseOSC11(t *testing.T) {
+	tests := []struct {
+		resp string
+		want Theme
+		err  bool
+	}{
+		{"\x1b]11;rgb:ffff/ffff/ffff\x1b\\", ThemeLight, false},
+		{"\x1b]11;rgb:0000/0000/0000\a", ThemeDark, false},
+		{"\x1b]11;rgb:fd/f6/e3\x1b\\", ThemeLight, false},
+		{"\x1b]11;rgb:28/2c/34\x1b\\", ThemeDark, false},
+		{"\x1b]11;rgb:ffff/ffff\x1b\\", ThemeDark, true},
+		{"\x1b]11;#ffffff\x1b\\", ThemeDark, true},
+	}
+	for _, tt := range tests {
+		got, err := parseOSC11(tt.resp)
+		if got != tt.want || (err != nil) != tt.err {
+			t.Errorf("parseOSC11(%q) = %v, %v, want %v, error %v", tt.resp, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestWithAutoTheme(t *testing.T) {
+	t.Setenv("EZLOG_THEME", "light")
+	warn := LightTheme().Levels[zerolog.WarnLevel]
+	tag := color.New(color.FgCyan)
+
+	s := New().WithAutoTheme().WithColorScheme(ColorScheme{Tag: tag}).scheme()
+	if !s.Levels[zerolog.WarnLevel].Equals(warn) || s.Tag != tag {
+		t.Errorf("scheme = %+v, want the light theme with the custom tag", s)
+	}
+	if s.Number == nil || !s.Number.Equals(LightTheme().Number) {
+		t.Errorf("number color = %v, want the light theme's", s.Number)
+	}
+
+	if s := New().scheme(); s.Levels[zerolog.WarnLevel].Equals(warn) {
+		t.Error("the light theme is used without WithAutoTheme")
+	}
+}
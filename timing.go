@@ -0,0 +1,62 @@
+package ezlog
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// spanDepthKey is the context key holding the nesting depth of Begin.
+type spanDepthKey struct{}
+
+// TimeIt returns a function that logs name with the time elapsed since the
+// call to TimeIt at debug level, for use with defer:
+//
+//	defer ezlog.TimeIt(l, "load config", "path", path)()
+//
+// fields are key/value pairs added to the event. Calls after the first are
+// no-ops.
+func TimeIt(l *zerolog.Logger, name string, fields ...any) func() {
+	return TimeItAt(l, zerolog.DebugLevel, name, fields...)
+}
+
+// TimeItAt is TimeIt logging at level.
+func TimeItAt(l *zerolog.Logger, level zerolog.Level, name string, fields ...any) func() {
+	start := time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.WithLevel(level).Fields(fields).Dur("elapsed", time.Since(start)).Msg(name)
+		})
+	}
+}
+
+// Begin logs the start of the operation name on the global logger, at debug
+// level, and returns a context for its children and a function ending it.
+// The end event carries the duration, and the error at error level when err
+// is not nil. Both events carry a depth field, one more than the enclosing
+// Begin of ctx, and the message is indented by the depth so nested
+// operations read as a tree. Calls to the end function after the first are
+// no-ops.
+func Begin(ctx context.Context, name string) (context.Context, func(err error)) {
+	depth, _ := ctx.Value(spanDepthKey{}).(int)
+	label := strings.Repeat("  ", depth) + name
+	start := time.Now()
+
+	log.Debug().Ctx(ctx).Int("depth", depth).Msg(label + " started")
+
+	var once sync.Once
+	return context.WithValue(ctx, spanDepthKey{}, depth+1), func(err error) {
+		once.Do(func() {
+			e := log.Debug()
+			if err != nil {
+				e = log.Error().Err(err)
+			}
+			e.Ctx(ctx).Int("depth", depth).Dur("elapsed", time.Since(start)).Msg(label + " finished")
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package ezlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// decodeLines decodes the JSON events of buf.
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var evt map[string]any
+		if err := json.Unmarshal([]byte(line), &evt); err != nil {
+			t.Fatalf("invalid event %q: %v", line, err)
+		}
+		events = append(events, evt)
+	}
+	return events
+}
+
+func TestTimeIt(t *testing.T) {
+	var buf bytes.Buffer
+	l := zerolog.New(&buf)
+	done := TimeIt(&l, "load config", "path", "/etc/app.yaml")
+	done()
+	done()
+	TimeItAt(&l, zerolog.InfoLevel, "warmup")()
+
+	events := decodeLines(t, &buf)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if e := events[0]; e["level"] != "debug" || e["message"] != "load config" || e["path"] != "/etc/app.yaml" || e["elapsed"] == nil {
+		t.Errorf("TimeIt event = %v", e)
+	}
+	if e := events[1]; e["level"] != "info" || e["message"] != "warmup" {
+		t.Errorf("TimeItAt event = %v", e)
+	}
+}
+
+func TestBeginNesting(t *testing.T) {
+	restoreGlobalLogger(t)
+	var buf bytes.Buffer
+	log.Logger = zerolog.New(&buf)
+
+	ctx, endRequest := Begin(context.Background(), "request")
+	_, endQuery := Begin(ctx, "query")
+	endQuery(errors.New("timeout"))
+	endQuery(nil)
+	endRequest(nil)
+
+	want := []struct {
+		level, message string
+		depth          float64
+		err            any
+	}{
+		{"debug", "request started", 0, nil},
+		{"debug", "  query started", 1, nil},
+		{"error", "  query finished", 1, "timeout"},
+		{"debug", "request finished", 0, nil},
+	}
+	events := decodeLines(t, &buf)
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, w := range want {
+		e := events[i]
+		if e["level"] != w.level || e["message"] != w.message || e["depth"] != w.depth || e["error"] != w.err {
+			t.Errorf("event %d = %v, want %+v", i, e, w)
+		}
+		if strings.HasSuffix(w.message, "finished") && e["elapsed"] == nil {
+			t.Errorf("event %d lacks the elapsed field", i)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package ezlog
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ProgressOption configures a ProgressTracker.
+type ProgressOption func(*ProgressTracker)
+
+// WithProgressClock sets the clock of a ProgressTracker, mostly so tests can
+// control the intervals.
+func WithProgressClock(now func() time.Time) ProgressOption {
+	return func(p *ProgressTracker) {
+		p.now = now
+	}
+}
+
+// ProgressTracker logs periodic progress lines for long-running loops, such
+// as batch jobs processing millions of rows, instead of one line per item.
+// Add is safe for concurrent use and only touches atomics between intervals.
+// It should be created using NewProgress.
+type ProgressTracker struct {
+	l        *zerolog.Logger
+	name     string
+	total    int64
+	interval time.Duration
+	now      func() time.Time
+	start    time.Time
+
+	count    atomic.Int64
+	nextEmit atomic.Int64
+	message  atomic.Pointer[string]
+	doneOnce sync.Once
+}
+
+// NewProgress starts tracking the progress of name towards total, which may
+// be 0 when unknown. At most one info event is logged per interval with the
+// count, the percentage and ETA when the total is known, and the rate per
+// second.
+func NewProgress(l *zerolog.Logger, name string, total int64, interval time.Duration, opts ...ProgressOption) *ProgressTracker {
+	p := &ProgressTracker{
+		l:        l,
+		name:     name,
+		total:    total,
+		interval: interval,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.start = p.now()
+	p.nextEmit.Store(p.start.Add(interval).UnixNano())
+	return p
+}
+
+// Add records n more processed items.
+func (p *ProgressTracker) Add(n int64) {
+	count := p.count.Add(n)
+
+	next := p.nextEmit.Load()
+	now := p.now()
+	if now.UnixNano() < next {
+		return
+	}
+	if !p.nextEmit.CompareAndSwap(next, now.Add(p.interval).UnixNano()) {
+		return
+	}
+	p.emit(count, now)
+}
+
+// SetMessage replaces the name in the following progress lines, e.g. with
+// the current phase of the job.
+func (p *ProgressTracker) SetMessage(s string) {
+	p.message.Store(&s)
+}
+
+// Done logs a summary with the final count, the total elapsed time and the
+// average rate. Calls after the first are no-ops.
+func (p *ProgressTracker) Done() {
+	p.doneOnce.Do(func() {
+		count := p.count.Load()
+		elapsed := p.now().Sub(p.start)
+		p.l.Info().
+			Int64("count", count).
+			Dur("elapsed", elapsed).
+			Float64("rate", progressRate(count, elapsed)).
+			Msg(p.name + " done")
+	})
+}
+
+// emit logs a progress line for count items at now.
+func (p *ProgressTracker) emit(count int64, now time.Time) {
+	msg := p.name
+	if m := p.message.Load(); m != nil {
+		msg = *m
+	}
+	elapsed := now.Sub(p.start)
+	rate := progressRate(count, elapsed)
+
+	e := p.l.Info().Int64("count", count)
+	if p.total > 0 {
+		e = e.Int64("total", p.total).
+			Float64("percent", math.Round(float64(count)*1000/float64(p.total))/10)
+	}
+	e = e.Float64("rate", rate)
+	if p.total > 0 && rate > 0 && count < p.total {
+		eta := time.Duration(float64(p.total-count) / rate * float64(time.Second))
+		e = e.Dur("eta", eta.Round(time.Second))
+	}
+	e.Msg(msg)
+}
+
+// progressRate returns count per second over elapsed, rounded to 0.01.
+func progressRate(count int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return math.Round(float64(count)/elapsed.Seconds()*100) / 100
+}
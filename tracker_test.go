@@ -0,0 +1,42 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressTracker(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().Build()
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	p := NewProgress(l, "rows", 1000, time.Second, WithProgressClock(func() time.Time { return now }))
+
+	p.Add(100)
+	if buf.Len() != 0 {
+		t.Fatalf("logged before the interval: %s", buf.String())
+	}
+	now = now.Add(2 * time.Second)
+	p.Add(100)
+	p.Add(100)
+	p.SetMessage("rows, phase 2")
+	now = now.Add(time.Second)
+	p.Add(300)
+	p.Done()
+	p.Done()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d events, want 3:\n%s", len(lines), buf.String())
+	}
+	for i, want := range [][2]string{
+		{`"count":200,"total":1000,"percent":20,"rate":100,"eta":8000`, `"message":"rows"`},
+		{`"count":600,"total":1000,"percent":60,"rate":200,"eta":2000`, `"message":"rows, phase 2"`},
+		{`"count":600,"elapsed":3000,"rate":200`, `"message":"rows done"`},
+	} {
+		if !strings.Contains(lines[i], want[0]) || !strings.Contains(lines[i], want[1]) {
+			t.Errorf("event %d = %s, want %s and %s", i, lines[i], want[0], want[1])
+		}
+	}
+}
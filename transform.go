@@ -0,0 +1,170 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// WithMessageTransform rewrites the message of every event with fn, e.g. to
+// prefix a correlation token, translate substrings or keep messages on a
+// single line for a legacy collector. In console output it applies to the
+// rendered message, after the tag prefix; in JSON and logfmt output it
+// rewrites the message field, before an audit chain is computed. It may be
+// called several times, the transforms running in order. A transform
+// returning "" keeps the event with an empty message.
+func (b *LogBuilder) WithMessageTransform(fn func(level zerolog.Level, msg string) string) *LogBuilder {
+	b.messageTransforms = append(b.messageTransforms, fn)
+	return b
+}
+
+// levelMessage is the message of a console event along with its level, for
+// the transforms run by FormatMessage.
+type levelMessage struct {
+	level zerolog.Level
+	msg   string
+}
+
+// String implements fmt.Stringer.
+func (m levelMessage) String() string {
+	return m.msg
+}
+
+// applyMessageTransforms runs the message transforms on the messages
+// rendered by w, tag prefix included.
+func (b *LogBuilder) applyMessageTransforms(w *zerolog.ConsoleWriter) {
+	addFormatPrepare(w, func(evt map[string]any) error {
+		level := zerolog.NoLevel
+		if name, ok := evt[zerolog.LevelFieldName].(string); ok {
+			if l, ok := b.parseLevelValue(name); ok {
+				level = l
+			}
+		}
+		msg, _ := evt[zerolog.MessageFieldName].(string)
+		evt[zerolog.MessageFieldName] = levelMessage{level: level, msg: msg}
+		return nil
+	})
+
+	format := w.FormatMessage
+	transforms := b.messageTransforms
+	w.FormatMessage = func(i any) string {
+		level := zerolog.NoLevel
+		if m, ok := i.(levelMessage); ok {
+			level = m.level
+		}
+		msg := format(i)
+		for _, fn := range transforms {
+			msg = fn(level, msg)
+		}
+		return msg
+	}
+}
+
+// messageTransformWriter rewrites the message of JSON events before they
+// reach out.
+type messageTransformWriter struct {
+	out        io.Writer
+	transforms []func(zerolog.Level, string) string
+}
+
+// Write implements io.Writer. Events that are not JSON objects are written
+// unchanged.
+func (w *messageTransformWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *messageTransformWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	rewritten, ok := w.rewrite(p)
+	if !ok {
+		return writeLevel(w.out, level, p)
+	}
+	if _, err := writeLevel(w.out, level, rewritten); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// rewrite returns p with its top-level message field transformed.
+func (w *messageTransformWriter) rewrite(p []byte) ([]byte, bool) {
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if t, err := d.Token(); err != nil || t != json.Delim('{') {
+		return nil, false
+	}
+
+	level := zerolog.NoLevel
+	var msg string
+	msgStart, msgEnd := -1, -1
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return nil, false
+		}
+		key, _ := t.(string)
+		start := int(d.InputOffset())
+		var v json.RawMessage
+		if err := d.Decode(&v); err != nil {
+			return nil, false
+		}
+		switch key {
+		case zerolog.LevelFieldName:
+			var s string
+			if json.Unmarshal(v, &s) == nil {
+				if l, err := ParseLevel(s); err == nil {
+					level = l
+				}
+			}
+		case zerolog.MessageFieldName:
+			if json.Unmarshal(v, &msg) != nil {
+				return nil, false
+			}
+			msgStart, msgEnd = start, int(d.InputOffset())
+		}
+	}
+	if _, err := d.Token(); err != nil {
+		return nil, false
+	}
+	end := int(d.InputOffset())
+
+	for _, fn := range w.transforms {
+		msg = fn(level, msg)
+	}
+	quoted := marshalJSONString(msg)
+
+	out := make([]byte, 0, len(p)+len(quoted))
+	switch {
+	case msgStart >= 0:
+		out = append(out, p[:msgStart]...)
+		out = append(out, ':')
+		out = append(out, quoted...)
+		out = append(out, p[msgEnd:]...)
+	case msg == "":
+		return p, true
+	default:
+		// The event has no message yet, as zerolog omits empty ones.
+		closing := bytes.LastIndexByte(p[:end], '}')
+		head := bytes.TrimSpace(p[:closing])
+		out = append(out, head...)
+		if head[len(head)-1] != '{' {
+			out = append(out, ',')
+		}
+		out = append(out, marshalJSONString(zerolog.MessageFieldName)...)
+		out = append(out, ':')
+		out = append(out, quoted...)
+		out = append(out, p[closing:]...)
+	}
+	return out, true
+}
+
+// marshalJSONString returns s as a JSON string, without escaping HTML
+// characters as zerolog does not either.
+func marshalJSONString(s string) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
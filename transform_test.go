@@ -0,0 +1,84 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func upper(_ zerolog.Level, msg string) string { return strings.ToUpper(msg) }
+
+func withLevel(level zerolog.Level, msg string) string { return level.String() + ": " + msg }
+
+func TestMessageTransformJSON(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().
+		WithMessageTransform(upper).WithMessageTransform(withLevel).Build()
+	l.Warn().Str("k", "v").Msg("disk low")
+
+	if got := buf.String(); !strings.Contains(got, `"message":"warn: DISK LOW"`) || !strings.Contains(got, `"k":"v"`) {
+		t.Errorf("output = %s", got)
+	}
+}
+
+func TestMessageTransformEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().
+		WithMessageTransform(func(zerolog.Level, string) string { return "" }).Build()
+	l.Info().Str("k", "v").Msg("dropped")
+
+	if got := buf.String(); strings.Contains(got, "dropped") || !strings.Contains(got, `"message":""`) {
+		t.Errorf("output = %s, want the event with an empty message", got)
+	}
+}
+
+func TestMessageTransformAddsMissingMessage(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().
+		WithMessageTransform(func(_ zerolog.Level, msg string) string { return "[req 7]" + msg }).Build()
+	l.Info().Msg("")
+
+	if got := buf.String(); !strings.Contains(got, `"message":"[req 7]"`) {
+		t.Errorf("output = %s", got)
+	}
+}
+
+func TestMessageTransformConsoleAfterTag(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestBuilder(&buf).WithTag("api").WithTimestampFunc(fixedClock(time.Unix(0, 0))).
+		WithMessageTransform(withLevel).Build()
+	l.Error().Msg("failed")
+
+	if got := buf.String(); !strings.Contains(got, "error: [api] failed") {
+		t.Errorf("output = %q, want the transform applied after the tag prefix", got)
+	}
+}
+
+func TestMessageTransformLogfmt(t *testing.T) {
+	var buf bytes.Buffer
+	l := New().AsLocal().WithWriter(&buf).WithLogfmtOutput().WithMessageTransform(upper).Build()
+	l.Info().Msg("two words")
+
+	if got := buf.String(); !strings.Contains(got, `message="TWO WORDS"`) {
+		t.Errorf("output = %s", got)
+	}
+}
+
+func TestMessageTransformBeforeAuditChain(t *testing.T) {
+	var buf bytes.Buffer
+	key := []byte("secret")
+	l := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithAuditChain(key).
+		WithMessageTransform(upper).Build()
+	l.Info().Msg("one")
+	l.Info().Msg("two")
+
+	if !strings.Contains(buf.String(), `"message":"TWO"`) {
+		t.Fatalf("output = %s", buf.String())
+	}
+	if err := VerifyAuditChain(&buf, key); err != nil {
+		t.Errorf("VerifyAuditChain: %v", err)
+	}
+}
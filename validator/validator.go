@@ -0,0 +1,66 @@
+// Package validator logs go-playground/validator errors with ezlog and
+// validates the models written through an ezlog.GormLogger. It is a separate
+// package to keep the validator library out of programs that do not import
+// it.
+//
+// The examples import it as ezvalidator, as the package shares its name with the
+// library it adapts:
+//
+//	import ezvalidator "github.com/ezydark/ezlog/validator"
+package validator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ezydark/ezlog"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+)
+
+// validationErrorsArray marshals validation errors as an array of objects.
+type validationErrorsArray validator.ValidationErrors
+
+// MarshalZerologArray implements zerolog.LogArrayMarshaler.
+func (a validationErrorsArray) MarshalZerologArray(arr *zerolog.Array) {
+	for _, fe := range a {
+		arr.Object(validationErrorObject{fe})
+	}
+}
+
+// validationErrorObject marshals a single validation error.
+type validationErrorObject struct {
+	fe validator.FieldError
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler.
+func (o validationErrorObject) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("field", o.fe.Namespace()).
+		Str("tag", o.fe.Tag()).
+		Str("value", fmt.Sprint(o.fe.Value())).
+		Str("param", o.fe.Param())
+}
+
+// ValidationErrors adds errs to e as an array under key, each error being an
+// object with field, tag, value and param keys. It works on any zerolog event:
+//
+//	if errs, ok := err.(validator.ValidationErrors); ok {
+//		ezvalidator.ValidationErrors(log.Warn(), "validation", errs).Msg("invalid form")
+//	}
+func ValidationErrors(e *zerolog.Event, key string, errs validator.ValidationErrors) *zerolog.Event {
+	return e.Array(key, validationErrorsArray(errs))
+}
+
+// WithModelValidation makes the GormLogger built by b validate models with v
+// before they are created or updated and log a warning listing the
+// validation failures. The write itself is not aborted. The GormLogger must
+// be registered as a plugin with db.Use.
+func WithModelValidation(b *ezlog.GormLoggerBuilder, v *validator.Validate) *ezlog.GormLoggerBuilder {
+	return b.WithModelCheck(func(ctx context.Context, model any) zerolog.LogArrayMarshaler {
+		errs, ok := v.StructCtx(ctx, model).(validator.ValidationErrors)
+		if !ok {
+			return nil
+		}
+		return validationErrorsArray(errs)
+	})
+}
@@ -0,0 +1,94 @@
+package validator
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ezydark/ezlog"
+	"github.com/glebarez/sqlite"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+type user struct {
+	ID   uint
+	Name string `validate:"required"`
+}
+
+func TestValidationErrors(t *testing.T) {
+	var buf bytes.Buffer
+	zl := zerolog.New(&buf)
+
+	errs := validator.New().Struct(user{}).(validator.ValidationErrors)
+	ValidationErrors(zl.Warn(), "validation", errs).Msg("invalid")
+
+	want := `"validation":[{"field":"user.Name","tag":"required","value":"","param":""}]`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output = %s, want %s", buf.String(), want)
+	}
+}
+
+func TestWithModelValidation(t *testing.T) {
+	var buf bytes.Buffer
+	l := WithModelValidation(ezlog.NewGormLogger().WithWriter(&buf), validator.New()).Build()
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true, Logger: l})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(l); err != nil {
+		t.Fatal(err)
+	}
+
+	db.Create(&user{Name: "ada"})
+	if strings.Contains(buf.String(), "validation failed") {
+		t.Errorf("valid model logged: %s", buf.String())
+	}
+
+	db.Create(&user{})
+	if got := buf.String(); !strings.Contains(got, "gorm model validation failed") || !strings.Contains(got, `tag="required"`) {
+		t.Errorf("output = %s", got)
+	}
+}
+
+func TestWithModelValidationSQLite(t *testing.T) {
+	var buf bytes.Buffer
+	l := WithModelValidation(ezlog.NewGormLogger().WithWriter(&buf), validator.New()).Build()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "app.db")), &gorm.Config{Logger: l})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(l); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&user{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Create(&user{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	got := ansiEscape.ReplaceAllString(buf.String(), "")
+	warning := strings.Index(got, `[WARN] gorm model validation failed table="users" validation=[(field="user.Name" param="" tag="required" value="")]`)
+	if warning < 0 || warning > strings.Index(got, "INSERT INTO") {
+		t.Errorf("output = %s, want the validation warning before the insert", got)
+	}
+
+	// The invalid model is still written.
+	var n int64
+	if err := db.Model(&user{}).Count(&n).Error; err != nil || n != 1 {
+		t.Errorf("got %d users, err %v, want the invalid one written", n, err)
+	}
+
+	buf.Reset()
+	db.Create(&user{Name: "ada"})
+	if got := buf.String(); strings.Contains(got, "validation failed") {
+		t.Errorf("valid model logged: %s", got)
+	}
+}
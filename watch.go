@@ -0,0 +1,320 @@
+package ezlog
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// LiveConfig holds the settings of a Config that can change while the
+// program runs: the level, the tag levels, the error sampling rate and the
+// redacted fields. Loggers built with WithLiveConfig follow its changes,
+// made with Apply or by WatchConfig. The other settings, such as the format
+// or the file, are only read by ApplyTo when a logger is built.
+// It should be created using NewLiveConfig.
+type LiveConfig struct {
+	mu    sync.Mutex
+	state atomic.Pointer[liveState]
+}
+
+// liveState is the parsed live subset of a Config, replaced as a whole so
+// that a change takes effect at once.
+type liveState struct {
+	cfg       Config
+	level     zerolog.Level
+	tagLevels map[string]zerolog.Level
+	redact    map[string]struct{}
+}
+
+// NewLiveConfig creates a LiveConfig set to c.
+func NewLiveConfig(c Config) (*LiveConfig, error) {
+	lc := &LiveConfig{}
+	if err := lc.Apply(c); err != nil {
+		return nil, err
+	}
+	return lc, nil
+}
+
+// Config returns the Config last applied.
+func (lc *LiveConfig) Config() Config {
+	return lc.state.Load().cfg
+}
+
+// Apply validates c and makes its live settings take effect. An invalid c
+// is rejected and the previous settings are kept.
+func (lc *LiveConfig) Apply(c Config) error {
+	_, _, err := lc.apply(c)
+	return err
+}
+
+// apply is Apply, returning the changed live settings and the changed
+// settings needing a rebuild of the loggers.
+func (lc *LiveConfig) apply(c Config) (changes, restart []string, err error) {
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+	s := &liveState{
+		cfg:       c,
+		level:     zerolog.TraceLevel,
+		tagLevels: make(map[string]zerolog.Level, len(c.TagLevels)),
+		redact:    make(map[string]struct{}, len(c.Redact)),
+	}
+	if c.Level != "" {
+		s.level, _ = ParseLevel(c.Level)
+	}
+	for tag, level := range c.TagLevels {
+		s.tagLevels[tag], _ = ParseLevel(level)
+	}
+	for _, name := range c.Redact {
+		s.redact[strings.ToLower(name)] = struct{}{}
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if old := lc.state.Load(); old != nil {
+		changes, restart = diffConfig(old.cfg, c)
+	}
+	lc.state.Store(s)
+	return changes, restart, nil
+}
+
+// minLevel returns the minimum level of the loggers of tag.
+func (s *liveState) minLevel(tag string) zerolog.Level {
+	if level, ok := s.tagLevels[tag]; ok {
+		return level
+	}
+	return s.level
+}
+
+// WithLiveConfig drops the events below the level of lc for the builder's
+// tag, samples error events and redacts fields as lc currently says. The
+// builder's own level still applies, so it should be low enough for lc to
+// lower the level at runtime.
+func (b *LogBuilder) WithLiveConfig(lc *LiveConfig) *LogBuilder {
+	b.liveConfig = lc
+	return b
+}
+
+// liveConfigHook applies the level and error sampling of a LiveConfig.
+type liveConfigHook struct {
+	lc  *LiveConfig
+	tag string
+}
+
+// Run implements zerolog.Hook.
+func (h liveConfigHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	s := h.lc.state.Load()
+	if level < s.minLevel(h.tag) {
+		e.Discard()
+		return
+	}
+	rate := s.cfg.ErrorSampling
+	if level == zerolog.ErrorLevel && rate > 0 && rate < 1 && rand.Float64() >= rate {
+		e.Discard()
+	}
+}
+
+// WatchOption configures WatchConfig.
+type WatchOption func(*configWatcher)
+
+// WithWatchInterval sets how often the file is checked for changes, 2s by
+// default.
+func WithWatchInterval(d time.Duration) WatchOption {
+	return func(w *configWatcher) {
+		w.interval = d
+	}
+}
+
+// WithWatchLogger logs the applied and rejected changes to l instead of the
+// global logger.
+func WithWatchLogger(l *zerolog.Logger) WatchOption {
+	return func(w *configWatcher) {
+		w.log = l
+	}
+}
+
+// configWatcher polls a config file and applies its changes.
+type configWatcher struct {
+	path     string
+	lc       *LiveConfig
+	interval time.Duration
+	log      *zerolog.Logger
+
+	last    []byte
+	readErr string
+}
+
+// WatchConfig applies the Config file at path to lc, then polls the file
+// and applies its changes, e.g. a Kubernetes ConfigMap update lowering the
+// level, without restarting the program. Polling reads the file rather than
+// relying on file system events, so it also sees ConfigMap updates, which
+// replace a symbolic link. Every change is logged with the settings that
+// changed. A file that cannot be parsed or is invalid is logged and ignored,
+// keeping the previous settings. Changes to settings that are not live are
+// logged as needing a restart.
+//
+// WatchConfig fails if the file cannot be loaded at first. stop ends the
+// watch and waits for it to finish.
+func WatchConfig(path string, lc *LiveConfig, opts ...WatchOption) (stop func(), err error) {
+	w := &configWatcher{
+		path:     path,
+		lc:       lc,
+		interval: 2 * time.Second,
+		log:      &log.Logger,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ezlog: read config: %w", err)
+	}
+	cfg, err := parseConfig(path, data)
+	if err != nil {
+		return nil, err
+	}
+	changes, restart, err := lc.apply(cfg)
+	if err != nil {
+		return nil, err
+	}
+	w.last = data
+	w.report(changes, restart)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				w.poll()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}, nil
+}
+
+// poll applies the file if it changed since the last poll.
+func (w *configWatcher) poll() {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		// The file can briefly be missing while it is replaced, so the
+		// error is only logged once and the previous settings are kept.
+		if err.Error() != w.readErr {
+			w.readErr = err.Error()
+			w.log.Warn().Err(err).Str("path", w.path).Msg("ezlog: config file unreadable, keeping the previous config")
+		}
+		return
+	}
+	w.readErr = ""
+	if bytes.Equal(data, w.last) {
+		return
+	}
+	w.last = data
+
+	cfg, err := parseConfig(w.path, data)
+	if err == nil {
+		var changes, restart []string
+		if changes, restart, err = w.lc.apply(cfg); err == nil {
+			w.report(changes, restart)
+			return
+		}
+	}
+	w.log.Error().Err(err).Str("path", w.path).Msg("ezlog: config update rejected, keeping the previous config")
+}
+
+// report logs the changes of an applied config.
+func (w *configWatcher) report(changes, restart []string) {
+	if len(changes) > 0 {
+		w.log.Info().Str("path", w.path).Strs("changes", changes).Msg("ezlog: config reloaded")
+	}
+	if len(restart) > 0 {
+		w.log.Warn().Str("path", w.path).Strs("settings", restart).Msg("ezlog: config changes need a restart")
+	}
+}
+
+// diffConfig returns the live settings changed from old to c, as
+// "level: info -> debug", and the names of the other changed settings.
+func diffConfig(old, c Config) (changes, restart []string) {
+	change := func(name, from, to string) {
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, from, to))
+		}
+	}
+	change("level", orUnset(strings.ToLower(old.Level)), orUnset(strings.ToLower(c.Level)))
+
+	tags := make([]string, 0, len(old.TagLevels)+len(c.TagLevels))
+	for tag := range old.TagLevels {
+		tags = append(tags, tag)
+	}
+	for tag := range c.TagLevels {
+		if _, ok := old.TagLevels[tag]; !ok {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		change("tag_levels."+tag, orUnset(strings.ToLower(old.TagLevels[tag])), orUnset(strings.ToLower(c.TagLevels[tag])))
+	}
+
+	change("error_sampling", fmt.Sprint(old.ErrorSampling), fmt.Sprint(c.ErrorSampling))
+	change("redact", fmt.Sprint(redactSet(old.Redact)), fmt.Sprint(redactSet(c.Redact)))
+
+	for _, s := range []struct {
+		name    string
+		changed bool
+	}{
+		{"format", !strings.EqualFold(old.Format, c.Format)},
+		{"no_color", old.NoColor != c.NoColor},
+		{"tag", old.Tag != c.Tag},
+		{"time_format", old.TimeFormat != c.TimeFormat},
+		{"utc", old.UTC != c.UTC},
+		{"caller", old.Caller != c.Caller},
+		{"file", old.File != c.File},
+	} {
+		if s.changed {
+			restart = append(restart, s.name)
+		}
+	}
+	return changes, restart
+}
+
+// orUnset returns s, or "(unset)" if it is empty.
+func orUnset(s string) string {
+	if s == "" {
+		return "(unset)"
+	}
+	return s
+}
+
+// redactSet returns the sorted, lowercased, deduplicated field names.
+func redactSet(names []string) []string {
+	set := make([]string, len(names))
+	for i, name := range names {
+		set[i] = strings.ToLower(name)
+	}
+	sort.Strings(set)
+	return slices.Compact(set)
+}
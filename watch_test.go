@@ -0,0 +1,150 @@
+package ezlog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// replaceFile atomically replaces the content of path with data, the way a
+// ConfigMap update does.
+func replaceFile(t *testing.T, path, data string) {
+	t.Helper()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitForOutput waits until buf contains want.
+func waitForOutput(t *testing.T, buf *lockedBuffer, want string) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !strings.Contains(buf.String(), want); {
+		if time.Now().After(deadline) {
+			t.Fatalf("output %q never contained %q", buf.String(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLiveConfig(t *testing.T) {
+	lc, err := NewLiveConfig(Config{Level: "info", TagLevels: map[string]string{"db": "error"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	api := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithTag("api").WithLiveConfig(lc).Build()
+	db := New().AsLocal().WithWriter(&buf).WithJSONOutput().WithTag("db").WithLiveConfig(lc).Build()
+
+	api.Debug().Msg("api debug 1")
+	api.Info().Str("token", "t1").Msg("api info 1")
+	db.Warn().Msg("db warn 1")
+
+	if err := lc.Apply(Config{Level: "debug", Redact: []string{"Token"}}); err != nil {
+		t.Fatal(err)
+	}
+	api.Debug().Str("token", "t2").Msg("api debug 2")
+	db.Warn().Msg("db warn 2")
+
+	if err := lc.Apply(Config{Level: "loud"}); err == nil {
+		t.Error("Apply accepted an invalid level")
+	}
+	if lc.Config().Level != "debug" {
+		t.Errorf("after a rejected Apply: level = %q, want debug", lc.Config().Level)
+	}
+
+	got := buf.String()
+	for _, want := range []string{`"api info 1"`, `"t1"`, `"api debug 2"`, `"token":"[REDACTED]"`, `"db warn 2"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output lacks %s:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"api debug 1", "db warn 1", "t2"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("output has %s:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	replaceFile(t, path, `{"level": "info"}`)
+
+	var watchLog lockedBuffer
+	wl := zerolog.New(&watchLog)
+	lc, _ := NewLiveConfig(Config{})
+	stop, err := WatchConfig(path, lc, WithWatchInterval(5*time.Millisecond), WithWatchLogger(&wl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	var out lockedBuffer
+	l := New().AsLocal().WithWriter(&out).WithJSONOutput().WithLiveConfig(lc).Build()
+	l.Debug().Msg("hidden")
+
+	replaceFile(t, path, `{"level": "debug", "format": "json"}`)
+	waitForOutput(t, &watchLog, "level: info -> debug")
+	l.Debug().Msg("shown")
+
+	replaceFile(t, path, `{"level": "debug", "format": "json"`)
+	waitForOutput(t, &watchLog, "config update rejected")
+	l.Debug().Msg("still shown")
+	stop()
+
+	if got := out.String(); strings.Contains(got, "hidden") || !strings.Contains(got, `"shown"`) || !strings.Contains(got, `"still shown"`) {
+		t.Errorf("output = %s", got)
+	}
+	logged := watchLog.String()
+	for _, want := range []string{`"changes":["level: (unset) -> info"]`, `"changes":["level: info -> debug"]`, `"settings":["format"]`} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("watch log lacks %s:\n%s", want, logged)
+		}
+	}
+}
+
+func TestWatchConfigInitialErrors(t *testing.T) {
+	lc, _ := NewLiveConfig(Config{Level: "warn"})
+	dir := t.TempDir()
+	if _, err := WatchConfig(filepath.Join(dir, "missing.json"), lc); err == nil {
+		t.Error("WatchConfig accepted a missing file")
+	}
+	path := filepath.Join(dir, "log.json")
+	replaceFile(t, path, `{"level": "loud"}`)
+	if _, err := WatchConfig(path, lc); err == nil {
+		t.Error("WatchConfig accepted an invalid file")
+	}
+	if lc.Config().Level != "warn" {
+		t.Errorf("level = %q, want warn kept", lc.Config().Level)
+	}
+}
+
+func TestDiffConfig(t *testing.T) {
+	old := Config{Level: "info", TagLevels: map[string]string{"db": "warn", "http": "info"}, Redact: []string{"token"}}
+	c := Config{Level: "INFO", TagLevels: map[string]string{"db": "error", "cache": "debug"}, Redact: []string{"Token", "password"},
+		ErrorSampling: 0.5, UTC: true, File: FileConfig{Path: "a.log"}}
+
+	changes, restart := diffConfig(old, c)
+	wantChanges := []string{
+		"tag_levels.cache: (unset) -> debug",
+		"tag_levels.db: warn -> error",
+		"tag_levels.http: info -> (unset)",
+		"error_sampling: 0 -> 0.5",
+		"redact: [token] -> [password token]",
+	}
+	if !slices.Equal(changes, wantChanges) {
+		t.Errorf("changes = %q, want %q", changes, wantChanges)
+	}
+	if want := []string{"utc", "file"}; !slices.Equal(restart, want) {
+		t.Errorf("restart = %q, want %q", restart, want)
+	}
+}
@@ -0,0 +1,201 @@
+package ezlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// WebhookOption configures a WebhookWriter.
+type WebhookOption func(*WebhookWriter)
+
+// WithWebhookBatchSize sets the maximum number of events per request, 100 by default.
+func WithWebhookBatchSize(n int) WebhookOption {
+	return func(w *WebhookWriter) {
+		w.batchSize = n
+	}
+}
+
+// WithWebhookInterval sets how often a partial batch is sent, 1s by default.
+func WithWebhookInterval(d time.Duration) WebhookOption {
+	return func(w *WebhookWriter) {
+		w.interval = d
+	}
+}
+
+// WithWebhookQueueSize sets how many events may wait for delivery before new
+// ones are dropped, 10000 by default.
+func WithWebhookQueueSize(n int) WebhookOption {
+	return func(w *WebhookWriter) {
+		w.queueSize = n
+	}
+}
+
+// WithWebhookHeader adds a header, such as an Authorization token, to every request.
+func WithWebhookHeader(key, value string) WebhookOption {
+	return func(w *WebhookWriter) {
+		w.header.Add(key, value)
+	}
+}
+
+// WithWebhookJSONArray sends batches as a JSON array instead of NDJSON.
+func WithWebhookJSONArray() WebhookOption {
+	return func(w *WebhookWriter) {
+		w.jsonArray = true
+	}
+}
+
+// WithWebhookGzip gzips request bodies.
+func WithWebhookGzip() WebhookOption {
+	return func(w *WebhookWriter) {
+		w.gzip = true
+	}
+}
+
+// WithWebhookRetry sets the number of retries of a failed request and the
+// initial backoff, doubled after every attempt, with jitter.
+func WithWebhookRetry(maxRetries int, backoff time.Duration) WebhookOption {
+	return func(w *WebhookWriter) {
+		w.retry = retryPolicy{maxRetries: maxRetries, backoff: backoff}
+	}
+}
+
+// WithWebhookHTTPClient sets the HTTP client used to send batches.
+func WithWebhookHTTPClient(c *http.Client) WebhookOption {
+	return func(w *WebhookWriter) {
+		w.client = c
+	}
+}
+
+// WebhookStats holds the delivery counters of a WebhookWriter.
+type WebhookStats struct {
+	// Sent is the number of events delivered.
+	Sent uint64
+	// Dropped is the number of events lost, because the queue was full or
+	// because their batch failed after all retries.
+	Dropped uint64
+	// Batches is the number of requests that succeeded.
+	Batches uint64
+}
+
+// WebhookWriter posts zerolog JSON events to an HTTP endpoint in batches.
+// It should be created using NewWebhookWriter.
+type WebhookWriter struct {
+	url       string
+	client    *http.Client
+	header    http.Header
+	retry     retryPolicy
+	batchSize int
+	interval  time.Duration
+	queueSize int
+	jsonArray bool
+	gzip      bool
+
+	batcher *batcher
+	sent    atomic.Uint64
+	failed  atomic.Uint64
+	batches atomic.Uint64
+}
+
+// NewWebhookWriter creates a WebhookWriter posting to url.
+func NewWebhookWriter(url string, opts ...WebhookOption) *WebhookWriter {
+	w := &WebhookWriter{
+		url:       url,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		header:    make(http.Header),
+		retry:     retryPolicy{maxRetries: 3, backoff: 500 * time.Millisecond},
+		batchSize: 100,
+		interval:  time.Second,
+		queueSize: 10000,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if w.jsonArray {
+		w.header.Set("Content-Type", "application/json")
+	} else {
+		w.header.Set("Content-Type", "application/x-ndjson")
+	}
+	if w.gzip {
+		w.header.Set("Content-Encoding", "gzip")
+	}
+
+	w.batcher = newBatcher(w.queueSize, w.batchSize, w.interval, w.send)
+	return w
+}
+
+// Write implements io.Writer. It never blocks; events are dropped when the
+// queue is full.
+func (w *WebhookWriter) Write(p []byte) (int, error) {
+	if err := w.batcher.add(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close sends the remaining events and stops the writer.
+func (w *WebhookWriter) Close() error {
+	w.batcher.close()
+	return nil
+}
+
+// Stats returns the delivery counters.
+func (w *WebhookWriter) Stats() WebhookStats {
+	return WebhookStats{
+		Sent:    w.sent.Load(),
+		Dropped: w.batcher.dropped.Load() + w.failed.Load(),
+		Batches: w.batches.Load(),
+	}
+}
+
+// send posts batch to the endpoint.
+func (w *WebhookWriter) send(batch []batchItem) {
+	var body bytes.Buffer
+	if w.jsonArray {
+		body.WriteByte('[')
+	}
+	for i, item := range batch {
+		if w.jsonArray && i > 0 {
+			body.WriteByte(',')
+		}
+		body.Write(bytes.TrimRight(item.p, "\n"))
+		if !w.jsonArray {
+			body.WriteByte('\n')
+		}
+	}
+	if w.jsonArray {
+		body.WriteByte(']')
+	}
+
+	payload := body.Bytes()
+	if w.gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(payload)
+		_ = zw.Close()
+		payload = buf.Bytes()
+	}
+
+	if err := w.retry.post(w.client, w.url, w.header, payload); err != nil {
+		w.failed.Add(uint64(len(batch)))
+		diag(zerolog.ErrorLevel, "webhook", "webhook delivery failed, %d events lost: %v", len(batch), err)
+		return
+	}
+	w.sent.Add(uint64(len(batch)))
+	w.batches.Add(1)
+}
+
+// QueueDepth returns the number of events waiting to be sent.
+func (w *WebhookWriter) QueueDepth() int {
+	return len(w.batcher.queue)
+}
+
+// Dropped returns the number of events lost.
+func (w *WebhookWriter) Dropped() uint64 {
+	return w.Stats().Dropped
+}
@@ -0,0 +1,138 @@
+package ezlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookRequest is a request received by a webhookServer.
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookServer records the requests posted to it.
+type webhookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []webhookRequest
+}
+
+// newWebhookServer starts a webhookServer answering with statuses in turn,
+// then with 200 OK.
+func newWebhookServer(t *testing.T, statuses ...int) *webhookServer {
+	s := &webhookServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, webhookRequest{header: r.Header.Clone(), body: body})
+		status := http.StatusOK
+		if len(s.requests) <= len(statuses) {
+			status = statuses[len(s.requests)-1]
+		}
+		s.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestWebhookWriterBatches(t *testing.T) {
+	s := newWebhookServer(t)
+	w := NewWebhookWriter(s.URL, WithWebhookBatchSize(2), WithWebhookInterval(time.Hour))
+	for _, msg := range []string{"a", "b", "c"} {
+		w.Write([]byte(`{"message":"` + msg + `"}` + "\n"))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(s.requests))
+	}
+	if got, want := string(s.requests[0].body), "{\"message\":\"a\"}\n{\"message\":\"b\"}\n"; got != want {
+		t.Errorf("first batch = %q, want %q", got, want)
+	}
+	if got, want := string(s.requests[1].body), "{\"message\":\"c\"}\n"; got != want {
+		t.Errorf("last batch = %q, want %q", got, want)
+	}
+	if ct := s.requests[0].header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if st := w.Stats(); st.Sent != 3 || st.Batches != 2 || st.Dropped != 0 {
+		t.Errorf("Stats() = %+v", st)
+	}
+}
+
+func TestWebhookWriterInterval(t *testing.T) {
+	s := newWebhookServer(t)
+	w := NewWebhookWriter(s.URL, WithWebhookInterval(10*time.Millisecond))
+	defer w.Close()
+	w.Write([]byte(`{"message":"a"}`))
+
+	for deadline := time.Now().Add(2 * time.Second); w.Stats().Batches == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("batch not sent after the interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWebhookWriterJSONArrayGzipHeaders(t *testing.T) {
+	s := newWebhookServer(t)
+	w := NewWebhookWriter(s.URL, WithWebhookJSONArray(), WithWebhookGzip(), WithWebhookHeader("Authorization", "Bearer token"))
+	w.Write([]byte(`{"message":"a"}` + "\n"))
+	w.Write([]byte(`{"message":"b"}` + "\n"))
+	w.Close()
+
+	if len(s.requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(s.requests))
+	}
+	req := s.requests[0]
+	if req.header.Get("Authorization") != "Bearer token" || req.header.Get("Content-Encoding") != "gzip" || req.header.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v", req.header)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(req.body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []map[string]string
+	if err := json.NewDecoder(zr).Decode(&events); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0]["message"] != "a" || events[1]["message"] != "b" {
+		t.Errorf("events = %v", events)
+	}
+}
+
+func TestWebhookWriterRetriesUnavailable(t *testing.T) {
+	s := newWebhookServer(t, http.StatusServiceUnavailable)
+	w := NewWebhookWriter(s.URL, WithWebhookRetry(2, time.Millisecond))
+	w.Write([]byte(`{"message":"a"}`))
+	w.Close()
+
+	if len(s.requests) != 2 {
+		t.Errorf("got %d requests, want a retry after 503", len(s.requests))
+	}
+	if st := w.Stats(); st.Sent != 1 || st.Dropped != 0 {
+		t.Errorf("Stats() = %+v", st)
+	}
+}
+
+func TestWebhookWriterCountsFailedBatches(t *testing.T) {
+	s := newWebhookServer(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	w := NewWebhookWriter(s.URL, WithWebhookRetry(1, time.Millisecond))
+	w.Write([]byte(`{"message":"a"}`))
+	w.Close()
+
+	if st := w.Stats(); st.Sent != 0 || st.Dropped != 1 || st.Batches != 0 {
+		t.Errorf("Stats() = %+v", st)
+	}
+}
@@ -0,0 +1,31 @@
+// Package yaml makes ezlog.LoadConfig and ezlog.WatchConfig read YAML files,
+// ending with ".yaml" or ".yml". It is a separate package to keep the YAML
+// library out of programs that do not import it, which is all it takes:
+//
+//	import _ "github.com/ezydark/ezlog/yaml"
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/ezydark/ezlog"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	ezlog.RegisterConfigFormat(".yaml", decodeConfig)
+	ezlog.RegisterConfigFormat(".yml", decodeConfig)
+}
+
+// decodeConfig decodes the YAML data into cfg, rejecting unknown keys. An
+// empty document leaves cfg unchanged.
+func decodeConfig(data []byte, cfg *ezlog.Config) error {
+	d := yaml.NewDecoder(bytes.NewReader(data))
+	d.KnownFields(true)
+	if err := d.Decode(cfg); !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
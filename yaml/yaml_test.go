@@ -0,0 +1,93 @@
+package yaml
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ezydark/ezlog"
+)
+
+// writeConfig writes data to a config file named name in dir and returns its
+// path.
+func writeConfig(t *testing.T, dir, name, data string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadYAMLConfig(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "logs", "api.log")
+	path := writeConfig(t, dir, "log.yaml", `
+level: info
+format: json
+tag: api
+file:
+  path: `+logPath+`
+  max_size_mb: 10
+  max_backups: 2
+tag_levels:
+  db: error
+redact: [password, token]
+`)
+	cfg, err := ezlog.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.File.MaxSizeMB != 10 || cfg.TagLevels["db"] != "error" || len(cfg.Redact) != 2 {
+		t.Errorf("config = %+v", cfg)
+	}
+
+	b := ezlog.New().AsLocal()
+	if err := cfg.ApplyTo(b); err != nil {
+		t.Fatal(err)
+	}
+	l, err := b.BuildE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Debug().Msg("hidden")
+	l.Info().Str("token", "s3cret").Msg("served")
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("log file not created: %v", err)
+	}
+	got := string(data)
+	if !strings.Contains(got, `"served"`) || !strings.Contains(got, `"token":"[REDACTED]"`) ||
+		strings.Contains(got, "hidden") || strings.Contains(got, "s3cret") {
+		t.Errorf("log file = %s", got)
+	}
+}
+
+func TestLoadYAMLConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name, data, want string
+	}{
+		{"unknown key", "levle: info\n", "field levle not found"},
+		{"invalid level", "level: verbose\n", `level: unknown level "verbose"`},
+		{"invalid format", "format: xml\n", `format: unknown format "xml"`},
+		{"syntax", "level: [info\n", "parse config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ezlog.LoadConfig(writeConfig(t, dir, "log.yml", tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadConfig() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadEmptyYAMLConfig(t *testing.T) {
+	cfg, err := ezlog.LoadConfig(writeConfig(t, t.TempDir(), "log.YAML", ""))
+	if err != nil || cfg.Level != "" || cfg.Format != "" {
+		t.Errorf("LoadConfig(empty) = %+v, %v", cfg, err)
+	}
+}
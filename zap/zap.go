@@ -0,0 +1,239 @@
+// Package zap bridges ezlog and zap in both directions, for code bases
+// moving from one to the other. It is a separate package to keep zap out of
+// programs that do not import it.
+//
+// The examples import it as ezzap, as the package shares its name with the
+// library it adapts:
+//
+//	import ezzap "github.com/ezydark/ezlog/zap"
+package zap
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ezydark/ezlog"
+	"github.com/rs/zerolog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ZapAdapter is an io.Writer re-emitting the JSON events of an ezlog logger
+// to a zap logger, so code migrated to zerolog keeps logging through the
+// zap setup of the rest of the program:
+//
+//	l := ezlog.New().AsLocal().WithWriter(ezzap.NewZapAdapter(z)).WithJSONOutput().Build()
+//
+// The level, time, message and caller become the zap entry and the other
+// fields zap fields. Fatal and panic events are written without exiting or
+// panicking, which zerolog takes care of. Lines that are not JSON are
+// logged at info level as the message.
+// It should be created using NewZapAdapter.
+type ZapAdapter struct {
+	core zapcore.Core
+	name string
+}
+
+// NewZapAdapter creates a ZapAdapter writing to z.
+func NewZapAdapter(z *zap.Logger) *ZapAdapter {
+	return &ZapAdapter{core: z.Core(), name: z.Name()}
+}
+
+// Write implements io.Writer.
+func (a *ZapAdapter) Write(p []byte) (int, error) {
+	return a.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (a *ZapAdapter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	entry := zapcore.Entry{LoggerName: a.name, Time: time.Now(), Level: zapcore.InfoLevel}
+
+	var evt map[string]any
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		entry.Message = string(bytes.TrimRight(p, "\n"))
+		return len(p), a.write(entry, nil)
+	}
+
+	if s, ok := evt[zerolog.LevelFieldName].(string); ok && level == zerolog.NoLevel {
+		if l, err := ezlog.ParseLevel(s); err == nil {
+			level = l
+		}
+	}
+	entry.Level = zapLevel(level)
+	if t, ok := parseEventTime(evt[zerolog.TimestampFieldName]); ok {
+		entry.Time = t
+	}
+	entry.Message, _ = evt[zerolog.MessageFieldName].(string)
+	if c, ok := evt[zerolog.CallerFieldName].(string); ok {
+		entry.Caller = parseZapCaller(c)
+	}
+	for _, k := range []string{zerolog.LevelFieldName, zerolog.TimestampFieldName, zerolog.MessageFieldName, zerolog.CallerFieldName} {
+		delete(evt, k)
+	}
+
+	fields := make([]zapcore.Field, 0, len(evt))
+	for k, v := range evt {
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				v = i
+			} else if f, err := n.Float64(); err == nil {
+				v = f
+			}
+		}
+		fields = append(fields, zap.Any(k, v))
+	}
+	return len(p), a.write(entry, fields)
+}
+
+// write writes entry to the core if its level is enabled.
+func (a *ZapAdapter) write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if !a.core.Enabled(entry.Level) {
+		return nil
+	}
+	return a.core.Write(entry, fields)
+}
+
+// Sync flushes the zap core.
+func (a *ZapAdapter) Sync() error {
+	return a.core.Sync()
+}
+
+// parseEventTime parses the timestamp of a decoded event, written either with
+// RFC 3339 or with zerolog.TimeFieldFormat by other zerolog loggers.
+func parseEventTime(v any) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{time.RFC3339Nano, zerolog.TimeFieldFormat} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseZapCaller parses a "file:line" caller.
+func parseZapCaller(c string) zapcore.EntryCaller {
+	if i := strings.LastIndexByte(c, ':'); i >= 0 {
+		if line, err := strconv.Atoi(c[i+1:]); err == nil {
+			return zapcore.EntryCaller{Defined: true, File: c[:i], Line: line}
+		}
+	}
+	return zapcore.EntryCaller{Defined: true, File: c}
+}
+
+// ZapCore is a zapcore.Core writing to a zerolog logger, for programs using
+// zap as their logging interface but wanting ezlog's console output:
+//
+//	z := zap.New(ezzap.NewZapCore(ezlog.New().Build()), zap.AddCaller())
+//
+// Entries are logged with WithLevel, so fatal and panic entries exit and
+// panic through zap only. Callers come from zap.AddCaller, so zl should not
+// be built WithCaller.
+// It should be created using NewZapCore.
+type ZapCore struct {
+	zl     *zerolog.Logger
+	fields []zapcore.Field
+}
+
+// NewZapCore creates a ZapCore writing to zl.
+func NewZapCore(zl *zerolog.Logger) *ZapCore {
+	return &ZapCore{zl: zl}
+}
+
+// Enabled implements zapcore.LevelEnabler.
+func (c *ZapCore) Enabled(level zapcore.Level) bool {
+	l := zerologLevel(level)
+	return l >= c.zl.GetLevel() && l >= zerolog.GlobalLevel()
+}
+
+// With implements zapcore.Core.
+func (c *ZapCore) With(fields []zapcore.Field) zapcore.Core {
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(all, c.fields...)
+	all = append(all, fields...)
+	return &ZapCore{zl: c.zl, fields: all}
+}
+
+// Check implements zapcore.Core.
+func (c *ZapCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write implements zapcore.Core.
+func (c *ZapCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	e := c.zl.WithLevel(zerologLevel(entry.Level))
+	if entry.LoggerName != "" {
+		e = e.Str("logger", entry.LoggerName)
+	}
+	if entry.Caller.Defined {
+		e = e.Str(zerolog.CallerFieldName, entry.Caller.String())
+	}
+	if entry.Stack != "" {
+		e = e.Str("stack", entry.Stack)
+	}
+	e.Fields(enc.Fields).Msg(entry.Message)
+	return nil
+}
+
+// Sync implements zapcore.Core.
+func (c *ZapCore) Sync() error {
+	return nil
+}
+
+// zapLevel returns the zap level of a zerolog level. Trace becomes debug.
+func zapLevel(level zerolog.Level) zapcore.Level {
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return zapcore.DebugLevel
+	case zerolog.WarnLevel:
+		return zapcore.WarnLevel
+	case zerolog.ErrorLevel:
+		return zapcore.ErrorLevel
+	case zerolog.FatalLevel:
+		return zapcore.FatalLevel
+	case zerolog.PanicLevel:
+		return zapcore.PanicLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
+
+// zerologLevel returns the zerolog level of a zap level. DPanic becomes
+// panic.
+func zerologLevel(level zapcore.Level) zerolog.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return zerolog.DebugLevel
+	case zapcore.WarnLevel:
+		return zerolog.WarnLevel
+	case zapcore.ErrorLevel:
+		return zerolog.ErrorLevel
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return zerolog.PanicLevel
+	case zapcore.FatalLevel:
+		return zerolog.FatalLevel
+	default:
+		if level < zapcore.DebugLevel {
+			return zerolog.TraceLevel
+		}
+		return zerolog.InfoLevel
+	}
+}
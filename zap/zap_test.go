@@ -0,0 +1,122 @@
+package zap
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ezydark/ezlog"
+	"github.com/rs/zerolog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestZapAdapter(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	ts := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+	l := ezlog.New().AsLocal().WithWriter(NewZapAdapter(zap.New(core).Named("svc"))).WithJSONOutput().
+		WithLevel(zerolog.DebugLevel).WithTimestampFunc(func() time.Time { return ts }).Build()
+
+	l.Debug().Msg("debug")
+	l.Warn().Str("user", "ada").Int("id", 7).Float64("ratio", 0.5).Msg("slow")
+	l.WithLevel(zerolog.FatalLevel).Msg("fatal")
+	l.WithLevel(zerolog.PanicLevel).Msg("panic")
+
+	entries := logs.AllUntimed()
+	wantLevels := []zapcore.Level{zapcore.DebugLevel, zapcore.WarnLevel, zapcore.FatalLevel, zapcore.PanicLevel}
+	if len(entries) != len(wantLevels) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(wantLevels))
+	}
+	for i, e := range entries {
+		if e.Level != wantLevels[i] {
+			t.Errorf("entry %q level = %v, want %v", e.Message, e.Level, wantLevels[i])
+		}
+	}
+
+	warn := logs.All()[1]
+	if warn.Message != "slow" || warn.LoggerName != "svc" || !warn.Time.Equal(ts) {
+		t.Errorf("entry = %+v", warn.Entry)
+	}
+	fields := warn.ContextMap()
+	if fields["user"] != "ada" || fields["id"] != int64(7) || fields["ratio"] != 0.5 || len(fields) != 3 {
+		t.Errorf("fields = %v", fields)
+	}
+}
+
+func TestZapAdapterCallerAndPlainLines(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	a := NewZapAdapter(zap.New(core))
+
+	a.Write([]byte(`{"level":"info","caller":"pkg/file.go:42","message":"called"}` + "\n"))
+	a.Write([]byte("not json\n"))
+	a.Write([]byte(`{"level":"debug","message":"disabled"}` + "\n"))
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if c := entries[0].Caller; !c.Defined || c.File != "pkg/file.go" || c.Line != 42 {
+		t.Errorf("caller = %+v", c)
+	}
+	if e := entries[1]; e.Message != "not json" || e.Level != zapcore.InfoLevel {
+		t.Errorf("plain line entry = %+v", e.Entry)
+	}
+}
+
+func TestZapCore(t *testing.T) {
+	var buf bytes.Buffer
+	zl := zerolog.New(&buf).Level(zerolog.InfoLevel)
+	z := zap.New(NewZapCore(&zl)).Named("svc").With(zap.String("region", "eu"))
+
+	z.Debug("dropped")
+	z.Warn("slow", zap.Int("n", 1))
+	if ce := z.Check(zapcore.DebugLevel, "dropped"); ce != nil {
+		t.Error("Check enabled a level below the zerolog logger's")
+	}
+
+	var evt map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &evt); err != nil {
+		t.Fatalf("output %q: %v", buf.String(), err)
+	}
+	want := map[string]any{"level": "warn", "logger": "svc", "region": "eu", "n": float64(1), "message": "slow"}
+	if len(evt) != len(want) {
+		t.Errorf("event = %v, want %v", evt, want)
+	}
+	for k, v := range want {
+		if evt[k] != v {
+			t.Errorf("event[%q] = %v, want %v", k, evt[k], v)
+		}
+	}
+}
+
+func TestLevelTranslation(t *testing.T) {
+	for _, tt := range []struct {
+		zap     zapcore.Level
+		zerolog zerolog.Level
+	}{
+		{zapcore.DebugLevel - 1, zerolog.TraceLevel},
+		{zapcore.DebugLevel, zerolog.DebugLevel},
+		{zapcore.InfoLevel, zerolog.InfoLevel},
+		{zapcore.WarnLevel, zerolog.WarnLevel},
+		{zapcore.ErrorLevel, zerolog.ErrorLevel},
+		{zapcore.DPanicLevel, zerolog.PanicLevel},
+		{zapcore.PanicLevel, zerolog.PanicLevel},
+		{zapcore.FatalLevel, zerolog.FatalLevel},
+	} {
+		if got := zerologLevel(tt.zap); got != tt.zerolog {
+			t.Errorf("zerologLevel(%v) = %v, want %v", tt.zap, got, tt.zerolog)
+		}
+		if tt.zap == zapcore.DPanicLevel {
+			continue
+		}
+		want := tt.zap
+		if want < zapcore.DebugLevel {
+			want = zapcore.DebugLevel
+		}
+		if got := zapLevel(tt.zerolog); got != want {
+			t.Errorf("zapLevel(%v) = %v, want %v", tt.zerolog, got, want)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// Package zstd implements ezlog.CompressionZstd. It is a separate package to
+// keep the zstd library out of programs that do not import it, which is all
+// it takes:
+//
+//	import _ "github.com/ezydark/ezlog/zstd"
+package zstd
+
+import (
+	"io"
+
+	"github.com/ezydark/ezlog"
+	"github.com/klauspost/compress/zstd"
+)
+
+func init() {
+	ezlog.RegisterCompression(ezlog.CompressionZstd, ".zst", newWriter)
+}
+
+// newWriter returns a writer compressing to w.
+func newWriter(w io.Writer) io.WriteCloser {
+	// NewWriter only fails on invalid options.
+	zw, _ := zstd.NewWriter(w)
+	return zw
+}
@@ -0,0 +1,60 @@
+package zstd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ezydark/ezlog"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestCompressionRoundTrip(t *testing.T) {
+	var out bytes.Buffer
+	w := ezlog.NewCompressedWriter(&out, ezlog.CompressionZstd)
+	w.Write([]byte(`{"level":"info","message":"compressed"}` + "\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zstd.NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `{"level":"info","message":"compressed"}`+"\n" {
+		t.Errorf("decompressed = %q", got)
+	}
+}
+
+func TestRotatingFileExtension(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	w := ezlog.NewRotatingFileWriter(dir, "app", "log", ezlog.DailyRotation(time.UTC), 0,
+		ezlog.WithRotationCompression(ezlog.CompressionZstd), ezlog.WithRotationClock(func() time.Time { return now }))
+	w.Write([]byte("event\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "app-2025-03-14.log.zst"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := zstd.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	if got, err := io.ReadAll(zr); err != nil || string(got) != "event\n" {
+		t.Errorf("decompressed = %q, %v", got, err)
+	}
+}